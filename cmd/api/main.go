@@ -5,20 +5,39 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"server/database"
 	"server/internal/api/handler"
+	"server/internal/cache"
+	"server/internal/domain"
+	"server/internal/events"
+	"server/internal/jobs"
 	"server/internal/logger"
+	"server/internal/mail"
 	"server/internal/repo"
 	"server/internal/server"
 	"server/internal/service"
 	"server/internal/session"
+	"server/internal/storage"
 	"server/internal/ws"
 )
 
+// offerExpiryInterval is how often open offers past their expiry are lapsed
+const offerExpiryInterval = time.Minute
+
+// digestInterval is how often due notification digests are looked for
+const digestInterval = 15 * time.Minute
+
+// webhookDeliveryInterval is how often queued webhook deliveries are sent
+const webhookDeliveryInterval = 5 * time.Second
+
+// outboxRelayInterval is how often recorded outbox events are relayed
+const outboxRelayInterval = time.Second
+
 func gracefulShutdown(apiServer *http.Server, done chan bool) {
 	// Create context that listens for the interrupt signal from the OS.
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
@@ -58,28 +77,171 @@ func main() {
 
 	// Setup repositories
 	userRepo := repo.NewUserRepository(dbService.DB())
-	listingRepo := repo.NewListingRepository(dbService.DB())
+	var listingRepo repo.IListingRepo = repo.NewListingRepository(dbService.DB())
 	favoriteRepo := repo.NewFavoriteRepo(dbService.DB())
 	notificationRepo := repo.NewNotificationRepository(dbService.DB())
+	notificationPreferenceRepo := repo.NewNotificationPreferenceRepository(dbService.DB())
+	marketRepo := repo.NewMarketRepository(dbService.DB())
+	mortgageRepo := repo.NewMortgageRepository(dbService.DB())
+	openHouseRepo := repo.NewOpenHouseRepository(dbService.DB())
+	showingRepo := repo.NewShowingRepository(dbService.DB())
+	availabilityRepo := repo.NewAvailabilityRepository(dbService.DB())
+	messageRepo := repo.NewMessageRepository(dbService.DB())
+	leadRepo := repo.NewLeadRepository(dbService.DB())
+	offerRepo := repo.NewOfferRepository(dbService.DB())
+	reviewRepo := repo.NewReviewRepository(dbService.DB())
+	var brokerageRepo repo.IBrokerageRepo = repo.NewBrokerageRepository(dbService.DB())
+	followRepo := repo.NewFollowRepository(dbService.DB())
+	webhookRepo := repo.NewWebhookRepository(dbService.DB())
+	outboxRepo := repo.NewOutboxRepository(dbService.DB())
+	passwordResetRepo := repo.NewPasswordResetRepository(dbService.DB())
+
+	// Wrap listing reads, including brokerage listings, in the Redis cache when enabled
+	var cachedListingRepo *repo.CachedListingRepo
+	if cacheConfig := cache.ConfigFromEnv(); cacheConfig.Enabled {
+		cachedListingRepo = repo.NewCachedListingRepo(
+			listingRepo,
+			cache.NewRedisStore(client),
+			cacheConfig.TTL,
+		)
+		listingRepo = cachedListingRepo
+		brokerageRepo = repo.NewCachedBrokerageRepo(brokerageRepo, cachedListingRepo)
+	}
+
+	// Domain events are published in-process to the subscribers registered below
+	bus := events.NewBus()
+
+	// Email is rendered in request handlers but delivered from a background queue
+	mailConfig := mail.ConfigFromEnv()
+	mailer, err := mail.NewMailer(mailConfig)
+	if err != nil {
+		panic(fmt.Sprintf("mail setup error: %s", err))
+	}
+	mailQueue := mail.NewQueue(mailer, mail.DefaultQueueSize)
 
 	// Setup services
+	emailService := service.NewEmailService(
+		mailQueue,
+		userRepo,
+		listingRepo,
+		notificationPreferenceRepo,
+		mailConfig.AppURL,
+	)
+	webhookService := service.NewWebhookService(webhookRepo, userRepo)
 	userService := service.NewUserService(userRepo)
-	authService := service.NewAuthService(userRepo, session)
-	listingService := service.NewListingService(listingRepo)
-	favoriteService := service.NewFavoriteService(favoriteRepo)
+	authService := service.NewAuthService(userRepo, passwordResetRepo, session, emailService)
+	favoriteService := service.NewFavoriteService(favoriteRepo, listingRepo, userRepo, bus)
+	notificationDispatcher := service.NewNotificationDispatcher(
+		notificationRepo,
+		notificationPreferenceRepo,
+	)
+	notificationDispatcher.RegisterSender(domain.NotificationChannelEmail, emailService)
+	notificationDispatcher.RegisterSender(domain.NotificationChannelWebhook, webhookService)
 	notificationService := service.NewNotificationService(
 		notificationRepo,
+		notificationPreferenceRepo,
 		favoriteRepo,
+		notificationDispatcher,
+	)
+	followService := service.NewFollowService(followRepo, userRepo, notificationService)
+	listingService := service.NewListingService(listingRepo, bus)
+
+	// Relay events recorded alongside listing and favorite changes onto the bus
+	outboxRelay := service.NewOutboxRelay(outboxRepo)
+	outboxRelay.Subscribe(domain.OutboxEventListingPriceChanged, bus.PublishOutboxEvent)
+	outboxRelay.Subscribe(domain.OutboxEventListingStatusChanged, bus.PublishOutboxEvent)
+	outboxRelay.Subscribe(domain.OutboxEventFavoriteCreated, bus.PublishOutboxEvent)
+
+	// Subscribers to an event run in the order they are registered. There is
+	// no analytics subscriber: brokerage analytics are computed from the
+	// listings and leads tables when they are read, so there is nothing to
+	// record from these events.
+	if cachedListingRepo != nil {
+		events.Subscribe(bus, "listing_cache", cachedListingRepo.OnListingPriceChanged)
+		events.Subscribe(bus, "listing_cache", cachedListingRepo.OnListingStatusChanged)
+	}
+	events.Subscribe(bus, "followers", followService.OnListingCreated)
+	events.Subscribe(bus, "notifications", notificationService.OnListingPriceChanged)
+	events.Subscribe(bus, "notifications", notificationService.OnListingStatusChanged)
+	events.Subscribe(bus, "notifications", notificationService.OnFavoriteAdded)
+	events.Subscribe(bus, "webhooks", webhookService.OnListingPriceChanged)
+	events.Subscribe(bus, "webhooks", webhookService.OnListingStatusChanged)
+	events.Subscribe(bus, "webhooks", webhookService.OnFavoriteAdded)
+
+	// Serve market stats from the materialized view when a refresh interval is set
+	marketRefreshInterval, _ := time.ParseDuration(os.Getenv("MARKET_STATS_REFRESH_INTERVAL"))
+	marketService := service.NewMarketService(marketRepo, marketRefreshInterval > 0)
+	valuationService := service.NewValuationService(listingRepo)
+	mortgageService := service.NewMortgageService(mortgageRepo)
+	openHouseService := service.NewOpenHouseService(
+		openHouseRepo,
 		listingRepo,
+		userRepo,
+		notificationService,
+	)
+	showingService := service.NewShowingService(
+		showingRepo,
+		listingRepo,
+		notificationService,
+		emailService,
+		webhookService,
+	)
+	availabilityService := service.NewAvailabilityService(
+		availabilityRepo,
+		showingRepo,
+		openHouseRepo,
+		listingRepo,
+		notificationService,
+	)
+	messageService := service.NewMessageService(messageRepo, listingRepo, notificationPreferenceRepo)
+	leadService := service.NewLeadService(
+		leadRepo,
+		listingRepo,
+		userRepo,
+		notificationService,
+		webhookService,
 	)
+	offerService := service.NewOfferService(
+		offerRepo,
+		listingRepo,
+		notificationService,
+		webhookService,
+	)
+	reviewService := service.NewReviewService(reviewRepo, userRepo)
+	uploadStore := storage.NewLocalStore(storage.ConfigFromEnv())
+	agentProfileService := service.NewAgentProfileService(userRepo, uploadStore)
+	brokerageService := service.NewBrokerageService(brokerageRepo, userRepo)
 
 	// Setup handlers
 	userHandler := handler.NewUserHandler(userService)
 	authHandler := handler.NewAuthHandler(authService)
-	listingHandler := handler.NewListingHandler(listingService, userService)
+	listingHandler := handler.NewListingHandler(
+		listingService,
+		userService,
+		mortgageService,
+		openHouseService,
+		favoriteService,
+	)
 	favoriteHandler := handler.NewFavoriteHandler(favoriteService)
 	notificationHandler := handler.NewNotificationHandler(notificationService)
-	wsManager := ws.NewManager(notificationService)
+	marketHandler := handler.NewMarketHandler(marketService)
+	valuationHandler := handler.NewValuationHandler(valuationService)
+	mortgageHandler := handler.NewMortgageHandler(mortgageService)
+	openHouseHandler := handler.NewOpenHouseHandler(openHouseService)
+	showingHandler := handler.NewShowingHandler(showingService)
+	availabilityHandler := handler.NewAvailabilityHandler(availabilityService)
+	messageHandler := handler.NewMessageHandler(messageService)
+	leadHandler := handler.NewLeadHandler(leadService)
+	offerHandler := handler.NewOfferHandler(offerService)
+	reviewHandler := handler.NewReviewHandler(reviewService)
+	agentProfileHandler := handler.NewAgentProfileHandler(agentProfileService)
+	brokerageHandler := handler.NewBrokerageHandler(brokerageService)
+	followHandler := handler.NewFollowHandler(followService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	wsManager := ws.NewManager()
+	wsManager.Subscribe(bus)
+	notificationDispatcher.SetPusher(wsManager)
+	messageService.SetPusher(wsManager)
 
 	server := server.NewServer(
 		dbService,
@@ -91,9 +253,44 @@ func main() {
 		listingHandler,
 		favoriteHandler,
 		notificationHandler,
+		marketHandler,
+		valuationHandler,
+		mortgageHandler,
+		openHouseHandler,
+		showingHandler,
+		availabilityHandler,
+		messageHandler,
+		leadHandler,
+		offerHandler,
+		reviewHandler,
+		agentProfileHandler,
+		brokerageHandler,
+		followHandler,
+		webhookHandler,
+		uploadStore.Path(),
+		uploadStore.Handler(),
 		wsManager,
 	)
 
+	// Background jobs run until the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+
+	go mailQueue.Run(jobsCtx, mail.DefaultQueueWorkers)
+
+	if marketRefreshInterval > 0 {
+		go jobs.Every(
+			jobsCtx,
+			"refresh_market_stats",
+			marketRefreshInterval,
+			marketService.RefreshMarketStats,
+		)
+	}
+
+	go jobs.Every(jobsCtx, "expire_offers", offerExpiryInterval, offerService.ExpireOffers)
+	go jobs.Every(jobsCtx, "send_notification_digests", digestInterval, emailService.SendDigests)
+	go jobs.Every(jobsCtx, "deliver_webhooks", webhookDeliveryInterval, webhookService.DeliverDue)
+	go jobs.Every(jobsCtx, "relay_outbox", outboxRelayInterval, outboxRelay.RelayPending)
+
 	// Create a done channel to signal when the shutdown is complete
 	done := make(chan bool, 1)
 
@@ -102,12 +299,13 @@ func main() {
 
 	slog.Info("Server starting up...", slog.String("addr", server.Addr))
 
-	err := server.ListenAndServe()
+	err = server.ListenAndServe()
 	if err != nil && err != http.ErrServerClosed {
 		panic(fmt.Sprintf("http server error: %s", err))
 	}
 
 	// Wait for the graceful shutdown to complete
 	<-done
+	stopJobs()
 	slog.Info("Graceful shutdown complete.")
 }
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE listings
+    ADD COLUMN city TEXT NOT NULL DEFAULT '',
+    ADD COLUMN state TEXT NOT NULL DEFAULT '',
+    ADD COLUMN zip_code TEXT NOT NULL DEFAULT '',
+    ADD COLUMN property_type TEXT NOT NULL DEFAULT 'single_family',
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'active',
+    ADD COLUMN sold_at TIMESTAMPTZ;
+
+ALTER TABLE listings
+ADD CONSTRAINT chk_property_type
+CHECK (property_type IN ('single_family', 'condo', 'townhouse', 'multi_family', 'land'));
+
+ALTER TABLE listings
+ADD CONSTRAINT chk_status
+CHECK (status IN ('active', 'pending', 'sold', 'withdrawn'));
+
+-- indexes
+CREATE INDEX idx_listings_city ON listings(LOWER(city));
+CREATE INDEX idx_listings_zip_code ON listings(zip_code);
+CREATE INDEX idx_listings_status ON listings(status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE listings
+    DROP COLUMN city,
+    DROP COLUMN state,
+    DROP COLUMN zip_code,
+    DROP COLUMN property_type,
+    DROP COLUMN status,
+    DROP COLUMN sold_at;
+-- +goose StatementEnd
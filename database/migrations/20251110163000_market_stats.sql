@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW market_stats AS
+SELECT
+    GROUPING(LOWER(city), zip_code, property_type) AS grouping_id,
+    COALESCE(LOWER(city), '') AS city_key,
+    COALESCE(zip_code, '') AS zip_key,
+    COALESCE(property_type, '') AS property_type_key,
+    COALESCE(
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY price::FLOAT8)
+            FILTER (WHERE status = 'active'),
+        0
+    ) AS median_list_price,
+    COALESCE(AVG(price) FILTER (WHERE status = 'active'), 0)::FLOAT8 AS average_list_price,
+    COALESCE(
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY price::FLOAT8 / sq_ft)
+            FILTER (WHERE status = 'active'),
+        0
+    ) AS median_price_per_sq_ft,
+    COUNT(*) FILTER (WHERE status = 'active') AS active_inventory,
+    COALESCE(
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY (EXTRACT(EPOCH FROM NOW() - created_at) / 86400)::FLOAT8)
+            FILTER (WHERE status = 'active'),
+        0
+    ) AS median_days_on_market,
+    COALESCE(
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY price::FLOAT8)
+            FILTER (WHERE created_at >= date_trunc('month', NOW())),
+        0
+    ) AS current_month_median_price,
+    COALESCE(
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY price::FLOAT8)
+            FILTER (
+                WHERE created_at >= date_trunc('month', NOW()) - INTERVAL '1 month'
+                AND created_at < date_trunc('month', NOW())
+            ),
+        0
+    ) AS previous_month_median_price,
+    COUNT(*) FILTER (WHERE created_at >= date_trunc('month', NOW())) AS current_month_new_listings,
+    COUNT(*) FILTER (
+        WHERE created_at >= date_trunc('month', NOW()) - INTERVAL '1 month'
+        AND created_at < date_trunc('month', NOW())
+    ) AS previous_month_new_listings,
+    NOW() AS refreshed_at
+FROM listings
+GROUP BY CUBE (LOWER(city), zip_code, property_type);
+
+-- indexes
+CREATE UNIQUE INDEX idx_market_stats_key
+ON market_stats(grouping_id, city_key, zip_key, property_type_key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP MATERIALIZED VIEW IF EXISTS market_stats;
+-- +goose StatementEnd
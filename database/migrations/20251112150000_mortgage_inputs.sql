@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE listings
+    ADD COLUMN hoa_monthly INT NOT NULL DEFAULT 0 CHECK (hoa_monthly >= 0),
+    ADD COLUMN annual_property_tax INT CHECK (annual_property_tax >= 0);
+
+CREATE TABLE mortgage_preferences (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    down_payment_pct NUMERIC(5, 2) NOT NULL CHECK (down_payment_pct BETWEEN 0 AND 100),
+    interest_rate NUMERIC(5, 3) NOT NULL CHECK (interest_rate BETWEEN 0 AND 30),
+    term_years INT NOT NULL CHECK (term_years BETWEEN 1 AND 40),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS mortgage_preferences;
+
+ALTER TABLE listings
+    DROP COLUMN hoa_monthly,
+    DROP COLUMN annual_property_tax;
+-- +goose StatementEnd
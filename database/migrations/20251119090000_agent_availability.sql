@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+
+-- Weekly rules are wall-clock times in the agent's timezone
+CREATE TABLE agent_availability_rules (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    agent_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    CHECK (end_time > start_time)
+);
+
+CREATE TABLE agent_blackouts (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    agent_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+-- indexes
+CREATE INDEX idx_agent_availability_rules_agent_id ON agent_availability_rules(agent_id);
+CREATE INDEX idx_agent_blackouts_agent_id_end_date ON agent_blackouts(agent_id, end_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS agent_blackouts;
+DROP TABLE IF EXISTS agent_availability_rules;
+
+ALTER TABLE users
+DROP COLUMN timezone;
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE leads (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    listing_id BIGINT NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+    agent_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    phone TEXT,
+    message TEXT NOT NULL,
+    preferred_contact TEXT NOT NULL DEFAULT 'email'
+        CHECK (preferred_contact IN ('email', 'phone', 'text')),
+    status TEXT NOT NULL DEFAULT 'new'
+        CHECK (status IN ('new', 'contacted', 'qualified', 'lost')),
+    ip_address TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE lead_notes (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    lead_id BIGINT NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+    author_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- assigned_by is NULL for the automatic assignment to the listing agent
+CREATE TABLE lead_assignments (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    lead_id BIGINT NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+    agent_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    assigned_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- indexes
+CREATE INDEX idx_leads_agent_id_status ON leads(agent_id, status);
+CREATE INDEX idx_leads_ip_address_created_at ON leads(ip_address, created_at);
+CREATE INDEX idx_leads_email_created_at ON leads(LOWER(email), created_at);
+CREATE INDEX idx_lead_notes_lead_id ON lead_notes(lead_id);
+CREATE INDEX idx_lead_assignments_lead_id ON lead_assignments(lead_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS lead_assignments;
+DROP TABLE IF EXISTS lead_notes;
+DROP TABLE IF EXISTS leads;
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE offers (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    listing_id BIGINT NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+    buyer_agent_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    listing_agent_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'countered', 'accepted', 'rejected', 'withdrawn', 'expired')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Every submission and counter is kept; the latest revision holds the live terms
+CREATE TABLE offer_revisions (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    offer_id BIGINT NOT NULL REFERENCES offers(id) ON DELETE CASCADE,
+    author_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INT NOT NULL CHECK (amount > 0),
+    earnest_money INT NOT NULL CHECK (earnest_money >= 0),
+    contingencies TEXT[] NOT NULL DEFAULT '{}',
+    closing_date DATE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- indexes
+CREATE INDEX idx_offers_listing_id ON offers(listing_id);
+CREATE INDEX idx_offers_buyer_agent_id ON offers(buyer_agent_id);
+CREATE INDEX idx_offers_listing_agent_id ON offers(listing_agent_id);
+CREATE INDEX idx_offers_open_expires_at ON offers(expires_at)
+    WHERE status IN ('pending', 'countered');
+CREATE UNIQUE INDEX idx_offers_one_accepted_per_listing ON offers(listing_id)
+    WHERE status = 'accepted';
+CREATE INDEX idx_offer_revisions_offer_id ON offer_revisions(offer_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS offer_revisions;
+DROP TABLE IF EXISTS offers;
+-- +goose StatementEnd
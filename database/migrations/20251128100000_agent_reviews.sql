@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE agent_reviews (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    agent_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reviewer_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body TEXT,
+    status TEXT NOT NULL DEFAULT 'published'
+        CHECK (status IN ('published', 'hidden')),
+    reply TEXT,
+    replied_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (agent_id, reviewer_id)
+);
+
+-- indexes
+CREATE INDEX idx_agent_reviews_agent_id_status ON agent_reviews(agent_id, status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS agent_reviews;
+-- +goose StatementEnd
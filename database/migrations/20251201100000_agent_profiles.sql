@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE agent_profiles (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    bio TEXT,
+    phone TEXT,
+    license_number TEXT,
+    license_state TEXT,
+    brokerage_name TEXT,
+    headshot_url TEXT,
+    languages TEXT[] NOT NULL DEFAULT '{}',
+    specialties TEXT[] NOT NULL DEFAULT '{}',
+    service_zip_codes TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- indexes
+CREATE INDEX idx_agent_profiles_languages ON agent_profiles USING GIN (languages);
+CREATE INDEX idx_agent_profiles_specialties ON agent_profiles USING GIN (specialties);
+CREATE INDEX idx_agent_profiles_service_zip_codes ON agent_profiles USING GIN (service_zip_codes);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS agent_profiles;
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE brokerages (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    logo_url TEXT,
+    primary_color TEXT CHECK (primary_color ~ '^#[0-9A-Fa-f]{6}$'),
+    website_url TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE teams (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    brokerage_id BIGINT NOT NULL REFERENCES brokerages(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (brokerage_id, name)
+);
+
+ALTER TABLE users
+ADD COLUMN brokerage_id BIGINT REFERENCES brokerages(id) ON DELETE SET NULL,
+ADD COLUMN team_id BIGINT REFERENCES teams(id) ON DELETE SET NULL;
+
+ALTER TABLE users
+DROP CONSTRAINT chk_role;
+
+ALTER TABLE users
+ADD CONSTRAINT chk_role
+CHECK (role IN ('user', 'agent', 'broker', 'admin'));
+
+-- indexes
+CREATE INDEX idx_teams_brokerage_id ON teams(brokerage_id);
+CREATE INDEX idx_users_brokerage_id ON users(brokerage_id);
+CREATE INDEX idx_users_team_id ON users(team_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE users SET role = 'agent' WHERE role = 'broker';
+
+ALTER TABLE users
+DROP CONSTRAINT chk_role;
+
+ALTER TABLE users
+ADD CONSTRAINT chk_role
+CHECK (role IN ('user', 'agent', 'admin'));
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS team_id,
+DROP COLUMN IF EXISTS brokerage_id;
+
+DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS brokerages;
+-- +goose StatementEnd
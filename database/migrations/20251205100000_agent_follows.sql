@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE agent_follows (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    agent_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, agent_id),
+    CHECK (user_id <> agent_id)
+);
+
+-- indexes
+-- Fan-out pages through an agent's followers in user_id order
+CREATE INDEX idx_agent_follows_agent_id_user_id ON agent_follows(agent_id, user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS agent_follows;
+-- +goose StatementEnd
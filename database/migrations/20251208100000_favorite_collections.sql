@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE favorites
+ADD COLUMN note TEXT;
+
+CREATE TABLE favorite_collections (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    share_token TEXT UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
+
+-- A saved listing can sit in any number of the owner's collections
+CREATE TABLE favorite_collection_items (
+    collection_id BIGINT NOT NULL REFERENCES favorite_collections(id) ON DELETE CASCADE,
+    favorite_id BIGINT NOT NULL REFERENCES favorites(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (collection_id, favorite_id)
+);
+
+-- indexes
+CREATE UNIQUE INDEX idx_favorite_collections_one_default ON favorite_collections(user_id)
+    WHERE is_default;
+CREATE INDEX idx_favorite_collection_items_favorite_id ON favorite_collection_items(favorite_id);
+
+-- Existing favorites start out in each user's default collection
+INSERT INTO favorite_collections (user_id, name, is_default)
+SELECT DISTINCT user_id, 'Saved', TRUE
+FROM favorites;
+
+INSERT INTO favorite_collection_items (collection_id, favorite_id, created_at)
+SELECT favorite_collections.id, favorites.id, favorites.created_at
+FROM favorites
+INNER JOIN favorite_collections
+    ON favorite_collections.user_id = favorites.user_id AND favorite_collections.is_default;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS favorite_collection_items;
+DROP TABLE IF EXISTS favorite_collections;
+
+ALTER TABLE favorites
+DROP COLUMN IF EXISTS note;
+-- +goose StatementEnd
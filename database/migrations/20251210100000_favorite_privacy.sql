@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Listing agents only see who saved their listing when the buyer opts in
+ALTER TABLE users
+ADD COLUMN share_favorites_with_agents BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- indexes
+CREATE INDEX idx_favorites_user_id_created_at ON favorites(user_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_favorites_user_id_created_at;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS share_favorites_with_agents;
+-- +goose StatementEnd
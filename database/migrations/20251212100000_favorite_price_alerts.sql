@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-favorite price alerts. alert_base_price is the listing price when the
+-- alert was set and is what alert_drop_percent is measured against.
+ALTER TABLE favorites
+ADD COLUMN alert_below_price INTEGER CHECK (alert_below_price > 0),
+ADD COLUMN alert_drop_percent NUMERIC(5, 2) CHECK (alert_drop_percent > 0 AND alert_drop_percent < 100),
+ADD COLUMN alert_base_price INTEGER;
+
+-- indexes
+CREATE INDEX idx_favorites_listing_id_alerts ON favorites(listing_id)
+WHERE alert_below_price IS NOT NULL OR alert_drop_percent IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_favorites_listing_id_alerts;
+
+ALTER TABLE favorites
+DROP COLUMN IF EXISTS alert_base_price,
+DROP COLUMN IF EXISTS alert_drop_percent,
+DROP COLUMN IF EXISTS alert_below_price;
+-- +goose StatementEnd
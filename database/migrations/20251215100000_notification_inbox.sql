@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Newest-first inbox pages walk this index backwards from the cursor
+CREATE INDEX idx_notifications_user_id_id ON notifications(user_id, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notifications_user_id_id;
+-- +goose StatementEnd
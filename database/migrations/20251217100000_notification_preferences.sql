@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Quiet hours are in users.timezone and may wrap past midnight
+CREATE TABLE notification_settings (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    quiet_hours_start TIME,
+    quiet_hours_end TIME,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_quiet_hours CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL))
+);
+
+-- Missing rows fall back to the default channels for the type
+CREATE TABLE notification_preferences (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    in_app BOOLEAN NOT NULL,
+    push BOOLEAN NOT NULL,
+    email BOOLEAN NOT NULL,
+    webhook BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, type)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notification_settings;
+-- +goose StatementEnd
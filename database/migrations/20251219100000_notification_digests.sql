@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- last_digest_at claims a digest period so a rerun of the job sends nothing
+ALTER TABLE notification_settings
+    ADD COLUMN digest TEXT NOT NULL DEFAULT 'off',
+    ADD COLUMN last_digest_at TIMESTAMPTZ,
+    ADD CONSTRAINT chk_digest CHECK (digest IN ('off', 'daily', 'weekly'));
+
+-- Set once a notification has been included in a digest email
+ALTER TABLE notifications
+    ADD COLUMN digested_at TIMESTAMPTZ;
+
+-- indexes
+CREATE INDEX idx_notifications_user_id_undigested
+    ON notifications (user_id)
+    WHERE is_read = FALSE AND digested_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notifications_user_id_undigested;
+ALTER TABLE notifications DROP COLUMN IF EXISTS digested_at;
+ALTER TABLE notification_settings
+    DROP CONSTRAINT IF EXISTS chk_digest,
+    DROP COLUMN IF EXISTS last_digest_at,
+    DROP COLUMN IF EXISTS digest;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A subscription belongs to one agent or to a whole brokerage
+CREATE TABLE webhook_subscriptions (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    agent_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    brokerage_id BIGINT REFERENCES brokerages(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    secret TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_webhook_owner CHECK ((agent_id IS NULL) <> (brokerage_id IS NULL))
+);
+
+-- One row per event per subscription, doubling as the delivery log
+CREATE TABLE webhook_deliveries (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_status_code INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_webhook_delivery_status CHECK (status IN ('pending', 'delivered', 'dead_letter'))
+);
+
+-- indexes
+CREATE INDEX idx_webhook_subscriptions_agent_id ON webhook_subscriptions (agent_id);
+CREATE INDEX idx_webhook_subscriptions_brokerage_id ON webhook_subscriptions (brokerage_id);
+CREATE INDEX idx_webhook_deliveries_subscription_id_id ON webhook_deliveries (subscription_id, id);
+CREATE INDEX idx_webhook_deliveries_pending
+    ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Domain events written in the same transaction as the change that raised
+-- them and published afterwards by the outbox relay
+CREATE TABLE outbox_events (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    dedup_key TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_outbox_event_status CHECK (status IN ('pending', 'published', 'failed'))
+);
+
+-- The relay delivers at least once; consumers drop repeats by dedup key
+ALTER TABLE notifications
+    ADD COLUMN dedup_key TEXT;
+
+ALTER TABLE webhook_deliveries
+    ADD COLUMN dedup_key TEXT;
+
+-- indexes
+CREATE UNIQUE INDEX idx_outbox_events_dedup_key ON outbox_events (dedup_key);
+CREATE INDEX idx_outbox_events_pending
+    ON outbox_events (next_attempt_at)
+    WHERE status = 'pending';
+CREATE UNIQUE INDEX idx_notifications_user_id_dedup_key
+    ON notifications (user_id, dedup_key);
+CREATE UNIQUE INDEX idx_webhook_deliveries_subscription_id_dedup_key
+    ON webhook_deliveries (subscription_id, dedup_key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_webhook_deliveries_subscription_id_dedup_key;
+DROP INDEX IF EXISTS idx_notifications_user_id_dedup_key;
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS dedup_key;
+ALTER TABLE notifications DROP COLUMN IF EXISTS dedup_key;
+DROP TABLE IF EXISTS outbox_events;
+-- +goose StatementEnd
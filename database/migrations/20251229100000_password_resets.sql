@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Only a hash of each token is stored; the token itself is only ever in the
+-- email sent to the user
+CREATE TABLE password_reset_tokens (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- indexes
+CREATE UNIQUE INDEX idx_password_reset_tokens_token_hash ON password_reset_tokens (token_hash);
+CREATE INDEX idx_password_reset_tokens_user_id ON password_reset_tokens (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_password_reset_tokens_user_id;
+DROP INDEX IF EXISTS idx_password_reset_tokens_token_hash;
+DROP TABLE IF EXISTS password_reset_tokens;
+-- +goose StatementEnd
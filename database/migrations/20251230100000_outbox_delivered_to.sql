@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Bus subscribers that have handled the event, so a retry reruns only the
+-- ones that failed
+ALTER TABLE outbox_events
+    ADD COLUMN delivered_to TEXT[] NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS delivered_to;
+-- +goose StatementEnd
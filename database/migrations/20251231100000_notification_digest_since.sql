@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- When the current digest mode was turned on. Digests only cover
+-- notifications created since, so the first one is not the whole inbox.
+ALTER TABLE notification_settings
+    ADD COLUMN digest_since TIMESTAMPTZ;
+
+UPDATE notification_settings
+SET digest_since = COALESCE(last_digest_at, NOW())
+WHERE digest <> 'off';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notification_settings DROP COLUMN IF EXISTS digest_since;
+-- +goose StatementEnd
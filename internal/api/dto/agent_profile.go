@@ -0,0 +1,14 @@
+package dto
+
+// UpdateAgentProfileRequest only changes the fields that are set. Send an
+// empty string or list to clear a field.
+type UpdateAgentProfileRequest struct {
+	Bio             *string   `json:"bio"`
+	Phone           *string   `json:"phone"`
+	LicenseNumber   *string   `json:"license_number"`
+	LicenseState    *string   `json:"license_state"`
+	BrokerageName   *string   `json:"brokerage_name"`
+	Languages       *[]string `json:"languages"`
+	Specialties     *[]string `json:"specialties"`
+	ServiceZipCodes *[]string `json:"service_zip_codes"`
+}
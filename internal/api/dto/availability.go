@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"time"
+
+	"server/internal/domain"
+)
+
+type UpdateAvailabilityRequest struct {
+	Timezone string                    `json:"timezone"`
+	Rules    []domain.AvailabilityRule `json:"rules"`
+}
+
+type CreateBlackoutRequest struct {
+	StartDate string  `json:"start_date"`
+	EndDate   string  `json:"end_date"`
+	Reason    *string `json:"reason"`
+}
+
+type BookShowingRequest struct {
+	StartTime       time.Time `json:"start_time"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Message         *string   `json:"message"`
+}
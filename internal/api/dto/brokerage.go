@@ -0,0 +1,25 @@
+package dto
+
+type CreateBrokerageRequest struct {
+	Name         string  `json:"name"`
+	LogoURL      *string `json:"logo_url"`
+	PrimaryColor *string `json:"primary_color"`
+	WebsiteURL   *string `json:"website_url"`
+}
+
+// UpdateBrokerageRequest only changes the fields that are set. An empty string
+// clears an optional field.
+type UpdateBrokerageRequest struct {
+	Name         *string `json:"name"`
+	LogoURL      *string `json:"logo_url"`
+	PrimaryColor *string `json:"primary_color"`
+	WebsiteURL   *string `json:"website_url"`
+}
+
+type CreateTeamRequest struct {
+	Name string `json:"name"`
+}
+
+type SetMembershipRequest struct {
+	TeamID *int `json:"team_id"`
+}
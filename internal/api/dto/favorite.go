@@ -1,5 +1,19 @@
 package dto
 
 type FavoriteListingDto struct {
-	ListingID int `json:"listing_id"`
+	ListingID int     `json:"listing_id"`
+	Note      *string `json:"note"`
+}
+
+type UpdateFavoriteNoteRequest struct {
+	Note string `json:"note"`
+}
+
+type PriceAlertRequest struct {
+	BelowPrice  *int     `json:"alert_below_price"`
+	DropPercent *float64 `json:"alert_drop_percent"`
+}
+
+type FavoriteCollectionRequest struct {
+	Name string `json:"name"`
 }
@@ -0,0 +1,24 @@
+package dto
+
+type CreateInquiryRequest struct {
+	Name             string  `json:"name"`
+	Email            string  `json:"email"`
+	Phone            *string `json:"phone"`
+	Message          string  `json:"message"`
+	PreferredContact string  `json:"preferred_contact"`
+	// Website is a honeypot. It is hidden from real visitors, so any value
+	// means the form was filled in by a bot.
+	Website string `json:"website"`
+}
+
+type UpdateLeadRequest struct {
+	Status string `json:"status"`
+}
+
+type CreateLeadNoteRequest struct {
+	Body string `json:"body"`
+}
+
+type AssignLeadRequest struct {
+	AgentID int `json:"agent_id"`
+}
@@ -1,21 +1,34 @@
 package dto
 
 type CreateListingRequest struct {
-	Address     string  `json:"address"`
-	Price       int     `json:"price"`
-	Beds        int     `json:"beds"`
-	Baths       int     `json:"baths"`
-	SqFt        int     `json:"sq_ft"`
-	Description *string `json:"description"`
-	AgentID     *int    `json:"agent_id"`
+	Address      string  `json:"address"`
+	City         string  `json:"city"`
+	State        string  `json:"state"`
+	ZipCode      string  `json:"zip_code"`
+	PropertyType string  `json:"property_type"`
+	Price        int     `json:"price"`
+	Beds         int     `json:"beds"`
+	Baths        int     `json:"baths"`
+	SqFt         int     `json:"sq_ft"`
+	Description  *string `json:"description"`
+	HOAMonthly   int     `json:"hoa_monthly"`
+	AnnualTax    *int    `json:"annual_property_tax"`
+	AgentID      *int    `json:"agent_id"`
 }
 
 type UpdateListingRequest struct {
-	Address     *string `json:"address"`
-	Price       *int    `json:"price"`
-	Beds        *int    `json:"beds"`
-	Baths       *int    `json:"baths"`
-	SqFt        *int    `json:"sq_ft"`
-	Description *string `json:"description"`
-	AgentID     *int    `json:"agent_id"`
+	Address      *string `json:"address"`
+	City         *string `json:"city"`
+	State        *string `json:"state"`
+	ZipCode      *string `json:"zip_code"`
+	PropertyType *string `json:"property_type"`
+	Status       *string `json:"status"`
+	Price        *int    `json:"price"`
+	Beds         *int    `json:"beds"`
+	Baths        *int    `json:"baths"`
+	SqFt         *int    `json:"sq_ft"`
+	Description  *string `json:"description"`
+	HOAMonthly   *int    `json:"hoa_monthly"`
+	AnnualTax    *int    `json:"annual_property_tax"`
+	AgentID      *int    `json:"agent_id"`
 }
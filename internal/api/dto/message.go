@@ -0,0 +1,5 @@
+package dto
+
+type SendMessageRequest struct {
+	Body string `json:"body"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+type MortgageTermsRequest struct {
+	DownPaymentPct *float64 `json:"down_payment_pct"`
+	InterestRate   *float64 `json:"interest_rate"`
+	TermYears      *int     `json:"term_years"`
+}
+
+type MortgageCalculatorRequest struct {
+	MortgageTermsRequest
+	Price             int      `json:"price"`
+	AnnualPropertyTax *float64 `json:"annual_property_tax"`
+	AnnualInsurance   *float64 `json:"annual_insurance"`
+	HOAMonthly        float64  `json:"hoa_monthly"`
+	IncludeSchedule   bool     `json:"include_schedule"`
+}
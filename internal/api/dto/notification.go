@@ -1,7 +1,25 @@
 package dto
 
+import "server/internal/domain"
+
 type CreateNotificationRequest struct {
+	UserID    int    `json:"user_id"`
 	ListingID int    `json:"listing_id"`
 	Type      string `json:"type"`
 	Message   string `json:"message"`
 }
+
+type UpdateNotificationRequest struct {
+	IsRead *bool `json:"is_read"`
+}
+
+type UnreadCountResponse struct {
+	UnreadCount int `json:"unread_count"`
+}
+
+type UpdateNotificationSettingsRequest struct {
+	Timezone    string                          `json:"timezone"`
+	QuietHours  *domain.QuietHours              `json:"quiet_hours"`
+	Digest      string                          `json:"digest"`
+	Preferences []domain.NotificationPreference `json:"preferences"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+// OfferTermsRequest is used both to submit an offer and to counter one.
+type OfferTermsRequest struct {
+	Amount        int       `json:"amount"`
+	EarnestMoney  int       `json:"earnest_money"`
+	Contingencies []string  `json:"contingencies"`
+	ClosingDate   string    `json:"closing_date"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	Message       *string   `json:"message"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type CreateOpenHouseRequest struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Notes     *string   `json:"notes"`
+}
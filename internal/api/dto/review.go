@@ -0,0 +1,14 @@
+package dto
+
+type CreateReviewRequest struct {
+	Rating int     `json:"rating"`
+	Body   *string `json:"body"`
+}
+
+type ReplyToReviewRequest struct {
+	Reply string `json:"reply"`
+}
+
+type ModerateReviewRequest struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+type ShowingSlotRequest struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+type CreateShowingRequest struct {
+	Slots   []ShowingSlotRequest `json:"slots"`
+	Message *string              `json:"message"`
+}
+
+type AcceptShowingRequest struct {
+	SlotID int `json:"slot_id"`
+}
@@ -13,6 +13,8 @@ type UpdateUserRequest struct {
 	LastName  *string `json:"last_name"`
 	Email     *string `json:"email"`
 	Role      *string `json:"role"`
+
+	ShareFavoritesWithAgents *bool `json:"share_favorites_with_agents"`
 }
 
 type LoginUserRequest struct {
@@ -20,6 +22,15 @@ type LoginUserRequest struct {
 	Password string `json:"password"`
 }
 
+type PasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+type ConfirmPasswordResetRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
 type LoginUserResponse struct {
 	ID        int    `json:"id"`
 	FirstName string `json:"first_name"`
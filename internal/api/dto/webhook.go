@@ -0,0 +1,16 @@
+package dto
+
+// CreateWebhookRequest subscribes the current agent, or the brokerage when
+// BrokerageID is set. A secret is generated when none is given.
+type CreateWebhookRequest struct {
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"event_types"`
+	Secret      string   `json:"secret"`
+	BrokerageID *int     `json:"brokerage_id"`
+}
+
+type UpdateWebhookRequest struct {
+	URL        *string  `json:"url"`
+	EventTypes []string `json:"event_types"`
+	IsActive   *bool    `json:"is_active"`
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/server/middleware"
+	"server/internal/service"
+	"server/util"
+)
+
+// multipartOverhead leaves room for the form boundaries and headers around
+// the headshot itself.
+const multipartOverhead = 64 << 10
+
+type AgentProfileHandler struct {
+	agentProfileService *service.AgentProfileService
+}
+
+func NewAgentProfileHandler(agentProfileService *service.AgentProfileService) *AgentProfileHandler {
+	return &AgentProfileHandler{agentProfileService: agentProfileService}
+}
+
+func (h *AgentProfileHandler) GetMyProfile(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	agent, err := h.agentProfileService.GetMyProfile(r.Context(), currentAgentCtx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "Agent profile could not be found")
+			return
+		}
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch profile")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, agent)
+}
+
+func (h *AgentProfileHandler) UpdateMyProfile(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	var req dto.UpdateAgentProfileRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid fields")
+		return
+	}
+
+	agent, err := h.agentProfileService.UpdateMyProfile(r.Context(), &req, currentAgentCtx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "Agent profile could not be found")
+			return
+		}
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, agent)
+}
+
+// UploadHeadshot expects a multipart form with the image in the headshot field.
+func (h *AgentProfileHandler) UploadHeadshot(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxHeadshotSizeBytes+multipartOverhead)
+
+	file, header, err := r.FormFile("headshot")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			util.RespondWithError(w, http.StatusRequestEntityTooLarge, "Headshot cannot be larger than 5MB")
+			return
+		}
+		util.RespondWithError(w, http.StatusBadRequest, "Please upload a headshot image")
+		return
+	}
+
+	defer file.Close()
+
+	if header.Size > service.MaxHeadshotSizeBytes {
+		util.RespondWithError(w, http.StatusRequestEntityTooLarge, "Headshot cannot be larger than 5MB")
+		return
+	}
+
+	agent, err := h.agentProfileService.UploadHeadshot(r.Context(), currentAgentCtx, file)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, agent)
+}
@@ -95,3 +95,47 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 
 	util.WriteJSON(w, http.StatusOK, map[string]string{"message": "Successfully logged out"})
 }
+
+func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req dto.PasswordResetRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid payload")
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(r.Context(), &req); err != nil {
+		if service.IsValidationError(err) {
+			util.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.Error("Request Password Reset - Service Error", slog.String("error", err.Error()))
+		util.RespondWithError(w, http.StatusInternalServerError, "Error requesting password reset")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusAccepted, map[string]string{
+		"message": "If an account exists for that email, a reset link has been sent",
+	})
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ConfirmPasswordResetRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid payload")
+		return
+	}
+
+	if err := h.authService.ResetPassword(r.Context(), &req); err != nil {
+		if service.IsValidationError(err) {
+			util.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.Error("Reset Password - Service Error", slog.String("error", err.Error()))
+		util.RespondWithError(w, http.StatusInternalServerError, "Error resetting password")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, map[string]string{"message": "Password has been reset"})
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+	"server/internal/server/middleware"
+	"server/internal/service"
+	"server/util"
+)
+
+type AvailabilityHandler struct {
+	availabilityService *service.AvailabilityService
+}
+
+func NewAvailabilityHandler(availabilityService *service.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{availabilityService: availabilityService}
+}
+
+func (h *AvailabilityHandler) GetMyAvailability(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	availability, err := h.availabilityService.GetAvailability(r.Context(), currentAgentCtx.UserID)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch availability")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, availability)
+}
+
+func (h *AvailabilityHandler) UpdateMyAvailability(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	var req dto.UpdateAvailabilityRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a timezone and rules")
+		return
+	}
+
+	availability, err := h.availabilityService.UpdateAvailability(r.Context(), &req, currentAgentCtx)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, availability)
+}
+
+func (h *AvailabilityHandler) CreateBlackout(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	var req dto.CreateBlackoutRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a start and end date")
+		return
+	}
+
+	blackout, err := h.availabilityService.CreateBlackout(r.Context(), &req, currentAgentCtx)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, blackout)
+}
+
+func (h *AvailabilityHandler) DeleteBlackout(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	blackoutId, err := strconv.Atoi(chi.URLParam(r, "blackoutId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Blackout id is in incorrect format")
+		return
+	}
+
+	if err := h.availabilityService.DeleteBlackout(r.Context(), currentAgentCtx, blackoutId); err != nil {
+		util.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, map[string]string{"message": "Blackout deleted"})
+}
+
+func (h *AvailabilityHandler) GetBookableSlots(w http.ResponseWriter, r *http.Request) {
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Listing id is in incorrect format")
+		return
+	}
+
+	query := r.URL.Query()
+	var days, duration int
+
+	if v := query.Get("days"); v != "" {
+		if days, err = strconv.Atoi(v); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Days is in incorrect format")
+			return
+		}
+	}
+
+	if v := query.Get("duration"); v != "" {
+		if duration, err = strconv.Atoi(v); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Duration is in incorrect format")
+			return
+		}
+	}
+
+	slots, err := h.availabilityService.GetBookableSlots(
+		r.Context(),
+		listingId,
+		query.Get("from"),
+		days,
+		duration,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "Agent could not be found")
+			return
+		}
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, slots)
+}
+
+func (h *AvailabilityHandler) BookShowing(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Listing id is in incorrect format")
+		return
+	}
+
+	var req dto.BookShowingRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please select a start time")
+		return
+	}
+
+	showing, err := h.availabilityService.BookShowing(r.Context(), &req, currentUserCtx, listingId)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSlotUnavailable), errors.Is(err, repo.ErrShowingConflict):
+			util.RespondWithError(w, http.StatusConflict, err.Error())
+		default:
+			util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, showing)
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+	"server/internal/server/middleware"
+	"server/internal/service"
+	"server/util"
+)
+
+type BrokerageHandler struct {
+	brokerageService *service.BrokerageService
+}
+
+func NewBrokerageHandler(brokerageService *service.BrokerageService) *BrokerageHandler {
+	return &BrokerageHandler{brokerageService: brokerageService}
+}
+
+func respondWithBrokerageError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		util.RespondWithError(w, http.StatusNotFound, "Brokerage or user could not be found")
+	case errors.Is(err, service.ErrBrokerageForbidden):
+		util.RespondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, repo.ErrBrokerageExists):
+		util.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func brokerageIdParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	brokerageId, err := strconv.Atoi(chi.URLParam(r, "brokerageId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Brokerage id is in incorrect format")
+		return 0, false
+	}
+
+	return brokerageId, true
+}
+
+func (h *BrokerageHandler) CreateBrokerage(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	var req dto.CreateBrokerageRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a brokerage name")
+		return
+	}
+
+	brokerage, err := h.brokerageService.CreateBrokerage(r.Context(), &req, currentUserCtx)
+	if err != nil {
+		respondWithBrokerageError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, brokerage)
+}
+
+func (h *BrokerageHandler) GetBrokerageById(w http.ResponseWriter, r *http.Request) {
+	brokerageId, ok := brokerageIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	brokerage, err := h.brokerageService.GetBrokerageById(r.Context(), brokerageId)
+	if err != nil {
+		respondWithBrokerageError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, brokerage)
+}
+
+func (h *BrokerageHandler) UpdateBrokerage(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	brokerageId, ok := brokerageIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateBrokerageRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid fields")
+		return
+	}
+
+	brokerage, err := h.brokerageService.UpdateBrokerage(r.Context(), &req, currentUserCtx, brokerageId)
+	if err != nil {
+		respondWithBrokerageError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, brokerage)
+}
+
+func (h *BrokerageHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	brokerageId, ok := brokerageIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateTeamRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a team name")
+		return
+	}
+
+	team, err := h.brokerageService.CreateTeam(r.Context(), currentUserCtx, brokerageId, req.Name)
+	if err != nil {
+		respondWithBrokerageError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, team)
+}
+
+func (h *BrokerageHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	brokerageId, ok := brokerageIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "User id is in incorrect format")
+		return
+	}
+
+	var req dto.SetMembershipRequest
+
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Invalid fields")
+			return
+		}
+	}
+
+	err = h.brokerageService.AddMember(r.Context(), currentUserCtx, brokerageId, userId, req.TeamID)
+	if err != nil {
+		respondWithBrokerageError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BrokerageHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	brokerageId, ok := brokerageIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "User id is in incorrect format")
+		return
+	}
+
+	err = h.brokerageService.RemoveMember(r.Context(), currentUserCtx, brokerageId, userId)
+	if err != nil {
+		respondWithBrokerageError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BrokerageHandler) GetBrokerageAgents(w http.ResponseWriter, r *http.Request) {
+	brokerageId, ok := brokerageIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	agents, err := h.brokerageService.GetAgents(r.Context(), brokerageId)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch agents")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, agents)
+}
+
+func (h *BrokerageHandler) GetBrokerageListings(w http.ResponseWriter, r *http.Request) {
+	brokerageId, ok := brokerageIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	listings, err := h.brokerageService.GetListings(
+		r.Context(),
+		brokerageId,
+		r.URL.Query().Get("status"),
+	)
+	if err != nil {
+		respondWithBrokerageError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, listings)
+}
+
+func (h *BrokerageHandler) GetBrokerageLeads(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	brokerageId, ok := brokerageIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	leads, err := h.brokerageService.GetLeads(
+		r.Context(),
+		currentUserCtx,
+		brokerageId,
+		r.URL.Query().Get("status"),
+	)
+	if err != nil {
+		respondWithBrokerageError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, leads)
+}
+
+func (h *BrokerageHandler) GetBrokerageAnalytics(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	brokerageId, ok := brokerageIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.brokerageService.GetAnalytics(r.Context(), currentUserCtx, brokerageId)
+	if err != nil {
+		respondWithBrokerageError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, stats)
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -9,6 +11,7 @@ import (
 
 	"server/internal/api/dto"
 	"server/internal/domain"
+	"server/internal/repo"
 	"server/internal/server/middleware"
 	"server/internal/service"
 	"server/util"
@@ -25,13 +28,62 @@ func NewFavoriteHandler(favoriteService *service.FavoriteService) *FavoriteHandl
 func (h *FavoriteHandler) GetUserFavorites(w http.ResponseWriter, r *http.Request) {
 	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
 
-	favorites, err := h.favoriteService.GetUserFavorites(r.Context(), userCtx)
+	query := r.URL.Query()
+	var limit, offset int
+	var err error
+
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Limit is in incorrect format")
+			return
+		}
+	}
+
+	if v := query.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Offset is in incorrect format")
+			return
+		}
+	}
+
+	page, err := h.favoriteService.GetFavoriteListings(
+		r.Context(),
+		userCtx,
+		query.Get("sort"),
+		query.Get("order"),
+		limit,
+		offset,
+	)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, page)
+}
+
+func (h *FavoriteHandler) GetListingFavoriters(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
 	if err != nil {
-		util.RespondWithError(w, http.StatusInternalServerError, "Error fetching user favorites")
+		util.RespondWithError(w, http.StatusBadRequest, "Listing id is in incorrect format")
 		return
 	}
 
-	util.WriteJSON(w, http.StatusOK, favorites)
+	favoriters, err := h.favoriteService.GetListingFavoriters(r.Context(), currentUserCtx, listingId)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			util.RespondWithError(w, http.StatusNotFound, "Listing could not be found")
+		case errors.Is(err, service.ErrListingFavoritesForbidden):
+			util.RespondWithError(w, http.StatusForbidden, err.Error())
+		default:
+			util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch favorites")
+		}
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, favoriters)
 }
 
 func (h *FavoriteHandler) CreateFavorite(w http.ResponseWriter, r *http.Request) {
@@ -43,7 +95,11 @@ func (h *FavoriteHandler) CreateFavorite(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	newFavorite := &domain.Favorite{UserID: userCtx.UserID, ListingID: req.ListingID}
+	newFavorite := &domain.Favorite{
+		UserID:    userCtx.UserID,
+		ListingID: req.ListingID,
+		Note:      req.Note,
+	}
 
 	favorite, err := h.favoriteService.CreateFavorite(r.Context(), newFavorite)
 	if err != nil {
@@ -70,3 +126,284 @@ func (h *FavoriteHandler) DeleteFavoriteByListingId(w http.ResponseWriter, r *ht
 
 	util.WriteJSON(w, http.StatusOK, map[string]string{"message": "Favorite deleted successfully"})
 }
+
+func respondWithCollectionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		util.RespondWithError(w, http.StatusNotFound, "Collection or listing could not be found")
+	case errors.Is(err, service.ErrCollectionForbidden):
+		util.RespondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, repo.ErrCollectionExists):
+		util.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func collectionIdParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	collectionId, err := strconv.Atoi(chi.URLParam(r, "collectionId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Collection id is in incorrect format")
+		return 0, false
+	}
+
+	return collectionId, true
+}
+
+func (h *FavoriteHandler) UpdateFavoriteNote(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Incorrect ID format")
+		return
+	}
+
+	var req dto.UpdateFavoriteNoteRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid payload")
+		return
+	}
+
+	favorite, err := h.favoriteService.UpdateFavoriteNote(r.Context(), userCtx, listingId, req.Note)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "You have not saved this listing")
+			return
+		}
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, favorite)
+}
+
+func (h *FavoriteHandler) SetPriceAlert(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Incorrect ID format")
+		return
+	}
+
+	var req dto.PriceAlertRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid payload")
+		return
+	}
+
+	favorite, err := h.favoriteService.SetPriceAlert(
+		r.Context(),
+		userCtx,
+		listingId,
+		req.BelowPrice,
+		req.DropPercent,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "You have not saved this listing")
+			return
+		}
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, favorite)
+}
+
+func (h *FavoriteHandler) ClearPriceAlert(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Incorrect ID format")
+		return
+	}
+
+	_, err = h.favoriteService.ClearPriceAlert(r.Context(), userCtx, listingId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "You have not saved this listing")
+			return
+		}
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not clear price alert")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *FavoriteHandler) GetCollections(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	collections, err := h.favoriteService.GetCollections(r.Context(), userCtx)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Error fetching collections")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, collections)
+}
+
+func (h *FavoriteHandler) GetCollectionById(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	collectionId, ok := collectionIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	collection, err := h.favoriteService.GetCollectionById(r.Context(), userCtx, collectionId)
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, collection)
+}
+
+func (h *FavoriteHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	var req dto.FavoriteCollectionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a collection name")
+		return
+	}
+
+	collection, err := h.favoriteService.CreateCollection(r.Context(), userCtx, req.Name)
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, collection)
+}
+
+func (h *FavoriteHandler) RenameCollection(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	collectionId, ok := collectionIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.FavoriteCollectionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a collection name")
+		return
+	}
+
+	collection, err := h.favoriteService.RenameCollection(r.Context(), userCtx, collectionId, req.Name)
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, collection)
+}
+
+func (h *FavoriteHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	collectionId, ok := collectionIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.favoriteService.DeleteCollection(r.Context(), userCtx, collectionId); err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *FavoriteHandler) AddToCollection(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	collectionId, ok := collectionIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Incorrect ID format")
+		return
+	}
+
+	err = h.favoriteService.AddToCollection(r.Context(), userCtx, collectionId, listingId)
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *FavoriteHandler) RemoveFromCollection(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	collectionId, ok := collectionIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Incorrect ID format")
+		return
+	}
+
+	err = h.favoriteService.RemoveFromCollection(r.Context(), userCtx, collectionId, listingId)
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *FavoriteHandler) ShareCollection(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	collectionId, ok := collectionIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	collection, err := h.favoriteService.ShareCollection(r.Context(), userCtx, collectionId)
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, collection)
+}
+
+func (h *FavoriteHandler) UnshareCollection(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	collectionId, ok := collectionIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	collection, err := h.favoriteService.UnshareCollection(r.Context(), userCtx, collectionId)
+	if err != nil {
+		respondWithCollectionError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, collection)
+}
+
+func (h *FavoriteHandler) GetSharedCollection(w http.ResponseWriter, r *http.Request) {
+	collection, err := h.favoriteService.GetSharedCollection(r.Context(), chi.URLParam(r, "shareToken"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "Shared collection could not be found")
+			return
+		}
+		util.RespondWithError(w, http.StatusInternalServerError, "Error fetching collection")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, collection)
+}
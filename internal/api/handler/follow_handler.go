@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"server/internal/domain"
+	"server/internal/server/middleware"
+	"server/internal/service"
+	"server/util"
+)
+
+type FollowHandler struct {
+	followService *service.FollowService
+}
+
+func NewFollowHandler(followService *service.FollowService) *FollowHandler {
+	return &FollowHandler{followService: followService}
+}
+
+func (h *FollowHandler) FollowAgent(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	agentId, err := strconv.Atoi(chi.URLParam(r, "agentId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Agent id is in incorrect format")
+		return
+	}
+
+	if err := h.followService.FollowAgent(r.Context(), currentUserCtx, agentId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "Agent could not be found")
+			return
+		}
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *FollowHandler) UnfollowAgent(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	agentId, err := strconv.Atoi(chi.URLParam(r, "agentId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Agent id is in incorrect format")
+		return
+	}
+
+	if err := h.followService.UnfollowAgent(r.Context(), currentUserCtx, agentId); err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not unfollow agent")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *FollowHandler) GetFollowStatus(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	agentId, err := strconv.Atoi(chi.URLParam(r, "agentId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Agent id is in incorrect format")
+		return
+	}
+
+	following, err := h.followService.IsFollowing(r.Context(), currentUserCtx, agentId)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch follow status")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, map[string]bool{"following": following})
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/server/middleware"
+	"server/internal/service"
+	"server/util"
+)
+
+type LeadHandler struct {
+	leadService *service.LeadService
+}
+
+func NewLeadHandler(leadService *service.LeadService) *LeadHandler {
+	return &LeadHandler{leadService: leadService}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func respondWithLeadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		util.RespondWithError(w, http.StatusNotFound, "Lead could not be found")
+	case errors.Is(err, service.ErrLeadForbidden):
+		util.RespondWithError(w, http.StatusForbidden, err.Error())
+	default:
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *LeadHandler) SubmitInquiry(w http.ResponseWriter, r *http.Request) {
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Listing id is in incorrect format")
+		return
+	}
+
+	var req dto.CreateInquiryRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter your name, email and message")
+		return
+	}
+
+	_, err = h.leadService.SubmitInquiry(
+		r.Context(),
+		&req,
+		middleware.UserFromContext(r.Context()),
+		listingId,
+		clientIP(r),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInquirySpam):
+			// Answer bots the same as real visitors so they learn nothing
+		case errors.Is(err, service.ErrTooManyInquiries):
+			util.RespondWithError(w, http.StatusTooManyRequests, err.Error())
+			return
+		default:
+			util.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	util.WriteJSON(
+		w,
+		http.StatusCreated,
+		map[string]string{"message": "Thanks! The listing agent will be in touch soon"},
+	)
+}
+
+func (h *LeadHandler) GetMyLeads(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	leads, err := h.leadService.GetMyLeads(r.Context(), currentAgentCtx, r.URL.Query().Get("status"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, leads)
+}
+
+func (h *LeadHandler) GetLeadById(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	leadId, err := strconv.Atoi(chi.URLParam(r, "leadId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Lead id is in incorrect format")
+		return
+	}
+
+	lead, err := h.leadService.GetLeadById(r.Context(), currentAgentCtx, leadId)
+	if err != nil {
+		respondWithLeadError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, lead)
+}
+
+func (h *LeadHandler) UpdateLead(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	leadId, err := strconv.Atoi(chi.URLParam(r, "leadId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Lead id is in incorrect format")
+		return
+	}
+
+	var req dto.UpdateLeadRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a status")
+		return
+	}
+
+	lead, err := h.leadService.UpdateLeadStatus(r.Context(), currentAgentCtx, leadId, req.Status)
+	if err != nil {
+		respondWithLeadError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, lead)
+}
+
+func (h *LeadHandler) AddLeadNote(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	leadId, err := strconv.Atoi(chi.URLParam(r, "leadId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Lead id is in incorrect format")
+		return
+	}
+
+	var req dto.CreateLeadNoteRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a note")
+		return
+	}
+
+	note, err := h.leadService.AddLeadNote(r.Context(), currentAgentCtx, leadId, req.Body)
+	if err != nil {
+		respondWithLeadError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, note)
+}
+
+func (h *LeadHandler) AssignLead(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	leadId, err := strconv.Atoi(chi.URLParam(r, "leadId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Lead id is in incorrect format")
+		return
+	}
+
+	var req dto.AssignLeadRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter an agent")
+		return
+	}
+
+	lead, err := h.leadService.AssignLead(r.Context(), currentAgentCtx, leadId, req.AgentID)
+	if err != nil {
+		respondWithLeadError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, lead)
+}
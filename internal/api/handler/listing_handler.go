@@ -2,8 +2,10 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
@@ -15,15 +17,114 @@ import (
 )
 
 type ListingHandler struct {
-	listingService *service.ListingService
-	userService    *service.UserService
+	listingService   *service.ListingService
+	userService      *service.UserService
+	mortgageService  *service.MortgageService
+	openHouseService *service.OpenHouseService
+	favoriteService  *service.FavoriteService
 }
 
 func NewListingHandler(
 	listingService *service.ListingService,
 	userService *service.UserService,
+	mortgageService *service.MortgageService,
+	openHouseService *service.OpenHouseService,
+	favoriteService *service.FavoriteService,
 ) *ListingHandler {
-	return &ListingHandler{listingService: listingService, userService: userService}
+	return &ListingHandler{
+		listingService:   listingService,
+		userService:      userService,
+		mortgageService:  mortgageService,
+		openHouseService: openHouseService,
+		favoriteService:  favoriteService,
+	}
+}
+
+// withOpenHouses applies the ?open_house=weekend filter, using the tz query
+// param (default UTC) to decide when the weekend starts, then attaches
+// upcoming open houses to the listings that remain.
+func (h *ListingHandler) withOpenHouses(
+	r *http.Request,
+	listings []*domain.Listing,
+) ([]*domain.Listing, error) {
+	query := r.URL.Query()
+
+	switch query.Get("open_house") {
+	case "":
+	case "weekend":
+		loc := time.UTC
+		if tz := query.Get("tz"); tz != "" {
+			var err error
+			if loc, err = time.LoadLocation(tz); err != nil {
+				return nil, errors.New("Time zone is in incorrect format")
+			}
+		}
+
+		var err error
+		listings, err = h.openHouseService.FilterOpenThisWeekend(r.Context(), listings, loc)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("Open house filter must be weekend")
+	}
+
+	if err := h.openHouseService.AttachUpcomingOpenHouses(r.Context(), listings...); err != nil {
+		return nil, err
+	}
+
+	return listings, nil
+}
+
+// withEstimatedPayments fills in monthly payment estimates when the request
+// asks for them with ?include=payment. Terms come from the down_payment_pct,
+// rate and term query params, then the user's saved preferences.
+func (h *ListingHandler) withEstimatedPayments(r *http.Request, listings ...*domain.Listing) error {
+	query := r.URL.Query()
+	if query.Get("include") != "payment" {
+		return nil
+	}
+
+	var overrides dto.MortgageTermsRequest
+
+	if v := query.Get("down_payment_pct"); v != "" {
+		downPaymentPct, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return errors.New("Down payment is in incorrect format")
+		}
+		overrides.DownPaymentPct = &downPaymentPct
+	}
+
+	if v := query.Get("rate"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return errors.New("Rate is in incorrect format")
+		}
+		overrides.InterestRate = &rate
+	}
+
+	if v := query.Get("term"); v != "" {
+		term, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.New("Term is in incorrect format")
+		}
+		overrides.TermYears = &term
+	}
+
+	terms, err := h.mortgageService.ResolveTerms(
+		r.Context(),
+		middleware.UserFromContext(r.Context()),
+		&overrides,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, listing := range listings {
+		listing.EstimatedPayment = h.mortgageService.EstimateListingPayment(listing, terms)
+	}
+
+	return nil
 }
 
 func (h *ListingHandler) GetAllListings(w http.ResponseWriter, r *http.Request) {
@@ -33,6 +134,17 @@ func (h *ListingHandler) GetAllListings(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	listings, err = h.withOpenHouses(r, listings)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.withEstimatedPayments(r, listings...); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	util.WriteJSON(w, http.StatusOK, listings)
 }
 
@@ -54,6 +166,11 @@ func (h *ListingHandler) GetMyListings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.favoriteService.AttachFavoriteCounts(r.Context(), listings...); err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch favorite counts")
+		return
+	}
+
 	util.WriteJSON(w, http.StatusOK, listings)
 }
 
@@ -76,6 +193,17 @@ func (h *ListingHandler) GetAgentListings(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	listings, err = h.withOpenHouses(r, listings)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.withEstimatedPayments(r, listings...); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	if len(listings) == 0 {
 		util.WriteJSON(w, http.StatusOK, []domain.Listing{})
 		return
@@ -105,6 +233,16 @@ func (h *ListingHandler) GetListingById(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := h.openHouseService.AttachUpcomingOpenHouses(r.Context(), listing); err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch open houses")
+		return
+	}
+
+	if err := h.withEstimatedPayments(r, listing); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	util.WriteJSON(w, http.StatusOK, listing)
 }
 
@@ -126,18 +264,36 @@ func (h *ListingHandler) CreateListing(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if agentCtx.Role == "broker" && req.AgentID != nil && *req.AgentID != agentCtx.UserID {
+		agent, err := h.userService.GetUserById(r.Context(), *req.AgentID)
+		if err != nil || !agentCtx.IsBrokerOf(agent.BrokerageID) {
+			util.RespondWithError(
+				w,
+				http.StatusForbidden,
+				"Brokers can only create listings for agents in their brokerage",
+			)
+			return
+		}
+	}
+
 	if req.AgentID == nil {
 		req.AgentID = &agentCtx.UserID
 	}
 
 	newListing := &domain.Listing{
-		Address:     req.Address,
-		Price:       req.Price,
-		Beds:        req.Beds,
-		Baths:       req.Baths,
-		SqFt:        req.SqFt,
-		Description: req.Description,
-		AgentID:     *req.AgentID,
+		Address:      req.Address,
+		City:         req.City,
+		State:        req.State,
+		ZipCode:      req.ZipCode,
+		PropertyType: req.PropertyType,
+		Price:        req.Price,
+		Beds:         req.Beds,
+		Baths:        req.Baths,
+		SqFt:         req.SqFt,
+		Description:  req.Description,
+		HOAMonthly:   req.HOAMonthly,
+		AnnualTax:    req.AnnualTax,
+		AgentID:      *req.AgentID,
 	}
 
 	listing, err := h.listingService.CreateListing(r.Context(), newListing)
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"server/internal/domain"
+	"server/internal/service"
+	"server/util"
+)
+
+type MarketHandler struct {
+	marketService *service.MarketService
+}
+
+func NewMarketHandler(marketService *service.MarketService) *MarketHandler {
+	return &MarketHandler{marketService: marketService}
+}
+
+func (h *MarketHandler) GetMarketStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &domain.MarketFilter{
+		City:         query.Get("city"),
+		ZipCode:      query.Get("zip"),
+		PropertyType: query.Get("property_type"),
+	}
+
+	stats, err := h.marketService.GetMarketStats(r.Context(), filter)
+	if err != nil {
+		if service.IsValidationError(err) {
+			util.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.Error("Get Market Stats - Service Error", slog.String("error", err.Error()))
+		util.RespondWithError(w, http.StatusInternalServerError, "Error fetching market stats")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, stats)
+}
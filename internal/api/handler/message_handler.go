@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/server/middleware"
+	"server/internal/service"
+	"server/util"
+)
+
+type MessageHandler struct {
+	messageService *service.MessageService
+}
+
+func NewMessageHandler(messageService *service.MessageService) *MessageHandler {
+	return &MessageHandler{messageService: messageService}
+}
+
+func respondWithConversationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		util.RespondWithError(w, http.StatusNotFound, "Conversation could not be found")
+	case errors.Is(err, service.ErrConversationForbidden):
+		util.RespondWithError(w, http.StatusForbidden, err.Error())
+	default:
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *MessageHandler) StartConversation(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Listing id is in incorrect format")
+		return
+	}
+
+	var req dto.SendMessageRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a message")
+		return
+	}
+
+	message, err := h.messageService.StartConversation(r.Context(), currentUserCtx, listingId, req.Body)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, message)
+}
+
+func (h *MessageHandler) GetConversations(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	conversations, err := h.messageService.GetConversations(r.Context(), currentUserCtx)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch conversations")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, conversations)
+}
+
+func (h *MessageHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	count, err := h.messageService.GetUnreadCount(r.Context(), currentUserCtx)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch unread count")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, map[string]int{"unread": count})
+}
+
+func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	conversationId, err := strconv.Atoi(chi.URLParam(r, "conversationId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Conversation id is in incorrect format")
+		return
+	}
+
+	query := r.URL.Query()
+	var before, limit int
+
+	if v := query.Get("before"); v != "" {
+		if before, err = strconv.Atoi(v); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Before is in incorrect format")
+			return
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Limit is in incorrect format")
+			return
+		}
+	}
+
+	page, err := h.messageService.GetMessages(r.Context(), currentUserCtx, conversationId, before, limit)
+	if err != nil {
+		respondWithConversationError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, page)
+}
+
+func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	conversationId, err := strconv.Atoi(chi.URLParam(r, "conversationId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Conversation id is in incorrect format")
+		return
+	}
+
+	var req dto.SendMessageRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a message")
+		return
+	}
+
+	message, err := h.messageService.SendMessage(r.Context(), currentUserCtx, conversationId, req.Body)
+	if err != nil {
+		respondWithConversationError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, message)
+}
+
+func (h *MessageHandler) MarkConversationRead(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	conversationId, err := strconv.Atoi(chi.URLParam(r, "conversationId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Conversation id is in incorrect format")
+		return
+	}
+
+	receipt, err := h.messageService.MarkConversationRead(r.Context(), currentUserCtx, conversationId)
+	if err != nil {
+		respondWithConversationError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, receipt)
+}
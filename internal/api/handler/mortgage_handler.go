@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/server/middleware"
+	"server/internal/service"
+	"server/util"
+)
+
+type MortgageHandler struct {
+	mortgageService *service.MortgageService
+}
+
+func NewMortgageHandler(mortgageService *service.MortgageService) *MortgageHandler {
+	return &MortgageHandler{mortgageService: mortgageService}
+}
+
+func (h *MortgageHandler) CalculateMortgage(w http.ResponseWriter, r *http.Request) {
+	var req dto.MortgageCalculatorRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid payload")
+		return
+	}
+
+	estimate, err := h.mortgageService.CalculateMortgage(
+		r.Context(),
+		middleware.UserFromContext(r.Context()),
+		&req,
+	)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, estimate)
+}
+
+func (h *MortgageHandler) GetMortgagePreferences(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	prefs, err := h.mortgageService.GetMortgagePreferences(r.Context(), userCtx.UserID)
+	if err != nil {
+		util.RespondWithError(
+			w,
+			http.StatusInternalServerError,
+			"Error fetching mortgage preferences",
+		)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, prefs)
+}
+
+func (h *MortgageHandler) UpdateMortgagePreferences(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	var req dto.MortgageTermsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid payload")
+		return
+	}
+
+	prefs, err := h.mortgageService.UpdateMortgagePreferences(r.Context(), userCtx.UserID, &req)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, prefs)
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
 
@@ -24,28 +27,87 @@ func NewNotificationHandler(notificationService *service.NotificationService) *N
 	}
 }
 
-func (h *NotificationHandler) GetAllNotificationsByUserId(w http.ResponseWriter, r *http.Request) {
+// respondWithNotificationError answers 400 only for requests the service
+// rejected as invalid; anything else is a failure on our side.
+func respondWithNotificationError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case service.IsValidationError(err):
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrNotificationForbidden):
+		util.RespondWithError(w, http.StatusForbidden, err.Error())
+	default:
+		slog.Error(message, slog.String("error", err.Error()))
+		util.RespondWithError(w, http.StatusInternalServerError, message)
+	}
+}
+
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
 	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
 
-	notifications, err := h.notificationService.GetAllNotificationsByUserId(
-		r.Context(),
-		userCtx.UserID,
-	)
+	query := r.URL.Query()
+	var before, limit int
+	var err error
+
+	if v := query.Get("before"); v != "" {
+		if before, err = strconv.Atoi(v); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Before is in incorrect format")
+			return
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Limit is in incorrect format")
+			return
+		}
+	}
+
+	filter := domain.NotificationFilter{Type: query.Get("type")}
+
+	if v := query.Get("is_read"); v != "" {
+		isRead, err := strconv.ParseBool(v)
+		if err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Is read must be true or false")
+			return
+		}
+		filter.IsRead = &isRead
+	}
+
+	page, err := h.notificationService.GetNotifications(r.Context(), userCtx.UserID, filter, before, limit)
 	if err != nil {
-		util.RespondWithError(
-			w,
-			http.StatusInternalServerError,
-			"Error fetching user notifications",
-		)
+		respondWithNotificationError(w, err, "Error fetching notifications")
 		return
 	}
 
-	util.WriteJSON(w, http.StatusOK, notifications)
+	util.WriteJSON(w, http.StatusOK, page)
 }
 
-func (h *NotificationHandler) CreateNotification(w http.ResponseWriter, r *http.Request) {
+func (h *NotificationHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	count, err := h.notificationService.GetUnreadCount(r.Context(), userCtx.UserID)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Error fetching unread count")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, dto.UnreadCountResponse{UnreadCount: count})
+}
+
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
 	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
 
+	if err := h.notificationService.MarkAllRead(r.Context(), userCtx.UserID); err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Error marking notifications as read")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) CreateNotification(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
 	var req dto.CreateNotificationRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -54,41 +116,102 @@ func (h *NotificationHandler) CreateNotification(w http.ResponseWriter, r *http.
 	}
 
 	newNotification := &domain.Notification{
-		UserID:    userCtx.UserID,
+		UserID:    req.UserID,
 		ListingID: req.ListingID,
 		Type:      req.Type,
 		Message:   req.Message,
-		IsRead:    false,
 	}
 
-	notification, err := h.notificationService.CreateNotification(r.Context(), newNotification)
+	err := h.notificationService.SendNotification(r.Context(), currentUserCtx, newNotification)
 	if err != nil {
-		util.RespondWithError(w, http.StatusInternalServerError, "Error creating notification")
+		respondWithNotificationError(w, err, "Error sending notification")
 		return
 	}
 
-	util.WriteJSON(w, http.StatusOK, notification)
+	w.WriteHeader(http.StatusAccepted)
 }
 
-func (h *NotificationHandler) ToggleNotificationReadStatus(w http.ResponseWriter, r *http.Request) {
+func (h *NotificationHandler) UpdateNotification(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
 	notificationId, err := strconv.Atoi(chi.URLParam(r, "notificationId"))
 	if err != nil {
 		util.RespondWithError(w, http.StatusBadRequest, "Invalid id")
 		return
 	}
 
-	notification, err := h.notificationService.ToggleNotificationReadStatus(
+	var req dto.UpdateNotificationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.IsRead == nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please set is_read to true or false")
+		return
+	}
+
+	notification, err := h.notificationService.SetReadStatus(
 		r.Context(),
+		userCtx.UserID,
 		notificationId,
+		*req.IsRead,
 	)
 	if err != nil {
-		util.RespondWithError(
-			w,
-			http.StatusInternalServerError,
-			err.Error(),
-		)
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "Notification could not be found")
+			return
+		}
+		util.RespondWithError(w, http.StatusInternalServerError, "Error updating notification")
 		return
 	}
 
 	util.WriteJSON(w, http.StatusOK, notification)
 }
+
+func (h *NotificationHandler) DeleteNotification(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	notificationId, err := strconv.Atoi(chi.URLParam(r, "notificationId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid id")
+		return
+	}
+
+	err = h.notificationService.DeleteNotification(r.Context(), userCtx.UserID, notificationId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "Notification could not be found")
+			return
+		}
+		util.RespondWithError(w, http.StatusInternalServerError, "Error deleting notification")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	settings, err := h.notificationService.GetSettings(r.Context(), userCtx.UserID)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Error fetching notification settings")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, settings)
+}
+
+func (h *NotificationHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	var req dto.UpdateNotificationSettingsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid notification settings")
+		return
+	}
+
+	settings, err := h.notificationService.UpdateSettings(r.Context(), userCtx.UserID, &req)
+	if err != nil {
+		respondWithNotificationError(w, err, "Error updating notification settings")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, settings)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+	"server/internal/server/middleware"
+	"server/internal/service"
+	"server/util"
+)
+
+type OfferHandler struct {
+	offerService *service.OfferService
+}
+
+func NewOfferHandler(offerService *service.OfferService) *OfferHandler {
+	return &OfferHandler{offerService: offerService}
+}
+
+func respondWithOfferError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		util.RespondWithError(w, http.StatusNotFound, "Offer could not be found")
+	case errors.Is(err, service.ErrOfferForbidden):
+		util.RespondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, repo.ErrOfferChanged),
+		errors.Is(err, repo.ErrListingUnderContract),
+		errors.Is(err, repo.ErrListingNotActive):
+		util.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// offerAction handles the body-less responses to an offer.
+func (h *OfferHandler) offerAction(
+	w http.ResponseWriter,
+	r *http.Request,
+	action func(*domain.ContextSessionData, int) (*domain.Offer, error),
+) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	offerId, err := strconv.Atoi(chi.URLParam(r, "offerId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Offer id is in incorrect format")
+		return
+	}
+
+	offer, err := action(currentAgentCtx, offerId)
+	if err != nil {
+		respondWithOfferError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, offer)
+}
+
+func (h *OfferHandler) SubmitOffer(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Listing id is in incorrect format")
+		return
+	}
+
+	var req dto.OfferTermsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter the offer terms")
+		return
+	}
+
+	offer, err := h.offerService.SubmitOffer(r.Context(), &req, currentAgentCtx, listingId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "Listing could not be found")
+			return
+		}
+		respondWithOfferError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, offer)
+}
+
+func (h *OfferHandler) GetMyOffers(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	offers, err := h.offerService.GetMyOffers(r.Context(), currentAgentCtx)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch offers")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, offers)
+}
+
+func (h *OfferHandler) GetOfferById(w http.ResponseWriter, r *http.Request) {
+	h.offerAction(w, r, func(agentCtx *domain.ContextSessionData, offerId int) (*domain.Offer, error) {
+		return h.offerService.GetOfferById(r.Context(), agentCtx, offerId)
+	})
+}
+
+func (h *OfferHandler) CounterOffer(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	offerId, err := strconv.Atoi(chi.URLParam(r, "offerId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Offer id is in incorrect format")
+		return
+	}
+
+	var req dto.OfferTermsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter the counter terms")
+		return
+	}
+
+	offer, err := h.offerService.CounterOffer(r.Context(), &req, currentAgentCtx, offerId)
+	if err != nil {
+		respondWithOfferError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, offer)
+}
+
+func (h *OfferHandler) AcceptOffer(w http.ResponseWriter, r *http.Request) {
+	h.offerAction(w, r, func(agentCtx *domain.ContextSessionData, offerId int) (*domain.Offer, error) {
+		return h.offerService.AcceptOffer(r.Context(), agentCtx, offerId)
+	})
+}
+
+func (h *OfferHandler) RejectOffer(w http.ResponseWriter, r *http.Request) {
+	h.offerAction(w, r, func(agentCtx *domain.ContextSessionData, offerId int) (*domain.Offer, error) {
+		return h.offerService.RejectOffer(r.Context(), agentCtx, offerId)
+	})
+}
+
+func (h *OfferHandler) WithdrawOffer(w http.ResponseWriter, r *http.Request) {
+	h.offerAction(w, r, func(agentCtx *domain.ContextSessionData, offerId int) (*domain.Offer, error) {
+		return h.offerService.WithdrawOffer(r.Context(), agentCtx, offerId)
+	})
+}
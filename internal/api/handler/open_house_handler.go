@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/ical"
+	"server/internal/server/middleware"
+	"server/internal/service"
+	"server/util"
+)
+
+type OpenHouseHandler struct {
+	openHouseService *service.OpenHouseService
+}
+
+func NewOpenHouseHandler(openHouseService *service.OpenHouseService) *OpenHouseHandler {
+	return &OpenHouseHandler{openHouseService: openHouseService}
+}
+
+func writeCalendar(w http.ResponseWriter, cal *ical.Calendar) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = ical.Write(w, *cal)
+}
+
+func (h *OpenHouseHandler) GetListingOpenHouses(w http.ResponseWriter, r *http.Request) {
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Listing id is in incorrect format")
+		return
+	}
+
+	openHouses, err := h.openHouseService.GetUpcomingOpenHousesByListingId(r.Context(), listingId)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch open houses")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, openHouses)
+}
+
+func (h *OpenHouseHandler) GetListingCalendar(w http.ResponseWriter, r *http.Request) {
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Listing id is in incorrect format")
+		return
+	}
+
+	cal, err := h.openHouseService.GetListingCalendar(r.Context(), listingId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "Listing could not be found")
+			return
+		}
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not build calendar")
+		return
+	}
+
+	writeCalendar(w, cal)
+}
+
+func (h *OpenHouseHandler) GetAgentCalendar(w http.ResponseWriter, r *http.Request) {
+	agentId, err := strconv.Atoi(chi.URLParam(r, "agentId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Agent id is in incorrect format")
+		return
+	}
+
+	cal, err := h.openHouseService.GetAgentCalendar(r.Context(), agentId)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not build calendar")
+		return
+	}
+
+	writeCalendar(w, cal)
+}
+
+func (h *OpenHouseHandler) CreateOpenHouse(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Listing id is in incorrect format")
+		return
+	}
+
+	var req dto.CreateOpenHouseRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a valid start and end time")
+		return
+	}
+
+	openHouse, err := h.openHouseService.CreateOpenHouse(r.Context(), &req, currentUserCtx, listingId)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			util.RespondWithError(w, http.StatusNotFound, "Listing could not be found")
+		case errors.Is(err, service.ErrOpenHouseForbidden):
+			util.RespondWithError(w, http.StatusForbidden, err.Error())
+		default:
+			util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, openHouse)
+}
+
+func (h *OpenHouseHandler) CancelOpenHouse(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Listing id is in incorrect format")
+		return
+	}
+
+	openHouseId, err := strconv.Atoi(chi.URLParam(r, "openHouseId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Open house id is in incorrect format")
+		return
+	}
+
+	openHouse, err := h.openHouseService.CancelOpenHouse(
+		r.Context(),
+		currentUserCtx,
+		listingId,
+		openHouseId,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			util.RespondWithError(w, http.StatusNotFound, "Listing could not be found")
+		case errors.Is(err, service.ErrOpenHouseForbidden):
+			util.RespondWithError(w, http.StatusForbidden, err.Error())
+		default:
+			util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, openHouse)
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+	"server/internal/server/middleware"
+	"server/internal/service"
+	"server/util"
+)
+
+type ReviewHandler struct {
+	reviewService *service.ReviewService
+}
+
+func NewReviewHandler(reviewService *service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService}
+}
+
+func respondWithReviewError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		util.RespondWithError(w, http.StatusNotFound, "Review could not be found")
+	case errors.Is(err, service.ErrReviewForbidden), errors.Is(err, service.ErrReviewNotAllowed):
+		util.RespondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, repo.ErrReviewExists):
+		util.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *ReviewHandler) GetAgentReviews(w http.ResponseWriter, r *http.Request) {
+	agentId, err := strconv.Atoi(chi.URLParam(r, "agentId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Agent id is in incorrect format")
+		return
+	}
+
+	currentUserCtx := middleware.UserFromContext(r.Context())
+
+	reviews, err := h.reviewService.GetAgentReviews(r.Context(), currentUserCtx, agentId)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch reviews")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, reviews)
+}
+
+func (h *ReviewHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	agentId, err := strconv.Atoi(chi.URLParam(r, "agentId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Agent id is in incorrect format")
+		return
+	}
+
+	var req dto.CreateReviewRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a rating")
+		return
+	}
+
+	review, err := h.reviewService.CreateReview(r.Context(), &req, currentUserCtx, agentId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.RespondWithError(w, http.StatusNotFound, "Agent could not be found")
+			return
+		}
+		respondWithReviewError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, review)
+}
+
+func (h *ReviewHandler) ReplyToReview(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	reviewId, err := strconv.Atoi(chi.URLParam(r, "reviewId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Review id is in incorrect format")
+		return
+	}
+
+	var req dto.ReplyToReviewRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a reply")
+		return
+	}
+
+	review, err := h.reviewService.ReplyToReview(r.Context(), currentAgentCtx, reviewId, req.Reply)
+	if err != nil {
+		respondWithReviewError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, review)
+}
+
+func (h *ReviewHandler) ModerateReview(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	reviewId, err := strconv.Atoi(chi.URLParam(r, "reviewId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Review id is in incorrect format")
+		return
+	}
+
+	var req dto.ModerateReviewRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a status")
+		return
+	}
+
+	review, err := h.reviewService.ModerateReview(r.Context(), currentUserCtx, reviewId, req.Status)
+	if err != nil {
+		respondWithReviewError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, review)
+}
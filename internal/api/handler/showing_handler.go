@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+	"server/internal/server/middleware"
+	"server/internal/service"
+	"server/util"
+)
+
+type ShowingHandler struct {
+	showingService *service.ShowingService
+}
+
+func NewShowingHandler(showingService *service.ShowingService) *ShowingHandler {
+	return &ShowingHandler{showingService: showingService}
+}
+
+func respondWithShowingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		util.RespondWithError(w, http.StatusNotFound, "Showing could not be found")
+	case errors.Is(err, service.ErrShowingForbidden):
+		util.RespondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, repo.ErrShowingConflict), errors.Is(err, repo.ErrShowingChanged):
+		util.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *ShowingHandler) RequestShowing(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Listing id is in incorrect format")
+		return
+	}
+
+	var req dto.CreateShowingRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter at least one time slot")
+		return
+	}
+
+	showing, err := h.showingService.RequestShowing(r.Context(), &req, currentUserCtx, listingId)
+	if err != nil {
+		respondWithShowingError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, showing)
+}
+
+func (h *ShowingHandler) GetMyShowings(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	showings, err := h.showingService.GetMyShowings(r.Context(), currentUserCtx)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Could not fetch showings")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, showings)
+}
+
+func (h *ShowingHandler) GetShowingById(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	showingId, err := strconv.Atoi(chi.URLParam(r, "showingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Showing id is in incorrect format")
+		return
+	}
+
+	showing, err := h.showingService.GetShowingById(r.Context(), currentUserCtx, showingId)
+	if err != nil {
+		respondWithShowingError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, showing)
+}
+
+func (h *ShowingHandler) AcceptShowing(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	showingId, err := strconv.Atoi(chi.URLParam(r, "showingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Showing id is in incorrect format")
+		return
+	}
+
+	var req dto.AcceptShowingRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please select a time slot")
+		return
+	}
+
+	showing, err := h.showingService.AcceptShowing(r.Context(), currentUserCtx, showingId, req.SlotID)
+	if err != nil {
+		respondWithShowingError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, showing)
+}
+
+func (h *ShowingHandler) ProposeShowingTime(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	showingId, err := strconv.Atoi(chi.URLParam(r, "showingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Showing id is in incorrect format")
+		return
+	}
+
+	var req dto.ShowingSlotRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Please enter a valid start and end time")
+		return
+	}
+
+	showing, err := h.showingService.ProposeShowingTime(r.Context(), currentUserCtx, showingId, &req)
+	if err != nil {
+		respondWithShowingError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, showing)
+}
+
+func (h *ShowingHandler) DeclineShowing(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	showingId, err := strconv.Atoi(chi.URLParam(r, "showingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Showing id is in incorrect format")
+		return
+	}
+
+	showing, err := h.showingService.DeclineShowing(r.Context(), currentUserCtx, showingId)
+	if err != nil {
+		respondWithShowingError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, showing)
+}
+
+func (h *ShowingHandler) GetMyShowingCalendar(w http.ResponseWriter, r *http.Request) {
+	currentAgentCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	query := r.URL.Query()
+
+	var from, to time.Time
+	var err error
+
+	if v := query.Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "From is in incorrect format")
+			return
+		}
+	}
+
+	if v := query.Get("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "To is in incorrect format")
+			return
+		}
+	}
+
+	showings, err := h.showingService.GetAgentCalendar(r.Context(), currentAgentCtx.UserID, from, to)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, showings)
+}
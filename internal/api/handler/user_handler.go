@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
@@ -47,9 +48,17 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *UserHandler) GetAllAgents(w http.ResponseWriter, r *http.Request) {
-	agents, err := h.userService.GetUsersByRole(r.Context(), "agent")
+	query := r.URL.Query()
+	filter := &domain.AgentFilter{
+		Sort:      query.Get("sort"),
+		ZipCode:   query.Get("zip"),
+		Language:  strings.TrimSpace(query.Get("language")),
+		Specialty: query.Get("specialty"),
+	}
+
+	agents, err := h.userService.GetAgents(r.Context(), filter)
 	if err != nil {
-		util.RespondWithError(w, http.StatusBadRequest, "Could not find agents")
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
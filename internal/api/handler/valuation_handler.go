@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"server/internal/service"
+	"server/util"
+)
+
+type ValuationHandler struct {
+	valuationService *service.ValuationService
+}
+
+func NewValuationHandler(valuationService *service.ValuationService) *ValuationHandler {
+	return &ValuationHandler{valuationService: valuationService}
+}
+
+func (h *ValuationHandler) GetListingEstimate(w http.ResponseWriter, r *http.Request) {
+	listingId, err := strconv.Atoi(chi.URLParam(r, "listingId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Listing id is in incorrect format")
+		return
+	}
+
+	estimate, err := h.valuationService.EstimateListingValue(r.Context(), listingId)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			util.RespondWithError(w, http.StatusNotFound, "Listing could not be found")
+		case errors.Is(err, service.ErrNotEnoughComparables):
+			util.RespondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			util.RespondWithError(w, http.StatusInternalServerError, "Could not estimate value")
+		}
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, estimate)
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/server/middleware"
+	"server/internal/service"
+	"server/util"
+)
+
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+func respondWithWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		util.RespondWithError(w, http.StatusNotFound, "Webhook or delivery could not be found")
+	case errors.Is(err, service.ErrWebhookForbidden), errors.Is(err, service.ErrBrokerageForbidden):
+		util.RespondWithError(w, http.StatusForbidden, err.Error())
+	default:
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func webhookIdParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	webhookId, err := strconv.Atoi(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Webhook id is in incorrect format")
+		return 0, false
+	}
+
+	return webhookId, true
+}
+
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	var req dto.CreateWebhookRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	subscription, err := h.webhookService.CreateSubscription(r.Context(), currentUserCtx, &req)
+	if err != nil {
+		respondWithWebhookError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, subscription)
+}
+
+func (h *WebhookHandler) GetMyWebhooks(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+
+	subscriptions, err := h.webhookService.GetSubscriptions(r.Context(), currentUserCtx)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Error fetching webhooks")
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, subscriptions)
+}
+
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	webhookId, ok := webhookIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid fields")
+		return
+	}
+
+	subscription, err := h.webhookService.UpdateSubscription(r.Context(), currentUserCtx, webhookId, &req)
+	if err != nil {
+		respondWithWebhookError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, subscription)
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	webhookId, ok := webhookIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(r.Context(), currentUserCtx, webhookId); err != nil {
+		respondWithWebhookError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	webhookId, ok := webhookIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	var before, limit int
+	var err error
+
+	if v := query.Get("before"); v != "" {
+		if before, err = strconv.Atoi(v); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Before is in incorrect format")
+			return
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Limit is in incorrect format")
+			return
+		}
+	}
+
+	page, err := h.webhookService.GetDeliveries(r.Context(), currentUserCtx, webhookId, before, limit)
+	if err != nil {
+		respondWithWebhookError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, page)
+}
+
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	currentUserCtx := r.Context().Value(middleware.UserContextKey).(*domain.ContextSessionData)
+	webhookId, ok := webhookIdParam(w, r)
+	if !ok {
+		return
+	}
+
+	deliveryId, err := strconv.Atoi(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Delivery id is in incorrect format")
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(r.Context(), currentUserCtx, webhookId, deliveryId)
+	if err != nil {
+		respondWithWebhookError(w, err)
+		return
+	}
+
+	util.WriteJSON(w, http.StatusAccepted, delivery)
+}
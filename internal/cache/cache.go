@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+var ErrMiss = errors.New("cache miss")
+
+// Store is the key/value backend used by read-through caches.
+type Store interface {
+	// Get returns ErrMiss when the key does not exist or has expired.
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+type Config struct {
+	Enabled bool
+	TTL     time.Duration
+}
+
+const defaultTTL = 5 * time.Minute
+
+// ConfigFromEnv reads LISTING_CACHE_ENABLED and LISTING_CACHE_TTL. Caching is
+// off unless LISTING_CACHE_ENABLED is "true".
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Enabled: os.Getenv("LISTING_CACHE_ENABLED") == "true",
+		TTL:     defaultTTL,
+	}
+
+	if ttl, err := time.ParseDuration(os.Getenv("LISTING_CACHE_TTL")); err == nil && ttl > 0 {
+		cfg.TTL = ttl
+	}
+
+	return cfg
+}
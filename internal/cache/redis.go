@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisStore struct {
+	rc *redis.Client
+}
+
+func NewRedisStore(rc *redis.Client) *RedisStore {
+	return &RedisStore{rc: rc}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	val, err := s.rc.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+
+	return val, err
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.rc.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return s.rc.Del(ctx, keys...).Err()
+}
+
+func (s *RedisStore) Incr(ctx context.Context, key string) (int64, error) {
+	return s.rc.Incr(ctx, key).Result()
+}
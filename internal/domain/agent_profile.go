@@ -0,0 +1,26 @@
+package domain
+
+var AgentSpecialties = map[string]bool{
+	"buyers":            true,
+	"sellers":           true,
+	"first_time_buyers": true,
+	"luxury":            true,
+	"relocation":        true,
+	"investment":        true,
+	"new_construction":  true,
+	"land":              true,
+}
+
+// AgentProfile is the public profile an agent maintains about themselves.
+// Languages are stored lowercase so searches match regardless of case.
+type AgentProfile struct {
+	Bio             *string  `json:"bio"`
+	Phone           *string  `json:"phone"`
+	LicenseNumber   *string  `json:"license_number"`
+	LicenseState    *string  `json:"license_state"`
+	BrokerageName   *string  `json:"brokerage_name"`
+	HeadshotURL     *string  `json:"headshot_url"`
+	Languages       []string `json:"languages"`
+	Specialties     []string `json:"specialties"`
+	ServiceZipCodes []string `json:"service_zip_codes"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+type AgentAvailability struct {
+	AgentID   int                    `json:"agent_id"`
+	Timezone  string                 `json:"timezone"`
+	Rules     []AvailabilityRule     `json:"rules"`
+	Blackouts []AvailabilityBlackout `json:"blackouts"`
+}
+
+// AvailabilityRule is a weekly window in the agent's timezone. Weekday follows
+// time.Weekday (0 is Sunday) and times are HH:MM.
+type AvailabilityRule struct {
+	Weekday   int    `json:"weekday"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// AvailabilityBlackout blocks whole days, inclusive, as YYYY-MM-DD in the
+// agent's timezone.
+type AvailabilityBlackout struct {
+	ID        int     `json:"id"`
+	AgentID   int     `json:"agent_id"`
+	StartDate string  `json:"start_date"`
+	EndDate   string  `json:"end_date"`
+	Reason    *string `json:"reason"`
+}
+
+type BookableSlot struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// BrokerageBranding is what listings and agent profiles show about the
+// agent's brokerage.
+type BrokerageBranding struct {
+	ID           int     `json:"id"`
+	Name         string  `json:"name"`
+	LogoURL      *string `json:"logo_url"`
+	PrimaryColor *string `json:"primary_color"`
+	WebsiteURL   *string `json:"website_url"`
+}
+
+type Brokerage struct {
+	BrokerageBranding
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Teams     []Team    `json:"teams"`
+}
+
+type Team struct {
+	ID          int       `json:"id"`
+	BrokerageID int       `json:"brokerage_id"`
+	Name        string    `json:"name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BrokerageAgentStats is one agent's row in the brokerage analytics report.
+type BrokerageAgentStats struct {
+	AgentID         int    `json:"agent_id"`
+	FirstName       string `json:"first_name"`
+	LastName        string `json:"last_name"`
+	TeamID          *int   `json:"team_id"`
+	ActiveListings  int    `json:"active_listings"`
+	PendingListings int    `json:"pending_listings"`
+	SoldListings    int    `json:"sold_listings"`
+	ListingViews    int    `json:"listing_views"`
+	Leads           int    `json:"leads"`
+	NewLeads        int    `json:"new_leads"`
+}
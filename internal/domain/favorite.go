@@ -2,10 +2,71 @@ package domain
 
 import "time"
 
+// DefaultCollectionName is used for the collection new favorites land in.
+const DefaultCollectionName = "Saved"
+
 type Favorite struct {
 	ID        int       `json:"id"`
 	UserID    int       `json:"user_id"`
 	ListingID int       `json:"listing_id"`
+	Note      *string   `json:"note"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Listing   *Listing  `json:"listing,omitempty"`
+
+	PriceAlert
+}
+
+// PriceAlert asks for a notification when the listing price falls below
+// BelowPrice, or by more than DropPercent from BasePrice, the price when the
+// alert was set.
+type PriceAlert struct {
+	BelowPrice  *int     `json:"alert_below_price"`
+	DropPercent *float64 `json:"alert_drop_percent"`
+	BasePrice   *int     `json:"alert_base_price"`
+}
+
+// FavoritePage is one page of saved listings. NextOffset is passed back as
+// ?offset= to fetch the next page and is nil on the last page.
+type FavoritePage struct {
+	Favorites  []*Favorite `json:"favorites"`
+	NextOffset *int        `json:"next_offset"`
+}
+
+// ListingFavoriter is a user who saved a listing and agreed to share that
+// with listing agents.
+type ListingFavoriter struct {
+	UserID      int       `json:"user_id"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	Email       string    `json:"email"`
+	FavoritedAt time.Time `json:"favorited_at"`
+}
+
+// ListingFavoriters counts everyone who saved the listing but only names the
+// users who opted in.
+type ListingFavoriters struct {
+	ListingID     int                 `json:"listing_id"`
+	FavoriteCount int                 `json:"favorite_count"`
+	Users         []*ListingFavoriter `json:"users"`
+}
+
+type FavoriteCollection struct {
+	ID           int        `json:"id"`
+	UserID       int        `json:"user_id"`
+	Name         string     `json:"name"`
+	IsDefault    bool       `json:"is_default"`
+	ShareToken   *string    `json:"share_token"`
+	ListingCount int        `json:"listing_count"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Favorites    []Favorite `json:"favorites,omitempty"`
+}
+
+// SharedFavoriteCollection is the read-only view behind a share link. It
+// leaves out the owner and their private notes.
+type SharedFavoriteCollection struct {
+	Name       string    `json:"name"`
+	ListingIDs []int     `json:"listing_ids"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
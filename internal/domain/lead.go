@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+const (
+	LeadStatusNew       = "new"
+	LeadStatusContacted = "contacted"
+	LeadStatusQualified = "qualified"
+	LeadStatusLost      = "lost"
+)
+
+var LeadStatuses = map[string]bool{
+	LeadStatusNew:       true,
+	LeadStatusContacted: true,
+	LeadStatusQualified: true,
+	LeadStatusLost:      true,
+}
+
+var PreferredContactMethods = map[string]bool{
+	"email": true,
+	"phone": true,
+	"text":  true,
+}
+
+type Lead struct {
+	ID               int              `json:"id"`
+	ListingID        int              `json:"listing_id"`
+	AgentID          int              `json:"agent_id"`
+	UserID           *int             `json:"user_id"`
+	Name             string           `json:"name"`
+	Email            string           `json:"email"`
+	Phone            *string          `json:"phone"`
+	Message          string           `json:"message"`
+	PreferredContact string           `json:"preferred_contact"`
+	Status           string           `json:"status"`
+	IPAddress        string           `json:"-"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	Notes            []LeadNote       `json:"notes,omitempty"`
+	Assignments      []LeadAssignment `json:"assignments,omitempty"`
+}
+
+type LeadNote struct {
+	ID        int       `json:"id"`
+	LeadID    int       `json:"lead_id"`
+	AuthorID  int       `json:"author_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LeadAssignment struct {
+	ID         int       `json:"id"`
+	LeadID     int       `json:"lead_id"`
+	AgentID    int       `json:"agent_id"`
+	AssignedBy *int      `json:"assigned_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -2,17 +2,51 @@ package domain
 
 import "time"
 
+const (
+	ListingStatusActive    = "active"
+	ListingStatusPending   = "pending"
+	ListingStatusSold      = "sold"
+	ListingStatusWithdrawn = "withdrawn"
+)
+
+var ListingStatuses = map[string]bool{
+	ListingStatusActive:    true,
+	ListingStatusPending:   true,
+	ListingStatusSold:      true,
+	ListingStatusWithdrawn: true,
+}
+
+var PropertyTypes = map[string]bool{
+	"single_family": true,
+	"condo":         true,
+	"townhouse":     true,
+	"multi_family":  true,
+	"land":          true,
+}
+
 type Listing struct {
-	ID          int       `json:"id"`
-	Address     string    `json:"address"`
-	Price       int       `json:"price"`
-	Beds        int       `json:"beds"`
-	Baths       int       `json:"baths"`
-	SqFt        int       `json:"sq_ft"`
-	Description *string   `json:"description"`
-	AgentID     int       `json:"agent_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Agent       *Agent    `json:"agent"`
-	Views       int       `json:"views"`
+	ID           int        `json:"id"`
+	Address      string     `json:"address"`
+	City         string     `json:"city"`
+	State        string     `json:"state"`
+	ZipCode      string     `json:"zip_code"`
+	PropertyType string     `json:"property_type"`
+	Status       string     `json:"status"`
+	Price        int        `json:"price"`
+	Beds         int        `json:"beds"`
+	Baths        int        `json:"baths"`
+	SqFt         int        `json:"sq_ft"`
+	Description  *string    `json:"description"`
+	HOAMonthly   int        `json:"hoa_monthly"`
+	AnnualTax    *int       `json:"annual_property_tax"`
+	AgentID      int        `json:"agent_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	SoldAt       *time.Time `json:"sold_at"`
+	Agent        *Agent     `json:"agent"`
+	Views        int        `json:"views"`
+
+	EstimatedPayment *MonthlyPayment `json:"estimated_payment,omitempty"`
+	OpenHouses       []OpenHouse     `json:"open_houses,omitempty"`
+	FavoriteCount    *int            `json:"favorite_count,omitempty"`
 }
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+type MarketFilter struct {
+	City         string
+	ZipCode      string
+	PropertyType string
+}
+
+type MarketTrend struct {
+	CurrentMonthMedianPrice  float64  `json:"current_month_median_price"`
+	PreviousMonthMedianPrice float64  `json:"previous_month_median_price"`
+	MedianPriceChangePct     *float64 `json:"median_price_change_pct"`
+	CurrentMonthNewListings  int      `json:"current_month_new_listings"`
+	PreviousMonthNewListings int      `json:"previous_month_new_listings"`
+}
+
+type MarketStats struct {
+	City               string      `json:"city,omitempty"`
+	ZipCode            string      `json:"zip_code,omitempty"`
+	PropertyType       string      `json:"property_type,omitempty"`
+	MedianListPrice    float64     `json:"median_list_price"`
+	AverageListPrice   float64     `json:"average_list_price"`
+	MedianPricePerSqFt float64     `json:"median_price_per_sq_ft"`
+	ActiveInventory    int         `json:"active_inventory"`
+	MedianDaysOnMarket float64     `json:"median_days_on_market"`
+	Trend              MarketTrend `json:"month_over_month"`
+	GeneratedAt        time.Time   `json:"generated_at"`
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// Real-time event types pushed to conversation participants. New messages
+// are pushed unless the recipient turned push off for
+// NotificationTypeNewMessage.
+const (
+	EventNewMessage   = "new_message"
+	EventMessagesRead = "messages_read"
+)
+
+type Conversation struct {
+	ID            int        `json:"id"`
+	ListingID     int        `json:"listing_id"`
+	BuyerID       int        `json:"buyer_id"`
+	AgentID       int        `json:"agent_id"`
+	LastMessageAt *time.Time `json:"last_message_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UnreadCount   int        `json:"unread_count"`
+	LastMessage   *Message   `json:"last_message,omitempty"`
+}
+
+type Message struct {
+	ID             int        `json:"id"`
+	ConversationID int        `json:"conversation_id"`
+	SenderID       int        `json:"sender_id"`
+	Body           string     `json:"body"`
+	ReadAt         *time.Time `json:"read_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// MessagePage is one page of history, newest first. NextCursor is passed back
+// as ?before= to fetch older messages and is nil on the last page.
+type MessagePage struct {
+	Messages   []*Message `json:"messages"`
+	NextCursor *int       `json:"next_cursor"`
+}
+
+type ReadReceipt struct {
+	ConversationID int       `json:"conversation_id"`
+	ReaderID       int       `json:"reader_id"`
+	LastReadID     int       `json:"last_read_message_id"`
+	ReadAt         time.Time `json:"read_at"`
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+type MortgagePreferences struct {
+	UserID         int       `json:"user_id"`
+	DownPaymentPct float64   `json:"down_payment_pct"`
+	InterestRate   float64   `json:"interest_rate"`
+	TermYears      int       `json:"term_years"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// MortgageTerms are the financing inputs applied to a price. Percentages are
+// expressed as whole numbers, e.g. 6.5 for a 6.5% rate.
+type MortgageTerms struct {
+	DownPaymentPct float64 `json:"down_payment_pct"`
+	InterestRate   float64 `json:"interest_rate"`
+	TermYears      int     `json:"term_years"`
+}
+
+type MonthlyPayment struct {
+	MortgageTerms
+	DownPayment          float64 `json:"down_payment"`
+	LoanAmount           float64 `json:"loan_amount"`
+	PrincipalAndInterest float64 `json:"principal_and_interest"`
+	PropertyTax          float64 `json:"property_tax"`
+	HOA                  float64 `json:"hoa"`
+	Insurance            float64 `json:"insurance"`
+	Total                float64 `json:"total"`
+}
+
+type AmortizationPeriod struct {
+	Month     int     `json:"month"`
+	Payment   float64 `json:"payment"`
+	Principal float64 `json:"principal"`
+	Interest  float64 `json:"interest"`
+	Balance   float64 `json:"balance"`
+}
+
+type MortgageEstimate struct {
+	Price             int                  `json:"price"`
+	Monthly           MonthlyPayment       `json:"monthly"`
+	TotalInterest     float64              `json:"total_interest"`
+	AmortizationTable []AmortizationPeriod `json:"amortization_schedule,omitempty"`
+}
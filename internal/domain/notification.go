@@ -2,6 +2,58 @@ package domain
 
 import "time"
 
+const (
+	NotificationTypeFavoritedListing   = "favorited_listing_notification"
+	NotificationTypePriceDrop          = "price_drop_notification"
+	NotificationTypeStatusChange       = "status_changed_notification"
+	NotificationTypeOpenHouseScheduled = "open_house_scheduled_notification"
+	NotificationTypeOpenHouseCancelled = "open_house_cancelled_notification"
+	NotificationTypeShowingRequested   = "showing_requested_notification"
+	NotificationTypeShowingProposed    = "showing_proposed_notification"
+	NotificationTypeShowingConfirmed   = "showing_confirmed_notification"
+	NotificationTypeShowingDeclined    = "showing_declined_notification"
+	NotificationTypeShowingBooked      = "showing_booked_notification"
+	NotificationTypeNewLead            = "new_lead_notification"
+	NotificationTypeOfferReceived      = "offer_received_notification"
+	NotificationTypeOfferCountered     = "offer_countered_notification"
+	NotificationTypeOfferAccepted      = "offer_accepted_notification"
+	NotificationTypeOfferRejected      = "offer_rejected_notification"
+	NotificationTypeOfferWithdrawn     = "offer_withdrawn_notification"
+	NotificationTypeOfferExpired       = "offer_expired_notification"
+	NotificationTypeListingPending     = "listing_pending_notification"
+	NotificationTypeAgentListed        = "agent_listed_notification"
+	NotificationTypePriceAlert         = "price_alert_notification"
+	NotificationTypeNewMessage         = "new_message_notification"
+	NotificationTypeNewMatch           = "new_match_notification"
+)
+
+// NotificationTypes is the registry of notification types the server sends.
+// Notifications of any other type are rejected.
+var NotificationTypes = map[string]bool{
+	NotificationTypeFavoritedListing:   true,
+	NotificationTypePriceDrop:          true,
+	NotificationTypeStatusChange:       true,
+	NotificationTypeOpenHouseScheduled: true,
+	NotificationTypeOpenHouseCancelled: true,
+	NotificationTypeShowingRequested:   true,
+	NotificationTypeShowingProposed:    true,
+	NotificationTypeShowingConfirmed:   true,
+	NotificationTypeShowingDeclined:    true,
+	NotificationTypeShowingBooked:      true,
+	NotificationTypeNewLead:            true,
+	NotificationTypeOfferReceived:      true,
+	NotificationTypeOfferCountered:     true,
+	NotificationTypeOfferAccepted:      true,
+	NotificationTypeOfferRejected:      true,
+	NotificationTypeOfferWithdrawn:     true,
+	NotificationTypeOfferExpired:       true,
+	NotificationTypeListingPending:     true,
+	NotificationTypeAgentListed:        true,
+	NotificationTypePriceAlert:         true,
+	NotificationTypeNewMessage:         true,
+	NotificationTypeNewMatch:           true,
+}
+
 type Notification struct {
 	ID        int       `json:"id"`
 	UserID    int       `json:"user_id"`
@@ -11,3 +63,18 @@ type Notification struct {
 	IsRead    bool      `json:"is_read"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// NotificationFilter narrows an inbox page. Empty Type and nil IsRead match
+// everything.
+type NotificationFilter struct {
+	Type   string
+	IsRead *bool
+}
+
+// NotificationPage is one page of the inbox, newest first. NextCursor is
+// passed back as ?before= to fetch older notifications and is nil on the last
+// page.
+type NotificationPage struct {
+	Notifications []*Notification `json:"notifications"`
+	NextCursor    *int            `json:"next_cursor"`
+}
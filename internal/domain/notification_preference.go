@@ -0,0 +1,106 @@
+package domain
+
+import "time"
+
+const (
+	NotificationChannelInApp   = "in_app"
+	NotificationChannelPush    = "push"
+	NotificationChannelEmail   = "email"
+	NotificationChannelWebhook = "webhook"
+)
+
+// Digest modes batch unread notifications into one summary email per period
+// instead of an email per notification.
+const (
+	NotificationDigestOff    = "off"
+	NotificationDigestDaily  = "daily"
+	NotificationDigestWeekly = "weekly"
+)
+
+// NotificationChannels says where a notification type is delivered. InApp
+// keeps it in the inbox and Push sends it to open WebSocket connections.
+type NotificationChannels struct {
+	InApp   bool `json:"in_app"`
+	Push    bool `json:"push"`
+	Email   bool `json:"email"`
+	Webhook bool `json:"webhook"`
+}
+
+// DefaultNotificationChannels applies to any type the user hasn't configured.
+var DefaultNotificationChannels = NotificationChannels{InApp: true, Push: true}
+
+type NotificationPreference struct {
+	Type string `json:"type"`
+	NotificationChannels
+}
+
+// QuietHours is a daily HH:MM window in the user's timezone during which
+// push and email are suppressed rather than deferred. End before Start wraps
+// past midnight.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type NotificationSettings struct {
+	UserID      int                      `json:"user_id"`
+	Timezone    string                   `json:"timezone"`
+	QuietHours  *QuietHours              `json:"quiet_hours"`
+	Digest      string                   `json:"digest"`
+	Preferences []NotificationPreference `json:"preferences"`
+}
+
+// NotificationDelivery is how one user wants one notification type delivered.
+type NotificationDelivery struct {
+	Channels   NotificationChannels
+	Timezone   string
+	QuietHours *QuietHours
+	Digest     string
+}
+
+// DefaultNotificationDelivery applies to users with no saved settings.
+func DefaultNotificationDelivery() *NotificationDelivery {
+	return &NotificationDelivery{
+		Channels: DefaultNotificationChannels,
+		Timezone: "UTC",
+		Digest:   NotificationDigestOff,
+	}
+}
+
+// DigestSubscriber is a user with a digest mode on and when they were last
+// sent one.
+type DigestSubscriber struct {
+	UserID       int
+	Digest       string
+	Timezone     string
+	LastDigestAt *time.Time
+}
+
+// InQuietHours reports whether t falls inside the user's quiet hours.
+func (d *NotificationDelivery) InQuietHours(t time.Time) bool {
+	if d.QuietHours == nil {
+		return false
+	}
+
+	loc, err := time.LoadLocation(d.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	start, startErr := time.Parse("15:04", d.QuietHours.Start)
+	end, endErr := time.Parse("15:04", d.QuietHours.End)
+	if startErr != nil || endErr != nil {
+		return false
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	if startMinute <= endMinute {
+		return minute >= startMinute && minute < endMinute
+	}
+
+	return minute >= startMinute || minute < endMinute
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+const (
+	OfferStatusPending   = "pending"
+	OfferStatusCountered = "countered"
+	OfferStatusAccepted  = "accepted"
+	OfferStatusRejected  = "rejected"
+	OfferStatusWithdrawn = "withdrawn"
+	OfferStatusExpired   = "expired"
+)
+
+var OfferContingencies = map[string]bool{
+	"inspection":   true,
+	"financing":    true,
+	"appraisal":    true,
+	"sale_of_home": true,
+}
+
+// Offer is a negotiation between a buyer's agent and the listing agent. Its
+// current terms are the latest entry in Revisions.
+type Offer struct {
+	ID             int             `json:"id"`
+	ListingID      int             `json:"listing_id"`
+	BuyerAgentID   int             `json:"buyer_agent_id"`
+	ListingAgentID int             `json:"listing_agent_id"`
+	Status         string          `json:"status"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	Revisions      []OfferRevision `json:"revisions"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type OfferRevision struct {
+	ID            int       `json:"id"`
+	OfferID       int       `json:"offer_id"`
+	AuthorID      int       `json:"author_id"`
+	Amount        int       `json:"amount"`
+	EarnestMoney  int       `json:"earnest_money"`
+	Contingencies []string  `json:"contingencies"`
+	ClosingDate   string    `json:"closing_date"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	Message       *string   `json:"message"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// LatestRevision returns the revision holding the offer's current terms.
+func (o *Offer) LatestRevision() *OfferRevision {
+	if len(o.Revisions) == 0 {
+		return nil
+	}
+
+	return &o.Revisions[len(o.Revisions)-1]
+}
+
+// IsOpen reports whether the offer can still be accepted, rejected or countered.
+func (o *Offer) IsOpen() bool {
+	return o.Status == OfferStatusPending || o.Status == OfferStatusCountered
+}
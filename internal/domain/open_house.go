@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+type OpenHouse struct {
+	ID          int        `json:"id"`
+	ListingID   int        `json:"listing_id"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	Notes       *string    `json:"notes"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Listing     *Listing   `json:"listing,omitempty"`
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Outbox event types. They share their names with the webhook events the
+// relay forwards them as.
+const (
+	OutboxEventListingPriceChanged  = WebhookEventListingPriceChanged
+	OutboxEventListingStatusChanged = WebhookEventListingStatusChanged
+	OutboxEventFavoriteCreated      = WebhookEventFavoriteCreated
+)
+
+const (
+	OutboxEventPending   = "pending"
+	OutboxEventPublished = "published"
+	// OutboxEventFailed events ran out of attempts and are no longer relayed
+	OutboxEventFailed = "failed"
+)
+
+// OutboxEvent is a domain event recorded alongside the change that raised
+// it. DedupKey is unique per event and is carried into every notification
+// and webhook delivery the event produces, so relaying it twice is harmless.
+// DeliveredTo names the consumers that have already handled it, which are
+// skipped when it is retried.
+type OutboxEvent struct {
+	ID            int             `json:"id"`
+	EventType     string          `json:"event_type"`
+	DedupKey      string          `json:"dedup_key"`
+	Payload       json.RawMessage `json:"payload"`
+	DeliveredTo   []string        `json:"delivered_to"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     *string         `json:"last_error"`
+	PublishedAt   *time.Time      `json:"published_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
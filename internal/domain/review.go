@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+const (
+	ReviewStatusPublished = "published"
+	ReviewStatusHidden    = "hidden"
+)
+
+var ReviewStatuses = map[string]bool{
+	ReviewStatusPublished: true,
+	ReviewStatusHidden:    true,
+}
+
+type Review struct {
+	ID         int        `json:"id"`
+	AgentID    int        `json:"agent_id"`
+	ReviewerID int        `json:"reviewer_id"`
+	Reviewer   string     `json:"reviewer"`
+	Rating     int        `json:"rating"`
+	Body       *string    `json:"body"`
+	Status     string     `json:"status"`
+	Reply      *string    `json:"reply"`
+	RepliedAt  *time.Time `json:"replied_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -11,4 +11,15 @@ type ContextSessionData struct {
 	SessionID string
 	UserID    int
 	Role      string
+
+	// BrokerageID is the brokerage the user belongs to, if any
+	BrokerageID *int
+}
+
+// IsBrokerOf reports whether the user is a broker of brokerageId.
+func (c *ContextSessionData) IsBrokerOf(brokerageId *int) bool {
+	return c.Role == "broker" &&
+		c.BrokerageID != nil &&
+		brokerageId != nil &&
+		*c.BrokerageID == *brokerageId
 }
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+const (
+	ShowingStatusRequested = "requested"
+	ShowingStatusProposed  = "proposed"
+	ShowingStatusConfirmed = "confirmed"
+	ShowingStatusDeclined  = "declined"
+)
+
+type Showing struct {
+	ID             int           `json:"id"`
+	ListingID      int           `json:"listing_id"`
+	BuyerID        int           `json:"buyer_id"`
+	AgentID        int           `json:"agent_id"`
+	Status         string        `json:"status"`
+	Message        *string       `json:"message"`
+	ScheduledStart *time.Time    `json:"scheduled_start"`
+	ScheduledEnd   *time.Time    `json:"scheduled_end"`
+	Slots          []ShowingSlot `json:"slots"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type ShowingSlot struct {
+	ID         int       `json:"id"`
+	ShowingID  int       `json:"showing_id"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	ProposedBy int       `json:"proposed_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -13,6 +13,12 @@ type User struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	Role         string    `json:"role"`
+	BrokerageID  *int      `json:"brokerage_id"`
+	TeamID       *int      `json:"team_id"`
+
+	// ShareFavoritesWithAgents lets listing agents see that the user saved
+	// their listing
+	ShareFavoritesWithAgents bool `json:"share_favorites_with_agents"`
 }
 
 type Agent struct {
@@ -21,7 +27,28 @@ type Agent struct {
 	LastName     string    `json:"last_name"`
 	Email        string    `json:"email"`
 	PasswordHash string    `json:"-"`
+	Timezone     string    `json:"timezone"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	Listings     []Listing `json:"listings"`
+
+	// AverageRating is nil until the agent has a published review
+	AverageRating *float64 `json:"average_rating"`
+	ReviewCount   int      `json:"review_count"`
+	FollowerCount int      `json:"follower_count"`
+
+	TeamID    *int               `json:"team_id"`
+	Brokerage *BrokerageBranding `json:"brokerage"`
+
+	AgentProfile
+}
+
+const AgentSortRating = "rating"
+
+// AgentFilter narrows GET /agents. Empty fields match every agent.
+type AgentFilter struct {
+	Sort      string
+	ZipCode   string
+	Language  string
+	Specialty string
 }
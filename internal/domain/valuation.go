@@ -0,0 +1,15 @@
+package domain
+
+type Comparable struct {
+	Listing       *Listing `json:"listing"`
+	Score         float64  `json:"score"`
+	AdjustedPrice int      `json:"adjusted_price"`
+}
+
+type ValuationEstimate struct {
+	ListingID   int          `json:"listing_id"`
+	Estimate    int          `json:"estimate"`
+	Low         int          `json:"low"`
+	High        int          `json:"high"`
+	Comparables []Comparable `json:"comparables"`
+}
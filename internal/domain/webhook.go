@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook event types agents can subscribe to.
+const (
+	WebhookEventLeadCreated          = "lead.created"
+	WebhookEventLeadAssigned         = "lead.assigned"
+	WebhookEventFavoriteCreated      = "favorite.created"
+	WebhookEventListingPriceChanged  = "listing.price_changed"
+	WebhookEventListingStatusChanged = "listing.status_changed"
+	WebhookEventShowingRequested     = "showing.requested"
+	WebhookEventShowingConfirmed     = "showing.confirmed"
+	WebhookEventOfferSubmitted       = "offer.submitted"
+	WebhookEventOfferCountered       = "offer.countered"
+	WebhookEventOfferAccepted        = "offer.accepted"
+	WebhookEventOfferRejected        = "offer.rejected"
+	WebhookEventOfferWithdrawn       = "offer.withdrawn"
+	WebhookEventOfferExpired         = "offer.expired"
+	// WebhookEventNotificationCreated carries notifications the agent routed
+	// to the webhook channel in their notification settings
+	WebhookEventNotificationCreated = "notification.created"
+)
+
+// WebhookEvents lists every event type a subscription may ask for.
+var WebhookEvents = map[string]bool{
+	WebhookEventLeadCreated:          true,
+	WebhookEventLeadAssigned:         true,
+	WebhookEventFavoriteCreated:      true,
+	WebhookEventListingPriceChanged:  true,
+	WebhookEventListingStatusChanged: true,
+	WebhookEventShowingRequested:     true,
+	WebhookEventShowingConfirmed:     true,
+	WebhookEventOfferSubmitted:       true,
+	WebhookEventOfferCountered:       true,
+	WebhookEventOfferAccepted:        true,
+	WebhookEventOfferRejected:        true,
+	WebhookEventOfferWithdrawn:       true,
+	WebhookEventOfferExpired:         true,
+
+	WebhookEventNotificationCreated: true,
+}
+
+const (
+	WebhookDeliveryPending    = "pending"
+	WebhookDeliveryDelivered  = "delivered"
+	WebhookDeliveryDeadLetter = "dead_letter"
+)
+
+// WebhookSubscription sends the listed events for one agent, or for every
+// agent in a brokerage, to URL. Secret is only returned when the
+// subscription is created.
+type WebhookSubscription struct {
+	ID          int       `json:"id"`
+	AgentID     *int      `json:"agent_id"`
+	BrokerageID *int      `json:"brokerage_id"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"event_types"`
+	Secret      string    `json:"secret,omitempty"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event queued for one subscription. It stays pending
+// while retries remain and moves to dead_letter once they run out.
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	SubscriptionID int             `json:"subscription_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+
+	// URL and Secret are filled in on deliveries claimed for sending
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// WebhookDeliveryPage is one page of a subscription's delivery log, newest
+// first. NextCursor is passed back as ?before= and is nil on the last page.
+type WebhookDeliveryPage struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	NextCursor *int               `json:"next_cursor"`
+}
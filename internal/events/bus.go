@@ -0,0 +1,136 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"server/internal/domain"
+)
+
+type subscriber struct {
+	name   string
+	handle func(ctx context.Context, event Event) error
+}
+
+// call runs the subscriber, turning a panic into an error so it cannot take
+// down the publisher or the subscribers after it.
+func (s subscriber) call(ctx context.Context, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return s.handle(ctx, event)
+}
+
+// Bus delivers published events to their subscribers synchronously, in the
+// order the subscribers registered. A nil Bus drops every event, so services
+// can run without one.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]subscriber
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: map[string][]subscriber{}}
+}
+
+// Subscribe registers fn for every published event of type E. name
+// identifies the subscriber in errors and, for events relayed from the
+// outbox, in the record of who has handled the event, so it must be unique
+// among the subscribers to E.
+func Subscribe[E Event](b *Bus, name string, fn func(ctx context.Context, event E) error) {
+	var event E
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers[event.Name()] = append(b.subscribers[event.Name()], subscriber{
+		name: name,
+		handle: func(ctx context.Context, event Event) error {
+			return fn(ctx, event.(E))
+		},
+	})
+}
+
+// Publish runs every subscriber to the event. A subscriber that fails or
+// panics does not stop the ones after it; the failures are returned joined,
+// each prefixed with the subscriber's name.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	_, err := b.publish(ctx, event, nil)
+	return err
+}
+
+// publish runs every subscriber to the event not named in skip and returns
+// the names of those that succeeded.
+func (b *Bus) publish(ctx context.Context, event Event, skip []string) ([]string, error) {
+	if b == nil {
+		return nil, nil
+	}
+
+	b.mu.RLock()
+	subscribers := b.subscribers[event.Name()]
+	b.mu.RUnlock()
+
+	var delivered []string
+	var errs []error
+	for _, s := range subscribers {
+		if slices.Contains(skip, s.name) {
+			continue
+		}
+
+		if err := s.call(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			continue
+		}
+
+		delivered = append(delivered, s.name)
+	}
+
+	return delivered, errors.Join(errs...)
+}
+
+// PublishOutboxEvent is the outbox relay handler. It decodes the relayed
+// event into its typed form, stamped with the outbox dedup key, and
+// publishes it to the subscribers not yet in event.DeliveredTo, adding those
+// that succeed. Any subscriber failure is returned so the relay retries, and
+// the retry only reaches the subscribers that failed.
+func (b *Bus) PublishOutboxEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	var typed Event
+
+	switch event.EventType {
+	case NameListingPriceChanged:
+		var change ListingPriceChanged
+		if err := json.Unmarshal(event.Payload, &change); err != nil {
+			return err
+		}
+		change.DedupKey = event.DedupKey
+		typed = change
+	case NameListingStatusChanged:
+		var change ListingStatusChanged
+		if err := json.Unmarshal(event.Payload, &change); err != nil {
+			return err
+		}
+		change.DedupKey = event.DedupKey
+		typed = change
+	case NameFavoriteAdded:
+		var favorite FavoriteAdded
+		if err := json.Unmarshal(event.Payload, &favorite); err != nil {
+			return err
+		}
+		favorite.DedupKey = event.DedupKey
+		typed = favorite
+	default:
+		return fmt.Errorf("Unknown outbox event type %q", event.EventType)
+	}
+
+	delivered, err := b.publish(ctx, typed, event.DeliveredTo)
+	event.DeliveredTo = append(event.DeliveredTo, delivered...)
+
+	return err
+}
@@ -0,0 +1,156 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"server/internal/domain"
+)
+
+func TestPublishOrder(t *testing.T) {
+	bus := NewBus()
+
+	var ran []string
+	record := func(name string) func(ctx context.Context, event FavoriteAdded) error {
+		return func(ctx context.Context, event FavoriteAdded) error {
+			ran = append(ran, name)
+			return nil
+		}
+	}
+
+	Subscribe(bus, "notifications", record("notifications"))
+	Subscribe(bus, "webhooks", record("webhooks"))
+	Subscribe(bus, "ws", record("ws"))
+	Subscribe(bus, "other", func(ctx context.Context, event FavoriteRemoved) error {
+		ran = append(ran, "removed")
+		return nil
+	})
+
+	if err := bus.Publish(context.Background(), FavoriteAdded{ListingID: 7}); err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	expected := []string{"notifications", "webhooks", "ws"}
+	if !reflect.DeepEqual(ran, expected) {
+		t.Errorf("Expected %v, received %v", expected, ran)
+	}
+}
+
+func TestPublishIsolatesFailures(t *testing.T) {
+	bus := NewBus()
+
+	var received []int
+	Subscribe(bus, "notifications", func(ctx context.Context, event ListingPriceChanged) error {
+		return errors.New("db down")
+	})
+	Subscribe(bus, "analytics", func(ctx context.Context, event ListingPriceChanged) error {
+		panic("nil map")
+	})
+	Subscribe(bus, "listing_cache", func(ctx context.Context, event ListingPriceChanged) error {
+		received = append(received, event.ListingID)
+		return nil
+	})
+
+	err := bus.Publish(context.Background(), ListingPriceChanged{ListingID: 7})
+
+	if !reflect.DeepEqual(received, []int{7}) {
+		t.Errorf("Expected later subscriber to receive listing 7, received %v", received)
+	}
+
+	if err == nil {
+		t.Fatal("Expected the failures to be returned")
+	}
+
+	for _, expected := range []string{"notifications: db down", "analytics: panic: nil map"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in %q", expected, err.Error())
+		}
+	}
+}
+
+func TestNilBus(t *testing.T) {
+	var bus *Bus
+
+	if err := bus.Publish(context.Background(), ListingCreated{}); err != nil {
+		t.Errorf("Expected nil, received %v", err)
+	}
+}
+
+func TestPublishOutboxEvent(t *testing.T) {
+	bus := NewBus()
+
+	var received FavoriteAdded
+	Subscribe(bus, "notifications", func(ctx context.Context, event FavoriteAdded) error {
+		received = event
+		return nil
+	})
+
+	payload, _ := json.Marshal(FavoriteAdded{FavoriteID: 5, UserID: 8, ListingID: 7, AgentID: 2})
+	err := bus.PublishOutboxEvent(context.Background(), &domain.OutboxEvent{
+		EventType: domain.OutboxEventFavoriteCreated,
+		DedupKey:  "favorite.created:5",
+		Payload:   payload,
+	})
+	if err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	expected := FavoriteAdded{
+		FavoriteID: 5,
+		UserID:     8,
+		ListingID:  7,
+		AgentID:    2,
+		DedupKey:   "favorite.created:5",
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("Expected %+v, received %+v", expected, received)
+	}
+
+	err = bus.PublishOutboxEvent(context.Background(), &domain.OutboxEvent{
+		EventType: "listing.deleted",
+		Payload:   []byte(`{}`),
+	})
+	if err == nil {
+		t.Error("Expected unknown outbox event type to be rejected")
+	}
+}
+
+func TestPublishOutboxEventSkipsDelivered(t *testing.T) {
+	bus := NewBus()
+
+	var ran []string
+	Subscribe(bus, "notifications", func(ctx context.Context, event ListingStatusChanged) error {
+		ran = append(ran, "notifications")
+		return nil
+	})
+	Subscribe(bus, "webhooks", func(ctx context.Context, event ListingStatusChanged) error {
+		ran = append(ran, "webhooks")
+		return errors.New("db down")
+	})
+	Subscribe(bus, "listing_cache", func(ctx context.Context, event ListingStatusChanged) error {
+		ran = append(ran, "listing_cache")
+		return nil
+	})
+
+	event := &domain.OutboxEvent{
+		EventType:   domain.OutboxEventListingStatusChanged,
+		Payload:     []byte(`{"listing_id":7}`),
+		DeliveredTo: []string{"notifications"},
+	}
+
+	if err := bus.PublishOutboxEvent(context.Background(), event); err == nil {
+		t.Fatal("Expected the webhooks failure to be returned")
+	}
+
+	if expected := []string{"webhooks", "listing_cache"}; !reflect.DeepEqual(ran, expected) {
+		t.Errorf("Expected %v, received %v", expected, ran)
+	}
+
+	if expected := []string{"notifications", "listing_cache"}; !reflect.DeepEqual(event.DeliveredTo, expected) {
+		t.Errorf("Expected %v, received %v", expected, event.DeliveredTo)
+	}
+}
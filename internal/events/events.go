@@ -0,0 +1,79 @@
+// Package events is the in-process domain event bus. Services publish typed
+// events and subscribers such as notifications, webhooks, WebSocket fan-out
+// and cache invalidation register for the ones they care about, without the
+// publisher knowing who is listening.
+//
+// Events that must not be lost are recorded in the outbox by the repository
+// that makes the change and reach the bus through the outbox relay. Those
+// carry a DedupKey, since the relay may publish them more than once.
+package events
+
+import (
+	"time"
+
+	"server/internal/domain"
+)
+
+// Event names. Events relayed from the outbox share their outbox and
+// webhook event type.
+const (
+	NameListingCreated       = "listing.created"
+	NameListingPriceChanged  = domain.OutboxEventListingPriceChanged
+	NameListingStatusChanged = domain.OutboxEventListingStatusChanged
+	NameFavoriteAdded        = domain.OutboxEventFavoriteCreated
+	NameFavoriteRemoved      = "favorite.removed"
+)
+
+type Event interface {
+	Name() string
+}
+
+type ListingCreated struct {
+	Listing *domain.Listing
+}
+
+func (ListingCreated) Name() string { return NameListingCreated }
+
+type ListingPriceChanged struct {
+	ListingID int       `json:"listing_id"`
+	AgentID   int       `json:"agent_id"`
+	Address   string    `json:"address"`
+	OldPrice  int       `json:"old_price"`
+	NewPrice  int       `json:"new_price"`
+	ChangedAt time.Time `json:"changed_at"`
+	DedupKey  string    `json:"-"`
+}
+
+func (ListingPriceChanged) Name() string { return NameListingPriceChanged }
+
+type ListingStatusChanged struct {
+	ListingID int       `json:"listing_id"`
+	AgentID   int       `json:"agent_id"`
+	Address   string    `json:"address"`
+	OldStatus string    `json:"old_status"`
+	NewStatus string    `json:"new_status"`
+	ChangedAt time.Time `json:"changed_at"`
+	DedupKey  string    `json:"-"`
+}
+
+func (ListingStatusChanged) Name() string { return NameListingStatusChanged }
+
+type FavoriteAdded struct {
+	FavoriteID int       `json:"favorite_id"`
+	UserID     int       `json:"user_id"`
+	ListingID  int       `json:"listing_id"`
+	AgentID    int       `json:"agent_id"`
+	Address    string    `json:"address"`
+	CreatedAt  time.Time `json:"created_at"`
+	DedupKey   string    `json:"-"`
+}
+
+func (FavoriteAdded) Name() string { return NameFavoriteAdded }
+
+type FavoriteRemoved struct {
+	UserID    int `json:"user_id"`
+	ListingID int `json:"listing_id"`
+	AgentID   int `json:"agent_id"`
+}
+
+func (FavoriteRemoved) Name() string { return NameFavoriteRemoved }
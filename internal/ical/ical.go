@@ -0,0 +1,112 @@
+// Package ical writes minimal RFC 5545 calendars for subscription feeds.
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	timeFormat    = "20060102T150405Z"
+	maxLineOctets = 75
+)
+
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	Cancelled   bool
+	Stamp       time.Time
+}
+
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Write renders cal as a text/calendar document with CRLF line endings.
+func Write(w io.Writer, cal Calendar) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Live Listings//Open Houses//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escape(cal.Name),
+	}
+
+	for _, event := range cal.Events {
+		status := "CONFIRMED"
+		if event.Cancelled {
+			status = "CANCELLED"
+		}
+
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+event.UID,
+			"DTSTAMP:"+event.Stamp.UTC().Format(timeFormat),
+			"DTSTART:"+event.Start.UTC().Format(timeFormat),
+			"DTEND:"+event.End.UTC().Format(timeFormat),
+			"SUMMARY:"+escape(event.Summary),
+		)
+
+		if event.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escape(event.Description))
+		}
+
+		if event.Location != "" {
+			lines = append(lines, "LOCATION:"+escape(event.Location))
+		}
+
+		lines = append(lines, "STATUS:"+status, "END:VEVENT")
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := fmt.Fprint(w, fold(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// fold splits lines longer than 75 octets, continuing them with a leading
+// space, without breaking multi-byte characters.
+func fold(line string) string {
+	if len(line) <= maxLineOctets {
+		return line
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > maxLineOctets {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2025, 11, 15, 13, 0, 0, 0, time.FixedZone("CST", -6*60*60))
+
+	t.Run("Events render with UTC times and escaped text", func(t *testing.T) {
+		var b strings.Builder
+		err := Write(&b, Calendar{
+			Name: "Open houses",
+			Events: []Event{
+				{
+					UID:      "open-house-1@livelistings",
+					Start:    start,
+					End:      start.Add(2 * time.Hour),
+					Summary:  "Open house: 123 Test St, Nashville, TN",
+					Location: "123 Test St; Nashville",
+					Stamp:    start,
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Expected success, received %v", err)
+		}
+
+		out := b.String()
+		for _, want := range []string{
+			"BEGIN:VCALENDAR\r\n",
+			"DTSTART:20251115T190000Z\r\n",
+			"DTEND:20251115T210000Z\r\n",
+			"SUMMARY:Open house: 123 Test St\\, Nashville\\, TN\r\n",
+			"LOCATION:123 Test St\\; Nashville\r\n",
+			"STATUS:CONFIRMED\r\n",
+			"END:VCALENDAR\r\n",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected output to contain %q, received %q", want, out)
+			}
+		}
+	})
+
+	t.Run("Cancelled events are marked cancelled", func(t *testing.T) {
+		var b strings.Builder
+		Write(&b, Calendar{Events: []Event{{UID: "1", Start: start, End: start, Cancelled: true}}})
+
+		if !strings.Contains(b.String(), "STATUS:CANCELLED\r\n") {
+			t.Errorf("Expected cancelled status, received %q", b.String())
+		}
+	})
+
+	t.Run("Long lines are folded at 75 octets", func(t *testing.T) {
+		folded := fold("DESCRIPTION:" + strings.Repeat("é", 60))
+
+		for _, line := range strings.Split(folded, "\r\n") {
+			if len(line) > maxLineOctets {
+				t.Errorf("Expected line of at most 75 octets, received %d", len(line))
+			}
+		}
+
+		if strings.ReplaceAll(folded, "\r\n ", "") != "DESCRIPTION:"+strings.Repeat("é", 60) {
+			t.Error("Expected unfolded line to match original")
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Message is a rendered email with both HTML and plain text bodies.
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Mailer delivers a single message. Implementations may block on the network,
+// so request paths should go through a Queue instead of calling one directly.
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+const (
+	DriverSMTP = "smtp"
+	DriverLog  = "log"
+	DriverFile = "file"
+)
+
+type Config struct {
+	Driver   string
+	From     string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// Dir is where the file driver writes .eml files
+	Dir string
+	// AppURL prefixes links to listings and account pages in email bodies
+	AppURL string
+}
+
+const (
+	defaultFrom   = "no-reply@localhost"
+	defaultHost   = "localhost"
+	defaultPort   = 1025
+	defaultDir    = "mail"
+	defaultAppURL = "http://localhost:8080"
+)
+
+// ConfigFromEnv reads MAIL_DRIVER, MAIL_FROM, MAIL_DIR, APP_URL and the
+// SMTP_* settings. Mail is logged rather than sent unless MAIL_DRIVER is set,
+// and SMTP defaults to a local MailHog on port 1025.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Driver:   DriverLog,
+		From:     defaultFrom,
+		Host:     defaultHost,
+		Port:     defaultPort,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		Dir:      defaultDir,
+		AppURL:   defaultAppURL,
+	}
+
+	if driver := os.Getenv("MAIL_DRIVER"); driver != "" {
+		cfg.Driver = driver
+	}
+
+	if from := os.Getenv("MAIL_FROM"); from != "" {
+		cfg.From = from
+	}
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		cfg.Host = host
+	}
+
+	if port, err := strconv.Atoi(os.Getenv("SMTP_PORT")); err == nil && port > 0 {
+		cfg.Port = port
+	}
+
+	if dir := os.Getenv("MAIL_DIR"); dir != "" {
+		cfg.Dir = dir
+	}
+
+	if appURL := os.Getenv("APP_URL"); appURL != "" {
+		cfg.AppURL = appURL
+	}
+
+	return cfg
+}
+
+// NewMailer builds the Mailer selected by cfg.Driver.
+func NewMailer(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case DriverSMTP:
+		return NewSMTPMailer(cfg), nil
+	case DriverLog:
+		return NewLogMailer(), nil
+	case DriverFile:
+		return NewFileMailer(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
+
+// encode renders msg as a multipart/alternative MIME message.
+func encode(from string, msg *Message, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", msg.Text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	}
+
+	for _, part := range parts {
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "From: %s\r\n", from)
+	fmt.Fprintf(&out, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&out, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&out, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	out.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&out, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", writer.Boundary())
+	out.Write(body.Bytes())
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,133 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"server/internal/domain"
+)
+
+func TestRender(t *testing.T) {
+	listing := &domain.Listing{
+		Address:      "12 <Main> St",
+		City:         "Austin",
+		State:        "TX",
+		ZipCode:      "78701",
+		PropertyType: "condo",
+		Price:        1250000,
+		Beds:         3,
+		Baths:        2,
+		SqFt:         1800,
+	}
+
+	msg, err := Render(TemplateNotification, "buyer@example.com", NotificationData{
+		Name:       "Ada",
+		Message:    "Price dropped",
+		Listing:    listing,
+		ListingURL: "http://localhost:8080/listings/1",
+	})
+	if err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if msg.Subject != "Price dropped: 12 <Main> St" {
+		t.Errorf("Expected %v, received %v", "Price dropped: 12 <Main> St", msg.Subject)
+	}
+
+	for _, want := range []string{"$1,250,000", "12 <Main> St, Austin, TX 78701", "3 bd"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("Expected text body to contain %q, received %q", want, msg.Text)
+		}
+	}
+
+	if !strings.Contains(msg.HTML, "12 &lt;Main&gt; St") {
+		t.Errorf("Expected HTML body to escape the address, received %q", msg.HTML)
+	}
+
+	if _, err := Render("missing", "buyer@example.com", nil); err == nil {
+		t.Errorf("Expected an error for an unknown template")
+	}
+}
+
+type flakyMailer struct {
+	mu       sync.Mutex
+	failures int
+	sent     []*Message
+	done     chan struct{}
+}
+
+func (m *flakyMailer) Send(ctx context.Context, msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("connection refused")
+	}
+
+	m.sent = append(m.sent, msg)
+	close(m.done)
+
+	return nil
+}
+
+func TestQueueRetries(t *testing.T) {
+	mailer := &flakyMailer{failures: 2, done: make(chan struct{})}
+	queue := NewQueue(mailer, 1)
+	queue.backoff = time.Millisecond
+
+	if err := queue.Send(context.Background(), &Message{To: "a@example.com"}); err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if err := queue.Send(context.Background(), &Message{To: "b@example.com"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected %v, received %v", ErrQueueFull, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.Run(ctx, 1)
+
+	select {
+	case <-mailer.done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the message to be delivered after retrying")
+	}
+
+	if len(mailer.sent) != 1 || mailer.sent[0].To != "a@example.com" {
+		t.Errorf("Expected one delivery to a@example.com, received %v", mailer.sent)
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	mailer := NewFileMailer(Config{From: "no-reply@example.com", Dir: dir})
+
+	err := mailer.Send(context.Background(), &Message{
+		To:      "buyer@example.com",
+		Subject: "Hello",
+		Text:    "plain body",
+		HTML:    "<p>html body</p>",
+	})
+	if err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file, received %d", len(files))
+	}
+
+	data, _ := os.ReadFile(files[0])
+	for _, want := range []string{"To: buyer@example.com", "Subject: Hello", "plain body", "<p>html body</p>"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected message to contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var ErrQueueFull = errors.New("mail queue is full")
+
+const (
+	DefaultQueueSize    = 1000
+	DefaultQueueWorkers = 4
+
+	maxSendAttempts = 5
+	initialBackoff  = 2 * time.Second
+	sendTimeout     = time.Minute
+)
+
+// Queue is a Mailer that hands messages to background workers, so callers
+// never wait on the network. Failed sends are retried with exponential
+// backoff before being dropped and logged.
+type Queue struct {
+	mailer  Mailer
+	pending chan *Message
+	backoff time.Duration
+}
+
+func NewQueue(mailer Mailer, size int) *Queue {
+	return &Queue{
+		mailer:  mailer,
+		pending: make(chan *Message, size),
+		backoff: initialBackoff,
+	}
+}
+
+// Send enqueues msg and returns immediately. It fails with ErrQueueFull
+// rather than blocking when the workers have fallen behind.
+func (q *Queue) Send(ctx context.Context, msg *Message) error {
+	select {
+	case q.pending <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Run delivers queued messages on the given number of workers until ctx is
+// cancelled. Messages still queued at that point are logged and dropped.
+func (q *Queue) Run(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg := <-q.pending:
+					q.deliver(ctx, msg)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if dropped := len(q.pending); dropped > 0 {
+		slog.Warn("Dropped queued email on shutdown", slog.Int("count", dropped))
+	}
+}
+
+func (q *Queue) deliver(ctx context.Context, msg *Message) {
+	backoff := q.backoff
+
+	for attempt := 1; ; attempt++ {
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := q.mailer.Send(sendCtx, msg)
+		cancel()
+
+		if err == nil {
+			return
+		}
+
+		if attempt == maxSendAttempts || ctx.Err() != nil {
+			slog.Error(
+				"Failed to send email",
+				slog.String("to", msg.To),
+				slog.String("subject", msg.Subject),
+				slog.Int("attempts", attempt),
+				slog.String("error", err.Error()),
+			)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
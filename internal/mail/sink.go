@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// LogMailer logs each message instead of sending it, for local development.
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	slog.Info(
+		"Email",
+		slog.String("to", msg.To),
+		slog.String("subject", msg.Subject),
+		slog.String("text", msg.Text),
+	)
+
+	return nil
+}
+
+// FileMailer writes each message to Dir as an .eml file that mail clients
+// can open directly.
+type FileMailer struct {
+	from string
+	dir  string
+	seq  atomic.Int64
+}
+
+func NewFileMailer(cfg Config) *FileMailer {
+	return &FileMailer{from: cfg.From, dir: cfg.Dir}
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	now := time.Now()
+
+	data, err := encode(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%d.eml", now.UTC().Format("20060102T150405.000000000"), m.seq.Add(1))
+
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0o644)
+}
@@ -0,0 +1,155 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+
+	"server/internal/domain"
+)
+
+const (
+	TemplateNotification     = "notification"
+	TemplateWelcome          = "welcome"
+	TemplatePasswordReset    = "password_reset"
+	TemplateShowingConfirmed = "showing_confirmed"
+	TemplateDigest           = "digest"
+)
+
+// NotificationData fills the notification template. Listing is nil for
+// notifications that are not about a listing.
+type NotificationData struct {
+	Name       string
+	Message    string
+	Listing    *domain.Listing
+	ListingURL string
+}
+
+type WelcomeData struct {
+	Name   string
+	AppURL string
+}
+
+type PasswordResetData struct {
+	Name     string
+	ResetURL string
+}
+
+// ShowingConfirmedData fills the showing confirmation. Start and End should
+// already be in the recipient's timezone.
+type ShowingConfirmedData struct {
+	Name       string
+	Listing    *domain.Listing
+	ListingURL string
+	Start      time.Time
+	End        time.Time
+}
+
+// DigestData fills the digest summary, with notifications grouped by the
+// listing they are about.
+type DigestData struct {
+	Name     string
+	Period   string
+	Count    int
+	Listings []DigestListing
+	InboxURL string
+}
+
+// DigestListing is one listing's notifications in a digest. Listing is nil
+// when it could not be loaded.
+type DigestListing struct {
+	Listing       *domain.Listing
+	ListingURL    string
+	Notifications []*domain.Notification
+}
+
+//go:embed templates
+var templateFS embed.FS
+
+var funcs = map[string]any{
+	"price":    formatPrice,
+	"datetime": func(t time.Time) string { return t.Format("Mon, Jan 2, 2006 at 3:04 PM MST") },
+	"clock":    func(t time.Time) string { return t.Format("3:04 PM") },
+}
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var templates = mustParseTemplates(
+	TemplateNotification,
+	TemplateWelcome,
+	TemplatePasswordReset,
+	TemplateShowingConfirmed,
+	TemplateDigest,
+)
+
+// mustParseTemplates loads each name from name.txt, which also defines the
+// "subject" block, and name.html, which is wrapped in the shared layout.
+func mustParseTemplates(names ...string) map[string]emailTemplate {
+	parsed := make(map[string]emailTemplate, len(names))
+
+	for _, name := range names {
+		text := texttemplate.Must(
+			texttemplate.New(name+".txt").Funcs(funcs).ParseFS(templateFS, "templates/"+name+".txt"),
+		)
+		html := htmltemplate.Must(
+			htmltemplate.New("layout.html").
+				Funcs(funcs).
+				ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html"),
+		)
+
+		parsed[name] = emailTemplate{text: text, html: html}
+	}
+
+	return parsed
+}
+
+// Render builds a message to the given address from the named template.
+func Render(name string, to string, data any) (*Message, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		To:      to,
+		Subject: subject.String(),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// formatPrice renders whole dollars with thousands separators.
+func formatPrice(price int) string {
+	digits := strconv.Itoa(price)
+
+	var out []byte
+	for i, digit := range []byte(digits) {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out = append(out, ',')
+		}
+		out = append(out, digit)
+	}
+
+	return "$" + string(out)
+}
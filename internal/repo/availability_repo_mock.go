@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"context"
+
+	"server/internal/domain"
+)
+
+type AvailabilityRepoMock struct {
+	GetAvailabilityFunc     func(ctx context.Context, agentId int) (*domain.AgentAvailability, error)
+	ReplaceAvailabilityFunc func(ctx context.Context, availability *domain.AgentAvailability) (*domain.AgentAvailability, error)
+	CreateBlackoutFunc      func(ctx context.Context, blackout *domain.AvailabilityBlackout) (*domain.AvailabilityBlackout, error)
+	DeleteBlackoutFunc      func(ctx context.Context, agentId int, blackoutId int) error
+}
+
+func (a *AvailabilityRepoMock) GetAvailability(
+	ctx context.Context,
+	agentId int,
+) (*domain.AgentAvailability, error) {
+	return a.GetAvailabilityFunc(ctx, agentId)
+}
+
+func (a *AvailabilityRepoMock) ReplaceAvailability(
+	ctx context.Context,
+	availability *domain.AgentAvailability,
+) (*domain.AgentAvailability, error) {
+	return a.ReplaceAvailabilityFunc(ctx, availability)
+}
+
+func (a *AvailabilityRepoMock) CreateBlackout(
+	ctx context.Context,
+	blackout *domain.AvailabilityBlackout,
+) (*domain.AvailabilityBlackout, error) {
+	return a.CreateBlackoutFunc(ctx, blackout)
+}
+
+func (a *AvailabilityRepoMock) DeleteBlackout(ctx context.Context, agentId int, blackoutId int) error {
+	return a.DeleteBlackoutFunc(ctx, agentId, blackoutId)
+}
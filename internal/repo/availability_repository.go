@@ -0,0 +1,244 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"server/internal/domain"
+)
+
+type IAvailabilityRepo interface {
+	GetAvailability(ctx context.Context, agentId int) (*domain.AgentAvailability, error)
+	ReplaceAvailability(
+		ctx context.Context,
+		availability *domain.AgentAvailability,
+	) (*domain.AgentAvailability, error)
+	CreateBlackout(
+		ctx context.Context,
+		blackout *domain.AvailabilityBlackout,
+	) (*domain.AvailabilityBlackout, error)
+	DeleteBlackout(ctx context.Context, agentId int, blackoutId int) error
+}
+
+type AvailabilityRepository struct {
+	db *sql.DB
+}
+
+func NewAvailabilityRepository(db *sql.DB) *AvailabilityRepository {
+	return &AvailabilityRepository{db: db}
+}
+
+func (r *AvailabilityRepository) getAvailability(
+	ctx context.Context,
+	q queryer,
+	agentId int,
+) (*domain.AgentAvailability, error) {
+	availability := &domain.AgentAvailability{
+		AgentID:   agentId,
+		Rules:     []domain.AvailabilityRule{},
+		Blackouts: []domain.AvailabilityBlackout{},
+	}
+
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT timezone FROM users WHERE id = $1 AND role IN ('agent', 'broker')`,
+		agentId,
+	).Scan(&availability.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	rulesQuery := `
+		SELECT weekday, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')
+		FROM agent_availability_rules
+		WHERE agent_id = $1
+		ORDER BY weekday, start_time
+	`
+
+	rows, err := q.QueryContext(ctx, rulesQuery, agentId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule domain.AvailabilityRule
+
+		if err := rows.Scan(&rule.Weekday, &rule.StartTime, &rule.EndTime); err != nil {
+			return nil, err
+		}
+
+		availability.Rules = append(availability.Rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	blackoutsQuery := `
+		SELECT
+			id,
+			agent_id,
+			to_char(start_date, 'YYYY-MM-DD'),
+			to_char(end_date, 'YYYY-MM-DD'),
+			reason
+		FROM agent_blackouts
+		WHERE agent_id = $1 AND end_date >= CURRENT_DATE - 1
+		ORDER BY start_date
+	`
+
+	blackoutRows, err := q.QueryContext(ctx, blackoutsQuery, agentId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer blackoutRows.Close()
+
+	for blackoutRows.Next() {
+		var blackout domain.AvailabilityBlackout
+
+		if err := blackoutRows.Scan(
+			&blackout.ID,
+			&blackout.AgentID,
+			&blackout.StartDate,
+			&blackout.EndDate,
+			&blackout.Reason,
+		); err != nil {
+			return nil, err
+		}
+
+		availability.Blackouts = append(availability.Blackouts, blackout)
+	}
+
+	if err := blackoutRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return availability, nil
+}
+
+func (r *AvailabilityRepository) GetAvailability(
+	ctx context.Context,
+	agentId int,
+) (*domain.AgentAvailability, error) {
+	return r.getAvailability(ctx, r.db, agentId)
+}
+
+// ReplaceAvailability swaps the agent's timezone and weekly rules in one
+// transaction. Blackouts are managed separately and left untouched.
+func (r *AvailabilityRepository) ReplaceAvailability(
+	ctx context.Context,
+	availability *domain.AgentAvailability,
+) (*domain.AgentAvailability, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE users SET timezone = $1, updated_at = NOW() WHERE id = $2`,
+		availability.Timezone,
+		availability.AgentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`DELETE FROM agent_availability_rules WHERE agent_id = $1`,
+		availability.AgentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	insertQuery := `
+		INSERT INTO agent_availability_rules (agent_id, weekday, start_time, end_time)
+		VALUES ($1, $2, $3::time, $4::time)
+	`
+
+	for _, rule := range availability.Rules {
+		_, err := tx.ExecContext(
+			ctx,
+			insertQuery,
+			availability.AgentID,
+			rule.Weekday,
+			rule.StartTime,
+			rule.EndTime,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	saved, err := r.getAvailability(ctx, tx, availability.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (r *AvailabilityRepository) CreateBlackout(
+	ctx context.Context,
+	blackout *domain.AvailabilityBlackout,
+) (*domain.AvailabilityBlackout, error) {
+	query := `
+		INSERT INTO agent_blackouts (agent_id, start_date, end_date, reason)
+		VALUES ($1, $2::date, $3::date, $4)
+		RETURNING id
+	`
+
+	newBlackout := *blackout
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		blackout.AgentID,
+		blackout.StartDate,
+		blackout.EndDate,
+		blackout.Reason,
+	).Scan(&newBlackout.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &newBlackout, nil
+}
+
+func (r *AvailabilityRepository) DeleteBlackout(
+	ctx context.Context,
+	agentId int,
+	blackoutId int,
+) error {
+	result, err := r.db.ExecContext(
+		ctx,
+		`DELETE FROM agent_blackouts WHERE id = $1 AND agent_id = $2`,
+		blackoutId,
+		agentId,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("Blackout not found or you do not have permission")
+	}
+
+	return nil
+}
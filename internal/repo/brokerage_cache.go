@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"server/internal/domain"
+)
+
+// CachedBrokerageRepo serves a brokerage's listings, filtered by status,
+// from the listing cache. The entries live under the listing generation, so
+// any listing write drops them along with the other cached lists. Every other
+// method goes straight to the wrapped repo.
+type CachedBrokerageRepo struct {
+	IBrokerageRepo
+	listings *CachedListingRepo
+}
+
+func NewCachedBrokerageRepo(next IBrokerageRepo, listings *CachedListingRepo) *CachedBrokerageRepo {
+	return &CachedBrokerageRepo{IBrokerageRepo: next, listings: listings}
+}
+
+func (r *CachedBrokerageRepo) GetBrokerageListings(
+	ctx context.Context,
+	brokerageId int,
+	status string,
+) ([]*domain.Listing, error) {
+	key := r.listings.listKey(ctx, fmt.Sprintf("brokerage:%d:%s", brokerageId, status))
+
+	return readThrough(ctx, r.listings, key, func(ctx context.Context) ([]*domain.Listing, error) {
+		return r.IBrokerageRepo.GetBrokerageListings(ctx, brokerageId, status)
+	})
+}
+
+// SetMembership changes which brokerage an agent's listings belong to, so it
+// drops the cached lists.
+func (r *CachedBrokerageRepo) SetMembership(
+	ctx context.Context,
+	userId int,
+	brokerageId *int,
+	teamId *int,
+) error {
+	if err := r.IBrokerageRepo.SetMembership(ctx, userId, brokerageId, teamId); err != nil {
+		return err
+	}
+
+	r.listings.invalidate(ctx)
+
+	return nil
+}
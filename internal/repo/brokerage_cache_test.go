@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/cache"
+	"server/internal/domain"
+)
+
+func TestCachedBrokerageRepo(t *testing.T) {
+	price := 400000
+	members := map[int]bool{2: true}
+	calls := map[string]int{}
+
+	brokerageRepo := &BrokerageRepoMock{
+		GetBrokerageListingsFunc: func(ctx context.Context, brokerageId int, status string) ([]*domain.Listing, error) {
+			calls[status]++
+
+			var listings []*domain.Listing
+			for agentId := range members {
+				listings = append(listings, &domain.Listing{ID: agentId, AgentID: agentId, Price: price})
+			}
+			return listings, nil
+		},
+		SetMembershipFunc: func(ctx context.Context, userId int, brokerageId *int, teamId *int) error {
+			members[userId] = brokerageId != nil
+			return nil
+		},
+	}
+	listingRepo := &ListingRepoMock{
+		UpdateListingByIdFunc: func(ctx context.Context, listing *dto.UpdateListingRequest, currentUserCtx *domain.ContextSessionData, listingId int) (*domain.Listing, error) {
+			price = *listing.Price
+			return &domain.Listing{ID: listingId, Price: price}, nil
+		},
+	}
+
+	listings := NewCachedListingRepo(listingRepo, cache.NewMemoryStore(), time.Minute)
+	r := NewCachedBrokerageRepo(brokerageRepo, listings)
+	ctx := context.Background()
+
+	t.Run("Each status filter is cached separately", func(t *testing.T) {
+		r.GetBrokerageListings(ctx, 10, "active")
+		r.GetBrokerageListings(ctx, 10, "active")
+		r.GetBrokerageListings(ctx, 10, "sold")
+
+		if calls["active"] != 1 || calls["sold"] != 1 {
+			t.Errorf("Expected 1 call per status, received %v", calls)
+		}
+	})
+
+	t.Run("Listing writes drop cached brokerage listings", func(t *testing.T) {
+		newPrice := 350000
+		listings.UpdateListingById(ctx, &dto.UpdateListingRequest{Price: &newPrice}, &domain.ContextSessionData{UserID: 2}, 2)
+
+		result, _ := r.GetBrokerageListings(ctx, 10, "active")
+		if calls["active"] != 2 || result[0].Price != newPrice {
+			t.Errorf("Expected a reload at price %d, received %d calls and %+v", newPrice, calls["active"], result[0])
+		}
+	})
+
+	t.Run("Membership changes drop cached brokerage listings", func(t *testing.T) {
+		brokerageId := 10
+		r.SetMembership(ctx, 3, &brokerageId, nil)
+
+		result, _ := r.GetBrokerageListings(ctx, 10, "active")
+		if calls["active"] != 3 || len(result) != 2 {
+			t.Errorf("Expected a reload with 2 listings, received %d calls and %d listings", calls["active"], len(result))
+		}
+	})
+}
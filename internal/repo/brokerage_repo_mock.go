@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+
+	"server/internal/domain"
+)
+
+type BrokerageRepoMock struct {
+	CreateBrokerageFunc        func(ctx context.Context, brokerage *domain.Brokerage) (*domain.Brokerage, error)
+	GetBrokerageByIdFunc       func(ctx context.Context, id int) (*domain.Brokerage, error)
+	UpdateBrokerageFunc        func(ctx context.Context, brokerage *domain.Brokerage) (*domain.Brokerage, error)
+	CreateTeamFunc             func(ctx context.Context, team *domain.Team) (*domain.Team, error)
+	SetMembershipFunc          func(ctx context.Context, userId int, brokerageId *int, teamId *int) error
+	GetBrokerageAgentsFunc     func(ctx context.Context, brokerageId int) ([]*domain.Agent, error)
+	GetBrokerageListingsFunc   func(ctx context.Context, brokerageId int, status string) ([]*domain.Listing, error)
+	GetBrokerageLeadsFunc      func(ctx context.Context, brokerageId int, status string) ([]*domain.Lead, error)
+	GetBrokerageAgentStatsFunc func(ctx context.Context, brokerageId int) ([]*domain.BrokerageAgentStats, error)
+}
+
+func (b *BrokerageRepoMock) CreateBrokerage(
+	ctx context.Context,
+	brokerage *domain.Brokerage,
+) (*domain.Brokerage, error) {
+	return b.CreateBrokerageFunc(ctx, brokerage)
+}
+
+func (b *BrokerageRepoMock) GetBrokerageById(ctx context.Context, id int) (*domain.Brokerage, error) {
+	return b.GetBrokerageByIdFunc(ctx, id)
+}
+
+func (b *BrokerageRepoMock) UpdateBrokerage(
+	ctx context.Context,
+	brokerage *domain.Brokerage,
+) (*domain.Brokerage, error) {
+	return b.UpdateBrokerageFunc(ctx, brokerage)
+}
+
+func (b *BrokerageRepoMock) CreateTeam(ctx context.Context, team *domain.Team) (*domain.Team, error) {
+	return b.CreateTeamFunc(ctx, team)
+}
+
+func (b *BrokerageRepoMock) SetMembership(
+	ctx context.Context,
+	userId int,
+	brokerageId *int,
+	teamId *int,
+) error {
+	return b.SetMembershipFunc(ctx, userId, brokerageId, teamId)
+}
+
+func (b *BrokerageRepoMock) GetBrokerageAgents(
+	ctx context.Context,
+	brokerageId int,
+) ([]*domain.Agent, error) {
+	return b.GetBrokerageAgentsFunc(ctx, brokerageId)
+}
+
+func (b *BrokerageRepoMock) GetBrokerageListings(
+	ctx context.Context,
+	brokerageId int,
+	status string,
+) ([]*domain.Listing, error) {
+	return b.GetBrokerageListingsFunc(ctx, brokerageId, status)
+}
+
+func (b *BrokerageRepoMock) GetBrokerageLeads(
+	ctx context.Context,
+	brokerageId int,
+	status string,
+) ([]*domain.Lead, error) {
+	return b.GetBrokerageLeadsFunc(ctx, brokerageId, status)
+}
+
+func (b *BrokerageRepoMock) GetBrokerageAgentStats(
+	ctx context.Context,
+	brokerageId int,
+) ([]*domain.BrokerageAgentStats, error) {
+	return b.GetBrokerageAgentStatsFunc(ctx, brokerageId)
+}
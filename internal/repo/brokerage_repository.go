@@ -0,0 +1,478 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"server/internal/domain"
+)
+
+var (
+	ErrBrokerageExists    = errors.New("A brokerage with this name already exists")
+	ErrTeamNotInBrokerage = errors.New("Team does not belong to this brokerage")
+)
+
+type IBrokerageRepo interface {
+	CreateBrokerage(ctx context.Context, brokerage *domain.Brokerage) (*domain.Brokerage, error)
+	GetBrokerageById(ctx context.Context, id int) (*domain.Brokerage, error)
+	UpdateBrokerage(ctx context.Context, brokerage *domain.Brokerage) (*domain.Brokerage, error)
+	CreateTeam(ctx context.Context, team *domain.Team) (*domain.Team, error)
+	// SetMembership moves the agent or broker into brokerageId and teamId.
+	// A nil brokerageId removes them from their brokerage and team.
+	SetMembership(ctx context.Context, userId int, brokerageId *int, teamId *int) error
+	GetBrokerageAgents(ctx context.Context, brokerageId int) ([]*domain.Agent, error)
+	GetBrokerageListings(
+		ctx context.Context,
+		brokerageId int,
+		status string,
+	) ([]*domain.Listing, error)
+	GetBrokerageLeads(ctx context.Context, brokerageId int, status string) ([]*domain.Lead, error)
+	GetBrokerageAgentStats(
+		ctx context.Context,
+		brokerageId int,
+	) ([]*domain.BrokerageAgentStats, error)
+}
+
+type BrokerageRepository struct {
+	db *sql.DB
+}
+
+func NewBrokerageRepository(db *sql.DB) *BrokerageRepository {
+	return &BrokerageRepository{db: db}
+}
+
+// brokerageBrandingColumn selects the brokerages relation as a JSON object, or
+// NULL when the agent has no brokerage. Scan it with jsonColumn.
+const brokerageBrandingColumn = `
+	CASE WHEN brokerages.id IS NOT NULL THEN json_build_object(
+		'id', brokerages.id,
+		'name', brokerages.name,
+		'logo_url', brokerages.logo_url,
+		'primary_color', brokerages.primary_color,
+		'website_url', brokerages.website_url
+	) END
+`
+
+// jsonColumn scans a JSON column into dest, leaving dest untouched on NULL.
+type jsonColumn struct {
+	dest any
+}
+
+func (c jsonColumn) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(value, c.dest)
+	case string:
+		return json.Unmarshal([]byte(value), c.dest)
+	default:
+		return fmt.Errorf("Cannot scan %T into JSON", src)
+	}
+}
+
+const brokerageColumns = `
+	id,
+	name,
+	logo_url,
+	primary_color,
+	website_url,
+	created_at,
+	updated_at
+`
+
+func brokerageFields(brokerage *domain.Brokerage) []any {
+	return []any{
+		&brokerage.ID,
+		&brokerage.Name,
+		&brokerage.LogoURL,
+		&brokerage.PrimaryColor,
+		&brokerage.WebsiteURL,
+		&brokerage.CreatedAt,
+		&brokerage.UpdatedAt,
+	}
+}
+
+// CreateBrokerage returns ErrBrokerageExists if the name is taken.
+func (r *BrokerageRepository) CreateBrokerage(
+	ctx context.Context,
+	brokerage *domain.Brokerage,
+) (*domain.Brokerage, error) {
+	query := `
+		INSERT INTO brokerages (name, logo_url, primary_color, website_url)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING ` + brokerageColumns
+
+	newBrokerage := domain.Brokerage{Teams: []domain.Team{}}
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		brokerage.Name,
+		brokerage.LogoURL,
+		brokerage.PrimaryColor,
+		brokerage.WebsiteURL,
+	).Scan(brokerageFields(&newBrokerage)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBrokerageExists
+		}
+		return nil, err
+	}
+
+	return &newBrokerage, nil
+}
+
+// GetBrokerageById returns the brokerage with its teams.
+func (r *BrokerageRepository) GetBrokerageById(
+	ctx context.Context,
+	id int,
+) (*domain.Brokerage, error) {
+	query := `
+		SELECT ` + brokerageColumns + `
+		FROM brokerages
+		WHERE id = $1
+	`
+
+	var brokerage domain.Brokerage
+
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(brokerageFields(&brokerage)...); err != nil {
+		return nil, err
+	}
+
+	teamsQuery := `
+		SELECT id, brokerage_id, name, created_at
+		FROM teams
+		WHERE brokerage_id = $1
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, teamsQuery, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	brokerage.Teams = []domain.Team{}
+	for rows.Next() {
+		var team domain.Team
+
+		if err := rows.Scan(&team.ID, &team.BrokerageID, &team.Name, &team.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		brokerage.Teams = append(brokerage.Teams, team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &brokerage, nil
+}
+
+// UpdateBrokerage replaces the brokerage's name and branding.
+func (r *BrokerageRepository) UpdateBrokerage(
+	ctx context.Context,
+	brokerage *domain.Brokerage,
+) (*domain.Brokerage, error) {
+	query := `
+		UPDATE brokerages
+		SET name = $2,
+			logo_url = $3,
+			primary_color = $4,
+			website_url = $5,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + brokerageColumns
+
+	updated := domain.Brokerage{Teams: brokerage.Teams}
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		brokerage.ID,
+		brokerage.Name,
+		brokerage.LogoURL,
+		brokerage.PrimaryColor,
+		brokerage.WebsiteURL,
+	).Scan(brokerageFields(&updated)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (r *BrokerageRepository) CreateTeam(ctx context.Context, team *domain.Team) (*domain.Team, error) {
+	query := `
+		INSERT INTO teams (brokerage_id, name)
+		VALUES ($1, $2)
+		RETURNING id, brokerage_id, name, created_at
+	`
+
+	var newTeam domain.Team
+
+	err := r.db.QueryRowContext(ctx, query, team.BrokerageID, team.Name).Scan(
+		&newTeam.ID,
+		&newTeam.BrokerageID,
+		&newTeam.Name,
+		&newTeam.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &newTeam, nil
+}
+
+// SetMembership returns sql.ErrNoRows if the user is not an agent or broker,
+// and ErrTeamNotInBrokerage if teamId belongs to a different brokerage.
+func (r *BrokerageRepository) SetMembership(
+	ctx context.Context,
+	userId int,
+	brokerageId *int,
+	teamId *int,
+) error {
+	if teamId != nil {
+		var inBrokerage bool
+
+		query := `SELECT EXISTS (SELECT 1 FROM teams WHERE id = $1 AND brokerage_id = $2)`
+
+		if err := r.db.QueryRowContext(ctx, query, teamId, brokerageId).Scan(&inBrokerage); err != nil {
+			return err
+		}
+
+		if !inBrokerage {
+			return ErrTeamNotInBrokerage
+		}
+	}
+
+	query := `
+		UPDATE users
+		SET brokerage_id = $2,
+			team_id = $3,
+			updated_at = NOW()
+		WHERE id = $1 AND role IN ('agent', 'broker')
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userId, brokerageId, teamId)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *BrokerageRepository) GetBrokerageAgents(
+	ctx context.Context,
+	brokerageId int,
+) ([]*domain.Agent, error) {
+	query := `
+		SELECT ` + agentColumns + `
+		FROM users
+		` + agentRatingJoin + `
+		WHERE users.brokerage_id = $1 AND users.role IN ('agent', 'broker')
+		ORDER BY users.last_name, users.first_name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, brokerageId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	types := pgtype.NewMap()
+
+	var agents []*domain.Agent
+	for rows.Next() {
+		agent := new(domain.Agent)
+
+		if err := rows.Scan(agentFields(types, agent)...); err != nil {
+			return nil, err
+		}
+
+		agents = append(agents, agent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return agents, nil
+}
+
+// GetBrokerageListings lists the listings of every agent in the brokerage,
+// newest first. An empty status matches every listing.
+func (r *BrokerageRepository) GetBrokerageListings(
+	ctx context.Context,
+	brokerageId int,
+	status string,
+) ([]*domain.Listing, error) {
+	query := `
+		SELECT ` + listingColumns + `
+		FROM listings
+		INNER JOIN users ON users.id = listings.agent_id
+		WHERE users.brokerage_id = $1 AND ($2 = '' OR listings.status = $2)
+		ORDER BY listings.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, brokerageId, status)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var listings []*domain.Listing
+	for rows.Next() {
+		listing := new(domain.Listing)
+
+		if err := rows.Scan(listingFields(listing)...); err != nil {
+			return nil, err
+		}
+
+		listings = append(listings, listing)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return listings, nil
+}
+
+// GetBrokerageLeads lists the leads assigned to agents in the brokerage,
+// newest first. An empty status matches every lead.
+func (r *BrokerageRepository) GetBrokerageLeads(
+	ctx context.Context,
+	brokerageId int,
+	status string,
+) ([]*domain.Lead, error) {
+	query := `
+		SELECT ` + leadColumns + `
+		FROM leads
+		WHERE agent_id IN (SELECT id FROM users WHERE brokerage_id = $1)
+			AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, brokerageId, status)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var leads []*domain.Lead
+	for rows.Next() {
+		lead := new(domain.Lead)
+
+		if err := rows.Scan(leadFields(lead)...); err != nil {
+			return nil, err
+		}
+
+		leads = append(leads, lead)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return leads, nil
+}
+
+// GetBrokerageAgentStats reports listing and lead totals for each agent in the
+// brokerage, including agents with neither.
+func (r *BrokerageRepository) GetBrokerageAgentStats(
+	ctx context.Context,
+	brokerageId int,
+) ([]*domain.BrokerageAgentStats, error) {
+	query := `
+		SELECT
+			users.id,
+			users.first_name,
+			users.last_name,
+			users.team_id,
+			COALESCE(listing_stats.active, 0),
+			COALESCE(listing_stats.pending, 0),
+			COALESCE(listing_stats.sold, 0),
+			COALESCE(listing_stats.views, 0),
+			COALESCE(lead_stats.total, 0),
+			COALESCE(lead_stats.new, 0)
+		FROM users
+		LEFT JOIN (
+			SELECT
+				agent_id,
+				COUNT(*) FILTER (WHERE status = 'active') AS active,
+				COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+				COUNT(*) FILTER (WHERE status = 'sold') AS sold,
+				SUM(views) AS views
+			FROM listings
+			GROUP BY agent_id
+		) listing_stats ON listing_stats.agent_id = users.id
+		LEFT JOIN (
+			SELECT
+				agent_id,
+				COUNT(*) AS total,
+				COUNT(*) FILTER (WHERE status = 'new') AS new
+			FROM leads
+			GROUP BY agent_id
+		) lead_stats ON lead_stats.agent_id = users.id
+		WHERE users.brokerage_id = $1 AND users.role IN ('agent', 'broker')
+		ORDER BY users.last_name, users.first_name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, brokerageId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var stats []*domain.BrokerageAgentStats
+	for rows.Next() {
+		agentStats := new(domain.BrokerageAgentStats)
+
+		err := rows.Scan(
+			&agentStats.AgentID,
+			&agentStats.FirstName,
+			&agentStats.LastName,
+			&agentStats.TeamID,
+			&agentStats.ActiveListings,
+			&agentStats.PendingListings,
+			&agentStats.SoldListings,
+			&agentStats.ListingViews,
+			&agentStats.Leads,
+			&agentStats.NewLeads,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		stats = append(stats, agentStats)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
@@ -7,10 +7,38 @@ import (
 )
 
 type FavoriteRepoMock struct {
-	GetUserFavoritesFunc          func(ctx context.Context, userCtx *domain.ContextSessionData) ([]*domain.Favorite, error)
+	GetUserFavoritesFunc    func(ctx context.Context, userCtx *domain.ContextSessionData) ([]*domain.Favorite, error)
+	GetFavoriteListingsFunc func(
+		ctx context.Context,
+		userId int,
+		sort string,
+		descending bool,
+		limit int,
+		offset int,
+	) ([]*domain.Favorite, error)
 	CreateFavoriteFunc            func(ctx context.Context, favorite *domain.Favorite) (*domain.Favorite, error)
 	DeleteFavoriteByListingIdFunc func(ctx context.Context, listingId int, userCtx *domain.ContextSessionData) error
 	GetAllUserIdsByListingIdFunc  func(ctx context.Context, listingId int) (map[int]bool, error)
+	GetFavoriteCountsFunc         func(ctx context.Context, listingIds []int) (map[int]int, error)
+	GetListingFavoritersFunc      func(ctx context.Context, listingId int) ([]*domain.ListingFavoriter, error)
+	UpdateFavoriteNoteFunc        func(ctx context.Context, userId int, listingId int, note *string) (*domain.Favorite, error)
+	SetPriceAlertFunc             func(
+		ctx context.Context,
+		userId int,
+		listingId int,
+		belowPrice *int,
+		dropPercent *float64,
+	) (*domain.Favorite, error)
+	GetPriceAlertsFunc            func(ctx context.Context, listingId int, oldPrice int, newPrice int) (map[int]bool, error)
+	GetCollectionsByUserIdFunc    func(ctx context.Context, userId int) ([]*domain.FavoriteCollection, error)
+	GetCollectionByIdFunc         func(ctx context.Context, id int) (*domain.FavoriteCollection, error)
+	GetCollectionByShareTokenFunc func(ctx context.Context, token string) (*domain.FavoriteCollection, error)
+	CreateCollectionFunc          func(ctx context.Context, collection *domain.FavoriteCollection) (*domain.FavoriteCollection, error)
+	RenameCollectionFunc          func(ctx context.Context, id int, name string) (*domain.FavoriteCollection, error)
+	DeleteCollectionFunc          func(ctx context.Context, id int) error
+	SetCollectionShareTokenFunc   func(ctx context.Context, id int, token *string) (*domain.FavoriteCollection, error)
+	AddToCollectionFunc           func(ctx context.Context, collectionId int, userId int, listingId int) error
+	RemoveFromCollectionFunc      func(ctx context.Context, collectionId int, listingId int) error
 }
 
 func (f *FavoriteRepoMock) GetUserFavorites(
@@ -20,6 +48,31 @@ func (f *FavoriteRepoMock) GetUserFavorites(
 	return f.GetUserFavoritesFunc(ctx, userCtx)
 }
 
+func (f *FavoriteRepoMock) GetFavoriteListings(
+	ctx context.Context,
+	userId int,
+	sort string,
+	descending bool,
+	limit int,
+	offset int,
+) ([]*domain.Favorite, error) {
+	return f.GetFavoriteListingsFunc(ctx, userId, sort, descending, limit, offset)
+}
+
+func (f *FavoriteRepoMock) GetFavoriteCounts(
+	ctx context.Context,
+	listingIds []int,
+) (map[int]int, error) {
+	return f.GetFavoriteCountsFunc(ctx, listingIds)
+}
+
+func (f *FavoriteRepoMock) GetListingFavoriters(
+	ctx context.Context,
+	listingId int,
+) ([]*domain.ListingFavoriter, error) {
+	return f.GetListingFavoritersFunc(ctx, listingId)
+}
+
 func (f *FavoriteRepoMock) CreateFavorite(
 	ctx context.Context,
 	favorite *domain.Favorite,
@@ -41,3 +94,96 @@ func (f *FavoriteRepoMock) GetAllUserIdsByListingId(
 ) (map[int]bool, error) {
 	return f.GetAllUserIdsByListingIdFunc(ctx, listingId)
 }
+
+func (f *FavoriteRepoMock) UpdateFavoriteNote(
+	ctx context.Context,
+	userId int,
+	listingId int,
+	note *string,
+) (*domain.Favorite, error) {
+	return f.UpdateFavoriteNoteFunc(ctx, userId, listingId, note)
+}
+
+func (f *FavoriteRepoMock) SetPriceAlert(
+	ctx context.Context,
+	userId int,
+	listingId int,
+	belowPrice *int,
+	dropPercent *float64,
+) (*domain.Favorite, error) {
+	return f.SetPriceAlertFunc(ctx, userId, listingId, belowPrice, dropPercent)
+}
+
+func (f *FavoriteRepoMock) GetPriceAlerts(
+	ctx context.Context,
+	listingId int,
+	oldPrice int,
+	newPrice int,
+) (map[int]bool, error) {
+	return f.GetPriceAlertsFunc(ctx, listingId, oldPrice, newPrice)
+}
+
+func (f *FavoriteRepoMock) GetCollectionsByUserId(
+	ctx context.Context,
+	userId int,
+) ([]*domain.FavoriteCollection, error) {
+	return f.GetCollectionsByUserIdFunc(ctx, userId)
+}
+
+func (f *FavoriteRepoMock) GetCollectionById(
+	ctx context.Context,
+	id int,
+) (*domain.FavoriteCollection, error) {
+	return f.GetCollectionByIdFunc(ctx, id)
+}
+
+func (f *FavoriteRepoMock) GetCollectionByShareToken(
+	ctx context.Context,
+	token string,
+) (*domain.FavoriteCollection, error) {
+	return f.GetCollectionByShareTokenFunc(ctx, token)
+}
+
+func (f *FavoriteRepoMock) CreateCollection(
+	ctx context.Context,
+	collection *domain.FavoriteCollection,
+) (*domain.FavoriteCollection, error) {
+	return f.CreateCollectionFunc(ctx, collection)
+}
+
+func (f *FavoriteRepoMock) RenameCollection(
+	ctx context.Context,
+	id int,
+	name string,
+) (*domain.FavoriteCollection, error) {
+	return f.RenameCollectionFunc(ctx, id, name)
+}
+
+func (f *FavoriteRepoMock) DeleteCollection(ctx context.Context, id int) error {
+	return f.DeleteCollectionFunc(ctx, id)
+}
+
+func (f *FavoriteRepoMock) SetCollectionShareToken(
+	ctx context.Context,
+	id int,
+	token *string,
+) (*domain.FavoriteCollection, error) {
+	return f.SetCollectionShareTokenFunc(ctx, id, token)
+}
+
+func (f *FavoriteRepoMock) AddToCollection(
+	ctx context.Context,
+	collectionId int,
+	userId int,
+	listingId int,
+) error {
+	return f.AddToCollectionFunc(ctx, collectionId, userId, listingId)
+}
+
+func (f *FavoriteRepoMock) RemoveFromCollection(
+	ctx context.Context,
+	collectionId int,
+	listingId int,
+) error {
+	return f.RemoveFromCollectionFunc(ctx, collectionId, listingId)
+}
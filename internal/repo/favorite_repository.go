@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"server/internal/domain"
+	"server/internal/events"
 )
 
 type IFavoriteRepo interface {
@@ -14,6 +15,16 @@ type IFavoriteRepo interface {
 		ctx context.Context,
 		userCtx *domain.ContextSessionData,
 	) ([]*domain.Favorite, error)
+	// GetFavoriteListings returns a page of the user's favorites with their
+	// listing and agent, ordered by one of FavoriteSortColumns.
+	GetFavoriteListings(
+		ctx context.Context,
+		userId int,
+		sort string,
+		descending bool,
+		limit int,
+		offset int,
+	) ([]*domain.Favorite, error)
 	CreateFavorite(ctx context.Context, favorite *domain.Favorite) (*domain.Favorite, error)
 	DeleteFavoriteByListingId(
 		ctx context.Context,
@@ -21,6 +32,57 @@ type IFavoriteRepo interface {
 		userCtx *domain.ContextSessionData,
 	) error
 	GetAllUserIdsByListingId(ctx context.Context, listingId int) (map[int]bool, error)
+	GetFavoriteCounts(ctx context.Context, listingIds []int) (map[int]int, error)
+	// GetListingFavoriters lists the users who saved the listing and opted in
+	// to sharing their favorites with agents.
+	GetListingFavoriters(ctx context.Context, listingId int) ([]*domain.ListingFavoriter, error)
+	UpdateFavoriteNote(
+		ctx context.Context,
+		userId int,
+		listingId int,
+		note *string,
+	) (*domain.Favorite, error)
+	// SetPriceAlert replaces the favorite's price alert, measuring percentage
+	// drops from the listing's current price. Nil thresholds clear the alert.
+	SetPriceAlert(
+		ctx context.Context,
+		userId int,
+		listingId int,
+		belowPrice *int,
+		dropPercent *float64,
+	) (*domain.Favorite, error)
+	// GetPriceAlerts returns every user with a price alert on the listing,
+	// mapped to whether the price moving from oldPrice to newPrice crosses it.
+	GetPriceAlerts(ctx context.Context, listingId int, oldPrice int, newPrice int) (map[int]bool, error)
+	GetCollectionsByUserId(ctx context.Context, userId int) ([]*domain.FavoriteCollection, error)
+	GetCollectionById(ctx context.Context, id int) (*domain.FavoriteCollection, error)
+	GetCollectionByShareToken(ctx context.Context, token string) (*domain.FavoriteCollection, error)
+	CreateCollection(
+		ctx context.Context,
+		collection *domain.FavoriteCollection,
+	) (*domain.FavoriteCollection, error)
+	RenameCollection(ctx context.Context, id int, name string) (*domain.FavoriteCollection, error)
+	DeleteCollection(ctx context.Context, id int) error
+	SetCollectionShareToken(
+		ctx context.Context,
+		id int,
+		token *string,
+	) (*domain.FavoriteCollection, error)
+	// AddToCollection saves the listing for the user if they haven't already.
+	AddToCollection(ctx context.Context, collectionId int, userId int, listingId int) error
+	RemoveFromCollection(ctx context.Context, collectionId int, listingId int) error
+}
+
+var (
+	ErrCollectionExists  = errors.New("You already have a collection with this name")
+	ErrDefaultCollection = errors.New("Your default collection cannot be deleted")
+)
+
+// FavoriteSortColumns maps the sort options for saved listings to the column
+// they order by.
+var FavoriteSortColumns = map[string]string{
+	"saved": "favorites.created_at",
+	"price": "listings.price",
 }
 
 type FavoriteRepo struct {
@@ -31,12 +93,38 @@ func NewFavoriteRepo(db *sql.DB) *FavoriteRepo {
 	return &FavoriteRepo{db: db}
 }
 
+const favoriteColumns = `
+	favorites.id,
+	favorites.user_id,
+	favorites.listing_id,
+	favorites.note,
+	favorites.created_at,
+	favorites.updated_at,
+	favorites.alert_below_price,
+	favorites.alert_drop_percent,
+	favorites.alert_base_price
+`
+
+func favoriteFields(favorite *domain.Favorite) []any {
+	return []any{
+		&favorite.ID,
+		&favorite.UserID,
+		&favorite.ListingID,
+		&favorite.Note,
+		&favorite.CreatedAt,
+		&favorite.UpdatedAt,
+		&favorite.BelowPrice,
+		&favorite.DropPercent,
+		&favorite.BasePrice,
+	}
+}
+
 func (r *FavoriteRepo) GetUserFavorites(
 	ctx context.Context,
 	userCtx *domain.ContextSessionData,
 ) ([]*domain.Favorite, error) {
 	query := `
-		SELECT * FROM favorites
+		SELECT ` + favoriteColumns + ` FROM favorites
 		WHERE user_id = $1
 	`
 
@@ -52,14 +140,81 @@ func (r *FavoriteRepo) GetUserFavorites(
 	for rows.Next() {
 		favorite := new(domain.Favorite)
 
-		err := rows.Scan(
-			&favorite.ID,
-			&favorite.UserID,
-			&favorite.ListingID,
-			&favorite.CreatedAt,
-			&favorite.UpdatedAt,
+		if err := rows.Scan(favoriteFields(favorite)...); err != nil {
+			return nil, err
+		}
+
+		favorites = append(favorites, favorite)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return favorites, nil
+}
+
+func (r *FavoriteRepo) GetFavoriteListings(
+	ctx context.Context,
+	userId int,
+	sort string,
+	descending bool,
+	limit int,
+	offset int,
+) ([]*domain.Favorite, error) {
+	column, ok := FavoriteSortColumns[sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown favorite sort %q", sort)
+	}
+
+	direction := "ASC"
+	if descending {
+		direction = "DESC"
+	}
+
+	query := `
+		SELECT ` + favoriteColumns + `,
+			` + listingColumns + `,
+			users.id,
+			users.first_name,
+			users.last_name,
+			users.email,
+			` + brokerageBrandingColumn + `
+		FROM favorites
+		INNER JOIN listings
+			ON listings.id = favorites.listing_id
+		INNER JOIN users
+			ON users.id = listings.agent_id
+		LEFT JOIN brokerages
+			ON brokerages.id = users.brokerage_id
+		WHERE favorites.user_id = $1
+		ORDER BY ` + column + ` ` + direction + `, favorites.id ` + direction + `
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var favorites []*domain.Favorite
+	for rows.Next() {
+		favorite := new(domain.Favorite)
+		favorite.Listing = &domain.Listing{Agent: new(domain.Agent)}
+
+		fields := append(favoriteFields(favorite), listingFields(favorite.Listing)...)
+		fields = append(
+			fields,
+			&favorite.Listing.Agent.ID,
+			&favorite.Listing.Agent.FirstName,
+			&favorite.Listing.Agent.LastName,
+			&favorite.Listing.Agent.Email,
+			jsonColumn{&favorite.Listing.Agent.Brokerage},
 		)
-		if err != nil {
+
+		if err := rows.Scan(fields...); err != nil {
 			return nil, err
 		}
 
@@ -73,19 +228,29 @@ func (r *FavoriteRepo) GetUserFavorites(
 	return favorites, nil
 }
 
+// CreateFavorite saves the listing and files it in the user's default
+// collection, creating that collection on first use. A favorite.created
+// outbox event is recorded in the same transaction.
 func (r *FavoriteRepo) CreateFavorite(
 	ctx context.Context,
 	favorite *domain.Favorite,
 ) (*domain.Favorite, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
 	query := `
-		INSERT into favorites (user_id, listing_id)
-		VALUES ($1, $2)
+		INSERT into favorites (user_id, listing_id, note)
+		VALUES ($1, $2, $3)
 		ON CONFLICT (user_id, listing_id) DO NOTHING
 		RETURNING id, created_at, updated_at
 	`
 
 	newFavorite := *favorite
-	err := r.db.QueryRowContext(ctx, query, favorite.UserID, favorite.ListingID).
+	err = tx.QueryRowContext(ctx, query, favorite.UserID, favorite.ListingID, favorite.Note).
 		Scan(&newFavorite.ID, &newFavorite.CreatedAt, &newFavorite.UpdatedAt)
 
 	if err == sql.ErrNoRows {
@@ -96,9 +261,92 @@ func (r *FavoriteRepo) CreateFavorite(
 		return nil, fmt.Errorf("Create favorite: %w", err)
 	}
 
+	collectionId, err := defaultCollectionId(ctx, tx, favorite.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertCollectionItem(ctx, tx, collectionId, newFavorite.ID); err != nil {
+		return nil, err
+	}
+
+	event := events.FavoriteAdded{
+		FavoriteID: newFavorite.ID,
+		UserID:     newFavorite.UserID,
+		ListingID:  newFavorite.ListingID,
+		CreatedAt:  newFavorite.CreatedAt,
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT agent_id, address FROM listings WHERE id = $1`,
+		newFavorite.ListingID,
+	).Scan(&event.AgentID, &event.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertOutboxEvent(
+		ctx,
+		tx,
+		domain.OutboxEventFavoriteCreated,
+		fmt.Sprintf("%s:%d", domain.OutboxEventFavoriteCreated, newFavorite.ID),
+		event,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	return &newFavorite, nil
 }
 
+// defaultCollectionId returns the user's default collection, creating it if
+// needed.
+func defaultCollectionId(ctx context.Context, tx *sql.Tx, userId int) (int, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO favorite_collections (user_id, name, is_default)
+			VALUES ($1, $2, TRUE)
+			ON CONFLICT (user_id) WHERE is_default DO NOTHING
+			RETURNING id
+		)
+		SELECT id FROM inserted
+		UNION ALL
+		SELECT id FROM favorite_collections WHERE user_id = $1 AND is_default
+		LIMIT 1
+	`
+
+	var collectionId int
+
+	err := tx.QueryRowContext(ctx, query, userId, domain.DefaultCollectionName).Scan(&collectionId)
+	if err != nil {
+		return 0, err
+	}
+
+	return collectionId, nil
+}
+
+func insertCollectionItem(ctx context.Context, tx *sql.Tx, collectionId int, favoriteId int) error {
+	query := `
+		INSERT INTO favorite_collection_items (collection_id, favorite_id)
+		VALUES ($1, $2)
+		ON CONFLICT (collection_id, favorite_id) DO NOTHING
+	`
+
+	if _, err := tx.ExecContext(ctx, query, collectionId, favoriteId); err != nil {
+		return err
+	}
+
+	query = `UPDATE favorite_collections SET updated_at = NOW() WHERE id = $1`
+
+	_, err := tx.ExecContext(ctx, query, collectionId)
+	return err
+}
+
 func (r *FavoriteRepo) DeleteFavoriteByListingId(
 	ctx context.Context,
 	listingId int,
@@ -164,3 +412,504 @@ func (r *FavoriteRepo) GetAllUserIdsByListingId(
 
 	return userIds, nil
 }
+
+// GetFavoriteCounts returns how many users saved each listing. Listings
+// nobody saved are left out of the map.
+func (r *FavoriteRepo) GetFavoriteCounts(
+	ctx context.Context,
+	listingIds []int,
+) (map[int]int, error) {
+	query := `
+		SELECT listing_id, COUNT(*)
+		FROM favorites
+		WHERE listing_id = ANY($1)
+		GROUP BY listing_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, listingIds)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	counts := make(map[int]int, len(listingIds))
+	for rows.Next() {
+		var listingId, count int
+
+		if err := rows.Scan(&listingId, &count); err != nil {
+			return nil, err
+		}
+
+		counts[listingId] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (r *FavoriteRepo) GetListingFavoriters(
+	ctx context.Context,
+	listingId int,
+) ([]*domain.ListingFavoriter, error) {
+	query := `
+		SELECT users.id, users.first_name, users.last_name, users.email, favorites.created_at
+		FROM favorites
+		INNER JOIN users
+			ON users.id = favorites.user_id
+		WHERE favorites.listing_id = $1 AND users.share_favorites_with_agents
+		ORDER BY favorites.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, listingId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var favoriters []*domain.ListingFavoriter
+	for rows.Next() {
+		favoriter := new(domain.ListingFavoriter)
+
+		err := rows.Scan(
+			&favoriter.UserID,
+			&favoriter.FirstName,
+			&favoriter.LastName,
+			&favoriter.Email,
+			&favoriter.FavoritedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		favoriters = append(favoriters, favoriter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return favoriters, nil
+}
+
+// UpdateFavoriteNote returns sql.ErrNoRows if the user hasn't saved the
+// listing.
+func (r *FavoriteRepo) UpdateFavoriteNote(
+	ctx context.Context,
+	userId int,
+	listingId int,
+	note *string,
+) (*domain.Favorite, error) {
+	query := `
+		UPDATE favorites
+		SET note = $3, updated_at = NOW()
+		WHERE user_id = $1 AND listing_id = $2
+		RETURNING ` + favoriteColumns
+
+	var favorite domain.Favorite
+
+	err := r.db.QueryRowContext(ctx, query, userId, listingId, note).Scan(favoriteFields(&favorite)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &favorite, nil
+}
+
+func (r *FavoriteRepo) SetPriceAlert(
+	ctx context.Context,
+	userId int,
+	listingId int,
+	belowPrice *int,
+	dropPercent *float64,
+) (*domain.Favorite, error) {
+	query := `
+		UPDATE favorites
+		SET
+			alert_below_price = $3,
+			alert_drop_percent = $4,
+			alert_base_price = CASE
+				WHEN $4::NUMERIC IS NULL THEN NULL
+				ELSE (SELECT price FROM listings WHERE listings.id = favorites.listing_id)
+			END,
+			updated_at = NOW()
+		WHERE user_id = $1 AND listing_id = $2
+		RETURNING ` + favoriteColumns
+
+	var favorite domain.Favorite
+
+	err := r.db.QueryRowContext(ctx, query, userId, listingId, belowPrice, dropPercent).
+		Scan(favoriteFields(&favorite)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &favorite, nil
+}
+
+func (r *FavoriteRepo) GetPriceAlerts(
+	ctx context.Context,
+	listingId int,
+	oldPrice int,
+	newPrice int,
+) (map[int]bool, error) {
+	query := `
+		SELECT
+			user_id,
+			COALESCE(
+				($3 < alert_below_price AND $2 >= alert_below_price)
+					OR ($3 <= drop_price AND $2 > drop_price),
+				FALSE
+			) AS crossed
+		FROM (
+			SELECT
+				user_id,
+				alert_below_price,
+				alert_base_price * (1 - alert_drop_percent / 100) AS drop_price
+			FROM favorites
+			WHERE listing_id = $1
+				AND (alert_below_price IS NOT NULL OR alert_drop_percent IS NOT NULL)
+		) alerts
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, listingId, oldPrice, newPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	alerts := map[int]bool{}
+	for rows.Next() {
+		var userId int
+		var crossed bool
+
+		if err := rows.Scan(&userId, &crossed); err != nil {
+			return nil, err
+		}
+
+		alerts[userId] = crossed
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+const collectionColumns = `
+	favorite_collections.id,
+	favorite_collections.user_id,
+	favorite_collections.name,
+	favorite_collections.is_default,
+	favorite_collections.share_token,
+	(
+		SELECT COUNT(*)
+		FROM favorite_collection_items
+		WHERE favorite_collection_items.collection_id = favorite_collections.id
+	),
+	favorite_collections.created_at,
+	favorite_collections.updated_at
+`
+
+func collectionFields(collection *domain.FavoriteCollection) []any {
+	return []any{
+		&collection.ID,
+		&collection.UserID,
+		&collection.Name,
+		&collection.IsDefault,
+		&collection.ShareToken,
+		&collection.ListingCount,
+		&collection.CreatedAt,
+		&collection.UpdatedAt,
+	}
+}
+
+// GetCollectionsByUserId lists the user's collections, default first.
+func (r *FavoriteRepo) GetCollectionsByUserId(
+	ctx context.Context,
+	userId int,
+) ([]*domain.FavoriteCollection, error) {
+	query := `
+		SELECT ` + collectionColumns + `
+		FROM favorite_collections
+		WHERE user_id = $1
+		ORDER BY is_default DESC, name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var collections []*domain.FavoriteCollection
+	for rows.Next() {
+		collection := new(domain.FavoriteCollection)
+
+		if err := rows.Scan(collectionFields(collection)...); err != nil {
+			return nil, err
+		}
+
+		collections = append(collections, collection)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+// getCollection loads the collection matching where, along with its saved
+// listings, most recently added first.
+func (r *FavoriteRepo) getCollection(
+	ctx context.Context,
+	where string,
+	arg any,
+) (*domain.FavoriteCollection, error) {
+	query := `
+		SELECT ` + collectionColumns + `
+		FROM favorite_collections
+		WHERE ` + where
+
+	var collection domain.FavoriteCollection
+
+	if err := r.db.QueryRowContext(ctx, query, arg).Scan(collectionFields(&collection)...); err != nil {
+		return nil, err
+	}
+
+	favoritesQuery := `
+		SELECT ` + favoriteColumns + `
+		FROM favorite_collection_items
+		INNER JOIN favorites ON favorites.id = favorite_collection_items.favorite_id
+		WHERE favorite_collection_items.collection_id = $1
+		ORDER BY favorite_collection_items.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, favoritesQuery, collection.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	collection.Favorites = []domain.Favorite{}
+	for rows.Next() {
+		var favorite domain.Favorite
+
+		if err := rows.Scan(favoriteFields(&favorite)...); err != nil {
+			return nil, err
+		}
+
+		collection.Favorites = append(collection.Favorites, favorite)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &collection, nil
+}
+
+func (r *FavoriteRepo) GetCollectionById(
+	ctx context.Context,
+	id int,
+) (*domain.FavoriteCollection, error) {
+	return r.getCollection(ctx, `id = $1`, id)
+}
+
+func (r *FavoriteRepo) GetCollectionByShareToken(
+	ctx context.Context,
+	token string,
+) (*domain.FavoriteCollection, error) {
+	return r.getCollection(ctx, `share_token = $1`, token)
+}
+
+// CreateCollection returns ErrCollectionExists if the user already has a
+// collection with the same name.
+func (r *FavoriteRepo) CreateCollection(
+	ctx context.Context,
+	collection *domain.FavoriteCollection,
+) (*domain.FavoriteCollection, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO favorite_collections (user_id, name)
+			VALUES ($1, $2)
+			ON CONFLICT (user_id, name) DO NOTHING
+			RETURNING *
+		)
+		SELECT ` + collectionColumns + `
+		FROM inserted favorite_collections
+	`
+
+	var newCollection domain.FavoriteCollection
+
+	err := r.db.QueryRowContext(ctx, query, collection.UserID, collection.Name).
+		Scan(collectionFields(&newCollection)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCollectionExists
+		}
+		return nil, err
+	}
+
+	return &newCollection, nil
+}
+
+func (r *FavoriteRepo) updateCollection(
+	ctx context.Context,
+	set string,
+	id int,
+	value any,
+) (*domain.FavoriteCollection, error) {
+	query := `
+		UPDATE favorite_collections
+		SET ` + set + `, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + collectionColumns
+
+	var collection domain.FavoriteCollection
+
+	err := r.db.QueryRowContext(ctx, query, id, value).Scan(collectionFields(&collection)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &collection, nil
+}
+
+// RenameCollection returns ErrCollectionExists if the name is taken.
+func (r *FavoriteRepo) RenameCollection(
+	ctx context.Context,
+	id int,
+	name string,
+) (*domain.FavoriteCollection, error) {
+	var taken bool
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM favorite_collections
+			WHERE name = $2
+				AND id <> $1
+				AND user_id = (SELECT user_id FROM favorite_collections WHERE id = $1)
+		)
+	`
+
+	if err := r.db.QueryRowContext(ctx, query, id, name).Scan(&taken); err != nil {
+		return nil, err
+	}
+
+	if taken {
+		return nil, ErrCollectionExists
+	}
+
+	return r.updateCollection(ctx, `name = $2`, id, name)
+}
+
+// SetCollectionShareToken replaces the collection's share token. A nil token
+// turns sharing off.
+func (r *FavoriteRepo) SetCollectionShareToken(
+	ctx context.Context,
+	id int,
+	token *string,
+) (*domain.FavoriteCollection, error) {
+	return r.updateCollection(ctx, `share_token = $2`, id, token)
+}
+
+// DeleteCollection removes the collection but keeps its listings saved. It
+// returns ErrDefaultCollection for the user's default collection.
+func (r *FavoriteRepo) DeleteCollection(ctx context.Context, id int) error {
+	query := `
+		DELETE FROM favorite_collections
+		WHERE id = $1 AND NOT is_default
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return ErrDefaultCollection
+	}
+
+	return nil
+}
+
+func (r *FavoriteRepo) AddToCollection(
+	ctx context.Context,
+	collectionId int,
+	userId int,
+	listingId int,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	// The no-op update lets RETURNING see an existing favorite. A missing
+	// listing inserts nothing and surfaces as sql.ErrNoRows.
+	query := `
+		INSERT INTO favorites (user_id, listing_id)
+		SELECT $1, id FROM listings WHERE id = $2
+		ON CONFLICT (user_id, listing_id) DO UPDATE SET user_id = EXCLUDED.user_id
+		RETURNING id
+	`
+
+	var favoriteId int
+
+	if err := tx.QueryRowContext(ctx, query, userId, listingId).Scan(&favoriteId); err != nil {
+		return err
+	}
+
+	if err := insertCollectionItem(ctx, tx, collectionId, favoriteId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RemoveFromCollection leaves the listing saved and in any other collections.
+func (r *FavoriteRepo) RemoveFromCollection(
+	ctx context.Context,
+	collectionId int,
+	listingId int,
+) error {
+	query := `
+		DELETE FROM favorite_collection_items
+		USING favorites
+		WHERE favorite_collection_items.favorite_id = favorites.id
+			AND favorite_collection_items.collection_id = $1
+			AND favorites.listing_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, collectionId, listingId)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package repo
+
+import "context"
+
+type FollowRepoMock struct {
+	FollowAgentFunc    func(ctx context.Context, userId int, agentId int) error
+	UnfollowAgentFunc  func(ctx context.Context, userId int, agentId int) error
+	IsFollowingFunc    func(ctx context.Context, userId int, agentId int) (bool, error)
+	GetFollowerIdsFunc func(ctx context.Context, agentId int, afterUserId int, limit int) ([]int, error)
+}
+
+func (f *FollowRepoMock) FollowAgent(ctx context.Context, userId int, agentId int) error {
+	return f.FollowAgentFunc(ctx, userId, agentId)
+}
+
+func (f *FollowRepoMock) UnfollowAgent(ctx context.Context, userId int, agentId int) error {
+	return f.UnfollowAgentFunc(ctx, userId, agentId)
+}
+
+func (f *FollowRepoMock) IsFollowing(ctx context.Context, userId int, agentId int) (bool, error) {
+	return f.IsFollowingFunc(ctx, userId, agentId)
+}
+
+func (f *FollowRepoMock) GetFollowerIds(
+	ctx context.Context,
+	agentId int,
+	afterUserId int,
+	limit int,
+) ([]int, error) {
+	return f.GetFollowerIdsFunc(ctx, agentId, afterUserId, limit)
+}
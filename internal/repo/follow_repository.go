@@ -0,0 +1,102 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+)
+
+type IFollowRepo interface {
+	FollowAgent(ctx context.Context, userId int, agentId int) error
+	UnfollowAgent(ctx context.Context, userId int, agentId int) error
+	IsFollowing(ctx context.Context, userId int, agentId int) (bool, error)
+	// GetFollowerIds returns up to limit followers of the agent with ids
+	// greater than afterUserId, in id order, for paging through large
+	// audiences.
+	GetFollowerIds(ctx context.Context, agentId int, afterUserId int, limit int) ([]int, error)
+}
+
+type FollowRepository struct {
+	db *sql.DB
+}
+
+func NewFollowRepository(db *sql.DB) *FollowRepository {
+	return &FollowRepository{db: db}
+}
+
+// FollowAgent is a no-op if the user already follows the agent.
+func (r *FollowRepository) FollowAgent(ctx context.Context, userId int, agentId int) error {
+	query := `
+		INSERT INTO agent_follows (user_id, agent_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, agent_id) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userId, agentId)
+	return err
+}
+
+// UnfollowAgent is a no-op if the user does not follow the agent.
+func (r *FollowRepository) UnfollowAgent(ctx context.Context, userId int, agentId int) error {
+	query := `
+		DELETE FROM agent_follows
+		WHERE user_id = $1 AND agent_id = $2
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userId, agentId)
+	return err
+}
+
+func (r *FollowRepository) IsFollowing(ctx context.Context, userId int, agentId int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM agent_follows WHERE user_id = $1 AND agent_id = $2
+		)
+	`
+
+	var following bool
+
+	if err := r.db.QueryRowContext(ctx, query, userId, agentId).Scan(&following); err != nil {
+		return false, err
+	}
+
+	return following, nil
+}
+
+func (r *FollowRepository) GetFollowerIds(
+	ctx context.Context,
+	agentId int,
+	afterUserId int,
+	limit int,
+) ([]int, error) {
+	query := `
+		SELECT user_id
+		FROM agent_follows
+		WHERE agent_id = $1 AND user_id > $2
+		ORDER BY user_id
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, agentId, afterUserId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var userIds []int
+	for rows.Next() {
+		var userId int
+
+		if err := rows.Scan(&userId); err != nil {
+			return nil, err
+		}
+
+		userIds = append(userIds, userId)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return userIds, nil
+}
@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"server/internal/domain"
+)
+
+type LeadRepoMock struct {
+	CreateLeadFunc           func(ctx context.Context, lead *domain.Lead) (*domain.Lead, error)
+	CountRecentInquiriesFunc func(ctx context.Context, ipAddress string, email string, since time.Time) (int, error)
+	GetLeadsByAgentIdFunc    func(ctx context.Context, agentId int, status string) ([]*domain.Lead, error)
+	GetLeadByIdFunc          func(ctx context.Context, id int) (*domain.Lead, error)
+	UpdateLeadStatusFunc     func(ctx context.Context, id int, status string) (*domain.Lead, error)
+	CreateLeadNoteFunc       func(ctx context.Context, note *domain.LeadNote) (*domain.LeadNote, error)
+	AssignLeadFunc           func(ctx context.Context, leadId int, agentId int, assignedBy int) (*domain.Lead, error)
+}
+
+func (l *LeadRepoMock) CreateLead(ctx context.Context, lead *domain.Lead) (*domain.Lead, error) {
+	return l.CreateLeadFunc(ctx, lead)
+}
+
+func (l *LeadRepoMock) CountRecentInquiries(
+	ctx context.Context,
+	ipAddress string,
+	email string,
+	since time.Time,
+) (int, error) {
+	return l.CountRecentInquiriesFunc(ctx, ipAddress, email, since)
+}
+
+func (l *LeadRepoMock) GetLeadsByAgentId(
+	ctx context.Context,
+	agentId int,
+	status string,
+) ([]*domain.Lead, error) {
+	return l.GetLeadsByAgentIdFunc(ctx, agentId, status)
+}
+
+func (l *LeadRepoMock) GetLeadById(ctx context.Context, id int) (*domain.Lead, error) {
+	return l.GetLeadByIdFunc(ctx, id)
+}
+
+func (l *LeadRepoMock) UpdateLeadStatus(
+	ctx context.Context,
+	id int,
+	status string,
+) (*domain.Lead, error) {
+	return l.UpdateLeadStatusFunc(ctx, id, status)
+}
+
+func (l *LeadRepoMock) CreateLeadNote(
+	ctx context.Context,
+	note *domain.LeadNote,
+) (*domain.LeadNote, error) {
+	return l.CreateLeadNoteFunc(ctx, note)
+}
+
+func (l *LeadRepoMock) AssignLead(
+	ctx context.Context,
+	leadId int,
+	agentId int,
+	assignedBy int,
+) (*domain.Lead, error) {
+	return l.AssignLeadFunc(ctx, leadId, agentId, assignedBy)
+}
@@ -0,0 +1,353 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"server/internal/domain"
+)
+
+type ILeadRepo interface {
+	CreateLead(ctx context.Context, lead *domain.Lead) (*domain.Lead, error)
+	CountRecentInquiries(
+		ctx context.Context,
+		ipAddress string,
+		email string,
+		since time.Time,
+	) (int, error)
+	GetLeadsByAgentId(ctx context.Context, agentId int, status string) ([]*domain.Lead, error)
+	GetLeadById(ctx context.Context, id int) (*domain.Lead, error)
+	UpdateLeadStatus(ctx context.Context, id int, status string) (*domain.Lead, error)
+	CreateLeadNote(ctx context.Context, note *domain.LeadNote) (*domain.LeadNote, error)
+	AssignLead(ctx context.Context, leadId int, agentId int, assignedBy int) (*domain.Lead, error)
+}
+
+type LeadRepository struct {
+	db *sql.DB
+}
+
+func NewLeadRepository(db *sql.DB) *LeadRepository {
+	return &LeadRepository{db: db}
+}
+
+const leadColumns = `
+	id,
+	listing_id,
+	agent_id,
+	user_id,
+	name,
+	email,
+	phone,
+	message,
+	preferred_contact,
+	status,
+	ip_address,
+	created_at,
+	updated_at
+`
+
+func leadFields(lead *domain.Lead) []any {
+	return []any{
+		&lead.ID,
+		&lead.ListingID,
+		&lead.AgentID,
+		&lead.UserID,
+		&lead.Name,
+		&lead.Email,
+		&lead.Phone,
+		&lead.Message,
+		&lead.PreferredContact,
+		&lead.Status,
+		&lead.IPAddress,
+		&lead.CreatedAt,
+		&lead.UpdatedAt,
+	}
+}
+
+func insertLeadAssignment(
+	ctx context.Context,
+	tx *sql.Tx,
+	leadId int,
+	agentId int,
+	assignedBy *int,
+) error {
+	query := `
+		INSERT INTO lead_assignments (lead_id, agent_id, assigned_by)
+		VALUES ($1, $2, $3)
+	`
+
+	_, err := tx.ExecContext(ctx, query, leadId, agentId, assignedBy)
+	return err
+}
+
+func (r *LeadRepository) CreateLead(ctx context.Context, lead *domain.Lead) (*domain.Lead, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO leads (
+			listing_id, agent_id, user_id, name, email, phone, message, preferred_contact, ip_address
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + leadColumns
+
+	var newLead domain.Lead
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		lead.ListingID,
+		lead.AgentID,
+		lead.UserID,
+		lead.Name,
+		lead.Email,
+		lead.Phone,
+		lead.Message,
+		lead.PreferredContact,
+		lead.IPAddress,
+	).Scan(leadFields(&newLead)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertLeadAssignment(ctx, tx, newLead.ID, newLead.AgentID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &newLead, nil
+}
+
+func (r *LeadRepository) CountRecentInquiries(
+	ctx context.Context,
+	ipAddress string,
+	email string,
+	since time.Time,
+) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM leads
+		WHERE (ip_address = $1 OR LOWER(email) = LOWER($2)) AND created_at > $3
+	`
+
+	var count int
+
+	if err := r.db.QueryRowContext(ctx, query, ipAddress, email, since).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetLeadsByAgentId lists the agent's leads, newest first. An empty status
+// returns every status.
+func (r *LeadRepository) GetLeadsByAgentId(
+	ctx context.Context,
+	agentId int,
+	status string,
+) ([]*domain.Lead, error) {
+	query := `
+		SELECT ` + leadColumns + `
+		FROM leads
+		WHERE agent_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, agentId, status)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var leads []*domain.Lead
+	for rows.Next() {
+		lead := new(domain.Lead)
+
+		if err := rows.Scan(leadFields(lead)...); err != nil {
+			return nil, err
+		}
+
+		leads = append(leads, lead)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return leads, nil
+}
+
+// GetLeadById returns the lead with its notes and full assignment history.
+func (r *LeadRepository) GetLeadById(ctx context.Context, id int) (*domain.Lead, error) {
+	query := `SELECT ` + leadColumns + ` FROM leads WHERE id = $1`
+
+	var lead domain.Lead
+
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(leadFields(&lead)...); err != nil {
+		return nil, err
+	}
+
+	lead.Notes = []domain.LeadNote{}
+	lead.Assignments = []domain.LeadAssignment{}
+
+	notesQuery := `
+		SELECT id, lead_id, author_id, body, created_at
+		FROM lead_notes
+		WHERE lead_id = $1
+		ORDER BY created_at
+	`
+
+	noteRows, err := r.db.QueryContext(ctx, notesQuery, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer noteRows.Close()
+
+	for noteRows.Next() {
+		var note domain.LeadNote
+
+		if err := noteRows.Scan(
+			&note.ID,
+			&note.LeadID,
+			&note.AuthorID,
+			&note.Body,
+			&note.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		lead.Notes = append(lead.Notes, note)
+	}
+
+	if err := noteRows.Err(); err != nil {
+		return nil, err
+	}
+
+	assignmentsQuery := `
+		SELECT id, lead_id, agent_id, assigned_by, created_at
+		FROM lead_assignments
+		WHERE lead_id = $1
+		ORDER BY created_at
+	`
+
+	assignmentRows, err := r.db.QueryContext(ctx, assignmentsQuery, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer assignmentRows.Close()
+
+	for assignmentRows.Next() {
+		var assignment domain.LeadAssignment
+
+		if err := assignmentRows.Scan(
+			&assignment.ID,
+			&assignment.LeadID,
+			&assignment.AgentID,
+			&assignment.AssignedBy,
+			&assignment.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		lead.Assignments = append(lead.Assignments, assignment)
+	}
+
+	if err := assignmentRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &lead, nil
+}
+
+func (r *LeadRepository) UpdateLeadStatus(
+	ctx context.Context,
+	id int,
+	status string,
+) (*domain.Lead, error) {
+	query := `
+		UPDATE leads
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING ` + leadColumns
+
+	var lead domain.Lead
+
+	if err := r.db.QueryRowContext(ctx, query, status, id).Scan(leadFields(&lead)...); err != nil {
+		return nil, err
+	}
+
+	return &lead, nil
+}
+
+func (r *LeadRepository) CreateLeadNote(
+	ctx context.Context,
+	note *domain.LeadNote,
+) (*domain.LeadNote, error) {
+	query := `
+		INSERT INTO lead_notes (lead_id, author_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	newNote := *note
+
+	err := r.db.QueryRowContext(ctx, query, note.LeadID, note.AuthorID, note.Body).
+		Scan(&newNote.ID, &newNote.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &newNote, nil
+}
+
+// AssignLead moves the lead to another agent and records the change.
+func (r *LeadRepository) AssignLead(
+	ctx context.Context,
+	leadId int,
+	agentId int,
+	assignedBy int,
+) (*domain.Lead, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		UPDATE leads
+		SET agent_id = $1, updated_at = NOW()
+		WHERE id = $2 AND agent_id <> $1
+		RETURNING ` + leadColumns
+
+	var lead domain.Lead
+
+	if err := tx.QueryRowContext(ctx, query, agentId, leadId).Scan(leadFields(&lead)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("Lead is already assigned to that agent")
+		}
+		return nil, err
+	}
+
+	if err := insertLeadAssignment(ctx, tx, leadId, agentId, &assignedBy); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &lead, nil
+}
@@ -0,0 +1,289 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"server/internal/api/dto"
+	"server/internal/cache"
+	"server/internal/domain"
+	"server/internal/events"
+)
+
+const listingsGenerationKey = "listings:gen"
+
+// CachedListingRepo decorates an IListingRepo with a read-through cache.
+// Single listings are cached under their own key and invalidated directly.
+// List queries are cached under a generation number that is bumped on every
+// write, so any number of list variants can be dropped with one INCR.
+//
+// Views are counted under a separate key per listing and added to the cached
+// listing when it is read, so a view does not evict the entry. Lists carry
+// the view counts they were cached with.
+type CachedListingRepo struct {
+	next  IListingRepo
+	store cache.Store
+	ttl   time.Duration
+	group singleflight.Group
+}
+
+func NewCachedListingRepo(next IListingRepo, store cache.Store, ttl time.Duration) *CachedListingRepo {
+	return &CachedListingRepo{
+		next:  next,
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+func listingKey(id int) string {
+	return fmt.Sprintf("listing:%d", id)
+}
+
+// listingViewsKey counts the views tracked since the listing was last loaded.
+func listingViewsKey(id int) string {
+	return fmt.Sprintf("listing:%d:views", id)
+}
+
+func (r *CachedListingRepo) listKey(ctx context.Context, name string) string {
+	gen := "0"
+
+	val, err := r.store.Get(ctx, listingsGenerationKey)
+	switch {
+	case err == nil:
+		gen = string(val)
+	case !errors.Is(err, cache.ErrMiss):
+		slog.Warn("Listing cache - read generation", slog.String("error", err.Error()))
+	}
+
+	return fmt.Sprintf("listings:%s:%s", gen, name)
+}
+
+// readThrough serves key from the cache, falling back to load on a miss.
+// Concurrent misses for the same key share a single load, and every caller
+// decodes its own copy so results can be mutated safely.
+func readThrough[T any](
+	ctx context.Context,
+	r *CachedListingRepo,
+	key string,
+	load func(ctx context.Context) (T, error),
+) (T, error) {
+	var result T
+
+	data, err := r.store.Get(ctx, key)
+	if err == nil {
+		if err = json.Unmarshal(data, &result); err == nil {
+			return result, nil
+		}
+	}
+
+	if !errors.Is(err, cache.ErrMiss) {
+		slog.Warn(
+			"Listing cache - read",
+			slog.String("key", key),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	shared, err, _ := r.group.Do(key, func() (any, error) {
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := r.store.Set(ctx, key, encoded, r.ttl); err != nil {
+			slog.Warn(
+				"Listing cache - write",
+				slog.String("key", key),
+				slog.String("error", err.Error()),
+			)
+		}
+
+		return encoded, nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if err := json.Unmarshal(shared.([]byte), &result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (r *CachedListingRepo) invalidate(ctx context.Context, listingIds ...int) {
+	keys := make([]string, 0, 2*len(listingIds))
+	for _, id := range listingIds {
+		keys = append(keys, listingKey(id), listingViewsKey(id))
+	}
+
+	if err := r.store.Delete(ctx, keys...); err != nil {
+		slog.Warn("Listing cache - invalidate listing", slog.String("error", err.Error()))
+	}
+
+	if _, err := r.store.Incr(ctx, listingsGenerationKey); err != nil {
+		slog.Warn("Listing cache - bump generation", slog.String("error", err.Error()))
+	}
+}
+
+func (r *CachedListingRepo) GetAllListings(ctx context.Context) ([]*domain.Listing, error) {
+	return readThrough(ctx, r, r.listKey(ctx, "all"), r.next.GetAllListings)
+}
+
+func (r *CachedListingRepo) GetListingById(ctx context.Context, id int) (*domain.Listing, error) {
+	listing, err := readThrough(ctx, r, listingKey(id), func(ctx context.Context) (*domain.Listing, error) {
+		listing, err := r.next.GetListingById(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		// The row already has every view counted so far. Views tracked
+		// between the read and this reset are dropped, so the cached count
+		// can trail the database but never runs ahead of it.
+		if err := r.store.Delete(ctx, listingViewsKey(id)); err != nil {
+			slog.Warn("Listing cache - reset views", slog.String("error", err.Error()))
+		}
+
+		return listing, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	listing.Views += r.trackedViews(ctx, id)
+
+	return listing, nil
+}
+
+func (r *CachedListingRepo) trackedViews(ctx context.Context, id int) int {
+	val, err := r.store.Get(ctx, listingViewsKey(id))
+	if err != nil {
+		if !errors.Is(err, cache.ErrMiss) {
+			slog.Warn("Listing cache - read views", slog.String("error", err.Error()))
+		}
+		return 0
+	}
+
+	views, err := strconv.Atoi(string(val))
+	if err != nil {
+		slog.Warn("Listing cache - read views", slog.String("error", err.Error()))
+		return 0
+	}
+
+	return views
+}
+
+func (r *CachedListingRepo) GetListingsByAgentId(
+	ctx context.Context,
+	agentId int,
+) ([]*domain.Listing, error) {
+	key := r.listKey(ctx, fmt.Sprintf("agent:%d", agentId))
+
+	return readThrough(ctx, r, key, func(ctx context.Context) ([]*domain.Listing, error) {
+		return r.next.GetListingsByAgentId(ctx, agentId)
+	})
+}
+
+func (r *CachedListingRepo) CreateListing(
+	ctx context.Context,
+	listing *domain.Listing,
+) (*domain.Listing, error) {
+	newListing, err := r.next.CreateListing(ctx, listing)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidate(ctx)
+
+	return newListing, nil
+}
+
+func (r *CachedListingRepo) UpdateListingById(
+	ctx context.Context,
+	listing *dto.UpdateListingRequest,
+	currentUserCtx *domain.ContextSessionData,
+	listingId int,
+) (*domain.Listing, error) {
+	updatedListing, err := r.next.UpdateListingById(ctx, listing, currentUserCtx, listingId)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidate(ctx, listingId)
+
+	return updatedListing, nil
+}
+
+func (r *CachedListingRepo) DeleteListingById(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	listingId int,
+) error {
+	if err := r.next.DeleteListingById(ctx, currentUserCtx, listingId); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, listingId)
+
+	return nil
+}
+
+func (r *CachedListingRepo) GetAgentIdByListingId(ctx context.Context, listingId int) (int, error) {
+	return r.next.GetAgentIdByListingId(ctx, listingId)
+}
+
+// TrackViewsByListingId counts the view next to the cached listing instead
+// of evicting it, otherwise a popular listing would never be served from the
+// cache.
+func (r *CachedListingRepo) TrackViewsByListingId(ctx context.Context, listingId int) error {
+	if err := r.next.TrackViewsByListingId(ctx, listingId); err != nil {
+		return err
+	}
+
+	if _, err := r.store.Incr(ctx, listingViewsKey(listingId)); err != nil {
+		slog.Warn("Listing cache - count view", slog.String("error", err.Error()))
+	}
+
+	return nil
+}
+
+func (r *CachedListingRepo) GetComparableListings(
+	ctx context.Context,
+	subject *domain.Listing,
+	since time.Time,
+) ([]*domain.Listing, error) {
+	return r.next.GetComparableListings(ctx, subject, since)
+}
+
+// OnListingPriceChanged and OnListingStatusChanged drop the listing again
+// once its change has been relayed. A read that started before the update
+// can put the old row back after UpdateListingById invalidated it, and this
+// bounds how long that stale copy is served to the relay interval instead of
+// the TTL.
+func (r *CachedListingRepo) OnListingPriceChanged(
+	ctx context.Context,
+	change events.ListingPriceChanged,
+) error {
+	r.invalidate(ctx, change.ListingID)
+	return nil
+}
+
+func (r *CachedListingRepo) OnListingStatusChanged(
+	ctx context.Context,
+	change events.ListingStatusChanged,
+) error {
+	r.invalidate(ctx, change.ListingID)
+	return nil
+}
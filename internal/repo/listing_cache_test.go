@@ -0,0 +1,215 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/cache"
+	"server/internal/domain"
+)
+
+func TestCachedListingRepo(t *testing.T) {
+	t.Run("Repeated listing reads hit the database once", func(t *testing.T) {
+		var calls atomic.Int32
+		mockRepo := &ListingRepoMock{
+			GetListingByIdFunc: func(ctx context.Context, id int) (*domain.Listing, error) {
+				calls.Add(1)
+				return &domain.Listing{ID: id, Price: 400000}, nil
+			},
+		}
+
+		r := NewCachedListingRepo(mockRepo, cache.NewMemoryStore(), time.Minute)
+		ctx := context.Background()
+
+		for range 3 {
+			listing, err := r.GetListingById(ctx, 1)
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if listing.Price != 400000 {
+				t.Errorf("Expected price 400000, received %d", listing.Price)
+			}
+		}
+
+		if calls.Load() != 1 {
+			t.Errorf("Expected 1 database call, received %d", calls.Load())
+		}
+	})
+
+	t.Run("Updating a listing invalidates its cached entry and lists", func(t *testing.T) {
+		price := 400000
+		var byIdCalls, agentCalls atomic.Int32
+		mockRepo := &ListingRepoMock{
+			GetListingByIdFunc: func(ctx context.Context, id int) (*domain.Listing, error) {
+				byIdCalls.Add(1)
+				return &domain.Listing{ID: id, Price: price, AgentID: 2}, nil
+			},
+			GetListingsByAgentIdFunc: func(ctx context.Context, agentId int) ([]*domain.Listing, error) {
+				agentCalls.Add(1)
+				return []*domain.Listing{{ID: 1, Price: price, AgentID: agentId}}, nil
+			},
+			UpdateListingByIdFunc: func(ctx context.Context, listing *dto.UpdateListingRequest, currentUserCtx *domain.ContextSessionData, listingId int) (*domain.Listing, error) {
+				price = *listing.Price
+				return &domain.Listing{ID: listingId, Price: price, AgentID: 2}, nil
+			},
+		}
+
+		r := NewCachedListingRepo(mockRepo, cache.NewMemoryStore(), time.Minute)
+		ctx := context.Background()
+		userCtx := &domain.ContextSessionData{UserID: 2, Role: "agent"}
+
+		r.GetListingById(ctx, 1)
+		r.GetListingsByAgentId(ctx, 2)
+
+		newPrice := 350000
+		if _, err := r.UpdateListingById(ctx, &dto.UpdateListingRequest{Price: &newPrice}, userCtx, 1); err != nil {
+			t.Fatalf("Expected success, received %v", err)
+		}
+
+		listing, _ := r.GetListingById(ctx, 1)
+		if listing.Price != newPrice {
+			t.Errorf("Expected price %d, received %d", newPrice, listing.Price)
+		}
+
+		listings, _ := r.GetListingsByAgentId(ctx, 2)
+		if listings[0].Price != newPrice {
+			t.Errorf("Expected agent listing price %d, received %d", newPrice, listings[0].Price)
+		}
+
+		if byIdCalls.Load() != 2 || agentCalls.Load() != 2 {
+			t.Errorf(
+				"Expected 2 calls each after invalidation, received %d and %d",
+				byIdCalls.Load(),
+				agentCalls.Load(),
+			)
+		}
+	})
+
+	t.Run("Creating a listing invalidates cached lists", func(t *testing.T) {
+		listings := []*domain.Listing{{ID: 1}}
+		mockRepo := &ListingRepoMock{
+			GetAllListingsFunc: func(ctx context.Context) ([]*domain.Listing, error) {
+				return listings, nil
+			},
+			CreateListingFunc: func(ctx context.Context, listing *domain.Listing) (*domain.Listing, error) {
+				listing.ID = 2
+				listings = append(listings, listing)
+				return listing, nil
+			},
+		}
+
+		r := NewCachedListingRepo(mockRepo, cache.NewMemoryStore(), time.Minute)
+		ctx := context.Background()
+
+		r.GetAllListings(ctx)
+		r.CreateListing(ctx, &domain.Listing{Address: "124 Test St, Nashville, TN"})
+
+		all, _ := r.GetAllListings(ctx)
+		if len(all) != 2 {
+			t.Errorf("Expected 2 listings, received %d", len(all))
+		}
+	})
+
+	t.Run("Tracking views updates the cached count without evicting the listing", func(t *testing.T) {
+		views := 5
+		calls := 0
+		mockRepo := &ListingRepoMock{
+			GetListingByIdFunc: func(ctx context.Context, id int) (*domain.Listing, error) {
+				calls++
+				return &domain.Listing{ID: id, Price: 400000, Views: views}, nil
+			},
+			TrackViewsByListingIdFunc: func(ctx context.Context, listingId int) error {
+				views++
+				return nil
+			},
+			UpdateListingByIdFunc: func(ctx context.Context, listing *dto.UpdateListingRequest, currentUserCtx *domain.ContextSessionData, listingId int) (*domain.Listing, error) {
+				return &domain.Listing{ID: listingId}, nil
+			},
+		}
+
+		r := NewCachedListingRepo(mockRepo, cache.NewMemoryStore(), time.Minute)
+		ctx := context.Background()
+
+		r.GetListingById(ctx, 1)
+		r.TrackViewsByListingId(ctx, 1)
+		r.TrackViewsByListingId(ctx, 1)
+
+		listing, _ := r.GetListingById(ctx, 1)
+		if calls != 1 || listing.Views != 7 {
+			t.Errorf("Expected the cached listing with 7 views, received %d calls and %d views", calls, listing.Views)
+		}
+
+		// Reloading starts counting from the row again
+		newPrice := 350000
+		r.UpdateListingById(ctx, &dto.UpdateListingRequest{Price: &newPrice}, &domain.ContextSessionData{UserID: 2}, 1)
+		r.TrackViewsByListingId(ctx, 1)
+
+		listing, _ = r.GetListingById(ctx, 1)
+		if calls != 2 || listing.Views != 8 {
+			t.Errorf("Expected a reload with 8 views, received %d calls and %d views", calls, listing.Views)
+		}
+
+		listing, _ = r.GetListingById(ctx, 1)
+		if listing.Views != 8 {
+			t.Errorf("Expected 8 views, received %d", listing.Views)
+		}
+	})
+
+	t.Run("Concurrent misses share a single database call", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		mockRepo := &ListingRepoMock{
+			GetListingByIdFunc: func(ctx context.Context, id int) (*domain.Listing, error) {
+				calls.Add(1)
+				<-release
+				return &domain.Listing{ID: id}, nil
+			},
+		}
+
+		r := NewCachedListingRepo(mockRepo, cache.NewMemoryStore(), time.Minute)
+		ctx := context.Background()
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := r.GetListingById(ctx, 1); err != nil {
+					t.Errorf("Expected success, received %v", err)
+				}
+			}()
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		if calls.Load() != 1 {
+			t.Errorf("Expected 1 database call, received %d", calls.Load())
+		}
+	})
+
+	t.Run("Cached results are not shared between callers", func(t *testing.T) {
+		mockRepo := &ListingRepoMock{
+			GetListingByIdFunc: func(ctx context.Context, id int) (*domain.Listing, error) {
+				return &domain.Listing{ID: id, Price: 400000}, nil
+			},
+		}
+
+		r := NewCachedListingRepo(mockRepo, cache.NewMemoryStore(), time.Minute)
+		ctx := context.Background()
+
+		first, _ := r.GetListingById(ctx, 1)
+		first.Price = 1
+
+		second, _ := r.GetListingById(ctx, 1)
+		if second.Price != 400000 {
+			t.Errorf("Expected price 400000, received %d", second.Price)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"server/internal/api/dto"
 	"server/internal/domain"
@@ -16,6 +17,7 @@ type ListingRepoMock struct {
 	DeleteListingByIdFunc     func(ctx context.Context, userCtx *domain.ContextSessionData, listingId int) error
 	GetAgentIdByListingIdFunc func(ctx context.Context, listingId int) (int, error)
 	TrackViewsByListingIdFunc func(ctx context.Context, listingId int) error
+	GetComparableListingsFunc func(ctx context.Context, subject *domain.Listing, since time.Time) ([]*domain.Listing, error)
 }
 
 func (l *ListingRepoMock) GetAllListings(ctx context.Context) ([]*domain.Listing, error) {
@@ -64,3 +66,11 @@ func (l *ListingRepoMock) GetAgentIdByListingId(ctx context.Context, listingId i
 func (l *ListingRepoMock) TrackViewsByListingId(ctx context.Context, listingId int) error {
 	return l.TrackViewsByListingIdFunc(ctx, listingId)
 }
+
+func (l *ListingRepoMock) GetComparableListings(
+	ctx context.Context,
+	subject *domain.Listing,
+	since time.Time,
+) ([]*domain.Listing, error) {
+	return l.GetComparableListingsFunc(ctx, subject, since)
+}
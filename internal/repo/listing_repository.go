@@ -4,9 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
 	"server/internal/api/dto"
 	"server/internal/domain"
+	"server/internal/events"
 )
 
 type IListingRepo interface {
@@ -27,6 +30,11 @@ type IListingRepo interface {
 	) error
 	GetAgentIdByListingId(ctx context.Context, listingId int) (int, error)
 	TrackViewsByListingId(ctx context.Context, listingId int) error
+	GetComparableListings(
+		ctx context.Context,
+		subject *domain.Listing,
+		since time.Time,
+	) ([]*domain.Listing, error)
 }
 
 type ListingRepository struct {
@@ -37,16 +45,66 @@ func NewListingRepository(db *sql.DB) *ListingRepository {
 	return &ListingRepository{db: db}
 }
 
+const listingColumns = `
+	listings.id,
+	listings.address,
+	listings.city,
+	listings.state,
+	listings.zip_code,
+	listings.property_type,
+	listings.status,
+	listings.price,
+	listings.beds,
+	listings.baths,
+	listings.sq_ft,
+	listings.description,
+	listings.hoa_monthly,
+	listings.annual_property_tax,
+	listings.agent_id,
+	listings.created_at,
+	listings.updated_at,
+	listings.sold_at,
+	listings.views
+`
+
+// listingFields returns scan destinations matching listingColumns.
+func listingFields(listing *domain.Listing) []any {
+	return []any{
+		&listing.ID,
+		&listing.Address,
+		&listing.City,
+		&listing.State,
+		&listing.ZipCode,
+		&listing.PropertyType,
+		&listing.Status,
+		&listing.Price,
+		&listing.Beds,
+		&listing.Baths,
+		&listing.SqFt,
+		&listing.Description,
+		&listing.HOAMonthly,
+		&listing.AnnualTax,
+		&listing.AgentID,
+		&listing.CreatedAt,
+		&listing.UpdatedAt,
+		&listing.SoldAt,
+		&listing.Views,
+	}
+}
+
 func (r *ListingRepository) GetAllListings(ctx context.Context) ([]*domain.Listing, error) {
 	query := `
-		SELECT listings.*,
+		SELECT ` + listingColumns + `,
 			users.id,
 			users.first_name,
 			users.last_name,
-			users.email
+			users.email,
+			` + brokerageBrandingColumn + `
 		FROM listings
 		INNER JOIN users
 			ON listings.agent_id = users.id
+		LEFT JOIN brokerages
+			ON brokerages.id = users.brokerage_id
 	`
 
 	rows, err := r.db.QueryContext(ctx, query)
@@ -61,23 +119,14 @@ func (r *ListingRepository) GetAllListings(ctx context.Context) ([]*domain.Listi
 		listing := new(domain.Listing)
 		listing.Agent = new(domain.Agent)
 
-		err := rows.Scan(
-			&listing.ID,
-			&listing.Address,
-			&listing.Price,
-			&listing.Beds,
-			&listing.Baths,
-			&listing.SqFt,
-			&listing.Description,
-			&listing.AgentID,
-			&listing.CreatedAt,
-			&listing.UpdatedAt,
-			&listing.Views,
+		err := rows.Scan(append(
+			listingFields(listing),
 			&listing.Agent.ID,
 			&listing.Agent.FirstName,
 			&listing.Agent.LastName,
 			&listing.Agent.Email,
-		)
+			jsonColumn{&listing.Agent.Brokerage},
+		)...)
 		if err != nil {
 			return nil, err
 		}
@@ -94,37 +143,31 @@ func (r *ListingRepository) GetAllListings(ctx context.Context) ([]*domain.Listi
 
 func (r *ListingRepository) GetListingById(ctx context.Context, id int) (*domain.Listing, error) {
 	query := `
-		SELECT listings.*,
+		SELECT ` + listingColumns + `,
 			users.id,
 			users.first_name,
 			users.last_name,
-			users.email
+			users.email,
+			` + brokerageBrandingColumn + `
 		FROM listings
 		INNER JOIN users
 			ON listings.agent_id = users.id
+		LEFT JOIN brokerages
+			ON brokerages.id = users.brokerage_id
 		WHERE listings.id = $1
 	`
 
 	var listing domain.Listing
 	listing.Agent = new(domain.Agent)
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&listing.ID,
-		&listing.Address,
-		&listing.Price,
-		&listing.Beds,
-		&listing.Baths,
-		&listing.SqFt,
-		&listing.Description,
-		&listing.AgentID,
-		&listing.CreatedAt,
-		&listing.UpdatedAt,
-		&listing.Views,
+	err := r.db.QueryRowContext(ctx, query, id).Scan(append(
+		listingFields(&listing),
 		&listing.Agent.ID,
 		&listing.Agent.FirstName,
 		&listing.Agent.LastName,
 		&listing.Agent.Email,
-	)
+		jsonColumn{&listing.Agent.Brokerage},
+	)...)
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +180,8 @@ func (r *ListingRepository) GetListingsByAgentId(
 	agentId int,
 ) ([]*domain.Listing, error) {
 	query := `
-		SELECT * FROM listings
+		SELECT ` + listingColumns + `
+		FROM listings
 		WHERE agent_id = $1
 	`
 	rows, err := r.db.QueryContext(ctx, query, agentId)
@@ -151,19 +195,7 @@ func (r *ListingRepository) GetListingsByAgentId(
 	for rows.Next() {
 		listing := new(domain.Listing)
 
-		err := rows.Scan(
-			&listing.ID,
-			&listing.Address,
-			&listing.Price,
-			&listing.Beds,
-			&listing.Baths,
-			&listing.SqFt,
-			&listing.Description,
-			&listing.AgentID,
-			&listing.CreatedAt,
-			&listing.UpdatedAt,
-			&listing.Views,
-		)
+		err := rows.Scan(listingFields(listing)...)
 		if err != nil {
 			return nil, err
 		}
@@ -184,9 +216,12 @@ func (r *ListingRepository) CreateListing(
 ) (*domain.Listing, error) {
 	query := `
 		WITH new_listing AS (
-			INSERT INTO listings (address, price, beds, baths, sq_ft, agent_id)
-			VALUES ($1, $2, $3, $4, $5, $6)
-			RETURNING id, created_at, updated_at, agent_id
+			INSERT INTO listings (
+				address, city, state, zip_code, property_type, price, beds,
+				baths, sq_ft, description, hoa_monthly, annual_property_tax, agent_id
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			RETURNING id, status, created_at, updated_at, agent_id
 		)
 		SELECT nl.*, u.id AS agent_id, u.first_name AS agent_first_name,
 			   u.last_name AS agent_last_name, u.email AS agent_email
@@ -197,17 +232,33 @@ func (r *ListingRepository) CreateListing(
 	newListing := *listing
 	newListing.Agent = new(domain.Agent)
 
-	err := r.db.QueryRowContext(ctx, query, listing.Address, listing.Price, listing.Beds, listing.Baths, listing.SqFt, listing.AgentID).
-		Scan(
-			&newListing.ID,
-			&newListing.CreatedAt,
-			&newListing.UpdatedAt,
-			&newListing.AgentID,
-			&newListing.Agent.ID,
-			&newListing.Agent.FirstName,
-			&newListing.Agent.LastName,
-			&newListing.Agent.Email,
-		)
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		listing.Address,
+		listing.City,
+		listing.State,
+		listing.ZipCode,
+		listing.PropertyType,
+		listing.Price,
+		listing.Beds,
+		listing.Baths,
+		listing.SqFt,
+		listing.Description,
+		listing.HOAMonthly,
+		listing.AnnualTax,
+		listing.AgentID,
+	).Scan(
+		&newListing.ID,
+		&newListing.Status,
+		&newListing.CreatedAt,
+		&newListing.UpdatedAt,
+		&newListing.AgentID,
+		&newListing.Agent.ID,
+		&newListing.Agent.FirstName,
+		&newListing.Agent.LastName,
+		&newListing.Agent.Email,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -215,65 +266,170 @@ func (r *ListingRepository) CreateListing(
 	return &newListing, nil
 }
 
+// UpdateListingById applies the update and, in the same transaction,
+// records listing.price_changed and listing.status_changed outbox events for
+// whatever it changed.
 func (r *ListingRepository) UpdateListingById(
 	ctx context.Context,
 	listing *dto.UpdateListingRequest,
 	currentUserCtx *domain.ContextSessionData,
 	listingId int,
 ) (*domain.Listing, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	var oldPrice int
+	var oldStatus string
+
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT price, status FROM listings WHERE id = $1 FOR UPDATE`,
+		listingId,
+	).Scan(&oldPrice, &oldStatus)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("Listing not found or you do not have permission")
+		}
+		return nil, err
+	}
+
 	query := `
 			UPDATE listings
 			SET address = COALESCE($1, address),
-				price = COALESCE($2, price),
-				beds = COALESCE($3, beds),
-				baths = COALESCE($4, baths),
-				sq_ft = COALESCE($5, sq_ft),
-				description = COALESCE($6, description),
-				agent_id = COALESCE($7, agent_id),
+				city = COALESCE($2, city),
+				state = COALESCE($3, state),
+				zip_code = COALESCE($4, zip_code),
+				property_type = COALESCE($5, property_type),
+				sold_at = CASE
+					WHEN $6::TEXT = 'sold' AND status <> 'sold' THEN NOW()
+					WHEN $6::TEXT <> 'sold' THEN NULL
+					ELSE sold_at
+				END,
+				status = COALESCE($6, status),
+				price = COALESCE($7, price),
+				beds = COALESCE($8, beds),
+				baths = COALESCE($9, baths),
+				sq_ft = COALESCE($10, sq_ft),
+				description = COALESCE($11, description),
+				hoa_monthly = COALESCE($12, hoa_monthly),
+				annual_property_tax = COALESCE($13, annual_property_tax),
+				agent_id = COALESCE($14, agent_id),
 				updated_at = NOW()
-			WHERE id = $8 AND 
+			WHERE id = $15 AND 
 			(
-				agent_id = $9
-				OR $10 = 'admin'
+				agent_id = $16
+				OR $17 = 'admin'
+				OR ($17 = 'broker' AND agent_id IN (
+					SELECT id FROM users WHERE brokerage_id = $18
+				))
+			)
+			AND (
+				$14::BIGINT IS NULL
+				OR $17 <> 'broker'
+				OR $14 IN (SELECT id FROM users WHERE brokerage_id = $18)
 			)
-			RETURNING *
+			RETURNING ` + listingColumns + `
 		`
 
 	var updatedListing domain.Listing
 
-	err := r.db.QueryRowContext(
+	err = tx.QueryRowContext(
 		ctx,
 		query,
 		listing.Address,
+		listing.City,
+		listing.State,
+		listing.ZipCode,
+		listing.PropertyType,
+		listing.Status,
 		listing.Price,
 		listing.Beds,
 		listing.Baths,
 		listing.SqFt,
 		listing.Description,
+		listing.HOAMonthly,
+		listing.AnnualTax,
 		listing.AgentID,
 		listingId,
 		currentUserCtx.UserID,
 		currentUserCtx.Role,
-	).Scan(
-		&updatedListing.ID,
-		&updatedListing.Address,
-		&updatedListing.Price,
-		&updatedListing.Beds,
-		&updatedListing.Baths,
-		&updatedListing.SqFt,
-		&updatedListing.Description,
-		&updatedListing.AgentID,
-		&updatedListing.CreatedAt,
-		&updatedListing.UpdatedAt,
-		&updatedListing.Views,
-	)
+		currentUserCtx.BrokerageID,
+	).Scan(listingFields(&updatedListing)...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errors.New("Listing not found or you do not have permission")
 		}
 		return nil, err
 	}
-	return &updatedListing, err
+
+	if err := recordListingChanges(ctx, tx, &updatedListing, oldPrice, oldStatus); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &updatedListing, nil
+}
+
+// recordListingChanges writes an outbox event for each of the price and
+// status that differ from their values before the update. The update time
+// makes the dedup key unique per change.
+func recordListingChanges(
+	ctx context.Context,
+	tx *sql.Tx,
+	listing *domain.Listing,
+	oldPrice int,
+	oldStatus string,
+) error {
+	changedAt := listing.UpdatedAt.UnixMicro()
+
+	if listing.Price != oldPrice {
+		err := insertOutboxEvent(
+			ctx,
+			tx,
+			domain.OutboxEventListingPriceChanged,
+			fmt.Sprintf("%s:%d:%d", domain.OutboxEventListingPriceChanged, listing.ID, changedAt),
+			events.ListingPriceChanged{
+				ListingID: listing.ID,
+				AgentID:   listing.AgentID,
+				Address:   listing.Address,
+				OldPrice:  oldPrice,
+				NewPrice:  listing.Price,
+				ChangedAt: listing.UpdatedAt,
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if listing.Status != oldStatus {
+		err := insertOutboxEvent(
+			ctx,
+			tx,
+			domain.OutboxEventListingStatusChanged,
+			fmt.Sprintf("%s:%d:%d", domain.OutboxEventListingStatusChanged, listing.ID, changedAt),
+			events.ListingStatusChanged{
+				ListingID: listing.ID,
+				AgentID:   listing.AgentID,
+				Address:   listing.Address,
+				OldStatus: oldStatus,
+				NewStatus: listing.Status,
+				ChangedAt: listing.UpdatedAt,
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (r *ListingRepository) DeleteListingById(
@@ -287,6 +443,9 @@ func (r *ListingRepository) DeleteListingById(
 		(
 			agent_id = $2
 			OR $3 = 'admin'
+			OR ($3 = 'broker' AND agent_id IN (
+				SELECT id FROM users WHERE brokerage_id = $4
+			))
 		)
 	`
 
@@ -296,6 +455,7 @@ func (r *ListingRepository) DeleteListingById(
 		listingId,
 		currentUserCtx.UserID,
 		currentUserCtx.Role,
+		currentUserCtx.BrokerageID,
 	)
 	if err != nil {
 		return err
@@ -348,3 +508,63 @@ func (r *ListingRepository) TrackViewsByListingId(
 
 	return nil
 }
+
+// GetComparableListings returns candidate comps for subject: same area and
+// property type, within a bedroom and 30% of its size, listed or sold since.
+// Scoring and final selection happen in ValuationService.
+func (r *ListingRepository) GetComparableListings(
+	ctx context.Context,
+	subject *domain.Listing,
+	since time.Time,
+) ([]*domain.Listing, error) {
+	query := `
+		SELECT ` + listingColumns + `
+		FROM listings
+		WHERE id <> $1
+			AND status IN ('active', 'pending', 'sold')
+			AND property_type = $2
+			AND (
+				($3 <> '' AND zip_code = $3)
+				OR ($3 = '' AND $4 <> '' AND LOWER(city) = LOWER($4))
+			)
+			AND beds BETWEEN $5 - 1 AND $5 + 1
+			AND sq_ft BETWEEN $6 * 0.7 AND $6 * 1.3
+			AND COALESCE(sold_at, created_at) >= $7
+		ORDER BY COALESCE(sold_at, created_at) DESC
+		LIMIT 50
+	`
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		query,
+		subject.ID,
+		subject.PropertyType,
+		subject.ZipCode,
+		subject.City,
+		subject.Beds,
+		subject.SqFt,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var listings []*domain.Listing
+	for rows.Next() {
+		listing := new(domain.Listing)
+
+		if err := rows.Scan(listingFields(listing)...); err != nil {
+			return nil, err
+		}
+
+		listings = append(listings, listing)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return listings, nil
+}
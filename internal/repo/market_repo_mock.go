@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+
+	"server/internal/domain"
+)
+
+type MarketRepoMock struct {
+	GetMarketStatsFunc             func(ctx context.Context, filter *domain.MarketFilter) (*domain.MarketStats, error)
+	GetMaterializedMarketStatsFunc func(ctx context.Context, filter *domain.MarketFilter) (*domain.MarketStats, error)
+	RefreshMarketStatsFunc         func(ctx context.Context) error
+}
+
+func (m *MarketRepoMock) GetMarketStats(
+	ctx context.Context,
+	filter *domain.MarketFilter,
+) (*domain.MarketStats, error) {
+	return m.GetMarketStatsFunc(ctx, filter)
+}
+
+func (m *MarketRepoMock) GetMaterializedMarketStats(
+	ctx context.Context,
+	filter *domain.MarketFilter,
+) (*domain.MarketStats, error) {
+	return m.GetMaterializedMarketStatsFunc(ctx, filter)
+}
+
+func (m *MarketRepoMock) RefreshMarketStats(ctx context.Context) error {
+	return m.RefreshMarketStatsFunc(ctx)
+}
@@ -0,0 +1,171 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"server/internal/domain"
+)
+
+type IMarketRepo interface {
+	GetMarketStats(ctx context.Context, filter *domain.MarketFilter) (*domain.MarketStats, error)
+	GetMaterializedMarketStats(
+		ctx context.Context,
+		filter *domain.MarketFilter,
+	) (*domain.MarketStats, error)
+	RefreshMarketStats(ctx context.Context) error
+}
+
+type MarketRepository struct {
+	db *sql.DB
+}
+
+func NewMarketRepository(db *sql.DB) *MarketRepository {
+	return &MarketRepository{db: db}
+}
+
+// marketStatsFields returns scan destinations for the aggregate columns shared
+// by the live query and the market_stats materialized view.
+func marketStatsFields(stats *domain.MarketStats) []any {
+	return []any{
+		&stats.MedianListPrice,
+		&stats.AverageListPrice,
+		&stats.MedianPricePerSqFt,
+		&stats.ActiveInventory,
+		&stats.MedianDaysOnMarket,
+		&stats.Trend.CurrentMonthMedianPrice,
+		&stats.Trend.PreviousMonthMedianPrice,
+		&stats.Trend.CurrentMonthNewListings,
+		&stats.Trend.PreviousMonthNewListings,
+		&stats.GeneratedAt,
+	}
+}
+
+func (r *MarketRepository) GetMarketStats(
+	ctx context.Context,
+	filter *domain.MarketFilter,
+) (*domain.MarketStats, error) {
+	query := `
+		SELECT
+			COALESCE(
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY price::FLOAT8)
+					FILTER (WHERE status = 'active'),
+				0
+			),
+			COALESCE(AVG(price) FILTER (WHERE status = 'active'), 0)::FLOAT8,
+			COALESCE(
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY price::FLOAT8 / sq_ft)
+					FILTER (WHERE status = 'active'),
+				0
+			),
+			COUNT(*) FILTER (WHERE status = 'active'),
+			COALESCE(
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY (EXTRACT(EPOCH FROM NOW() - created_at) / 86400)::FLOAT8)
+					FILTER (WHERE status = 'active'),
+				0
+			),
+			COALESCE(
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY price::FLOAT8)
+					FILTER (WHERE created_at >= date_trunc('month', NOW())),
+				0
+			),
+			COALESCE(
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY price::FLOAT8)
+					FILTER (
+						WHERE created_at >= date_trunc('month', NOW()) - INTERVAL '1 month'
+						AND created_at < date_trunc('month', NOW())
+					),
+				0
+			),
+			COUNT(*) FILTER (WHERE created_at >= date_trunc('month', NOW())),
+			COUNT(*) FILTER (
+				WHERE created_at >= date_trunc('month', NOW()) - INTERVAL '1 month'
+				AND created_at < date_trunc('month', NOW())
+			),
+			NOW()
+		FROM listings
+		WHERE ($1 = '' OR LOWER(city) = LOWER($1))
+			AND ($2 = '' OR zip_code = $2)
+			AND ($3 = '' OR property_type = $3)
+	`
+
+	stats := &domain.MarketStats{}
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		filter.City,
+		filter.ZipCode,
+		filter.PropertyType,
+	).Scan(marketStatsFields(stats)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetMaterializedMarketStats reads the pre-aggregated row for the filter
+// combination. grouping_id has a bit set for every column that was rolled up,
+// in the order city (4), zip (2), property type (1).
+func (r *MarketRepository) GetMaterializedMarketStats(
+	ctx context.Context,
+	filter *domain.MarketFilter,
+) (*domain.MarketStats, error) {
+	query := `
+		SELECT
+			median_list_price,
+			average_list_price,
+			median_price_per_sq_ft,
+			active_inventory,
+			median_days_on_market,
+			current_month_median_price,
+			previous_month_median_price,
+			current_month_new_listings,
+			previous_month_new_listings,
+			refreshed_at
+		FROM market_stats
+		WHERE grouping_id = $1
+			AND city_key = LOWER($2)
+			AND zip_key = $3
+			AND property_type_key = $4
+	`
+
+	groupingId := 0
+	if filter.City == "" {
+		groupingId |= 4
+	}
+	if filter.ZipCode == "" {
+		groupingId |= 2
+	}
+	if filter.PropertyType == "" {
+		groupingId |= 1
+	}
+
+	stats := &domain.MarketStats{}
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		groupingId,
+		filter.City,
+		filter.ZipCode,
+		filter.PropertyType,
+	).Scan(marketStatsFields(stats)...)
+	if err != nil {
+		// No listings in this area when the view was last refreshed
+		if errors.Is(err, sql.ErrNoRows) {
+			return &domain.MarketStats{GeneratedAt: time.Now()}, nil
+		}
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (r *MarketRepository) RefreshMarketStats(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY market_stats`)
+	return err
+}
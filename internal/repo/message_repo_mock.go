@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"context"
+
+	"server/internal/domain"
+)
+
+type MessageRepoMock struct {
+	GetOrCreateConversationFunc  func(ctx context.Context, listingId int, buyerId int, agentId int) (*domain.Conversation, error)
+	GetConversationByIdFunc      func(ctx context.Context, id int) (*domain.Conversation, error)
+	GetConversationsByUserIdFunc func(ctx context.Context, userId int) ([]*domain.Conversation, error)
+	CreateMessageFunc            func(ctx context.Context, message *domain.Message) (*domain.Message, error)
+	GetMessagesFunc              func(ctx context.Context, conversationId int, beforeId int, limit int) ([]*domain.Message, error)
+	MarkConversationReadFunc     func(ctx context.Context, conversationId int, readerId int) (*domain.ReadReceipt, error)
+	GetUnreadCountFunc           func(ctx context.Context, userId int) (int, error)
+}
+
+func (m *MessageRepoMock) GetOrCreateConversation(
+	ctx context.Context,
+	listingId int,
+	buyerId int,
+	agentId int,
+) (*domain.Conversation, error) {
+	return m.GetOrCreateConversationFunc(ctx, listingId, buyerId, agentId)
+}
+
+func (m *MessageRepoMock) GetConversationById(
+	ctx context.Context,
+	id int,
+) (*domain.Conversation, error) {
+	return m.GetConversationByIdFunc(ctx, id)
+}
+
+func (m *MessageRepoMock) GetConversationsByUserId(
+	ctx context.Context,
+	userId int,
+) ([]*domain.Conversation, error) {
+	return m.GetConversationsByUserIdFunc(ctx, userId)
+}
+
+func (m *MessageRepoMock) CreateMessage(
+	ctx context.Context,
+	message *domain.Message,
+) (*domain.Message, error) {
+	return m.CreateMessageFunc(ctx, message)
+}
+
+func (m *MessageRepoMock) GetMessages(
+	ctx context.Context,
+	conversationId int,
+	beforeId int,
+	limit int,
+) ([]*domain.Message, error) {
+	return m.GetMessagesFunc(ctx, conversationId, beforeId, limit)
+}
+
+func (m *MessageRepoMock) MarkConversationRead(
+	ctx context.Context,
+	conversationId int,
+	readerId int,
+) (*domain.ReadReceipt, error) {
+	return m.MarkConversationReadFunc(ctx, conversationId, readerId)
+}
+
+func (m *MessageRepoMock) GetUnreadCount(ctx context.Context, userId int) (int, error) {
+	return m.GetUnreadCountFunc(ctx, userId)
+}
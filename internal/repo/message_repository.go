@@ -0,0 +1,341 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"server/internal/domain"
+)
+
+type IMessageRepo interface {
+	GetOrCreateConversation(
+		ctx context.Context,
+		listingId int,
+		buyerId int,
+		agentId int,
+	) (*domain.Conversation, error)
+	GetConversationById(ctx context.Context, id int) (*domain.Conversation, error)
+	GetConversationsByUserId(ctx context.Context, userId int) ([]*domain.Conversation, error)
+	CreateMessage(ctx context.Context, message *domain.Message) (*domain.Message, error)
+	GetMessages(
+		ctx context.Context,
+		conversationId int,
+		beforeId int,
+		limit int,
+	) ([]*domain.Message, error)
+	MarkConversationRead(
+		ctx context.Context,
+		conversationId int,
+		readerId int,
+	) (*domain.ReadReceipt, error)
+	GetUnreadCount(ctx context.Context, userId int) (int, error)
+}
+
+type MessageRepository struct {
+	db *sql.DB
+}
+
+func NewMessageRepository(db *sql.DB) *MessageRepository {
+	return &MessageRepository{db: db}
+}
+
+func (r *MessageRepository) GetOrCreateConversation(
+	ctx context.Context,
+	listingId int,
+	buyerId int,
+	agentId int,
+) (*domain.Conversation, error) {
+	// The no-op update makes RETURNING yield the existing row on conflict
+	query := `
+		INSERT INTO conversations (listing_id, buyer_id, agent_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (listing_id, buyer_id) DO UPDATE
+		SET listing_id = EXCLUDED.listing_id
+		RETURNING id, listing_id, buyer_id, agent_id, last_message_at, created_at
+	`
+
+	var conversation domain.Conversation
+
+	err := r.db.QueryRowContext(ctx, query, listingId, buyerId, agentId).Scan(
+		&conversation.ID,
+		&conversation.ListingID,
+		&conversation.BuyerID,
+		&conversation.AgentID,
+		&conversation.LastMessageAt,
+		&conversation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+func (r *MessageRepository) GetConversationById(
+	ctx context.Context,
+	id int,
+) (*domain.Conversation, error) {
+	query := `
+		SELECT id, listing_id, buyer_id, agent_id, last_message_at, created_at
+		FROM conversations
+		WHERE id = $1
+	`
+
+	var conversation domain.Conversation
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&conversation.ID,
+		&conversation.ListingID,
+		&conversation.BuyerID,
+		&conversation.AgentID,
+		&conversation.LastMessageAt,
+		&conversation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+// GetConversationsByUserId returns the user's conversations, most recently
+// active first, with the latest message and the user's unread count.
+func (r *MessageRepository) GetConversationsByUserId(
+	ctx context.Context,
+	userId int,
+) ([]*domain.Conversation, error) {
+	query := `
+		SELECT
+			c.id,
+			c.listing_id,
+			c.buyer_id,
+			c.agent_id,
+			c.last_message_at,
+			c.created_at,
+			(
+				SELECT COUNT(*) FROM messages
+				WHERE conversation_id = c.id AND sender_id <> $1 AND read_at IS NULL
+			),
+			last.id,
+			last.sender_id,
+			last.body,
+			last.read_at,
+			last.created_at
+		FROM conversations c
+		LEFT JOIN LATERAL (
+			SELECT id, sender_id, body, read_at, created_at
+			FROM messages
+			WHERE conversation_id = c.id
+			ORDER BY id DESC
+			LIMIT 1
+		) last ON TRUE
+		WHERE c.buyer_id = $1 OR c.agent_id = $1
+		ORDER BY COALESCE(c.last_message_at, c.created_at) DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var conversations []*domain.Conversation
+	for rows.Next() {
+		conversation := new(domain.Conversation)
+
+		var (
+			lastId        sql.NullInt64
+			lastSenderId  sql.NullInt64
+			lastBody      sql.NullString
+			lastReadAt    *time.Time
+			lastCreatedAt sql.NullTime
+		)
+
+		if err := rows.Scan(
+			&conversation.ID,
+			&conversation.ListingID,
+			&conversation.BuyerID,
+			&conversation.AgentID,
+			&conversation.LastMessageAt,
+			&conversation.CreatedAt,
+			&conversation.UnreadCount,
+			&lastId,
+			&lastSenderId,
+			&lastBody,
+			&lastReadAt,
+			&lastCreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if lastId.Valid {
+			conversation.LastMessage = &domain.Message{
+				ID:             int(lastId.Int64),
+				ConversationID: conversation.ID,
+				SenderID:       int(lastSenderId.Int64),
+				Body:           lastBody.String,
+				ReadAt:         lastReadAt,
+				CreatedAt:      lastCreatedAt.Time,
+			}
+		}
+
+		conversations = append(conversations, conversation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return conversations, nil
+}
+
+func (r *MessageRepository) CreateMessage(
+	ctx context.Context,
+	message *domain.Message,
+) (*domain.Message, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO messages (conversation_id, sender_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	newMessage := *message
+
+	err = tx.QueryRowContext(ctx, query, message.ConversationID, message.SenderID, message.Body).
+		Scan(&newMessage.ID, &newMessage.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE conversations SET last_message_at = $1 WHERE id = $2`,
+		newMessage.CreatedAt,
+		message.ConversationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &newMessage, nil
+}
+
+// GetMessages returns up to limit messages older than beforeId, newest first.
+// A beforeId of 0 starts from the latest message.
+func (r *MessageRepository) GetMessages(
+	ctx context.Context,
+	conversationId int,
+	beforeId int,
+	limit int,
+) ([]*domain.Message, error) {
+	query := `
+		SELECT id, conversation_id, sender_id, body, read_at, created_at
+		FROM messages
+		WHERE conversation_id = $1 AND ($2 = 0 OR id < $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, conversationId, beforeId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		message := new(domain.Message)
+
+		if err := rows.Scan(
+			&message.ID,
+			&message.ConversationID,
+			&message.SenderID,
+			&message.Body,
+			&message.ReadAt,
+			&message.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// MarkConversationRead marks every unread message from the other participant
+// as read. It returns nil when there was nothing to mark.
+func (r *MessageRepository) MarkConversationRead(
+	ctx context.Context,
+	conversationId int,
+	readerId int,
+) (*domain.ReadReceipt, error) {
+	query := `
+		WITH updated AS (
+			UPDATE messages
+			SET read_at = NOW()
+			WHERE conversation_id = $1 AND sender_id <> $2 AND read_at IS NULL
+			RETURNING id, read_at
+		)
+		SELECT MAX(id), MAX(read_at) FROM updated
+	`
+
+	var (
+		lastReadId sql.NullInt64
+		readAt     sql.NullTime
+	)
+
+	if err := r.db.QueryRowContext(ctx, query, conversationId, readerId).
+		Scan(&lastReadId, &readAt); err != nil {
+		return nil, err
+	}
+
+	if !lastReadId.Valid {
+		return nil, nil
+	}
+
+	return &domain.ReadReceipt{
+		ConversationID: conversationId,
+		ReaderID:       readerId,
+		LastReadID:     int(lastReadId.Int64),
+		ReadAt:         readAt.Time,
+	}, nil
+}
+
+func (r *MessageRepository) GetUnreadCount(ctx context.Context, userId int) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM messages
+		INNER JOIN conversations
+			ON messages.conversation_id = conversations.id
+		WHERE (conversations.buyer_id = $1 OR conversations.agent_id = $1)
+			AND messages.sender_id <> $1
+			AND messages.read_at IS NULL
+	`
+
+	var count int
+
+	if err := r.db.QueryRowContext(ctx, query, userId).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
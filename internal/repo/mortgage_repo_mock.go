@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"context"
+
+	"server/internal/domain"
+)
+
+type MortgageRepoMock struct {
+	GetMortgagePreferencesFunc    func(ctx context.Context, userId int) (*domain.MortgagePreferences, error)
+	UpsertMortgagePreferencesFunc func(ctx context.Context, prefs *domain.MortgagePreferences) (*domain.MortgagePreferences, error)
+}
+
+func (m *MortgageRepoMock) GetMortgagePreferences(
+	ctx context.Context,
+	userId int,
+) (*domain.MortgagePreferences, error) {
+	return m.GetMortgagePreferencesFunc(ctx, userId)
+}
+
+func (m *MortgageRepoMock) UpsertMortgagePreferences(
+	ctx context.Context,
+	prefs *domain.MortgagePreferences,
+) (*domain.MortgagePreferences, error) {
+	return m.UpsertMortgagePreferencesFunc(ctx, prefs)
+}
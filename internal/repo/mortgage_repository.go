@@ -0,0 +1,82 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"server/internal/domain"
+)
+
+type IMortgageRepo interface {
+	GetMortgagePreferences(ctx context.Context, userId int) (*domain.MortgagePreferences, error)
+	UpsertMortgagePreferences(
+		ctx context.Context,
+		prefs *domain.MortgagePreferences,
+	) (*domain.MortgagePreferences, error)
+}
+
+type MortgageRepository struct {
+	db *sql.DB
+}
+
+func NewMortgageRepository(db *sql.DB) *MortgageRepository {
+	return &MortgageRepository{db: db}
+}
+
+func (r *MortgageRepository) GetMortgagePreferences(
+	ctx context.Context,
+	userId int,
+) (*domain.MortgagePreferences, error) {
+	query := `
+		SELECT user_id, down_payment_pct, interest_rate, term_years, updated_at
+		FROM mortgage_preferences
+		WHERE user_id = $1
+	`
+
+	var prefs domain.MortgagePreferences
+
+	err := r.db.QueryRowContext(ctx, query, userId).Scan(
+		&prefs.UserID,
+		&prefs.DownPaymentPct,
+		&prefs.InterestRate,
+		&prefs.TermYears,
+		&prefs.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &prefs, nil
+}
+
+func (r *MortgageRepository) UpsertMortgagePreferences(
+	ctx context.Context,
+	prefs *domain.MortgagePreferences,
+) (*domain.MortgagePreferences, error) {
+	query := `
+		INSERT INTO mortgage_preferences (user_id, down_payment_pct, interest_rate, term_years)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET down_payment_pct = EXCLUDED.down_payment_pct,
+			interest_rate = EXCLUDED.interest_rate,
+			term_years = EXCLUDED.term_years,
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	saved := *prefs
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		prefs.UserID,
+		prefs.DownPaymentPct,
+		prefs.InterestRate,
+		prefs.TermYears,
+	).Scan(&saved.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &saved, nil
+}
@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"server/internal/domain"
+)
+
+type NotificationPreferenceRepoMock struct {
+	GetSettingsFunc     func(ctx context.Context, userId int) (*domain.NotificationSettings, error)
+	ReplaceSettingsFunc func(ctx context.Context, settings *domain.NotificationSettings) (*domain.NotificationSettings, error)
+	GetDeliveriesFunc   func(ctx context.Context, userIds []int, notificationType string) (map[int]*domain.NotificationDelivery, error)
+
+	GetDigestSubscribersFunc func(ctx context.Context) ([]domain.DigestSubscriber, error)
+	ClaimDigestFunc          func(ctx context.Context, userId int, periodStart time.Time) ([]*domain.Notification, error)
+	ReleaseDigestFunc        func(ctx context.Context, userId int, lastDigestAt *time.Time, notificationIds []int) error
+}
+
+func (n *NotificationPreferenceRepoMock) GetSettings(
+	ctx context.Context,
+	userId int,
+) (*domain.NotificationSettings, error) {
+	return n.GetSettingsFunc(ctx, userId)
+}
+
+func (n *NotificationPreferenceRepoMock) ReplaceSettings(
+	ctx context.Context,
+	settings *domain.NotificationSettings,
+) (*domain.NotificationSettings, error) {
+	return n.ReplaceSettingsFunc(ctx, settings)
+}
+
+func (n *NotificationPreferenceRepoMock) GetDeliveries(
+	ctx context.Context,
+	userIds []int,
+	notificationType string,
+) (map[int]*domain.NotificationDelivery, error) {
+	return n.GetDeliveriesFunc(ctx, userIds, notificationType)
+}
+
+func (n *NotificationPreferenceRepoMock) GetDigestSubscribers(
+	ctx context.Context,
+) ([]domain.DigestSubscriber, error) {
+	return n.GetDigestSubscribersFunc(ctx)
+}
+
+func (n *NotificationPreferenceRepoMock) ClaimDigest(
+	ctx context.Context,
+	userId int,
+	periodStart time.Time,
+) ([]*domain.Notification, error) {
+	return n.ClaimDigestFunc(ctx, userId, periodStart)
+}
+
+func (n *NotificationPreferenceRepoMock) ReleaseDigest(
+	ctx context.Context,
+	userId int,
+	lastDigestAt *time.Time,
+	notificationIds []int,
+) error {
+	return n.ReleaseDigestFunc(ctx, userId, lastDigestAt, notificationIds)
+}
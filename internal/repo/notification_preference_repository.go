@@ -0,0 +1,478 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"server/internal/domain"
+)
+
+type INotificationPreferenceRepo interface {
+	GetSettings(ctx context.Context, userId int) (*domain.NotificationSettings, error)
+	ReplaceSettings(
+		ctx context.Context,
+		settings *domain.NotificationSettings,
+	) (*domain.NotificationSettings, error)
+	// GetDeliveries returns how each user wants notificationType delivered,
+	// falling back to the default channels for types they haven't configured.
+	GetDeliveries(
+		ctx context.Context,
+		userIds []int,
+		notificationType string,
+	) (map[int]*domain.NotificationDelivery, error)
+	GetDigestSubscribers(ctx context.Context) ([]domain.DigestSubscriber, error)
+	// ClaimDigest marks the user's digest as sent for the period starting at
+	// periodStart and returns their unread notifications not yet digested,
+	// marking them too. Only types the user has email on for, created since
+	// the digest was turned on, are included. A period that was already
+	// claimed returns nothing.
+	ClaimDigest(
+		ctx context.Context,
+		userId int,
+		periodStart time.Time,
+	) ([]*domain.Notification, error)
+	// ReleaseDigest undoes a claim whose email could not be queued, putting
+	// last_digest_at back to lastDigestAt and unmarking the notifications so
+	// the next run picks them up again.
+	ReleaseDigest(
+		ctx context.Context,
+		userId int,
+		lastDigestAt *time.Time,
+		notificationIds []int,
+	) error
+}
+
+type NotificationPreferenceRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationPreferenceRepository(db *sql.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{db: db}
+}
+
+func (r *NotificationPreferenceRepository) getSettings(
+	ctx context.Context,
+	q queryer,
+	userId int,
+) (*domain.NotificationSettings, error) {
+	settings := &domain.NotificationSettings{
+		UserID:      userId,
+		Preferences: []domain.NotificationPreference{},
+	}
+
+	settingsQuery := `
+		SELECT
+			users.timezone,
+			to_char(notification_settings.quiet_hours_start, 'HH24:MI'),
+			to_char(notification_settings.quiet_hours_end, 'HH24:MI'),
+			COALESCE(notification_settings.digest, $2)
+		FROM users
+		LEFT JOIN notification_settings
+			ON notification_settings.user_id = users.id
+		WHERE users.id = $1
+	`
+
+	var quietStart, quietEnd sql.NullString
+
+	err := q.QueryRowContext(ctx, settingsQuery, userId, domain.NotificationDigestOff).
+		Scan(&settings.Timezone, &quietStart, &quietEnd, &settings.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	if quietStart.Valid && quietEnd.Valid {
+		settings.QuietHours = &domain.QuietHours{Start: quietStart.String, End: quietEnd.String}
+	}
+
+	preferencesQuery := `
+		SELECT type, in_app, push, email, webhook
+		FROM notification_preferences
+		WHERE user_id = $1
+		ORDER BY type
+	`
+
+	rows, err := q.QueryContext(ctx, preferencesQuery, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var preference domain.NotificationPreference
+
+		if err := rows.Scan(
+			&preference.Type,
+			&preference.InApp,
+			&preference.Push,
+			&preference.Email,
+			&preference.Webhook,
+		); err != nil {
+			return nil, err
+		}
+
+		settings.Preferences = append(settings.Preferences, preference)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (r *NotificationPreferenceRepository) GetSettings(
+	ctx context.Context,
+	userId int,
+) (*domain.NotificationSettings, error) {
+	return r.getSettings(ctx, r.db, userId)
+}
+
+// ReplaceSettings swaps the user's timezone, quiet hours and per-type
+// preferences in one transaction.
+func (r *NotificationPreferenceRepository) ReplaceSettings(
+	ctx context.Context,
+	settings *domain.NotificationSettings,
+) (*domain.NotificationSettings, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE users SET timezone = $1, updated_at = NOW() WHERE id = $2`,
+		settings.Timezone,
+		settings.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var quietStart, quietEnd *string
+	if settings.QuietHours != nil {
+		quietStart = &settings.QuietHours.Start
+		quietEnd = &settings.QuietHours.End
+	}
+
+	// digest_since restarts whenever the digest is turned on from off
+	settingsQuery := `
+		INSERT INTO notification_settings (
+			user_id,
+			quiet_hours_start,
+			quiet_hours_end,
+			digest,
+			digest_since
+		)
+		VALUES ($1, $2::time, $3::time, $4, CASE WHEN $4 <> $5 THEN NOW() END)
+		ON CONFLICT (user_id) DO UPDATE
+		SET quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			digest = EXCLUDED.digest,
+			digest_since = CASE
+				WHEN EXCLUDED.digest = $5 THEN NULL
+				WHEN notification_settings.digest = $5 THEN NOW()
+				ELSE notification_settings.digest_since
+			END,
+			updated_at = NOW()
+	`
+
+	_, err = tx.ExecContext(
+		ctx,
+		settingsQuery,
+		settings.UserID,
+		quietStart,
+		quietEnd,
+		settings.Digest,
+		domain.NotificationDigestOff,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`DELETE FROM notification_preferences WHERE user_id = $1`,
+		settings.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	insertQuery := `
+		INSERT INTO notification_preferences (user_id, type, in_app, push, email, webhook)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	for _, preference := range settings.Preferences {
+		_, err := tx.ExecContext(
+			ctx,
+			insertQuery,
+			settings.UserID,
+			preference.Type,
+			preference.InApp,
+			preference.Push,
+			preference.Email,
+			preference.Webhook,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	newSettings, err := r.getSettings(ctx, tx, settings.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return newSettings, nil
+}
+
+func (r *NotificationPreferenceRepository) GetDeliveries(
+	ctx context.Context,
+	userIds []int,
+	notificationType string,
+) (map[int]*domain.NotificationDelivery, error) {
+	query := `
+		SELECT
+			users.id,
+			COALESCE(notification_preferences.in_app, $3),
+			COALESCE(notification_preferences.push, $4),
+			COALESCE(notification_preferences.email, $5),
+			COALESCE(notification_preferences.webhook, $6),
+			users.timezone,
+			to_char(notification_settings.quiet_hours_start, 'HH24:MI'),
+			to_char(notification_settings.quiet_hours_end, 'HH24:MI'),
+			COALESCE(notification_settings.digest, $7)
+		FROM users
+		LEFT JOIN notification_settings
+			ON notification_settings.user_id = users.id
+		LEFT JOIN notification_preferences
+			ON notification_preferences.user_id = users.id
+			AND notification_preferences.type = $2
+		WHERE users.id = ANY($1)
+	`
+
+	defaults := domain.DefaultNotificationChannels
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		query,
+		userIds,
+		notificationType,
+		defaults.InApp,
+		defaults.Push,
+		defaults.Email,
+		defaults.Webhook,
+		domain.NotificationDigestOff,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries := make(map[int]*domain.NotificationDelivery, len(userIds))
+	for rows.Next() {
+		var userId int
+		var quietStart, quietEnd sql.NullString
+		delivery := new(domain.NotificationDelivery)
+
+		if err := rows.Scan(
+			&userId,
+			&delivery.Channels.InApp,
+			&delivery.Channels.Push,
+			&delivery.Channels.Email,
+			&delivery.Channels.Webhook,
+			&delivery.Timezone,
+			&quietStart,
+			&quietEnd,
+			&delivery.Digest,
+		); err != nil {
+			return nil, err
+		}
+
+		if quietStart.Valid && quietEnd.Valid {
+			delivery.QuietHours = &domain.QuietHours{Start: quietStart.String, End: quietEnd.String}
+		}
+
+		deliveries[userId] = delivery
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *NotificationPreferenceRepository) GetDigestSubscribers(
+	ctx context.Context,
+) ([]domain.DigestSubscriber, error) {
+	query := `
+		SELECT
+			notification_settings.user_id,
+			notification_settings.digest,
+			users.timezone,
+			notification_settings.last_digest_at
+		FROM notification_settings
+		JOIN users ON users.id = notification_settings.user_id
+		WHERE notification_settings.digest <> $1
+		ORDER BY notification_settings.user_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.NotificationDigestOff)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var subscribers []domain.DigestSubscriber
+	for rows.Next() {
+		var subscriber domain.DigestSubscriber
+
+		if err := rows.Scan(
+			&subscriber.UserID,
+			&subscriber.Digest,
+			&subscriber.Timezone,
+			&subscriber.LastDigestAt,
+		); err != nil {
+			return nil, err
+		}
+
+		subscribers = append(subscribers, subscriber)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscribers, nil
+}
+
+func (r *NotificationPreferenceRepository) ClaimDigest(
+	ctx context.Context,
+	userId int,
+	periodStart time.Time,
+) ([]*domain.Notification, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	claimQuery := `
+		UPDATE notification_settings
+		SET last_digest_at = NOW()
+		WHERE user_id = $1
+			AND digest <> $2
+			AND (last_digest_at IS NULL OR last_digest_at < $3)
+	`
+
+	result, err := tx.ExecContext(ctx, claimQuery, userId, domain.NotificationDigestOff, periodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if claimed == 0 {
+		return nil, nil
+	}
+
+	// Only types the user gets email for, created since the digest was
+	// turned on
+	notificationsQuery := `
+		UPDATE notifications
+		SET digested_at = NOW()
+		WHERE user_id = $1
+			AND is_read = FALSE
+			AND digested_at IS NULL
+			AND created_at >= (
+				SELECT digest_since FROM notification_settings WHERE user_id = $1
+			)
+			AND COALESCE(
+				(
+					SELECT email FROM notification_preferences
+					WHERE user_id = $1 AND type = notifications.type
+				),
+				$2
+			)
+		RETURNING ` + notificationColumns
+
+	rows, err := tx.QueryContext(ctx, notificationsQuery, userId, domain.DefaultNotificationChannels.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		notification := new(domain.Notification)
+
+		if err := rows.Scan(notificationFields(notification)...); err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationPreferenceRepository) ReleaseDigest(
+	ctx context.Context,
+	userId int,
+	lastDigestAt *time.Time,
+	notificationIds []int,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE notification_settings SET last_digest_at = $2 WHERE user_id = $1`,
+		userId,
+		lastDigestAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE notifications SET digested_at = NULL WHERE user_id = $1 AND id = ANY($2)`,
+		userId,
+		notificationIds,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
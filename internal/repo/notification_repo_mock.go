@@ -7,16 +7,28 @@ import (
 )
 
 type NotificationRepoMock struct {
-	GetAllNotificationsByUserIdFunc func(ctx context.Context, userId int) ([]*domain.Notification, error)
-	CreateNotificationFunc          func(ctx context.Context, notification *domain.Notification) (*domain.Notification, error)
-	MarkNotificationAsReadFunc      func(ctx context.Context, userId int) (*domain.Notification, error)
+	GetNotificationsFunc         func(ctx context.Context, userId int, filter domain.NotificationFilter, beforeId int, limit int) ([]*domain.Notification, error)
+	GetUnreadCountFunc           func(ctx context.Context, userId int) (int, error)
+	CreateNotificationFunc       func(ctx context.Context, notification *domain.Notification) (*domain.Notification, error)
+	CreateNotificationsFunc      func(ctx context.Context, userIds []int, listingId int, notificationType string, message string) ([]*domain.Notification, error)
+	CreateNotificationsOnceFunc  func(ctx context.Context, dedupKey string, userIds []int, listingId int, notificationType string, message string) ([]*domain.Notification, error)
+	SetNotificationReadFunc      func(ctx context.Context, userId int, id int, isRead bool) (*domain.Notification, error)
+	MarkAllNotificationsReadFunc func(ctx context.Context, userId int) error
+	DeleteNotificationFunc       func(ctx context.Context, userId int, id int) error
 }
 
-func (n *NotificationRepoMock) GetAllNotificationsByUserId(
+func (n *NotificationRepoMock) GetNotifications(
 	ctx context.Context,
 	userId int,
+	filter domain.NotificationFilter,
+	beforeId int,
+	limit int,
 ) ([]*domain.Notification, error) {
-	return n.GetAllNotificationsByUserIdFunc(ctx, userId)
+	return n.GetNotificationsFunc(ctx, userId, filter, beforeId, limit)
+}
+
+func (n *NotificationRepoMock) GetUnreadCount(ctx context.Context, userId int) (int, error) {
+	return n.GetUnreadCountFunc(ctx, userId)
 }
 
 func (n *NotificationRepoMock) CreateNotification(
@@ -26,9 +38,40 @@ func (n *NotificationRepoMock) CreateNotification(
 	return n.CreateNotificationFunc(ctx, notification)
 }
 
-func (n *NotificationRepoMock) MarkNotificationAsRead(
+func (n *NotificationRepoMock) CreateNotifications(
+	ctx context.Context,
+	userIds []int,
+	listingId int,
+	notificationType string,
+	message string,
+) ([]*domain.Notification, error) {
+	return n.CreateNotificationsFunc(ctx, userIds, listingId, notificationType, message)
+}
+
+func (n *NotificationRepoMock) CreateNotificationsOnce(
+	ctx context.Context,
+	dedupKey string,
+	userIds []int,
+	listingId int,
+	notificationType string,
+	message string,
+) ([]*domain.Notification, error) {
+	return n.CreateNotificationsOnceFunc(ctx, dedupKey, userIds, listingId, notificationType, message)
+}
+
+func (n *NotificationRepoMock) SetNotificationRead(
 	ctx context.Context,
 	userId int,
+	id int,
+	isRead bool,
 ) (*domain.Notification, error) {
-	return n.MarkNotificationAsReadFunc(ctx, userId)
+	return n.SetNotificationReadFunc(ctx, userId, id, isRead)
+}
+
+func (n *NotificationRepoMock) MarkAllNotificationsRead(ctx context.Context, userId int) error {
+	return n.MarkAllNotificationsReadFunc(ctx, userId)
+}
+
+func (n *NotificationRepoMock) DeleteNotification(ctx context.Context, userId int, id int) error {
+	return n.DeleteNotificationFunc(ctx, userId, id)
 }
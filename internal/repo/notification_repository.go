@@ -8,15 +8,50 @@ import (
 )
 
 type INotificationRepo interface {
-	GetAllNotificationsByUserId(
+	// GetNotifications returns the user's notifications newest first, older
+	// than beforeId when it is set.
+	GetNotifications(
 		ctx context.Context,
 		userId int,
+		filter domain.NotificationFilter,
+		beforeId int,
+		limit int,
 	) ([]*domain.Notification, error)
+	GetUnreadCount(ctx context.Context, userId int) (int, error)
 	CreateNotification(
 		ctx context.Context,
 		notification *domain.Notification,
 	) (*domain.Notification, error)
-	ToggleNotificationReadStatus(ctx context.Context, userId int) (*domain.Notification, error)
+	// CreateNotifications inserts the same notification for every user in one
+	// statement.
+	CreateNotifications(
+		ctx context.Context,
+		userIds []int,
+		listingId int,
+		notificationType string,
+		message string,
+	) ([]*domain.Notification, error)
+	// CreateNotificationsOnce is CreateNotifications for events that may be
+	// delivered more than once. Users who already have a notification with
+	// dedupKey are skipped, so only the newly inserted rows are returned.
+	CreateNotificationsOnce(
+		ctx context.Context,
+		dedupKey string,
+		userIds []int,
+		listingId int,
+		notificationType string,
+		message string,
+	) ([]*domain.Notification, error)
+	// SetNotificationRead and DeleteNotification only touch the user's own
+	// notifications; anyone else's are reported as sql.ErrNoRows.
+	SetNotificationRead(
+		ctx context.Context,
+		userId int,
+		id int,
+		isRead bool,
+	) (*domain.Notification, error)
+	MarkAllNotificationsRead(ctx context.Context, userId int) error
+	DeleteNotification(ctx context.Context, userId int, id int) error
 }
 
 type NotificationRepository struct {
@@ -29,35 +64,58 @@ func NewNotificationRepository(db *sql.DB) *NotificationRepository {
 	}
 }
 
-func (r *NotificationRepository) GetAllNotificationsByUserId(
+const notificationColumns = `
+	id,
+	user_id,
+	listing_id,
+	type,
+	message,
+	is_read,
+	created_at
+`
+
+func notificationFields(notification *domain.Notification) []any {
+	return []any{
+		&notification.ID,
+		&notification.UserID,
+		&notification.ListingID,
+		&notification.Type,
+		&notification.Message,
+		&notification.IsRead,
+		&notification.CreatedAt,
+	}
+}
+
+func (r *NotificationRepository) GetNotifications(
 	ctx context.Context,
 	userId int,
+	filter domain.NotificationFilter,
+	beforeId int,
+	limit int,
 ) ([]*domain.Notification, error) {
 	query := `
-		SELECT * FROM notifications
+		SELECT ` + notificationColumns + `
+		FROM notifications
 		WHERE user_id = $1
+			AND ($2 = 0 OR id < $2)
+			AND ($3 = '' OR type = $3)
+			AND ($4::BOOLEAN IS NULL OR is_read = $4)
+		ORDER BY id DESC
+		LIMIT $5
 	`
 
-	var notifications []*domain.Notification
-
-	rows, err := r.db.QueryContext(ctx, query, userId)
+	rows, err := r.db.QueryContext(ctx, query, userId, beforeId, filter.Type, filter.IsRead, limit)
 	if err != nil {
 		return nil, err
 	}
 
 	defer rows.Close()
+
+	var notifications []*domain.Notification
 	for rows.Next() {
 		notification := new(domain.Notification)
 
-		if err := rows.Scan(
-			&notification.ID,
-			&notification.UserID,
-			&notification.ListingID,
-			&notification.Type,
-			&notification.Message,
-			&notification.IsRead,
-			&notification.CreatedAt,
-		); err != nil {
+		if err := rows.Scan(notificationFields(notification)...); err != nil {
 			return nil, err
 		}
 
@@ -71,6 +129,22 @@ func (r *NotificationRepository) GetAllNotificationsByUserId(
 	return notifications, nil
 }
 
+func (r *NotificationRepository) GetUnreadCount(ctx context.Context, userId int) (int, error) {
+	// Served by idx_notifications_user_id_is_read
+	query := `
+		SELECT COUNT(*) FROM notifications
+		WHERE user_id = $1 AND is_read = FALSE
+	`
+
+	var count int
+
+	if err := r.db.QueryRowContext(ctx, query, userId).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (r *NotificationRepository) CreateNotification(
 	ctx context.Context,
 	notification *domain.Notification,
@@ -99,35 +173,135 @@ func (r *NotificationRepository) CreateNotification(
 	return &newNotification, nil
 }
 
-func (r *NotificationRepository) ToggleNotificationReadStatus(
+func (r *NotificationRepository) CreateNotifications(
+	ctx context.Context,
+	userIds []int,
+	listingId int,
+	notificationType string,
+	message string,
+) ([]*domain.Notification, error) {
+	return r.insertNotifications(ctx, "", userIds, listingId, notificationType, message)
+}
+
+func (r *NotificationRepository) CreateNotificationsOnce(
+	ctx context.Context,
+	dedupKey string,
+	userIds []int,
+	listingId int,
+	notificationType string,
+	message string,
+) ([]*domain.Notification, error) {
+	return r.insertNotifications(ctx, dedupKey, userIds, listingId, notificationType, message)
+}
+
+// insertNotifications stores an empty dedupKey as NULL, which never
+// conflicts.
+func (r *NotificationRepository) insertNotifications(
+	ctx context.Context,
+	dedupKey string,
+	userIds []int,
+	listingId int,
+	notificationType string,
+	message string,
+) ([]*domain.Notification, error) {
+	query := `
+		INSERT INTO notifications (user_id, listing_id, type, message, dedup_key)
+		SELECT user_id, $2, $3, $4, NULLIF($5, '')
+		FROM UNNEST($1::BIGINT[]) AS user_id
+		ON CONFLICT (user_id, dedup_key) DO NOTHING
+		RETURNING id, user_id, created_at
+	`
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		query,
+		userIds,
+		listingId,
+		notificationType,
+		message,
+		dedupKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	notifications := make([]*domain.Notification, 0, len(userIds))
+	for rows.Next() {
+		notification := &domain.Notification{
+			ListingID: listingId,
+			Type:      notificationType,
+			Message:   message,
+		}
+
+		if err := rows.Scan(&notification.ID, &notification.UserID, &notification.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationRepository) SetNotificationRead(
 	ctx context.Context,
+	userId int,
 	id int,
+	isRead bool,
 ) (*domain.Notification, error) {
 	query := `
 		UPDATE notifications
-		SET is_read = NOT is_read
-		WHERE id = $1
-		RETURNING *
-	`
+		SET is_read = $3
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + notificationColumns
 
 	var updatedNotification domain.Notification
 
-	err := r.db.QueryRowContext(
-		ctx,
-		query,
-		id,
-	).Scan(
-		&updatedNotification.ID,
-		&updatedNotification.UserID,
-		&updatedNotification.ListingID,
-		&updatedNotification.Type,
-		&updatedNotification.Message,
-		&updatedNotification.IsRead,
-		&updatedNotification.CreatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, query, id, userId, isRead).
+		Scan(notificationFields(&updatedNotification)...)
 	if err != nil {
 		return nil, err
 	}
 
 	return &updatedNotification, nil
 }
+
+func (r *NotificationRepository) MarkAllNotificationsRead(ctx context.Context, userId int) error {
+	query := `
+		UPDATE notifications
+		SET is_read = TRUE
+		WHERE user_id = $1 AND is_read = FALSE
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userId)
+	return err
+}
+
+func (r *NotificationRepository) DeleteNotification(ctx context.Context, userId int, id int) error {
+	query := `
+		DELETE FROM notifications
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, userId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
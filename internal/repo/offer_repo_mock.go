@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"server/internal/domain"
+)
+
+type OfferRepoMock struct {
+	CreateOfferFunc       func(ctx context.Context, offer *domain.Offer) (*domain.Offer, error)
+	GetOfferByIdFunc      func(ctx context.Context, id int) (*domain.Offer, error)
+	GetOffersByUserIdFunc func(ctx context.Context, userId int) ([]*domain.Offer, error)
+	CounterOfferFunc      func(ctx context.Context, offerId int, latestRevisionId int, revision *domain.OfferRevision) (*domain.Offer, error)
+	AcceptOfferFunc       func(ctx context.Context, offerId int, latestRevisionId int) (*domain.Offer, []*domain.Offer, error)
+	UpdateOfferStatusFunc func(ctx context.Context, offerId int, fromStatus string, toStatus string) (*domain.Offer, error)
+	ExpireOffersFunc      func(ctx context.Context, now time.Time) ([]*domain.Offer, error)
+}
+
+func (o *OfferRepoMock) CreateOffer(ctx context.Context, offer *domain.Offer) (*domain.Offer, error) {
+	return o.CreateOfferFunc(ctx, offer)
+}
+
+func (o *OfferRepoMock) GetOfferById(ctx context.Context, id int) (*domain.Offer, error) {
+	return o.GetOfferByIdFunc(ctx, id)
+}
+
+func (o *OfferRepoMock) GetOffersByUserId(ctx context.Context, userId int) ([]*domain.Offer, error) {
+	return o.GetOffersByUserIdFunc(ctx, userId)
+}
+
+func (o *OfferRepoMock) CounterOffer(
+	ctx context.Context,
+	offerId int,
+	latestRevisionId int,
+	revision *domain.OfferRevision,
+) (*domain.Offer, error) {
+	return o.CounterOfferFunc(ctx, offerId, latestRevisionId, revision)
+}
+
+func (o *OfferRepoMock) AcceptOffer(
+	ctx context.Context,
+	offerId int,
+	latestRevisionId int,
+) (*domain.Offer, []*domain.Offer, error) {
+	return o.AcceptOfferFunc(ctx, offerId, latestRevisionId)
+}
+
+func (o *OfferRepoMock) UpdateOfferStatus(
+	ctx context.Context,
+	offerId int,
+	fromStatus string,
+	toStatus string,
+) (*domain.Offer, error) {
+	return o.UpdateOfferStatusFunc(ctx, offerId, fromStatus, toStatus)
+}
+
+func (o *OfferRepoMock) ExpireOffers(ctx context.Context, now time.Time) ([]*domain.Offer, error) {
+	return o.ExpireOffersFunc(ctx, now)
+}
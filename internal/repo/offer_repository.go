@@ -0,0 +1,535 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"server/internal/domain"
+)
+
+var (
+	ErrOfferChanged         = errors.New("Offer has already been updated")
+	ErrListingUnderContract = errors.New("Another offer on this listing has already been accepted")
+	ErrListingNotActive     = errors.New("Listing is no longer active")
+)
+
+type IOfferRepo interface {
+	CreateOffer(ctx context.Context, offer *domain.Offer) (*domain.Offer, error)
+	GetOfferById(ctx context.Context, id int) (*domain.Offer, error)
+	GetOffersByUserId(ctx context.Context, userId int) ([]*domain.Offer, error)
+	CounterOffer(
+		ctx context.Context,
+		offerId int,
+		latestRevisionId int,
+		revision *domain.OfferRevision,
+	) (*domain.Offer, error)
+	// AcceptOffer accepts the offer, rejects every other open offer on the
+	// listing and marks the listing pending, all or nothing. It returns the
+	// accepted offer and the competing offers it rejected.
+	AcceptOffer(
+		ctx context.Context,
+		offerId int,
+		latestRevisionId int,
+	) (*domain.Offer, []*domain.Offer, error)
+	UpdateOfferStatus(
+		ctx context.Context,
+		offerId int,
+		fromStatus string,
+		toStatus string,
+	) (*domain.Offer, error)
+	ExpireOffers(ctx context.Context, now time.Time) ([]*domain.Offer, error)
+}
+
+type OfferRepository struct {
+	db *sql.DB
+}
+
+func NewOfferRepository(db *sql.DB) *OfferRepository {
+	return &OfferRepository{db: db}
+}
+
+const offerColumns = `
+	id,
+	listing_id,
+	buyer_agent_id,
+	listing_agent_id,
+	status,
+	expires_at,
+	created_at,
+	updated_at
+`
+
+func offerFields(offer *domain.Offer) []any {
+	return []any{
+		&offer.ID,
+		&offer.ListingID,
+		&offer.BuyerAgentID,
+		&offer.ListingAgentID,
+		&offer.Status,
+		&offer.ExpiresAt,
+		&offer.CreatedAt,
+		&offer.UpdatedAt,
+	}
+}
+
+func (r *OfferRepository) queryOffers(
+	ctx context.Context,
+	q queryer,
+	query string,
+	args ...any,
+) ([]*domain.Offer, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var offers []*domain.Offer
+	for rows.Next() {
+		offer := new(domain.Offer)
+
+		if err := rows.Scan(offerFields(offer)...); err != nil {
+			return nil, err
+		}
+
+		offers = append(offers, offer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.attachRevisions(ctx, q, offers); err != nil {
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+// attachRevisions loads the full negotiation history for the given offers in
+// one query, oldest revision first.
+func (r *OfferRepository) attachRevisions(
+	ctx context.Context,
+	q queryer,
+	offers []*domain.Offer,
+) error {
+	if len(offers) == 0 {
+		return nil
+	}
+
+	byId := make(map[int]*domain.Offer, len(offers))
+	offerIds := make([]int, 0, len(offers))
+	for _, offer := range offers {
+		offer.Revisions = []domain.OfferRevision{}
+		byId[offer.ID] = offer
+		offerIds = append(offerIds, offer.ID)
+	}
+
+	query := `
+		SELECT
+			id,
+			offer_id,
+			author_id,
+			amount,
+			earnest_money,
+			contingencies,
+			to_char(closing_date, 'YYYY-MM-DD'),
+			expires_at,
+			message,
+			created_at
+		FROM offer_revisions
+		WHERE offer_id = ANY($1)
+		ORDER BY id
+	`
+
+	rows, err := q.QueryContext(ctx, query, offerIds)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	// database/sql has no array support, so text[] goes through pgtype
+	types := pgtype.NewMap()
+
+	for rows.Next() {
+		var revision domain.OfferRevision
+
+		if err := rows.Scan(
+			&revision.ID,
+			&revision.OfferID,
+			&revision.AuthorID,
+			&revision.Amount,
+			&revision.EarnestMoney,
+			types.SQLScanner(&revision.Contingencies),
+			&revision.ClosingDate,
+			&revision.ExpiresAt,
+			&revision.Message,
+			&revision.CreatedAt,
+		); err != nil {
+			return err
+		}
+
+		if revision.Contingencies == nil {
+			revision.Contingencies = []string{}
+		}
+
+		byId[revision.OfferID].Revisions = append(byId[revision.OfferID].Revisions, revision)
+	}
+
+	return rows.Err()
+}
+
+func (r *OfferRepository) getOfferById(
+	ctx context.Context,
+	q queryer,
+	id int,
+) (*domain.Offer, error) {
+	query := `SELECT ` + offerColumns + ` FROM offers WHERE id = $1`
+
+	offers, err := r.queryOffers(ctx, q, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(offers) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return offers[0], nil
+}
+
+func insertRevision(
+	ctx context.Context,
+	tx *sql.Tx,
+	offerId int,
+	revision *domain.OfferRevision,
+) error {
+	query := `
+		INSERT INTO offer_revisions (
+			offer_id, author_id, amount, earnest_money, contingencies, closing_date, expires_at, message
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := tx.ExecContext(
+		ctx,
+		query,
+		offerId,
+		revision.AuthorID,
+		revision.Amount,
+		revision.EarnestMoney,
+		revision.Contingencies,
+		revision.ClosingDate,
+		revision.ExpiresAt,
+		revision.Message,
+	)
+	return err
+}
+
+// lockOffer locks the offer row for the rest of tx, failing with
+// ErrOfferChanged unless it is still open, unexpired and latestRevisionId
+// still holds its current terms. Pinning the revision means nobody can act on
+// terms that were countered in the meantime.
+func lockOffer(ctx context.Context, tx *sql.Tx, offerId int, latestRevisionId int) (int, error) {
+	query := `
+		SELECT listing_id FROM offers
+		WHERE id = $1
+			AND status IN ($2, $3)
+			AND expires_at > NOW()
+			AND (SELECT MAX(id) FROM offer_revisions WHERE offer_id = $1) = $4
+		FOR UPDATE
+	`
+
+	var listingId int
+
+	err := tx.QueryRowContext(
+		ctx,
+		query,
+		offerId,
+		domain.OfferStatusPending,
+		domain.OfferStatusCountered,
+		latestRevisionId,
+	).Scan(&listingId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrOfferChanged
+		}
+		return 0, err
+	}
+
+	return listingId, nil
+}
+
+// CreateOffer inserts the offer with its first revision.
+func (r *OfferRepository) CreateOffer(
+	ctx context.Context,
+	offer *domain.Offer,
+) (*domain.Offer, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	revision := offer.Revisions[0]
+
+	query := `
+		INSERT INTO offers (listing_id, buyer_agent_id, listing_agent_id, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var offerId int
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		offer.ListingID,
+		offer.BuyerAgentID,
+		offer.ListingAgentID,
+		domain.OfferStatusPending,
+		revision.ExpiresAt,
+	).Scan(&offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertRevision(ctx, tx, offerId, &revision); err != nil {
+		return nil, err
+	}
+
+	newOffer, err := r.getOfferById(ctx, tx, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return newOffer, nil
+}
+
+func (r *OfferRepository) GetOfferById(ctx context.Context, id int) (*domain.Offer, error) {
+	return r.getOfferById(ctx, r.db, id)
+}
+
+// GetOffersByUserId returns every offer the agent is a party to, on either
+// side, most recently updated first.
+func (r *OfferRepository) GetOffersByUserId(
+	ctx context.Context,
+	userId int,
+) ([]*domain.Offer, error) {
+	query := `
+		SELECT ` + offerColumns + `
+		FROM offers
+		WHERE buyer_agent_id = $1 OR listing_agent_id = $1
+		ORDER BY updated_at DESC
+	`
+
+	return r.queryOffers(ctx, r.db, query, userId)
+}
+
+// CounterOffer records new terms and hands the turn to the other party.
+func (r *OfferRepository) CounterOffer(
+	ctx context.Context,
+	offerId int,
+	latestRevisionId int,
+	revision *domain.OfferRevision,
+) (*domain.Offer, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := lockOffer(ctx, tx, offerId, latestRevisionId); err != nil {
+		return nil, err
+	}
+
+	if err := insertRevision(ctx, tx, offerId, revision); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE offers
+		SET status = $1, expires_at = $2, updated_at = NOW()
+		WHERE id = $3
+	`
+
+	_, err = tx.ExecContext(ctx, query, domain.OfferStatusCountered, revision.ExpiresAt, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	offer, err := r.getOfferById(ctx, tx, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+// AcceptOffer marks the offer accepted. The listing row is locked so two
+// offers on the same listing can never both be accepted. The listing's
+// status change is recorded in the outbox in the same transaction, which is
+// how favoriters hear of it and cached copies are dropped.
+func (r *OfferRepository) AcceptOffer(
+	ctx context.Context,
+	offerId int,
+	latestRevisionId int,
+) (*domain.Offer, []*domain.Offer, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer tx.Rollback()
+
+	listingId, err := lockOffer(ctx, tx, offerId, latestRevisionId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var listingStatus string
+
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT status FROM listings WHERE id = $1 FOR UPDATE`,
+		listingId,
+	).Scan(&listingStatus)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var accepted bool
+
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM offers WHERE listing_id = $1 AND status = $2)`,
+		listingId,
+		domain.OfferStatusAccepted,
+	).Scan(&accepted)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if accepted {
+		return nil, nil, ErrListingUnderContract
+	}
+
+	if listingStatus != domain.ListingStatusActive {
+		return nil, nil, ErrListingNotActive
+	}
+
+	query := `UPDATE offers SET status = $1, updated_at = NOW() WHERE id = $2`
+
+	if _, err := tx.ExecContext(ctx, query, domain.OfferStatusAccepted, offerId); err != nil {
+		return nil, nil, err
+	}
+
+	query = `
+		UPDATE offers
+		SET status = $1, updated_at = NOW()
+		WHERE listing_id = $2 AND id <> $3 AND status IN ($4, $5)
+		RETURNING ` + offerColumns
+
+	rejected, err := r.queryOffers(
+		ctx,
+		tx,
+		query,
+		domain.OfferStatusRejected,
+		listingId,
+		offerId,
+		domain.OfferStatusPending,
+		domain.OfferStatusCountered,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query = `
+		UPDATE listings
+		SET status = $1, sold_at = NULL, updated_at = NOW()
+		WHERE id = $2
+		RETURNING ` + listingColumns
+
+	var listing domain.Listing
+
+	err = tx.QueryRowContext(ctx, query, domain.ListingStatusPending, listingId).
+		Scan(listingFields(&listing)...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := recordListingChanges(ctx, tx, &listing, listing.Price, listingStatus); err != nil {
+		return nil, nil, err
+	}
+
+	offer, err := r.getOfferById(ctx, tx, offerId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return offer, rejected, nil
+}
+
+func (r *OfferRepository) UpdateOfferStatus(
+	ctx context.Context,
+	offerId int,
+	fromStatus string,
+	toStatus string,
+) (*domain.Offer, error) {
+	query := `
+		UPDATE offers
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+		RETURNING ` + offerColumns
+
+	offers, err := r.queryOffers(ctx, r.db, query, toStatus, offerId, fromStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(offers) == 0 {
+		return nil, ErrOfferChanged
+	}
+
+	return offers[0], nil
+}
+
+// ExpireOffers lapses every open offer whose latest terms expired at or
+// before now and returns them.
+func (r *OfferRepository) ExpireOffers(ctx context.Context, now time.Time) ([]*domain.Offer, error) {
+	query := `
+		UPDATE offers
+		SET status = $1, updated_at = NOW()
+		WHERE status IN ($2, $3) AND expires_at <= $4
+		RETURNING ` + offerColumns
+
+	return r.queryOffers(
+		ctx,
+		r.db,
+		query,
+		domain.OfferStatusExpired,
+		domain.OfferStatusPending,
+		domain.OfferStatusCountered,
+		now,
+	)
+}
@@ -0,0 +1,64 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"server/internal/domain"
+)
+
+type OpenHouseRepoMock struct {
+	CreateOpenHouseFunc                    func(ctx context.Context, openHouse *domain.OpenHouse) (*domain.OpenHouse, error)
+	CancelOpenHouseFunc                    func(ctx context.Context, listingId int, openHouseId int) (*domain.OpenHouse, error)
+	GetOpenHousesByListingIdFunc           func(ctx context.Context, listingId int, from time.Time) ([]*domain.OpenHouse, error)
+	GetOpenHousesByAgentIdFunc             func(ctx context.Context, agentId int, from time.Time) ([]*domain.OpenHouse, error)
+	GetUpcomingOpenHousesByListingIdsFunc  func(ctx context.Context, listingIds []int, from time.Time) ([]*domain.OpenHouse, error)
+	GetListingIdsWithOpenHousesBetweenFunc func(ctx context.Context, from time.Time, to time.Time) (map[int]bool, error)
+}
+
+func (o *OpenHouseRepoMock) CreateOpenHouse(
+	ctx context.Context,
+	openHouse *domain.OpenHouse,
+) (*domain.OpenHouse, error) {
+	return o.CreateOpenHouseFunc(ctx, openHouse)
+}
+
+func (o *OpenHouseRepoMock) CancelOpenHouse(
+	ctx context.Context,
+	listingId int,
+	openHouseId int,
+) (*domain.OpenHouse, error) {
+	return o.CancelOpenHouseFunc(ctx, listingId, openHouseId)
+}
+
+func (o *OpenHouseRepoMock) GetOpenHousesByListingId(
+	ctx context.Context,
+	listingId int,
+	from time.Time,
+) ([]*domain.OpenHouse, error) {
+	return o.GetOpenHousesByListingIdFunc(ctx, listingId, from)
+}
+
+func (o *OpenHouseRepoMock) GetOpenHousesByAgentId(
+	ctx context.Context,
+	agentId int,
+	from time.Time,
+) ([]*domain.OpenHouse, error) {
+	return o.GetOpenHousesByAgentIdFunc(ctx, agentId, from)
+}
+
+func (o *OpenHouseRepoMock) GetUpcomingOpenHousesByListingIds(
+	ctx context.Context,
+	listingIds []int,
+	from time.Time,
+) ([]*domain.OpenHouse, error) {
+	return o.GetUpcomingOpenHousesByListingIdsFunc(ctx, listingIds, from)
+}
+
+func (o *OpenHouseRepoMock) GetListingIdsWithOpenHousesBetween(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+) (map[int]bool, error) {
+	return o.GetListingIdsWithOpenHousesBetweenFunc(ctx, from, to)
+}
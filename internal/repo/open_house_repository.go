@@ -0,0 +1,269 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"server/internal/domain"
+)
+
+type IOpenHouseRepo interface {
+	CreateOpenHouse(ctx context.Context, openHouse *domain.OpenHouse) (*domain.OpenHouse, error)
+	CancelOpenHouse(ctx context.Context, listingId int, openHouseId int) (*domain.OpenHouse, error)
+	GetOpenHousesByListingId(
+		ctx context.Context,
+		listingId int,
+		from time.Time,
+	) ([]*domain.OpenHouse, error)
+	GetOpenHousesByAgentId(
+		ctx context.Context,
+		agentId int,
+		from time.Time,
+	) ([]*domain.OpenHouse, error)
+	GetUpcomingOpenHousesByListingIds(
+		ctx context.Context,
+		listingIds []int,
+		from time.Time,
+	) ([]*domain.OpenHouse, error)
+	GetListingIdsWithOpenHousesBetween(
+		ctx context.Context,
+		from time.Time,
+		to time.Time,
+	) (map[int]bool, error)
+}
+
+type OpenHouseRepository struct {
+	db *sql.DB
+}
+
+func NewOpenHouseRepository(db *sql.DB) *OpenHouseRepository {
+	return &OpenHouseRepository{db: db}
+}
+
+func scanOpenHouses(rows *sql.Rows) ([]*domain.OpenHouse, error) {
+	defer rows.Close()
+
+	var openHouses []*domain.OpenHouse
+	for rows.Next() {
+		openHouse := new(domain.OpenHouse)
+
+		if err := rows.Scan(
+			&openHouse.ID,
+			&openHouse.ListingID,
+			&openHouse.StartTime,
+			&openHouse.EndTime,
+			&openHouse.Notes,
+			&openHouse.CancelledAt,
+			&openHouse.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		openHouses = append(openHouses, openHouse)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return openHouses, nil
+}
+
+func (r *OpenHouseRepository) CreateOpenHouse(
+	ctx context.Context,
+	openHouse *domain.OpenHouse,
+) (*domain.OpenHouse, error) {
+	query := `
+		INSERT INTO open_houses (listing_id, start_time, end_time, notes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	newOpenHouse := *openHouse
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		openHouse.ListingID,
+		openHouse.StartTime,
+		openHouse.EndTime,
+		openHouse.Notes,
+	).Scan(&newOpenHouse.ID, &newOpenHouse.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &newOpenHouse, nil
+}
+
+func (r *OpenHouseRepository) CancelOpenHouse(
+	ctx context.Context,
+	listingId int,
+	openHouseId int,
+) (*domain.OpenHouse, error) {
+	query := `
+		UPDATE open_houses
+		SET cancelled_at = NOW()
+		WHERE id = $1 AND listing_id = $2 AND cancelled_at IS NULL
+		RETURNING id, listing_id, start_time, end_time, notes, cancelled_at, created_at
+	`
+
+	var openHouse domain.OpenHouse
+
+	err := r.db.QueryRowContext(ctx, query, openHouseId, listingId).Scan(
+		&openHouse.ID,
+		&openHouse.ListingID,
+		&openHouse.StartTime,
+		&openHouse.EndTime,
+		&openHouse.Notes,
+		&openHouse.CancelledAt,
+		&openHouse.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("Open house not found or already cancelled")
+		}
+		return nil, err
+	}
+
+	return &openHouse, nil
+}
+
+// GetOpenHousesByListingId includes cancelled open houses so calendar feeds
+// can tell subscribers to remove them.
+func (r *OpenHouseRepository) GetOpenHousesByListingId(
+	ctx context.Context,
+	listingId int,
+	from time.Time,
+) ([]*domain.OpenHouse, error) {
+	query := `
+		SELECT id, listing_id, start_time, end_time, notes, cancelled_at, created_at
+		FROM open_houses
+		WHERE listing_id = $1 AND end_time >= $2
+		ORDER BY start_time
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, listingId, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanOpenHouses(rows)
+}
+
+func (r *OpenHouseRepository) GetOpenHousesByAgentId(
+	ctx context.Context,
+	agentId int,
+	from time.Time,
+) ([]*domain.OpenHouse, error) {
+	query := `
+		SELECT
+			open_houses.id,
+			open_houses.listing_id,
+			open_houses.start_time,
+			open_houses.end_time,
+			open_houses.notes,
+			open_houses.cancelled_at,
+			open_houses.created_at,
+			listings.address
+		FROM open_houses
+		INNER JOIN listings
+			ON open_houses.listing_id = listings.id
+		WHERE listings.agent_id = $1 AND open_houses.end_time >= $2
+		ORDER BY open_houses.start_time
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, agentId, from)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var openHouses []*domain.OpenHouse
+	for rows.Next() {
+		openHouse := new(domain.OpenHouse)
+		openHouse.Listing = &domain.Listing{AgentID: agentId}
+
+		if err := rows.Scan(
+			&openHouse.ID,
+			&openHouse.ListingID,
+			&openHouse.StartTime,
+			&openHouse.EndTime,
+			&openHouse.Notes,
+			&openHouse.CancelledAt,
+			&openHouse.CreatedAt,
+			&openHouse.Listing.Address,
+		); err != nil {
+			return nil, err
+		}
+
+		openHouse.Listing.ID = openHouse.ListingID
+		openHouses = append(openHouses, openHouse)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return openHouses, nil
+}
+
+func (r *OpenHouseRepository) GetUpcomingOpenHousesByListingIds(
+	ctx context.Context,
+	listingIds []int,
+	from time.Time,
+) ([]*domain.OpenHouse, error) {
+	query := `
+		SELECT id, listing_id, start_time, end_time, notes, cancelled_at, created_at
+		FROM open_houses
+		WHERE listing_id = ANY($1) AND end_time >= $2 AND cancelled_at IS NULL
+		ORDER BY start_time
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, listingIds, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanOpenHouses(rows)
+}
+
+func (r *OpenHouseRepository) GetListingIdsWithOpenHousesBetween(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+) (map[int]bool, error) {
+	query := `
+		SELECT DISTINCT listing_id
+		FROM open_houses
+		WHERE cancelled_at IS NULL AND end_time > $1 AND start_time < $2
+	`
+
+	listingIds := make(map[int]bool)
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var listingId int
+
+		if err := rows.Scan(&listingId); err != nil {
+			return nil, err
+		}
+
+		listingIds[listingId] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return listingIds, nil
+}
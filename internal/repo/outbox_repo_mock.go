@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"server/internal/domain"
+)
+
+type OutboxRepoMock struct {
+	ClaimDueEventsFunc func(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxEvent, error)
+	RecordAttemptFunc  func(ctx context.Context, event *domain.OutboxEvent) error
+}
+
+func (o *OutboxRepoMock) ClaimDueEvents(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]*domain.OutboxEvent, error) {
+	return o.ClaimDueEventsFunc(ctx, limit, lease)
+}
+
+func (o *OutboxRepoMock) RecordAttempt(ctx context.Context, event *domain.OutboxEvent) error {
+	return o.RecordAttemptFunc(ctx, event)
+}
@@ -0,0 +1,176 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"server/internal/domain"
+)
+
+type IOutboxRepo interface {
+	// ClaimDueEvents leases up to limit pending events whose next attempt is
+	// due, oldest first, by pushing that attempt back by lease so concurrent
+	// relays never pick up the same event.
+	ClaimDueEvents(
+		ctx context.Context,
+		limit int,
+		lease time.Duration,
+	) ([]*domain.OutboxEvent, error)
+	// RecordAttempt saves the status, attempt count, next attempt, last error
+	// and the consumers delivered to of an event after it was relayed.
+	RecordAttempt(ctx context.Context, event *domain.OutboxEvent) error
+}
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+const outboxEventColumns = `
+	id,
+	event_type,
+	dedup_key,
+	payload,
+	delivered_to,
+	status,
+	attempts,
+	next_attempt_at,
+	last_error,
+	published_at,
+	created_at
+`
+
+// outboxEventFields scans delivered_to through pgtype, since database/sql has
+// no array support.
+func outboxEventFields(event *domain.OutboxEvent) []any {
+	types := pgtype.NewMap()
+
+	return []any{
+		&event.ID,
+		&event.EventType,
+		&event.DedupKey,
+		&event.Payload,
+		types.SQLScanner(&event.DeliveredTo),
+		&event.Status,
+		&event.Attempts,
+		&event.NextAttemptAt,
+		&event.LastError,
+		&event.PublishedAt,
+		&event.CreatedAt,
+	}
+}
+
+// insertOutboxEvent records an event in the caller's transaction, so it is
+// relayed only if the change that raised it commits. Writing the same
+// dedupKey twice keeps the first event.
+func insertOutboxEvent(
+	ctx context.Context,
+	tx *sql.Tx,
+	eventType string,
+	dedupKey string,
+	data any,
+) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO outbox_events (event_type, dedup_key, payload)
+		VALUES ($1, $2, $3::jsonb)
+		ON CONFLICT (dedup_key) DO NOTHING
+	`
+
+	if _, err := tx.ExecContext(ctx, query, eventType, dedupKey, string(payload)); err != nil {
+		return fmt.Errorf("Record %s event: %w", eventType, err)
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) ClaimDueEvents(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]*domain.OutboxEvent, error) {
+	query := `
+		UPDATE outbox_events
+		SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id
+			FROM outbox_events
+			WHERE status = $3 AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxEventColumns
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds(), domain.OutboxEventPending)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var events []*domain.OutboxEvent
+	for rows.Next() {
+		event := new(domain.OutboxEvent)
+		if err := rows.Scan(outboxEventFields(event)...); err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// UPDATE ... RETURNING does not keep the subquery's order
+	slices.SortFunc(events, func(a, b *domain.OutboxEvent) int {
+		return a.ID - b.ID
+	})
+
+	return events, nil
+}
+
+func (r *OutboxRepository) RecordAttempt(ctx context.Context, event *domain.OutboxEvent) error {
+	query := `
+		UPDATE outbox_events
+		SET status = $2,
+			attempts = $3,
+			next_attempt_at = $4,
+			last_error = $5,
+			published_at = $6,
+			delivered_to = $7
+		WHERE id = $1
+	`
+
+	deliveredTo := event.DeliveredTo
+	if deliveredTo == nil {
+		deliveredTo = []string{}
+	}
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		event.ID,
+		event.Status,
+		event.Attempts,
+		event.NextAttemptAt,
+		event.LastError,
+		event.PublishedAt,
+		deliveredTo,
+	)
+	return err
+}
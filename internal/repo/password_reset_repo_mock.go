@@ -0,0 +1,28 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+type PasswordResetRepoMock struct {
+	CreateTokenFunc   func(ctx context.Context, userId int, tokenHash string, expiresAt time.Time) error
+	ResetPasswordFunc func(ctx context.Context, tokenHash string, passwordHash string) error
+}
+
+func (p *PasswordResetRepoMock) CreateToken(
+	ctx context.Context,
+	userId int,
+	tokenHash string,
+	expiresAt time.Time,
+) error {
+	return p.CreateTokenFunc(ctx, userId, tokenHash, expiresAt)
+}
+
+func (p *PasswordResetRepoMock) ResetPassword(
+	ctx context.Context,
+	tokenHash string,
+	passwordHash string,
+) error {
+	return p.ResetPasswordFunc(ctx, tokenHash, passwordHash)
+}
@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrResetTokenInvalid = errors.New("Reset link is invalid or has expired")
+
+type IPasswordResetRepo interface {
+	CreateToken(ctx context.Context, userId int, tokenHash string, expiresAt time.Time) error
+	// ResetPassword sets the password of the user the token was issued to and
+	// uses up every outstanding token of theirs. It fails with
+	// ErrResetTokenInvalid if the token is unknown, used or expired.
+	ResetPassword(ctx context.Context, tokenHash string, passwordHash string) error
+}
+
+type PasswordResetRepository struct {
+	db *sql.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+func (r *PasswordResetRepository) CreateToken(
+	ctx context.Context,
+	userId int,
+	tokenHash string,
+	expiresAt time.Time,
+) error {
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userId, tokenHash, expiresAt)
+	return err
+}
+
+func (r *PasswordResetRepository) ResetPassword(
+	ctx context.Context,
+	tokenHash string,
+	passwordHash string,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var userId int
+
+	err = tx.QueryRowContext(
+		ctx,
+		`
+			SELECT user_id FROM password_reset_tokens
+			WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+			FOR UPDATE
+		`,
+		tokenHash,
+	).Scan(&userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrResetTokenInvalid
+		}
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`,
+		passwordHash,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
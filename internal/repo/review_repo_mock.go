@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"context"
+
+	"server/internal/domain"
+)
+
+type ReviewRepoMock struct {
+	HasInteractedWithAgentFunc func(ctx context.Context, userId int, agentId int) (bool, error)
+	CreateReviewFunc           func(ctx context.Context, review *domain.Review) (*domain.Review, error)
+	GetReviewByIdFunc          func(ctx context.Context, id int) (*domain.Review, error)
+	GetReviewsByAgentIdFunc    func(ctx context.Context, agentId int, includeHidden bool) ([]*domain.Review, error)
+	ReplyToReviewFunc          func(ctx context.Context, id int, reply string) (*domain.Review, error)
+	UpdateReviewStatusFunc     func(ctx context.Context, id int, status string) (*domain.Review, error)
+}
+
+func (r *ReviewRepoMock) HasInteractedWithAgent(
+	ctx context.Context,
+	userId int,
+	agentId int,
+) (bool, error) {
+	return r.HasInteractedWithAgentFunc(ctx, userId, agentId)
+}
+
+func (r *ReviewRepoMock) CreateReview(
+	ctx context.Context,
+	review *domain.Review,
+) (*domain.Review, error) {
+	return r.CreateReviewFunc(ctx, review)
+}
+
+func (r *ReviewRepoMock) GetReviewById(ctx context.Context, id int) (*domain.Review, error) {
+	return r.GetReviewByIdFunc(ctx, id)
+}
+
+func (r *ReviewRepoMock) GetReviewsByAgentId(
+	ctx context.Context,
+	agentId int,
+	includeHidden bool,
+) ([]*domain.Review, error) {
+	return r.GetReviewsByAgentIdFunc(ctx, agentId, includeHidden)
+}
+
+func (r *ReviewRepoMock) ReplyToReview(
+	ctx context.Context,
+	id int,
+	reply string,
+) (*domain.Review, error) {
+	return r.ReplyToReviewFunc(ctx, id, reply)
+}
+
+func (r *ReviewRepoMock) UpdateReviewStatus(
+	ctx context.Context,
+	id int,
+	status string,
+) (*domain.Review, error) {
+	return r.UpdateReviewStatusFunc(ctx, id, status)
+}
@@ -0,0 +1,225 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"server/internal/domain"
+)
+
+var ErrReviewExists = errors.New("You have already reviewed this agent")
+
+type IReviewRepo interface {
+	HasInteractedWithAgent(ctx context.Context, userId int, agentId int) (bool, error)
+	CreateReview(ctx context.Context, review *domain.Review) (*domain.Review, error)
+	GetReviewById(ctx context.Context, id int) (*domain.Review, error)
+	GetReviewsByAgentId(
+		ctx context.Context,
+		agentId int,
+		includeHidden bool,
+	) ([]*domain.Review, error)
+	ReplyToReview(ctx context.Context, id int, reply string) (*domain.Review, error)
+	UpdateReviewStatus(ctx context.Context, id int, status string) (*domain.Review, error)
+}
+
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Reviewers are shown by first name and last initial only
+const reviewColumns = `
+	agent_reviews.id,
+	agent_reviews.agent_id,
+	agent_reviews.reviewer_id,
+	users.first_name || ' ' || LEFT(users.last_name, 1) || '.',
+	agent_reviews.rating,
+	agent_reviews.body,
+	agent_reviews.status,
+	agent_reviews.reply,
+	agent_reviews.replied_at,
+	agent_reviews.created_at,
+	agent_reviews.updated_at
+`
+
+func reviewFields(review *domain.Review) []any {
+	return []any{
+		&review.ID,
+		&review.AgentID,
+		&review.ReviewerID,
+		&review.Reviewer,
+		&review.Rating,
+		&review.Body,
+		&review.Status,
+		&review.Reply,
+		&review.RepliedAt,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	}
+}
+
+// HasInteractedWithAgent reports whether the user has had a showing,
+// conversation or inquiry with the agent.
+func (r *ReviewRepository) HasInteractedWithAgent(
+	ctx context.Context,
+	userId int,
+	agentId int,
+) (bool, error) {
+	query := `
+		SELECT
+			EXISTS (SELECT 1 FROM showings WHERE buyer_id = $1 AND agent_id = $2)
+			OR EXISTS (SELECT 1 FROM conversations WHERE buyer_id = $1 AND agent_id = $2)
+			OR EXISTS (SELECT 1 FROM leads WHERE user_id = $1 AND agent_id = $2)
+	`
+
+	var interacted bool
+
+	if err := r.db.QueryRowContext(ctx, query, userId, agentId).Scan(&interacted); err != nil {
+		return false, err
+	}
+
+	return interacted, nil
+}
+
+// CreateReview returns ErrReviewExists if the user has already reviewed the
+// agent.
+func (r *ReviewRepository) CreateReview(
+	ctx context.Context,
+	review *domain.Review,
+) (*domain.Review, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO agent_reviews (agent_id, reviewer_id, rating, body)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (agent_id, reviewer_id) DO NOTHING
+			RETURNING *
+		)
+		SELECT ` + reviewColumns + `
+		FROM inserted agent_reviews
+		INNER JOIN users ON users.id = agent_reviews.reviewer_id
+	`
+
+	var newReview domain.Review
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		review.AgentID,
+		review.ReviewerID,
+		review.Rating,
+		review.Body,
+	).Scan(reviewFields(&newReview)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReviewExists
+		}
+		return nil, err
+	}
+
+	return &newReview, nil
+}
+
+func (r *ReviewRepository) GetReviewById(ctx context.Context, id int) (*domain.Review, error) {
+	query := `
+		SELECT ` + reviewColumns + `
+		FROM agent_reviews
+		INNER JOIN users ON users.id = agent_reviews.reviewer_id
+		WHERE agent_reviews.id = $1
+	`
+
+	var review domain.Review
+
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(reviewFields(&review)...); err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// GetReviewsByAgentId lists the agent's reviews, newest first. Hidden reviews
+// are only included for moderators.
+func (r *ReviewRepository) GetReviewsByAgentId(
+	ctx context.Context,
+	agentId int,
+	includeHidden bool,
+) ([]*domain.Review, error) {
+	query := `
+		SELECT ` + reviewColumns + `
+		FROM agent_reviews
+		INNER JOIN users ON users.id = agent_reviews.reviewer_id
+		WHERE agent_reviews.agent_id = $1 AND ($2 OR agent_reviews.status = $3)
+		ORDER BY agent_reviews.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, agentId, includeHidden, domain.ReviewStatusPublished)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var reviews []*domain.Review
+	for rows.Next() {
+		review := new(domain.Review)
+
+		if err := rows.Scan(reviewFields(review)...); err != nil {
+			return nil, err
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (r *ReviewRepository) updateReview(
+	ctx context.Context,
+	set string,
+	id int,
+	value any,
+) (*domain.Review, error) {
+	query := `
+		WITH updated AS (
+			UPDATE agent_reviews
+			SET ` + set + `, updated_at = NOW()
+			WHERE id = $1
+			RETURNING *
+		)
+		SELECT ` + reviewColumns + `
+		FROM updated agent_reviews
+		INNER JOIN users ON users.id = agent_reviews.reviewer_id
+	`
+
+	var review domain.Review
+
+	if err := r.db.QueryRowContext(ctx, query, id, value).Scan(reviewFields(&review)...); err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// ReplyToReview sets the agent's public reply, replacing any earlier one.
+func (r *ReviewRepository) ReplyToReview(
+	ctx context.Context,
+	id int,
+	reply string,
+) (*domain.Review, error) {
+	return r.updateReview(ctx, `reply = $2, replied_at = NOW()`, id, reply)
+}
+
+func (r *ReviewRepository) UpdateReviewStatus(
+	ctx context.Context,
+	id int,
+	status string,
+) (*domain.Review, error) {
+	return r.updateReview(ctx, `status = $2`, id, status)
+}
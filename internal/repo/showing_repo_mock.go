@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"server/internal/domain"
+)
+
+type ShowingRepoMock struct {
+	CreateShowingFunc                 func(ctx context.Context, showing *domain.Showing) (*domain.Showing, error)
+	GetShowingByIdFunc                func(ctx context.Context, id int) (*domain.Showing, error)
+	GetShowingsByUserIdFunc           func(ctx context.Context, userId int) ([]*domain.Showing, error)
+	AddShowingSlotFunc                func(ctx context.Context, showingId int, fromStatus string, toStatus string, slot *domain.ShowingSlot) (*domain.Showing, error)
+	BookShowingFunc                   func(ctx context.Context, showing *domain.Showing) (*domain.Showing, error)
+	ConfirmShowingFunc                func(ctx context.Context, showingId int, fromStatus string, slotId int) (*domain.Showing, error)
+	UpdateShowingStatusFunc           func(ctx context.Context, showingId int, fromStatus string, toStatus string) (*domain.Showing, error)
+	GetConfirmedShowingsByAgentIdFunc func(ctx context.Context, agentId int, from time.Time, to time.Time) ([]*domain.Showing, error)
+}
+
+func (s *ShowingRepoMock) CreateShowing(
+	ctx context.Context,
+	showing *domain.Showing,
+) (*domain.Showing, error) {
+	return s.CreateShowingFunc(ctx, showing)
+}
+
+func (s *ShowingRepoMock) GetShowingById(ctx context.Context, id int) (*domain.Showing, error) {
+	return s.GetShowingByIdFunc(ctx, id)
+}
+
+func (s *ShowingRepoMock) GetShowingsByUserId(
+	ctx context.Context,
+	userId int,
+) ([]*domain.Showing, error) {
+	return s.GetShowingsByUserIdFunc(ctx, userId)
+}
+
+func (s *ShowingRepoMock) AddShowingSlot(
+	ctx context.Context,
+	showingId int,
+	fromStatus string,
+	toStatus string,
+	slot *domain.ShowingSlot,
+) (*domain.Showing, error) {
+	return s.AddShowingSlotFunc(ctx, showingId, fromStatus, toStatus, slot)
+}
+
+func (s *ShowingRepoMock) BookShowing(
+	ctx context.Context,
+	showing *domain.Showing,
+) (*domain.Showing, error) {
+	return s.BookShowingFunc(ctx, showing)
+}
+
+func (s *ShowingRepoMock) ConfirmShowing(
+	ctx context.Context,
+	showingId int,
+	fromStatus string,
+	slotId int,
+) (*domain.Showing, error) {
+	return s.ConfirmShowingFunc(ctx, showingId, fromStatus, slotId)
+}
+
+func (s *ShowingRepoMock) UpdateShowingStatus(
+	ctx context.Context,
+	showingId int,
+	fromStatus string,
+	toStatus string,
+) (*domain.Showing, error) {
+	return s.UpdateShowingStatusFunc(ctx, showingId, fromStatus, toStatus)
+}
+
+func (s *ShowingRepoMock) GetConfirmedShowingsByAgentId(
+	ctx context.Context,
+	agentId int,
+	from time.Time,
+	to time.Time,
+) ([]*domain.Showing, error) {
+	return s.GetConfirmedShowingsByAgentIdFunc(ctx, agentId, from, to)
+}
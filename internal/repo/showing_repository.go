@@ -0,0 +1,548 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"server/internal/domain"
+)
+
+var (
+	ErrShowingConflict = errors.New("Agent already has a confirmed showing at that time")
+	ErrShowingChanged  = errors.New("Showing has already been updated")
+)
+
+type IShowingRepo interface {
+	CreateShowing(ctx context.Context, showing *domain.Showing) (*domain.Showing, error)
+	GetShowingById(ctx context.Context, id int) (*domain.Showing, error)
+	GetShowingsByUserId(ctx context.Context, userId int) ([]*domain.Showing, error)
+	AddShowingSlot(
+		ctx context.Context,
+		showingId int,
+		fromStatus string,
+		toStatus string,
+		slot *domain.ShowingSlot,
+	) (*domain.Showing, error)
+	BookShowing(ctx context.Context, showing *domain.Showing) (*domain.Showing, error)
+	ConfirmShowing(
+		ctx context.Context,
+		showingId int,
+		fromStatus string,
+		slotId int,
+	) (*domain.Showing, error)
+	UpdateShowingStatus(
+		ctx context.Context,
+		showingId int,
+		fromStatus string,
+		toStatus string,
+	) (*domain.Showing, error)
+	GetConfirmedShowingsByAgentId(
+		ctx context.Context,
+		agentId int,
+		from time.Time,
+		to time.Time,
+	) ([]*domain.Showing, error)
+}
+
+type ShowingRepository struct {
+	db *sql.DB
+}
+
+func NewShowingRepository(db *sql.DB) *ShowingRepository {
+	return &ShowingRepository{db: db}
+}
+
+const showingColumns = `
+	id,
+	listing_id,
+	buyer_id,
+	agent_id,
+	status,
+	message,
+	scheduled_start,
+	scheduled_end,
+	created_at,
+	updated_at
+`
+
+func showingFields(showing *domain.Showing) []any {
+	return []any{
+		&showing.ID,
+		&showing.ListingID,
+		&showing.BuyerID,
+		&showing.AgentID,
+		&showing.Status,
+		&showing.Message,
+		&showing.ScheduledStart,
+		&showing.ScheduledEnd,
+		&showing.CreatedAt,
+		&showing.UpdatedAt,
+	}
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (r *ShowingRepository) queryShowings(
+	ctx context.Context,
+	q queryer,
+	query string,
+	args ...any,
+) ([]*domain.Showing, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var showings []*domain.Showing
+	for rows.Next() {
+		showing := new(domain.Showing)
+
+		if err := rows.Scan(showingFields(showing)...); err != nil {
+			return nil, err
+		}
+
+		showings = append(showings, showing)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.attachSlots(ctx, q, showings); err != nil {
+		return nil, err
+	}
+
+	return showings, nil
+}
+
+// attachSlots loads every slot for the given showings in one query.
+func (r *ShowingRepository) attachSlots(
+	ctx context.Context,
+	q queryer,
+	showings []*domain.Showing,
+) error {
+	if len(showings) == 0 {
+		return nil
+	}
+
+	byId := make(map[int]*domain.Showing, len(showings))
+	showingIds := make([]int, 0, len(showings))
+	for _, showing := range showings {
+		showing.Slots = []domain.ShowingSlot{}
+		byId[showing.ID] = showing
+		showingIds = append(showingIds, showing.ID)
+	}
+
+	query := `
+		SELECT id, showing_id, start_time, end_time, proposed_by, created_at
+		FROM showing_slots
+		WHERE showing_id = ANY($1)
+		ORDER BY start_time, id
+	`
+
+	rows, err := q.QueryContext(ctx, query, showingIds)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var slot domain.ShowingSlot
+
+		if err := rows.Scan(
+			&slot.ID,
+			&slot.ShowingID,
+			&slot.StartTime,
+			&slot.EndTime,
+			&slot.ProposedBy,
+			&slot.CreatedAt,
+		); err != nil {
+			return err
+		}
+
+		byId[slot.ShowingID].Slots = append(byId[slot.ShowingID].Slots, slot)
+	}
+
+	return rows.Err()
+}
+
+func (r *ShowingRepository) getShowingById(
+	ctx context.Context,
+	q queryer,
+	id int,
+) (*domain.Showing, error) {
+	query := `SELECT ` + showingColumns + ` FROM showings WHERE id = $1`
+
+	showings, err := r.queryShowings(ctx, q, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(showings) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return showings[0], nil
+}
+
+func insertSlot(ctx context.Context, tx *sql.Tx, showingId int, slot *domain.ShowingSlot) error {
+	query := `
+		INSERT INTO showing_slots (showing_id, start_time, end_time, proposed_by)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := tx.ExecContext(ctx, query, showingId, slot.StartTime, slot.EndTime, slot.ProposedBy)
+	return err
+}
+
+func (r *ShowingRepository) CreateShowing(
+	ctx context.Context,
+	showing *domain.Showing,
+) (*domain.Showing, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO showings (listing_id, buyer_id, agent_id, status, message)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var showingId int
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		showing.ListingID,
+		showing.BuyerID,
+		showing.AgentID,
+		domain.ShowingStatusRequested,
+		showing.Message,
+	).Scan(&showingId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range showing.Slots {
+		if err := insertSlot(ctx, tx, showingId, &showing.Slots[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	newShowing, err := r.getShowingById(ctx, tx, showingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return newShowing, nil
+}
+
+// BookShowing inserts an already confirmed showing for its single slot,
+// skipping the request and approval round trip.
+func (r *ShowingRepository) BookShowing(
+	ctx context.Context,
+	showing *domain.Showing,
+) (*domain.Showing, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	slot := showing.Slots[0]
+
+	err = lockAgentSchedule(ctx, tx, showing.AgentID, 0, slot.StartTime, slot.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO showings (
+			listing_id, buyer_id, agent_id, status, message, scheduled_start, scheduled_end
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var showingId int
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		showing.ListingID,
+		showing.BuyerID,
+		showing.AgentID,
+		domain.ShowingStatusConfirmed,
+		showing.Message,
+		slot.StartTime,
+		slot.EndTime,
+	).Scan(&showingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertSlot(ctx, tx, showingId, &slot); err != nil {
+		return nil, err
+	}
+
+	newShowing, err := r.getShowingById(ctx, tx, showingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return newShowing, nil
+}
+
+func (r *ShowingRepository) GetShowingById(ctx context.Context, id int) (*domain.Showing, error) {
+	return r.getShowingById(ctx, r.db, id)
+}
+
+func (r *ShowingRepository) GetShowingsByUserId(
+	ctx context.Context,
+	userId int,
+) ([]*domain.Showing, error) {
+	query := `
+		SELECT ` + showingColumns + `
+		FROM showings
+		WHERE buyer_id = $1 OR agent_id = $1
+		ORDER BY created_at DESC
+	`
+
+	return r.queryShowings(ctx, r.db, query, userId)
+}
+
+// lockShowing locks the showing row for the rest of tx, failing with
+// ErrShowingChanged if it is no longer in fromStatus.
+func lockShowing(ctx context.Context, tx *sql.Tx, showingId int, fromStatus string) (int, error) {
+	query := `
+		SELECT agent_id FROM showings
+		WHERE id = $1 AND status = $2
+		FOR UPDATE
+	`
+
+	var agentId int
+
+	if err := tx.QueryRowContext(ctx, query, showingId, fromStatus).Scan(&agentId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrShowingChanged
+		}
+		return 0, err
+	}
+
+	return agentId, nil
+}
+
+func (r *ShowingRepository) AddShowingSlot(
+	ctx context.Context,
+	showingId int,
+	fromStatus string,
+	toStatus string,
+	slot *domain.ShowingSlot,
+) (*domain.Showing, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := lockShowing(ctx, tx, showingId, fromStatus); err != nil {
+		return nil, err
+	}
+
+	if err := insertSlot(ctx, tx, showingId, slot); err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE showings SET status = $1, updated_at = NOW() WHERE id = $2`
+
+	if _, err := tx.ExecContext(ctx, query, toStatus, showingId); err != nil {
+		return nil, err
+	}
+
+	showing, err := r.getShowingById(ctx, tx, showingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return showing, nil
+}
+
+// lockAgentSchedule takes an advisory lock on the agent for the rest of tx and
+// fails with ErrShowingConflict if another confirmed showing overlaps
+// [start, end). The lock serializes confirmations across all of the agent's
+// listings, so two overlapping slots can never both pass the check.
+func lockAgentSchedule(
+	ctx context.Context,
+	tx *sql.Tx,
+	agentId int,
+	showingId int,
+	start time.Time,
+	end time.Time,
+) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, agentId); err != nil {
+		return err
+	}
+
+	var conflict bool
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM showings
+			WHERE agent_id = $1
+				AND status = $2
+				AND id <> $3
+				AND scheduled_start < $5
+				AND scheduled_end > $4
+		)
+	`
+
+	err := tx.QueryRowContext(
+		ctx,
+		query,
+		agentId,
+		domain.ShowingStatusConfirmed,
+		showingId,
+		start,
+		end,
+	).Scan(&conflict)
+	if err != nil {
+		return err
+	}
+
+	if conflict {
+		return ErrShowingConflict
+	}
+
+	return nil
+}
+
+// ConfirmShowing schedules the showing at the given slot.
+func (r *ShowingRepository) ConfirmShowing(
+	ctx context.Context,
+	showingId int,
+	fromStatus string,
+	slotId int,
+) (*domain.Showing, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	agentId, err := lockShowing(ctx, tx, showingId, fromStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	var start, end time.Time
+
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT start_time, end_time FROM showing_slots WHERE id = $1 AND showing_id = $2`,
+		slotId,
+		showingId,
+	).Scan(&start, &end)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("Slot not found on showing")
+		}
+		return nil, err
+	}
+
+	if err := lockAgentSchedule(ctx, tx, agentId, showingId, start, end); err != nil {
+		return nil, err
+	}
+
+	updateQuery := `
+		UPDATE showings
+		SET status = $1, scheduled_start = $2, scheduled_end = $3, updated_at = NOW()
+		WHERE id = $4
+	`
+
+	_, err = tx.ExecContext(ctx, updateQuery, domain.ShowingStatusConfirmed, start, end, showingId)
+	if err != nil {
+		return nil, err
+	}
+
+	showing, err := r.getShowingById(ctx, tx, showingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return showing, nil
+}
+
+func (r *ShowingRepository) UpdateShowingStatus(
+	ctx context.Context,
+	showingId int,
+	fromStatus string,
+	toStatus string,
+) (*domain.Showing, error) {
+	query := `
+		UPDATE showings
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+		RETURNING ` + showingColumns
+
+	showings, err := r.queryShowings(ctx, r.db, query, toStatus, showingId, fromStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(showings) == 0 {
+		return nil, ErrShowingChanged
+	}
+
+	return showings[0], nil
+}
+
+func (r *ShowingRepository) GetConfirmedShowingsByAgentId(
+	ctx context.Context,
+	agentId int,
+	from time.Time,
+	to time.Time,
+) ([]*domain.Showing, error) {
+	query := `
+		SELECT ` + showingColumns + `
+		FROM showings
+		WHERE agent_id = $1
+			AND status = $2
+			AND scheduled_end > $3
+			AND scheduled_start < $4
+		ORDER BY scheduled_start
+	`
+
+	return r.queryShowings(ctx, r.db, query, agentId, domain.ShowingStatusConfirmed, from, to)
+}
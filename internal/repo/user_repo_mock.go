@@ -8,13 +8,16 @@ import (
 )
 
 type UserRepoMock struct {
-	GetAllUsersFunc    func(ctx context.Context) ([]*domain.User, error)
-	GetUserByIdFunc    func(ctx context.Context, id int) (*domain.User, error)
-	GetAgentByIdFunc   func(ctx context.Context, id int) (*domain.Agent, error)
-	GetUserByEmailFunc func(ctx context.Context, email string) (*domain.User, error)
-	GetUsersByRoleFunc func(ctx context.Context, role string) ([]*domain.User, error)
-	CreateUserFunc     func(ctx context.Context, user *domain.User) (*domain.User, error)
-	UpdateUserByIdFunc func(ctx context.Context, user *dto.UpdateUserRequest, id int) (*domain.User, error)
+	GetAllUsersFunc         func(ctx context.Context) ([]*domain.User, error)
+	GetUserByIdFunc         func(ctx context.Context, id int) (*domain.User, error)
+	GetAgentByIdFunc        func(ctx context.Context, id int) (*domain.Agent, error)
+	GetUserByEmailFunc      func(ctx context.Context, email string) (*domain.User, error)
+	GetUsersByRoleFunc      func(ctx context.Context, role string) ([]*domain.User, error)
+	GetAgentsFunc           func(ctx context.Context, filter *domain.AgentFilter) ([]*domain.Agent, error)
+	UpdateAgentProfileFunc  func(ctx context.Context, agentId int, profile *domain.AgentProfile) error
+	UpdateAgentHeadshotFunc func(ctx context.Context, agentId int, url string) (*string, error)
+	CreateUserFunc          func(ctx context.Context, user *domain.User) (*domain.User, error)
+	UpdateUserByIdFunc      func(ctx context.Context, user *dto.UpdateUserRequest, id int) (*domain.User, error)
 }
 
 func (u *UserRepoMock) GetAllUsers(ctx context.Context) ([]*domain.User, error) {
@@ -37,6 +40,29 @@ func (u *UserRepoMock) GetUsersByRole(ctx context.Context, role string) ([]*doma
 	return u.GetUsersByRole(ctx, role)
 }
 
+func (u *UserRepoMock) GetAgents(
+	ctx context.Context,
+	filter *domain.AgentFilter,
+) ([]*domain.Agent, error) {
+	return u.GetAgentsFunc(ctx, filter)
+}
+
+func (u *UserRepoMock) UpdateAgentProfile(
+	ctx context.Context,
+	agentId int,
+	profile *domain.AgentProfile,
+) error {
+	return u.UpdateAgentProfileFunc(ctx, agentId, profile)
+}
+
+func (u *UserRepoMock) UpdateAgentHeadshot(
+	ctx context.Context,
+	agentId int,
+	url string,
+) (*string, error) {
+	return u.UpdateAgentHeadshotFunc(ctx, agentId, url)
+}
+
 func (u *UserRepoMock) CreateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
 	return u.CreateUserFunc(ctx, user)
 }
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5/pgtype"
+
 	"server/internal/api/dto"
 	"server/internal/domain"
 )
@@ -16,6 +18,10 @@ type IUserRepo interface {
 	GetAgentById(ctx context.Context, id int) (*domain.Agent, error)
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	GetUsersByRole(ctx context.Context, role string) ([]*domain.User, error)
+	GetAgents(ctx context.Context, filter *domain.AgentFilter) ([]*domain.Agent, error)
+	UpdateAgentProfile(ctx context.Context, agentId int, profile *domain.AgentProfile) error
+	// UpdateAgentHeadshot returns the replaced headshot URL, if any.
+	UpdateAgentHeadshot(ctx context.Context, agentId int, url string) (*string, error)
 	CreateUser(ctx context.Context, user *domain.User) (*domain.User, error)
 	UpdateUserById(
 		ctx context.Context,
@@ -73,7 +79,17 @@ func (r *UserRepository) GetAllUsers(ctx context.Context) ([]*domain.User, error
 
 func (r *UserRepository) GetUserById(ctx context.Context, id int) (*domain.User, error) {
 	query := `
-		SELECT id, first_name, last_name, email, created_at, updated_at, role
+		SELECT
+			id,
+			first_name,
+			last_name,
+			email,
+			created_at,
+			updated_at,
+			role,
+			brokerage_id,
+			team_id,
+			share_favorites_with_agents
 		FROM users
 		WHERE id = $1
 	`
@@ -87,6 +103,9 @@ func (r *UserRepository) GetUserById(ctx context.Context, id int) (*domain.User,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.Role,
+		&user.BrokerageID,
+		&user.TeamID,
+		&user.ShareFavoritesWithAgents,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -98,22 +117,90 @@ func (r *UserRepository) GetUserById(ctx context.Context, id int) (*domain.User,
 	return &user, nil
 }
 
-func (r *UserRepository) GetAgentById(ctx context.Context, id int) (*domain.Agent, error) {
-	query := `
-		SELECT id, first_name, last_name, email, created_at, updated_at
-		FROM users
-		WHERE id = $1 AND role = 'agent'
-	`
-	var agent domain.Agent
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+// agentRatingJoin adds the average rating and count of published reviews as
+// the ratings relation, the follower count as follows, the agent's profile if
+// they have filled it in and their brokerage if they belong to one.
+const agentRatingJoin = `
+	LEFT JOIN (
+		SELECT
+			agent_id,
+			ROUND(AVG(rating), 2)::FLOAT8 AS average_rating,
+			COUNT(*) AS review_count
+		FROM agent_reviews
+		WHERE status = 'published'
+		GROUP BY agent_id
+	) ratings ON ratings.agent_id = users.id
+	LEFT JOIN (
+		SELECT agent_id, COUNT(*) AS follower_count
+		FROM agent_follows
+		GROUP BY agent_id
+	) follows ON follows.agent_id = users.id
+	LEFT JOIN agent_profiles ON agent_profiles.user_id = users.id
+	LEFT JOIN brokerages ON brokerages.id = users.brokerage_id
+`
+
+const agentColumns = `
+	users.id,
+	users.first_name,
+	users.last_name,
+	users.email,
+	users.timezone,
+	users.created_at,
+	users.updated_at,
+	ratings.average_rating,
+	COALESCE(ratings.review_count, 0),
+	COALESCE(follows.follower_count, 0),
+	agent_profiles.bio,
+	agent_profiles.phone,
+	agent_profiles.license_number,
+	agent_profiles.license_state,
+	agent_profiles.brokerage_name,
+	agent_profiles.headshot_url,
+	COALESCE(agent_profiles.languages, '{}'),
+	COALESCE(agent_profiles.specialties, '{}'),
+	COALESCE(agent_profiles.service_zip_codes, '{}'),
+	users.team_id,
+	` + brokerageBrandingColumn + `
+`
+
+// agentFields scans agentColumns. database/sql has no array support, so the
+// profile's text[] columns go through types.
+func agentFields(types *pgtype.Map, agent *domain.Agent) []any {
+	return []any{
 		&agent.ID,
 		&agent.FirstName,
 		&agent.LastName,
 		&agent.Email,
+		&agent.Timezone,
 		&agent.CreatedAt,
 		&agent.UpdatedAt,
-	)
+		&agent.AverageRating,
+		&agent.ReviewCount,
+		&agent.FollowerCount,
+		&agent.Bio,
+		&agent.Phone,
+		&agent.LicenseNumber,
+		&agent.LicenseState,
+		&agent.BrokerageName,
+		&agent.HeadshotURL,
+		types.SQLScanner(&agent.Languages),
+		types.SQLScanner(&agent.Specialties),
+		types.SQLScanner(&agent.ServiceZipCodes),
+		&agent.TeamID,
+		jsonColumn{&agent.Brokerage},
+	}
+}
+
+func (r *UserRepository) GetAgentById(ctx context.Context, id int) (*domain.Agent, error) {
+	query := `
+		SELECT ` + agentColumns + `
+		FROM users
+		` + agentRatingJoin + `
+		WHERE users.id = $1 AND users.role IN ('agent', 'broker')
+	`
+	var agent domain.Agent
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(agentFields(pgtype.NewMap(), &agent)...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, err
@@ -124,9 +211,138 @@ func (r *UserRepository) GetAgentById(ctx context.Context, id int) (*domain.Agen
 	return &agent, nil
 }
 
+// GetAgents lists the agents matching filter with their rating and profile.
+// Sorting by rating puts unrated agents last.
+func (r *UserRepository) GetAgents(
+	ctx context.Context,
+	filter *domain.AgentFilter,
+) ([]*domain.Agent, error) {
+	query := `
+		SELECT ` + agentColumns + `
+		FROM users
+		` + agentRatingJoin + `
+		WHERE users.role IN ('agent', 'broker')
+			AND ($2 = '' OR agent_profiles.service_zip_codes @> ARRAY[$2])
+			AND ($3 = '' OR agent_profiles.languages @> ARRAY[LOWER($3)])
+			AND ($4 = '' OR agent_profiles.specialties @> ARRAY[$4])
+		ORDER BY
+			CASE WHEN $1 = 'rating' THEN ratings.average_rating END DESC NULLS LAST,
+			CASE WHEN $1 = 'rating' THEN ratings.review_count END DESC NULLS LAST,
+			users.id
+	`
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		query,
+		filter.Sort,
+		filter.ZipCode,
+		filter.Language,
+		filter.Specialty,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	types := pgtype.NewMap()
+
+	var agents []*domain.Agent
+	for rows.Next() {
+		agent := new(domain.Agent)
+
+		if err := rows.Scan(agentFields(types, agent)...); err != nil {
+			return nil, err
+		}
+
+		agents = append(agents, agent)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return agents, nil
+}
+
+// UpdateAgentProfile replaces every profile field except the headshot, which
+// is managed by UpdateAgentHeadshot.
+func (r *UserRepository) UpdateAgentProfile(
+	ctx context.Context,
+	agentId int,
+	profile *domain.AgentProfile,
+) error {
+	query := `
+		INSERT INTO agent_profiles (
+			user_id,
+			bio,
+			phone,
+			license_number,
+			license_state,
+			brokerage_name,
+			languages,
+			specialties,
+			service_zip_codes
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id) DO UPDATE
+		SET bio = EXCLUDED.bio,
+			phone = EXCLUDED.phone,
+			license_number = EXCLUDED.license_number,
+			license_state = EXCLUDED.license_state,
+			brokerage_name = EXCLUDED.brokerage_name,
+			languages = EXCLUDED.languages,
+			specialties = EXCLUDED.specialties,
+			service_zip_codes = EXCLUDED.service_zip_codes,
+			updated_at = NOW()
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		agentId,
+		profile.Bio,
+		profile.Phone,
+		profile.LicenseNumber,
+		profile.LicenseState,
+		profile.BrokerageName,
+		profile.Languages,
+		profile.Specialties,
+		profile.ServiceZipCodes,
+	)
+	return err
+}
+
+func (r *UserRepository) UpdateAgentHeadshot(
+	ctx context.Context,
+	agentId int,
+	url string,
+) (*string, error) {
+	// The old URL is read in a CTE because RETURNING only sees the new row
+	query := `
+		WITH previous AS (
+			SELECT headshot_url FROM agent_profiles WHERE user_id = $1
+		)
+		INSERT INTO agent_profiles (user_id, headshot_url)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET headshot_url = EXCLUDED.headshot_url, updated_at = NOW()
+		RETURNING (SELECT headshot_url FROM previous)
+	`
+
+	var previousURL *string
+
+	if err := r.db.QueryRowContext(ctx, query, agentId, url).Scan(&previousURL); err != nil {
+		return nil, err
+	}
+
+	return previousURL, nil
+}
+
 func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT * FROM users
+		SELECT id, first_name, last_name, email, password_hash, created_at, updated_at, role
+		FROM users
 		WHERE email = $1
 	`
 
@@ -222,14 +438,25 @@ func (r *UserRepository) UpdateUserById(
 			last_name  = COALESCE($2, last_name),
 			email      = COALESCE($3, email),
 			role       = COALESCE($4, role),
+			share_favorites_with_agents = COALESCE($6, share_favorites_with_agents),
 			updated_at = NOW()
 		WHERE id = $5
-		RETURNING id, first_name, last_name, email, created_at, updated_at, role
+		RETURNING id, first_name, last_name, email, created_at, updated_at, role,
+			share_favorites_with_agents
 	`
 
 	var updatedUser domain.User
 
-	err := r.db.QueryRowContext(ctx, query, user.FirstName, user.LastName, user.Email, user.Role, id).
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		user.FirstName,
+		user.LastName,
+		user.Email,
+		user.Role,
+		id,
+		user.ShareFavoritesWithAgents,
+	).
 		Scan(
 			&updatedUser.ID,
 			&updatedUser.FirstName,
@@ -238,6 +465,7 @@ func (r *UserRepository) UpdateUserById(
 			&updatedUser.CreatedAt,
 			&updatedUser.UpdatedAt,
 			&updatedUser.Role,
+			&updatedUser.ShareFavoritesWithAgents,
 		)
 	if err != nil {
 		return nil, fmt.Errorf("Update user: %w", err)
@@ -0,0 +1,96 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"server/internal/domain"
+)
+
+type WebhookRepoMock struct {
+	CreateSubscriptionFunc    func(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	GetSubscriptionByIdFunc   func(ctx context.Context, id int) (*domain.WebhookSubscription, error)
+	GetSubscriptionsFunc      func(ctx context.Context, agentId int, brokerageId *int) ([]*domain.WebhookSubscription, error)
+	UpdateSubscriptionFunc    func(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	DeleteSubscriptionFunc    func(ctx context.Context, id int) error
+	EnqueueDeliveriesFunc     func(ctx context.Context, agentIds []int, eventType string, dedupKey string, payload []byte) (int, error)
+	ClaimDueDeliveriesFunc    func(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error)
+	RecordDeliveryAttemptFunc func(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetDeliveriesFunc         func(ctx context.Context, subscriptionId int, beforeId int, limit int) ([]*domain.WebhookDelivery, error)
+	RedeliverDeliveryFunc     func(ctx context.Context, subscriptionId int, id int) (*domain.WebhookDelivery, error)
+}
+
+func (w *WebhookRepoMock) CreateSubscription(
+	ctx context.Context,
+	subscription *domain.WebhookSubscription,
+) (*domain.WebhookSubscription, error) {
+	return w.CreateSubscriptionFunc(ctx, subscription)
+}
+
+func (w *WebhookRepoMock) GetSubscriptionById(
+	ctx context.Context,
+	id int,
+) (*domain.WebhookSubscription, error) {
+	return w.GetSubscriptionByIdFunc(ctx, id)
+}
+
+func (w *WebhookRepoMock) GetSubscriptions(
+	ctx context.Context,
+	agentId int,
+	brokerageId *int,
+) ([]*domain.WebhookSubscription, error) {
+	return w.GetSubscriptionsFunc(ctx, agentId, brokerageId)
+}
+
+func (w *WebhookRepoMock) UpdateSubscription(
+	ctx context.Context,
+	subscription *domain.WebhookSubscription,
+) (*domain.WebhookSubscription, error) {
+	return w.UpdateSubscriptionFunc(ctx, subscription)
+}
+
+func (w *WebhookRepoMock) DeleteSubscription(ctx context.Context, id int) error {
+	return w.DeleteSubscriptionFunc(ctx, id)
+}
+
+func (w *WebhookRepoMock) EnqueueDeliveries(
+	ctx context.Context,
+	agentIds []int,
+	eventType string,
+	dedupKey string,
+	payload []byte,
+) (int, error) {
+	return w.EnqueueDeliveriesFunc(ctx, agentIds, eventType, dedupKey, payload)
+}
+
+func (w *WebhookRepoMock) ClaimDueDeliveries(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]*domain.WebhookDelivery, error) {
+	return w.ClaimDueDeliveriesFunc(ctx, limit, lease)
+}
+
+func (w *WebhookRepoMock) RecordDeliveryAttempt(
+	ctx context.Context,
+	delivery *domain.WebhookDelivery,
+) error {
+	return w.RecordDeliveryAttemptFunc(ctx, delivery)
+}
+
+func (w *WebhookRepoMock) GetDeliveries(
+	ctx context.Context,
+	subscriptionId int,
+	beforeId int,
+	limit int,
+) ([]*domain.WebhookDelivery, error) {
+	return w.GetDeliveriesFunc(ctx, subscriptionId, beforeId, limit)
+}
+
+func (w *WebhookRepoMock) RedeliverDelivery(
+	ctx context.Context,
+	subscriptionId int,
+	id int,
+) (*domain.WebhookDelivery, error) {
+	return w.RedeliverDeliveryFunc(ctx, subscriptionId, id)
+}
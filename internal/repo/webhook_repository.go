@@ -0,0 +1,463 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"server/internal/domain"
+)
+
+type IWebhookRepo interface {
+	CreateSubscription(
+		ctx context.Context,
+		subscription *domain.WebhookSubscription,
+	) (*domain.WebhookSubscription, error)
+	GetSubscriptionById(ctx context.Context, id int) (*domain.WebhookSubscription, error)
+	// GetSubscriptions returns the agent's own subscriptions and, when
+	// brokerageId is set, the brokerage's.
+	GetSubscriptions(
+		ctx context.Context,
+		agentId int,
+		brokerageId *int,
+	) ([]*domain.WebhookSubscription, error)
+	UpdateSubscription(
+		ctx context.Context,
+		subscription *domain.WebhookSubscription,
+	) (*domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int) error
+	// EnqueueDeliveries queues the event once for every active subscription
+	// of the agents or their brokerages that asked for eventType and returns
+	// how many were queued. Subscriptions that already have a delivery with
+	// a non-empty dedupKey are skipped.
+	EnqueueDeliveries(
+		ctx context.Context,
+		agentIds []int,
+		eventType string,
+		dedupKey string,
+		payload []byte,
+	) (int, error)
+	// ClaimDueDeliveries leases up to limit pending deliveries whose next
+	// attempt is due by pushing that attempt back by lease, so concurrent
+	// workers never pick up the same delivery.
+	ClaimDueDeliveries(
+		ctx context.Context,
+		limit int,
+		lease time.Duration,
+	) ([]*domain.WebhookDelivery, error)
+	// RecordDeliveryAttempt saves the status, attempt count, next attempt and
+	// last result of a delivery after a send.
+	RecordDeliveryAttempt(ctx context.Context, delivery *domain.WebhookDelivery) error
+	// GetDeliveries returns the subscription's deliveries newest first, older
+	// than beforeId when it is set.
+	GetDeliveries(
+		ctx context.Context,
+		subscriptionId int,
+		beforeId int,
+		limit int,
+	) ([]*domain.WebhookDelivery, error)
+	// RedeliverDelivery puts a delivery back in the queue with a fresh set of
+	// attempts, whatever state it finished in.
+	RedeliverDelivery(
+		ctx context.Context,
+		subscriptionId int,
+		id int,
+	) (*domain.WebhookDelivery, error)
+}
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const webhookSubscriptionColumns = `
+	id,
+	agent_id,
+	brokerage_id,
+	url,
+	event_types,
+	secret,
+	is_active,
+	created_at,
+	updated_at
+`
+
+// webhookSubscriptionFields scans event_types through pgtype, since
+// database/sql has no array support.
+func webhookSubscriptionFields(subscription *domain.WebhookSubscription) []any {
+	types := pgtype.NewMap()
+
+	return []any{
+		&subscription.ID,
+		&subscription.AgentID,
+		&subscription.BrokerageID,
+		&subscription.URL,
+		types.SQLScanner(&subscription.EventTypes),
+		&subscription.Secret,
+		&subscription.IsActive,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	}
+}
+
+// Qualified because claiming joins webhook_subscriptions
+const webhookDeliveryColumns = `
+	webhook_deliveries.id,
+	webhook_deliveries.subscription_id,
+	webhook_deliveries.event_type,
+	webhook_deliveries.payload,
+	webhook_deliveries.status,
+	webhook_deliveries.attempts,
+	webhook_deliveries.next_attempt_at,
+	webhook_deliveries.last_status_code,
+	webhook_deliveries.last_error,
+	webhook_deliveries.delivered_at,
+	webhook_deliveries.created_at,
+	webhook_deliveries.updated_at
+`
+
+func webhookDeliveryFields(delivery *domain.WebhookDelivery) []any {
+	return []any{
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	}
+}
+
+func (r *WebhookRepository) CreateSubscription(
+	ctx context.Context,
+	subscription *domain.WebhookSubscription,
+) (*domain.WebhookSubscription, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (agent_id, brokerage_id, url, event_types, secret)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + webhookSubscriptionColumns
+
+	newSubscription := new(domain.WebhookSubscription)
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		subscription.AgentID,
+		subscription.BrokerageID,
+		subscription.URL,
+		subscription.EventTypes,
+		subscription.Secret,
+	).Scan(webhookSubscriptionFields(newSubscription)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSubscription, nil
+}
+
+func (r *WebhookRepository) GetSubscriptionById(
+	ctx context.Context,
+	id int,
+) (*domain.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE id = $1
+	`
+
+	subscription := new(domain.WebhookSubscription)
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(webhookSubscriptionFields(subscription)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (r *WebhookRepository) GetSubscriptions(
+	ctx context.Context,
+	agentId int,
+	brokerageId *int,
+) ([]*domain.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE agent_id = $1
+			OR ($2::BIGINT IS NOT NULL AND brokerage_id = $2)
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, agentId, brokerageId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	subscriptions := []*domain.WebhookSubscription{}
+	for rows.Next() {
+		subscription := new(domain.WebhookSubscription)
+
+		if err := rows.Scan(webhookSubscriptionFields(subscription)...); err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *WebhookRepository) UpdateSubscription(
+	ctx context.Context,
+	subscription *domain.WebhookSubscription,
+) (*domain.WebhookSubscription, error) {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $2,
+			event_types = $3,
+			is_active = $4,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + webhookSubscriptionColumns
+
+	updated := new(domain.WebhookSubscription)
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		subscription.ID,
+		subscription.URL,
+		subscription.EventTypes,
+		subscription.IsActive,
+	).Scan(webhookSubscriptionFields(updated)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) EnqueueDeliveries(
+	ctx context.Context,
+	agentIds []int,
+	eventType string,
+	dedupKey string,
+	payload []byte,
+) (int, error) {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_type, payload, dedup_key)
+		SELECT webhook_subscriptions.id, $2, $3::jsonb, NULLIF($4, '')
+		FROM webhook_subscriptions
+		WHERE webhook_subscriptions.is_active
+			AND $2 = ANY(webhook_subscriptions.event_types)
+			AND (
+				webhook_subscriptions.agent_id = ANY($1)
+				OR webhook_subscriptions.brokerage_id IN (
+					SELECT brokerage_id FROM users WHERE id = ANY($1)
+				)
+			)
+		ON CONFLICT (subscription_id, dedup_key) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, agentIds, eventType, string(payload), dedupKey)
+	if err != nil {
+		return 0, err
+	}
+
+	queued, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(queued), nil
+}
+
+func (r *WebhookRepository) ClaimDueDeliveries(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]*domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = NOW() + $2 * INTERVAL '1 second',
+			updated_at = NOW()
+		FROM webhook_subscriptions
+		WHERE webhook_subscriptions.id = webhook_deliveries.subscription_id
+			AND webhook_deliveries.id IN (
+				SELECT webhook_deliveries.id
+				FROM webhook_deliveries
+				JOIN webhook_subscriptions
+					ON webhook_subscriptions.id = webhook_deliveries.subscription_id
+				WHERE webhook_deliveries.status = $3
+					AND webhook_deliveries.next_attempt_at <= NOW()
+					AND webhook_subscriptions.is_active
+				ORDER BY webhook_deliveries.next_attempt_at
+				LIMIT $1
+				FOR UPDATE OF webhook_deliveries SKIP LOCKED
+			)
+		RETURNING ` + webhookDeliveryColumns + `,
+			webhook_subscriptions.url,
+			webhook_subscriptions.secret
+	`
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		query,
+		limit,
+		lease.Seconds(),
+		domain.WebhookDeliveryPending,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		delivery := new(domain.WebhookDelivery)
+
+		fields := append(webhookDeliveryFields(delivery), &delivery.URL, &delivery.Secret)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) RecordDeliveryAttempt(
+	ctx context.Context,
+	delivery *domain.WebhookDelivery,
+) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2,
+			attempts = $3,
+			next_attempt_at = $4,
+			last_status_code = $5,
+			last_error = $6,
+			delivered_at = $7,
+			updated_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.DeliveredAt,
+	)
+	return err
+}
+
+func (r *WebhookRepository) GetDeliveries(
+	ctx context.Context,
+	subscriptionId int,
+	beforeId int,
+	limit int,
+) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+			AND ($2 = 0 OR id < $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, subscriptionId, beforeId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries := []*domain.WebhookDelivery{}
+	for rows.Next() {
+		delivery := new(domain.WebhookDelivery)
+
+		if err := rows.Scan(webhookDeliveryFields(delivery)...); err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) RedeliverDelivery(
+	ctx context.Context,
+	subscriptionId int,
+	id int,
+) (*domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $3,
+			attempts = 0,
+			next_attempt_at = NOW(),
+			delivered_at = NULL,
+			updated_at = NOW()
+		WHERE id = $1 AND subscription_id = $2
+		RETURNING ` + webhookDeliveryColumns
+
+	delivery := new(domain.WebhookDelivery)
+
+	err := r.db.QueryRowContext(ctx, query, id, subscriptionId, domain.WebhookDeliveryPending).
+		Scan(webhookDeliveryFields(delivery)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
@@ -22,50 +22,86 @@ func Authenticate(
 ) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
-
-			cookie, err := r.Cookie("session")
-			if err != nil {
-				sessionHeader := r.Header.Get("X-Session-Token")
-
-				if sessionHeader == "" {
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
-					return
-				}
-
-				cookie = &http.Cookie{Name: "session", Value: sessionHeader}
-			}
-
-			sessionID, err := url.QueryUnescape(cookie.Value)
+			contextSessionData, err := resolveSession(r, session, userRepo)
 			if err != nil {
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				return
 			}
 
-			sessionData, err := session.GetSession(ctx, sessionID)
-			if err != nil {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
+			userCtx := context.WithValue(r.Context(), UserContextKey, contextSessionData)
+			next.ServeHTTP(w, r.WithContext(userCtx))
+		})
+	}
+}
 
-			user, err := userRepo.GetUserById(ctx, sessionData.UserID)
+// OptionalAuthenticate attaches the session user to the context when a valid
+// session is present and otherwise lets the request through anonymously.
+// Handlers read the user with UserFromContext.
+func OptionalAuthenticate(
+	session session.ISession,
+	userRepo repo.IUserRepo,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contextSessionData, err := resolveSession(r, session, userRepo)
 			if err != nil {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				next.ServeHTTP(w, r)
 				return
 			}
 
-			contextSessionData := &domain.ContextSessionData{
-				SessionID: sessionID,
-				UserID:    sessionData.UserID,
-				Role:      user.Role,
-			}
-
-			userCtx := context.WithValue(ctx, UserContextKey, contextSessionData)
+			userCtx := context.WithValue(r.Context(), UserContextKey, contextSessionData)
 			next.ServeHTTP(w, r.WithContext(userCtx))
 		})
 	}
 }
 
+// UserFromContext returns the session user, or nil on anonymous requests.
+func UserFromContext(ctx context.Context) *domain.ContextSessionData {
+	userCtx, _ := ctx.Value(UserContextKey).(*domain.ContextSessionData)
+	return userCtx
+}
+
+func resolveSession(
+	r *http.Request,
+	session session.ISession,
+	userRepo repo.IUserRepo,
+) (*domain.ContextSessionData, error) {
+	ctx := r.Context()
+
+	cookie, err := r.Cookie("session")
+	if err != nil {
+		sessionHeader := r.Header.Get("X-Session-Token")
+
+		if sessionHeader == "" {
+			return nil, err
+		}
+
+		cookie = &http.Cookie{Name: "session", Value: sessionHeader}
+	}
+
+	sessionID, err := url.QueryUnescape(cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionData, err := session.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := userRepo.GetUserById(ctx, sessionData.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ContextSessionData{
+		SessionID:   sessionID,
+		UserID:      sessionData.UserID,
+		Role:        user.Role,
+		BrokerageID: user.BrokerageID,
+	}, nil
+}
+
 func Authorize() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -14,6 +14,7 @@ import (
 func (s *Server) RegisterRoutes() http.Handler {
 	r := chi.NewRouter()
 	authMiddleware := middleware.Authenticate(s.session, s.userRepo)
+	optionalAuthMiddleware := middleware.OptionalAuthenticate(s.session, s.userRepo)
 	authorizeMiddleware := middleware.Authorize()
 
 	r.Use(cm.Logger)
@@ -28,17 +29,37 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	// Public routes
 	r.Group(func(r chi.Router) {
+		r.Use(optionalAuthMiddleware)
+
 		r.Get("/listings", s.listingHandler.GetAllListings)
 		r.Get("/listings/{listingId}", s.listingHandler.GetListingById)
 		r.Patch("/listings/{listingId}/views", s.listingHandler.TrackViewsByListingId)
+		r.Get("/listings/{listingId}/estimate", s.valuationHandler.GetListingEstimate)
+		r.Get("/listings/{listingId}/open-houses", s.openHouseHandler.GetListingOpenHouses)
+		r.Get("/listings/{listingId}/open-houses.ics", s.openHouseHandler.GetListingCalendar)
+		r.Get("/listings/{listingId}/showing-slots", s.availabilityHandler.GetBookableSlots)
+		r.Post("/listings/{listingId}/inquiries", s.leadHandler.SubmitInquiry)
 
 		r.Get("/agents", s.userHandler.GetAllAgents)
 		r.Get("/agents/{agentId}", s.userHandler.GetAgentById)
 		r.Get("/agents/{agentId}/listings", s.listingHandler.GetAgentListings)
+		r.Get("/agents/{agentId}/open-houses.ics", s.openHouseHandler.GetAgentCalendar)
+		r.Get("/agents/{agentId}/reviews", s.reviewHandler.GetAgentReviews)
+
+		r.Get("/brokerages/{brokerageId}", s.brokerageHandler.GetBrokerageById)
+		r.Get("/brokerages/{brokerageId}/agents", s.brokerageHandler.GetBrokerageAgents)
+		r.Get("/brokerages/{brokerageId}/listings", s.brokerageHandler.GetBrokerageListings)
+
+		r.Get("/shared/collections/{shareToken}", s.favoriteHandler.GetSharedCollection)
+
+		r.Get("/market/stats", s.marketHandler.GetMarketStats)
+		r.Post("/calculators/mortgage", s.mortgageHandler.CalculateMortgage)
 
 		r.Route("/auth", func(u chi.Router) {
 			u.Post("/register", s.authHandler.Register)
 			u.Post("/login", s.authHandler.Login)
+			u.Post("/password-reset", s.authHandler.RequestPasswordReset)
+			u.Post("/password-reset/confirm", s.authHandler.ResetPassword)
 		})
 	})
 
@@ -48,18 +69,59 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 		r.Get("/users/profile", s.userHandler.GetCurrentUser)
 		r.Patch("/users/profile", s.userHandler.UpdateUserById)
+		r.Get("/users/profile/mortgage-preferences", s.mortgageHandler.GetMortgagePreferences)
+		r.Put("/users/profile/mortgage-preferences", s.mortgageHandler.UpdateMortgagePreferences)
 		r.Post("/auth/logout", s.authHandler.Logout)
 
 		r.Get("/favorites", s.favoriteHandler.GetUserFavorites)
 		r.Post("/favorites", s.favoriteHandler.CreateFavorite)
 		r.Delete("/favorites/{listingId}", s.favoriteHandler.DeleteFavoriteByListingId)
-
-		r.Get("/notifications", s.notificationHandler.GetAllNotificationsByUserId)
-		r.Post("/notifications", s.notificationHandler.CreateNotification)
-		r.Patch(
-			"/notifications/{notificationId}",
-			s.notificationHandler.ToggleNotificationReadStatus,
+		r.Patch("/favorites/{listingId}", s.favoriteHandler.UpdateFavoriteNote)
+		r.Put("/favorites/{listingId}/alert", s.favoriteHandler.SetPriceAlert)
+		r.Delete("/favorites/{listingId}/alert", s.favoriteHandler.ClearPriceAlert)
+		r.Get("/favorites/collections", s.favoriteHandler.GetCollections)
+		r.Post("/favorites/collections", s.favoriteHandler.CreateCollection)
+		r.Get("/favorites/collections/{collectionId}", s.favoriteHandler.GetCollectionById)
+		r.Patch("/favorites/collections/{collectionId}", s.favoriteHandler.RenameCollection)
+		r.Delete("/favorites/collections/{collectionId}", s.favoriteHandler.DeleteCollection)
+		r.Put(
+			"/favorites/collections/{collectionId}/listings/{listingId}",
+			s.favoriteHandler.AddToCollection,
+		)
+		r.Delete(
+			"/favorites/collections/{collectionId}/listings/{listingId}",
+			s.favoriteHandler.RemoveFromCollection,
 		)
+		r.Post("/favorites/collections/{collectionId}/share", s.favoriteHandler.ShareCollection)
+		r.Delete("/favorites/collections/{collectionId}/share", s.favoriteHandler.UnshareCollection)
+
+		r.Get("/notifications", s.notificationHandler.GetNotifications)
+		r.Get("/notifications/unread-count", s.notificationHandler.GetUnreadCount)
+		r.Post("/notifications/read-all", s.notificationHandler.MarkAllRead)
+		r.Get("/notifications/settings", s.notificationHandler.GetSettings)
+		r.Put("/notifications/settings", s.notificationHandler.UpdateSettings)
+		r.Patch("/notifications/{notificationId}", s.notificationHandler.UpdateNotification)
+		r.Delete("/notifications/{notificationId}", s.notificationHandler.DeleteNotification)
+
+		r.Post("/listings/{listingId}/showings", s.showingHandler.RequestShowing)
+		r.Post("/listings/{listingId}/showings/book", s.availabilityHandler.BookShowing)
+		r.Get("/showings", s.showingHandler.GetMyShowings)
+		r.Get("/showings/{showingId}", s.showingHandler.GetShowingById)
+		r.Post("/showings/{showingId}/accept", s.showingHandler.AcceptShowing)
+		r.Post("/showings/{showingId}/propose", s.showingHandler.ProposeShowingTime)
+		r.Post("/showings/{showingId}/decline", s.showingHandler.DeclineShowing)
+
+		r.Post("/listings/{listingId}/conversations", s.messageHandler.StartConversation)
+		r.Get("/conversations", s.messageHandler.GetConversations)
+		r.Get("/conversations/unread-count", s.messageHandler.GetUnreadCount)
+		r.Get("/conversations/{conversationId}/messages", s.messageHandler.GetMessages)
+		r.Post("/conversations/{conversationId}/messages", s.messageHandler.SendMessage)
+		r.Post("/conversations/{conversationId}/read", s.messageHandler.MarkConversationRead)
+
+		r.Post("/agents/{agentId}/reviews", s.reviewHandler.CreateReview)
+		r.Get("/agents/{agentId}/follow", s.followHandler.GetFollowStatus)
+		r.Post("/agents/{agentId}/follow", s.followHandler.FollowAgent)
+		r.Delete("/agents/{agentId}/follow", s.followHandler.UnfollowAgent)
 
 		r.Get("/ws", s.wsManager.StartWSConn)
 
@@ -68,9 +130,57 @@ func (s *Server) RegisterRoutes() http.Handler {
 			r.Use(authorizeMiddleware)
 
 			r.Get("/agents/me/listings", s.listingHandler.GetMyListings)
+			r.Get("/agents/me/profile", s.agentProfileHandler.GetMyProfile)
+			r.Patch("/agents/me/profile", s.agentProfileHandler.UpdateMyProfile)
+			r.Put("/agents/me/headshot", s.agentProfileHandler.UploadHeadshot)
+			r.Get("/agents/me/showings/calendar", s.showingHandler.GetMyShowingCalendar)
+			r.Get("/agents/me/availability", s.availabilityHandler.GetMyAvailability)
+			r.Put("/agents/me/availability", s.availabilityHandler.UpdateMyAvailability)
+			r.Post("/agents/me/blackouts", s.availabilityHandler.CreateBlackout)
+			r.Delete("/agents/me/blackouts/{blackoutId}", s.availabilityHandler.DeleteBlackout)
+			r.Get("/agents/me/leads", s.leadHandler.GetMyLeads)
+			r.Get("/agents/me/leads/{leadId}", s.leadHandler.GetLeadById)
+			r.Patch("/agents/me/leads/{leadId}", s.leadHandler.UpdateLead)
+			r.Post("/agents/me/leads/{leadId}/notes", s.leadHandler.AddLeadNote)
+			r.Post("/agents/me/leads/{leadId}/assign", s.leadHandler.AssignLead)
+			r.Post("/agents/me/reviews/{reviewId}/reply", s.reviewHandler.ReplyToReview)
+			r.Patch("/reviews/{reviewId}", s.reviewHandler.ModerateReview)
+			r.Post("/listings/{listingId}/offers", s.offerHandler.SubmitOffer)
+			r.Get("/offers", s.offerHandler.GetMyOffers)
+			r.Get("/offers/{offerId}", s.offerHandler.GetOfferById)
+			r.Post("/offers/{offerId}/counter", s.offerHandler.CounterOffer)
+			r.Post("/offers/{offerId}/accept", s.offerHandler.AcceptOffer)
+			r.Post("/offers/{offerId}/reject", s.offerHandler.RejectOffer)
+			r.Post("/offers/{offerId}/withdraw", s.offerHandler.WithdrawOffer)
 			r.Post("/listings", s.listingHandler.CreateListing)
 			r.Patch("/listings/{listingId}", s.listingHandler.UpdateMyListing)
 			r.Delete("/listings/{listingId}", s.listingHandler.DeleteMyListing)
+			r.Get("/listings/{listingId}/favorites", s.favoriteHandler.GetListingFavoriters)
+			r.Post("/listings/{listingId}/open-houses", s.openHouseHandler.CreateOpenHouse)
+			r.Delete(
+				"/listings/{listingId}/open-houses/{openHouseId}",
+				s.openHouseHandler.CancelOpenHouse,
+			)
+
+			r.Post("/brokerages", s.brokerageHandler.CreateBrokerage)
+			r.Patch("/brokerages/{brokerageId}", s.brokerageHandler.UpdateBrokerage)
+			r.Post("/brokerages/{brokerageId}/teams", s.brokerageHandler.CreateTeam)
+			r.Put("/brokerages/{brokerageId}/members/{userId}", s.brokerageHandler.SetMember)
+			r.Delete("/brokerages/{brokerageId}/members/{userId}", s.brokerageHandler.RemoveMember)
+			r.Get("/brokerages/{brokerageId}/leads", s.brokerageHandler.GetBrokerageLeads)
+			r.Get("/brokerages/{brokerageId}/analytics", s.brokerageHandler.GetBrokerageAnalytics)
+
+			r.Post("/notifications", s.notificationHandler.CreateNotification)
+
+			r.Get("/webhooks", s.webhookHandler.GetMyWebhooks)
+			r.Post("/webhooks", s.webhookHandler.CreateWebhook)
+			r.Patch("/webhooks/{webhookId}", s.webhookHandler.UpdateWebhook)
+			r.Delete("/webhooks/{webhookId}", s.webhookHandler.DeleteWebhook)
+			r.Get("/webhooks/{webhookId}/deliveries", s.webhookHandler.GetDeliveries)
+			r.Post(
+				"/webhooks/{webhookId}/deliveries/{deliveryId}/redeliver",
+				s.webhookHandler.Redeliver,
+			)
 
 			r.Get("/users", s.userHandler.GetAllUsers)
 			r.Patch("/users/{userId}", s.userHandler.UpdateUserById)
@@ -78,6 +188,7 @@ func (s *Server) RegisterRoutes() http.Handler {
 	})
 
 	r.Get("/health", s.healthHandler)
+	r.Handle(s.uploadsPath+"/*", s.uploadsHandler)
 
 	return r
 }
@@ -22,12 +22,28 @@ type Server struct {
 	db                  database.Service
 	session             *session.Session
 	userRepo            *repo.UserRepository
-	listingRepo         *repo.ListingRepository
+	listingRepo         repo.IListingRepo
 	userHandler         *handler.UserHandler
 	authHandler         *handler.AuthHandler
 	listingHandler      *handler.ListingHandler
 	favoriteHandler     *handler.FavoriteHandler
 	notificationHandler *handler.NotificationHandler
+	marketHandler       *handler.MarketHandler
+	valuationHandler    *handler.ValuationHandler
+	mortgageHandler     *handler.MortgageHandler
+	openHouseHandler    *handler.OpenHouseHandler
+	showingHandler      *handler.ShowingHandler
+	availabilityHandler *handler.AvailabilityHandler
+	messageHandler      *handler.MessageHandler
+	leadHandler         *handler.LeadHandler
+	offerHandler        *handler.OfferHandler
+	reviewHandler       *handler.ReviewHandler
+	agentProfileHandler *handler.AgentProfileHandler
+	brokerageHandler    *handler.BrokerageHandler
+	followHandler       *handler.FollowHandler
+	webhookHandler      *handler.WebhookHandler
+	uploadsPath         string
+	uploadsHandler      http.Handler
 	wsManager           *ws.Manager
 }
 
@@ -35,12 +51,28 @@ func NewServer(
 	db database.Service,
 	session *session.Session,
 	userRepo *repo.UserRepository,
-	listingRepo *repo.ListingRepository,
+	listingRepo repo.IListingRepo,
 	userHandler *handler.UserHandler,
 	authHandler *handler.AuthHandler,
 	listingHandler *handler.ListingHandler,
 	favoriteHandler *handler.FavoriteHandler,
 	notificationHandler *handler.NotificationHandler,
+	marketHandler *handler.MarketHandler,
+	valuationHandler *handler.ValuationHandler,
+	mortgageHandler *handler.MortgageHandler,
+	openHouseHandler *handler.OpenHouseHandler,
+	showingHandler *handler.ShowingHandler,
+	availabilityHandler *handler.AvailabilityHandler,
+	messageHandler *handler.MessageHandler,
+	leadHandler *handler.LeadHandler,
+	offerHandler *handler.OfferHandler,
+	reviewHandler *handler.ReviewHandler,
+	agentProfileHandler *handler.AgentProfileHandler,
+	brokerageHandler *handler.BrokerageHandler,
+	followHandler *handler.FollowHandler,
+	webhookHandler *handler.WebhookHandler,
+	uploadsPath string,
+	uploadsHandler http.Handler,
 	wsManager *ws.Manager,
 ) *http.Server {
 	port, _ := strconv.Atoi(os.Getenv("PORT"))
@@ -55,6 +87,22 @@ func NewServer(
 		listingHandler:      listingHandler,
 		favoriteHandler:     favoriteHandler,
 		notificationHandler: notificationHandler,
+		marketHandler:       marketHandler,
+		valuationHandler:    valuationHandler,
+		mortgageHandler:     mortgageHandler,
+		openHouseHandler:    openHouseHandler,
+		showingHandler:      showingHandler,
+		availabilityHandler: availabilityHandler,
+		messageHandler:      messageHandler,
+		leadHandler:         leadHandler,
+		offerHandler:        offerHandler,
+		reviewHandler:       reviewHandler,
+		agentProfileHandler: agentProfileHandler,
+		brokerageHandler:    brokerageHandler,
+		followHandler:       followHandler,
+		webhookHandler:      webhookHandler,
+		uploadsPath:         uploadsPath,
+		uploadsHandler:      uploadsHandler,
 		wsManager:           wsManager,
 	}
 
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+	"server/internal/storage"
+)
+
+const (
+	maxBioLength         = 2000
+	maxBrokerageLength   = 100
+	maxProfileLanguages  = 10
+	maxServiceZipCodes   = 25
+	MaxHeadshotSizeBytes = 5 << 20
+)
+
+var (
+	zipCodePattern       = regexp.MustCompile(`^\d{5}$`)
+	licenseStatePattern  = regexp.MustCompile(`^[A-Z]{2}$`)
+	licenseNumberPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,32}$`)
+	phoneDigitsPattern   = regexp.MustCompile(`^\+?\d{10,15}$`)
+
+	headshotExtensions = map[string]string{
+		"image/jpeg": ".jpg",
+		"image/png":  ".png",
+		"image/webp": ".webp",
+	}
+)
+
+type AgentProfileService struct {
+	userRepo repo.IUserRepo
+	store    storage.Store
+	now      func() time.Time
+}
+
+func NewAgentProfileService(userRepo repo.IUserRepo, store storage.Store) *AgentProfileService {
+	return &AgentProfileService{userRepo: userRepo, store: store, now: time.Now}
+}
+
+// optionalText trims value and turns an empty string into nil.
+func optionalText(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+// normalizeList trims, dedupes and drops empty entries, keeping order.
+func normalizeList(values []string, transform func(string) string) []string {
+	seen := make(map[string]bool, len(values))
+	normalized := make([]string, 0, len(values))
+
+	for _, value := range values {
+		value = transform(strings.TrimSpace(value))
+		if value == "" || seen[value] {
+			continue
+		}
+
+		seen[value] = true
+		normalized = append(normalized, value)
+	}
+
+	return normalized
+}
+
+func identity(value string) string {
+	return value
+}
+
+func (s *AgentProfileService) GetMyProfile(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+) (*domain.Agent, error) {
+	return s.userRepo.GetAgentById(ctx, currentAgentCtx.UserID)
+}
+
+// UpdateMyProfile applies the set fields of req on top of the agent's current
+// profile.
+func (s *AgentProfileService) UpdateMyProfile(
+	ctx context.Context,
+	req *dto.UpdateAgentProfileRequest,
+	currentAgentCtx *domain.ContextSessionData,
+) (*domain.Agent, error) {
+	agent, err := s.userRepo.GetAgentById(ctx, currentAgentCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := agent.AgentProfile
+
+	if req.Bio != nil {
+		if utf8.RuneCountInString(*req.Bio) > maxBioLength {
+			return nil, errors.New("Bio cannot be longer than 2000 characters")
+		}
+
+		profile.Bio = optionalText(*req.Bio)
+	}
+
+	if req.Phone != nil {
+		profile.Phone = optionalText(*req.Phone)
+
+		if profile.Phone != nil {
+			digits := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").
+				Replace(*profile.Phone)
+			if !phoneDigitsPattern.MatchString(digits) {
+				return nil, errors.New("Please enter a valid phone number")
+			}
+		}
+	}
+
+	if req.LicenseNumber != nil {
+		profile.LicenseNumber = optionalText(*req.LicenseNumber)
+
+		if profile.LicenseNumber != nil && !licenseNumberPattern.MatchString(*profile.LicenseNumber) {
+			return nil, errors.New("License number can only contain letters, numbers and dashes")
+		}
+	}
+
+	if req.LicenseState != nil {
+		profile.LicenseState = optionalText(strings.ToUpper(*req.LicenseState))
+
+		if profile.LicenseState != nil && !licenseStatePattern.MatchString(*profile.LicenseState) {
+			return nil, errors.New("License state must be a two letter state code")
+		}
+	}
+
+	if req.BrokerageName != nil {
+		if utf8.RuneCountInString(*req.BrokerageName) > maxBrokerageLength {
+			return nil, errors.New("Brokerage name cannot be longer than 100 characters")
+		}
+
+		profile.BrokerageName = optionalText(*req.BrokerageName)
+	}
+
+	if req.Languages != nil {
+		profile.Languages = normalizeList(*req.Languages, strings.ToLower)
+
+		if len(profile.Languages) > maxProfileLanguages {
+			return nil, errors.New("Please list at most 10 languages")
+		}
+	}
+
+	if req.Specialties != nil {
+		profile.Specialties = normalizeList(*req.Specialties, identity)
+
+		for _, specialty := range profile.Specialties {
+			if !domain.AgentSpecialties[specialty] {
+				return nil, fmt.Errorf("Invalid specialty %q", specialty)
+			}
+		}
+	}
+
+	if req.ServiceZipCodes != nil {
+		profile.ServiceZipCodes = normalizeList(*req.ServiceZipCodes, identity)
+
+		if len(profile.ServiceZipCodes) > maxServiceZipCodes {
+			return nil, errors.New("Please list at most 25 service area ZIP codes")
+		}
+
+		for _, zipCode := range profile.ServiceZipCodes {
+			if !zipCodePattern.MatchString(zipCode) {
+				return nil, fmt.Errorf("Invalid ZIP code %q", zipCode)
+			}
+		}
+	}
+
+	if err := s.userRepo.UpdateAgentProfile(ctx, currentAgentCtx.UserID, &profile); err != nil {
+		return nil, err
+	}
+
+	agent.AgentProfile = profile
+
+	return agent, nil
+}
+
+// UploadHeadshot stores a JPEG, PNG or WebP headshot, replacing the agent's
+// previous one. The type is sniffed from the content, not trusted from the
+// client.
+func (s *AgentProfileService) UploadHeadshot(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+	file io.Reader,
+) (*domain.Agent, error) {
+	reader := bufio.NewReaderSize(file, 512)
+
+	head, err := reader.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	extension, ok := headshotExtensions[http.DetectContentType(head)]
+	if !ok {
+		return nil, errors.New("Headshot must be a JPEG, PNG or WebP image")
+	}
+
+	key := fmt.Sprintf(
+		"headshots/%d-%d%s",
+		currentAgentCtx.UserID,
+		s.now().UnixNano(),
+		extension,
+	)
+
+	url, err := s.store.Put(ctx, key, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	previousURL, err := s.userRepo.UpdateAgentHeadshot(ctx, currentAgentCtx.UserID, url)
+	if err != nil {
+		s.store.Delete(ctx, url)
+		return nil, err
+	}
+
+	if previousURL != nil {
+		if err := s.store.Delete(ctx, *previousURL); err != nil {
+			slog.Warn(
+				"Failed to delete replaced headshot",
+				slog.String("url", *previousURL),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	return s.userRepo.GetAgentById(ctx, currentAgentCtx.UserID)
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+type fakeStore struct {
+	files   map[string][]byte
+	deleted []string
+}
+
+func (f *fakeStore) Put(ctx context.Context, key string, body io.Reader) (string, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	f.files[key] = data
+	return "/uploads/" + key, nil
+}
+
+func (f *fakeStore) Delete(ctx context.Context, url string) error {
+	f.deleted = append(f.deleted, url)
+	return nil
+}
+
+func ptr(value string) *string {
+	return &value
+}
+
+func TestUpdateMyProfile(t *testing.T) {
+	bio := "Existing bio"
+
+	tests := []struct {
+		name        string
+		req         dto.UpdateAgentProfileRequest
+		expectedErr string
+		check       func(t *testing.T, profile *domain.AgentProfile)
+	}{
+		{
+			name: "Valid update keeps unset fields and normalizes lists",
+			req: dto.UpdateAgentProfileRequest{
+				Phone:           ptr("(555) 123-4567"),
+				LicenseState:    ptr("ca"),
+				Languages:       &[]string{"English", " spanish ", "english"},
+				Specialties:     &[]string{"luxury", "first_time_buyers"},
+				ServiceZipCodes: &[]string{"94110", "94110", "94103"},
+			},
+			check: func(t *testing.T, profile *domain.AgentProfile) {
+				if profile.Bio == nil || *profile.Bio != bio {
+					t.Errorf("Expected %v, received %v", bio, profile.Bio)
+				}
+
+				if *profile.LicenseState != "CA" {
+					t.Errorf("Expected CA, received %v", *profile.LicenseState)
+				}
+
+				if len(profile.Languages) != 2 || profile.Languages[1] != "spanish" {
+					t.Errorf("Expected [english spanish], received %v", profile.Languages)
+				}
+
+				if len(profile.ServiceZipCodes) != 2 {
+					t.Errorf("Expected 2, received %v", profile.ServiceZipCodes)
+				}
+			},
+		},
+		{
+			name: "Empty string clears a field",
+			req:  dto.UpdateAgentProfileRequest{Bio: ptr("  ")},
+			check: func(t *testing.T, profile *domain.AgentProfile) {
+				if profile.Bio != nil {
+					t.Errorf("Expected nil, received %v", *profile.Bio)
+				}
+			},
+		},
+		{
+			name:        "Invalid phone",
+			req:         dto.UpdateAgentProfileRequest{Phone: ptr("12345")},
+			expectedErr: "Please enter a valid phone number",
+		},
+		{
+			name:        "Invalid license state",
+			req:         dto.UpdateAgentProfileRequest{LicenseState: ptr("California")},
+			expectedErr: "License state must be a two letter state code",
+		},
+		{
+			name:        "Unknown specialty",
+			req:         dto.UpdateAgentProfileRequest{Specialties: &[]string{"haunted_houses"}},
+			expectedErr: `Invalid specialty "haunted_houses"`,
+		},
+		{
+			name:        "Invalid ZIP code",
+			req:         dto.UpdateAgentProfileRequest{ServiceZipCodes: &[]string{"9411"}},
+			expectedErr: `Invalid ZIP code "9411"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *domain.AgentProfile
+
+			userRepo := &repo.UserRepoMock{
+				GetAgentByIdFunc: func(ctx context.Context, id int) (*domain.Agent, error) {
+					return &domain.Agent{ID: id, AgentProfile: domain.AgentProfile{Bio: &bio}}, nil
+				},
+				UpdateAgentProfileFunc: func(ctx context.Context, agentId int, profile *domain.AgentProfile) error {
+					saved = profile
+					return nil
+				},
+			}
+
+			s := NewAgentProfileService(userRepo, &fakeStore{files: map[string][]byte{}})
+
+			_, err := s.UpdateMyProfile(
+				context.Background(),
+				&tt.req,
+				&domain.ContextSessionData{UserID: 2, Role: "agent"},
+			)
+
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			tt.check(t, saved)
+		})
+	}
+}
+
+func TestUploadHeadshot(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 100)...)
+	previousURL := "/uploads/headshots/2-1.png"
+
+	tests := []struct {
+		name        string
+		file        []byte
+		expectedErr string
+	}{
+		{
+			name: "PNG replaces previous headshot",
+			file: png,
+		},
+		{
+			name:        "Non-image is rejected",
+			file:        []byte("<html><body>not an image</body></html>"),
+			expectedErr: "Headshot must be a JPEG, PNG or WebP image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{files: map[string][]byte{}}
+			var savedURL string
+
+			userRepo := &repo.UserRepoMock{
+				UpdateAgentHeadshotFunc: func(ctx context.Context, agentId int, url string) (*string, error) {
+					savedURL = url
+					return &previousURL, nil
+				},
+				GetAgentByIdFunc: func(ctx context.Context, id int) (*domain.Agent, error) {
+					return &domain.Agent{ID: id, AgentProfile: domain.AgentProfile{HeadshotURL: &savedURL}}, nil
+				},
+			}
+
+			s := NewAgentProfileService(userRepo, store)
+			s.now = func() time.Time { return time.Unix(0, 42) }
+
+			agent, err := s.UploadHeadshot(
+				context.Background(),
+				&domain.ContextSessionData{UserID: 2, Role: "agent"},
+				bytes.NewReader(tt.file),
+			)
+
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+
+				if len(store.files) != 0 {
+					t.Errorf("Expected nothing stored, received %v", store.files)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if !bytes.Equal(store.files["headshots/2-42.png"], tt.file) {
+				t.Errorf("Expected full file stored, received %v", store.files)
+			}
+
+			if *agent.HeadshotURL != "/uploads/headshots/2-42.png" {
+				t.Errorf("Expected %v, received %v", "/uploads/headshots/2-42.png", *agent.HeadshotURL)
+			}
+
+			if len(store.deleted) != 1 || store.deleted[0] != previousURL {
+				t.Errorf("Expected %v deleted, received %v", previousURL, store.deleted)
+			}
+		})
+	}
+}
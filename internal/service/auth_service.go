@@ -2,8 +2,15 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"server/internal/api/dto"
@@ -13,13 +20,37 @@ import (
 	"server/util"
 )
 
+// AuthMailer sends the welcome email to newly registered users and password
+// reset links to users who asked for one.
+type AuthMailer interface {
+	SendWelcome(ctx context.Context, user *domain.User) error
+	SendPasswordReset(ctx context.Context, user *domain.User, token string) error
+}
+
+// passwordResetTTL is how long a password reset link stays usable.
+const passwordResetTTL = time.Hour
+
 type AuthService struct {
-	userRepo repo.IUserRepo
-	session  session.ISession
+	userRepo          repo.IUserRepo
+	passwordResetRepo repo.IPasswordResetRepo
+	session           session.ISession
+	mailer            AuthMailer
+	now               func() time.Time
 }
 
-func NewAuthService(userRepo repo.IUserRepo, session session.ISession) *AuthService {
-	return &AuthService{userRepo: userRepo, session: session}
+func NewAuthService(
+	userRepo repo.IUserRepo,
+	passwordResetRepo repo.IPasswordResetRepo,
+	session session.ISession,
+	mailer AuthMailer,
+) *AuthService {
+	return &AuthService{
+		userRepo:          userRepo,
+		passwordResetRepo: passwordResetRepo,
+		session:           session,
+		mailer:            mailer,
+		now:               time.Now,
+	}
 }
 
 func (s *AuthService) Register(
@@ -59,6 +90,16 @@ func (s *AuthService) Register(
 		return nil, fmt.Errorf("Failed to create user: %v\n", err)
 	}
 
+	if s.mailer != nil {
+		if err := s.mailer.SendWelcome(ctx, newUser); err != nil {
+			slog.Warn(
+				"Failed to send welcome email",
+				slog.Int("user_id", newUser.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
 	sessionId, err := util.CreateSession(ctx, s.session, newUser)
 	if err != nil {
 		return nil, err
@@ -116,3 +157,81 @@ func (s *AuthService) Login(
 func (s *AuthService) Logout(ctx context.Context, sessionId string) error {
 	return s.session.DeleteSession(ctx, sessionId)
 }
+
+// hashResetToken is what is stored for a reset token, so a leaked table
+// cannot be used to reset anyone's password.
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RequestPasswordReset emails the user a link to choose a new password. It
+// succeeds whether or not the email belongs to an account, so the endpoint
+// cannot be used to find out who is registered.
+func (s *AuthService) RequestPasswordReset(
+	ctx context.Context,
+	req *dto.PasswordResetRequest,
+) error {
+	if req == nil || strings.TrimSpace(req.Email) == "" {
+		return newValidationError("Please enter your email")
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, strings.TrimSpace(req.Email))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return err
+	}
+	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	err = s.passwordResetRepo.CreateToken(
+		ctx,
+		user.ID,
+		hashResetToken(token),
+		s.now().Add(passwordResetTTL),
+	)
+	if err != nil {
+		return err
+	}
+
+	if s.mailer != nil {
+		if err := s.mailer.SendPasswordReset(ctx, user, token); err != nil {
+			slog.Warn(
+				"Failed to send password reset email",
+				slog.Int("user_id", user.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password using the token from a reset email. The
+// token works once and only until it expires.
+func (s *AuthService) ResetPassword(
+	ctx context.Context,
+	req *dto.ConfirmPasswordResetRequest,
+) error {
+	if req == nil || req.Token == "" || req.Password == "" {
+		return newValidationError("Please enter all fields")
+	}
+
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
+	err = s.passwordResetRepo.ResetPassword(ctx, hashResetToken(req.Token), hashedPassword)
+	if errors.Is(err, repo.ErrResetTokenInvalid) {
+		return newValidationError(err.Error())
+	}
+
+	return err
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -26,7 +27,7 @@ func TestRegister(t *testing.T) {
 			Role:      "agent",
 		}
 
-		a := NewAuthService(mockRepo, mockSession)
+		a := NewAuthService(mockRepo, &repo.PasswordResetRepoMock{}, mockSession, nil)
 
 		res, err := a.Register(ctx, req)
 		if err != nil {
@@ -73,7 +74,7 @@ func TestRegister(t *testing.T) {
 			Password:  "password",
 		}
 
-		a := NewAuthService(mockRepo, mockSession)
+		a := NewAuthService(mockRepo, &repo.PasswordResetRepoMock{}, mockSession, nil)
 
 		res, err := a.Register(ctx, req)
 		if err != nil {
@@ -148,7 +149,7 @@ func TestRegister(t *testing.T) {
 			ctx := context.Background()
 			req := vt.UserReq
 
-			a := NewAuthService(mockRepo, mockSession)
+			a := NewAuthService(mockRepo, &repo.PasswordResetRepoMock{}, mockSession, nil)
 
 			_, err := a.Register(ctx, req)
 			wantErr := "Please enter all fields"
@@ -206,7 +207,7 @@ func TestLogin(t *testing.T) {
 			ctx := context.Background()
 			req := lt.LoginReq
 
-			a := NewAuthService(mockRepo, mockSession)
+			a := NewAuthService(mockRepo, &repo.PasswordResetRepoMock{}, mockSession, nil)
 
 			_, err := a.Login(ctx, req)
 
@@ -263,3 +264,159 @@ func newMockSession() *session.SessionMock {
 		},
 	}
 }
+
+type resetMailRecorder struct {
+	tokens map[int]string
+}
+
+func (m *resetMailRecorder) SendWelcome(ctx context.Context, user *domain.User) error {
+	return nil
+}
+
+func (m *resetMailRecorder) SendPasswordReset(ctx context.Context, user *domain.User, token string) error {
+	m.tokens[user.ID] = token
+	return nil
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	now := time.Date(2025, 12, 29, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		email         string
+		expectedErr   bool
+		expectedEmail bool
+	}{
+		{
+			name:          "Registered email gets a reset link",
+			email:         "test@test.com",
+			expectedEmail: true,
+		},
+		{
+			name:  "Unknown email succeeds without sending anything",
+			email: "nobody@test.com",
+		},
+		{
+			name:        "Missing email",
+			email:       " ",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := newMockUserRepo()
+			userRepo.GetUserByEmailFunc = func(ctx context.Context, email string) (*domain.User, error) {
+				if email != "test@test.com" {
+					return nil, sql.ErrNoRows
+				}
+				return &domain.User{ID: 1, FirstName: "Bailey", Email: email}, nil
+			}
+
+			var storedHash string
+			var expiresAt time.Time
+			resetRepo := &repo.PasswordResetRepoMock{
+				CreateTokenFunc: func(ctx context.Context, userId int, tokenHash string, expires time.Time) error {
+					storedHash = tokenHash
+					expiresAt = expires
+					return nil
+				},
+			}
+
+			mailer := &resetMailRecorder{tokens: map[int]string{}}
+			a := NewAuthService(userRepo, resetRepo, newMockSession(), mailer)
+			a.now = func() time.Time { return now }
+
+			err := a.RequestPasswordReset(context.Background(), &dto.PasswordResetRequest{Email: tt.email})
+
+			if tt.expectedErr {
+				if !IsValidationError(err) {
+					t.Errorf("Expected a validation error, received %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			token, sent := mailer.tokens[1]
+			if sent != tt.expectedEmail {
+				t.Fatalf("Expected email sent %v, received %v", tt.expectedEmail, sent)
+			}
+
+			if !tt.expectedEmail {
+				return
+			}
+
+			if storedHash == token || storedHash != hashResetToken(token) {
+				t.Errorf("Expected only the token hash to be stored, received %q", storedHash)
+			}
+
+			if !expiresAt.Equal(now.Add(passwordResetTTL)) {
+				t.Errorf("Expected %v, received %v", now.Add(passwordResetTTL), expiresAt)
+			}
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         *dto.ConfirmPasswordResetRequest
+		resetErr    error
+		expectedErr string
+	}{
+		{
+			name: "Valid token sets the new password",
+			req:  &dto.ConfirmPasswordResetRequest{Token: "token", Password: "new-password"},
+		},
+		{
+			name:        "Used or expired token",
+			req:         &dto.ConfirmPasswordResetRequest{Token: "token", Password: "new-password"},
+			resetErr:    repo.ErrResetTokenInvalid,
+			expectedErr: repo.ErrResetTokenInvalid.Error(),
+		},
+		{
+			name:        "Missing password",
+			req:         &dto.ConfirmPasswordResetRequest{Token: "token"},
+			expectedErr: "Please enter all fields",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tokenHash, passwordHash string
+			resetRepo := &repo.PasswordResetRepoMock{
+				ResetPasswordFunc: func(ctx context.Context, hash string, password string) error {
+					tokenHash = hash
+					passwordHash = password
+					return tt.resetErr
+				},
+			}
+
+			a := NewAuthService(newMockUserRepo(), resetRepo, newMockSession(), nil)
+
+			err := a.ResetPassword(context.Background(), tt.req)
+
+			if tt.expectedErr != "" {
+				if !IsValidationError(err) || err.Error() != tt.expectedErr {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if tokenHash != hashResetToken(tt.req.Token) {
+				t.Errorf("Expected the token to be looked up by hash, received %q", tokenHash)
+			}
+
+			if err := util.CompareHashedPassword(passwordHash, tt.req.Password); err != nil {
+				t.Errorf("Expected the new password to be hashed, received %v", err)
+			}
+		})
+	}
+}
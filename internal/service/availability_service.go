@@ -0,0 +1,420 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+const (
+	dateFormat              = "2006-01-02"
+	clockFormat             = "15:04"
+	maxAvailabilityRules    = 50
+	defaultSlotDuration     = 30
+	slotDurationStep        = 15
+	defaultBookingDays      = 7
+	maxBookingDays          = 14
+	minBookingNotice        = 2 * time.Hour
+	maxSlotDurationMinutes  = 120
+	bookingBusyLookbackDays = 1
+)
+
+var ErrSlotUnavailable = errors.New("Selected time is no longer available")
+
+type AvailabilityService struct {
+	availabilityRepo    repo.IAvailabilityRepo
+	showingRepo         repo.IShowingRepo
+	openHouseRepo       repo.IOpenHouseRepo
+	listingRepo         repo.IListingRepo
+	notificationService *NotificationService
+	now                 func() time.Time
+}
+
+func NewAvailabilityService(
+	availabilityRepo repo.IAvailabilityRepo,
+	showingRepo repo.IShowingRepo,
+	openHouseRepo repo.IOpenHouseRepo,
+	listingRepo repo.IListingRepo,
+	notificationService *NotificationService,
+) *AvailabilityService {
+	return &AvailabilityService{
+		availabilityRepo:    availabilityRepo,
+		showingRepo:         showingRepo,
+		openHouseRepo:       openHouseRepo,
+		listingRepo:         listingRepo,
+		notificationService: notificationService,
+		now:                 time.Now,
+	}
+}
+
+func (s *AvailabilityService) GetAvailability(
+	ctx context.Context,
+	agentId int,
+) (*domain.AgentAvailability, error) {
+	return s.availabilityRepo.GetAvailability(ctx, agentId)
+}
+
+func (s *AvailabilityService) UpdateAvailability(
+	ctx context.Context,
+	req *dto.UpdateAvailabilityRequest,
+	currentAgentCtx *domain.ContextSessionData,
+) (*domain.AgentAvailability, error) {
+	if _, err := time.LoadLocation(req.Timezone); err != nil || req.Timezone == "" {
+		return nil, errors.New("Timezone must be a valid IANA name such as America/Chicago")
+	}
+
+	if len(req.Rules) > maxAvailabilityRules {
+		return nil, errors.New("Too many availability rules")
+	}
+
+	type window struct{ start, end time.Time }
+	byWeekday := make(map[int][]window)
+
+	for _, rule := range req.Rules {
+		if rule.Weekday < 0 || rule.Weekday > 6 {
+			return nil, errors.New("Weekday must be between 0 (Sunday) and 6 (Saturday)")
+		}
+
+		start, startErr := time.Parse(clockFormat, rule.StartTime)
+		end, endErr := time.Parse(clockFormat, rule.EndTime)
+		if startErr != nil || endErr != nil {
+			return nil, errors.New("Times must be in HH:MM format")
+		}
+
+		if !end.After(start) {
+			return nil, errors.New("End time must be after start time")
+		}
+
+		for _, other := range byWeekday[rule.Weekday] {
+			if start.Before(other.end) && end.After(other.start) {
+				return nil, errors.New("Availability rules cannot overlap on the same day")
+			}
+		}
+
+		byWeekday[rule.Weekday] = append(byWeekday[rule.Weekday], window{start, end})
+	}
+
+	return s.availabilityRepo.ReplaceAvailability(ctx, &domain.AgentAvailability{
+		AgentID:  currentAgentCtx.UserID,
+		Timezone: req.Timezone,
+		Rules:    req.Rules,
+	})
+}
+
+func (s *AvailabilityService) CreateBlackout(
+	ctx context.Context,
+	req *dto.CreateBlackoutRequest,
+	currentAgentCtx *domain.ContextSessionData,
+) (*domain.AvailabilityBlackout, error) {
+	start, startErr := time.Parse(dateFormat, req.StartDate)
+	end, endErr := time.Parse(dateFormat, req.EndDate)
+	if startErr != nil || endErr != nil {
+		return nil, errors.New("Dates must be in YYYY-MM-DD format")
+	}
+
+	if end.Before(start) {
+		return nil, errors.New("End date cannot be before start date")
+	}
+
+	return s.availabilityRepo.CreateBlackout(ctx, &domain.AvailabilityBlackout{
+		AgentID:   currentAgentCtx.UserID,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Reason:    req.Reason,
+	})
+}
+
+func (s *AvailabilityService) DeleteBlackout(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+	blackoutId int,
+) error {
+	return s.availabilityRepo.DeleteBlackout(ctx, currentAgentCtx.UserID, blackoutId)
+}
+
+func validateSlotDuration(minutes int) (time.Duration, error) {
+	if minutes == 0 {
+		minutes = defaultSlotDuration
+	}
+
+	if minutes < slotDurationStep ||
+		minutes > maxSlotDurationMinutes ||
+		minutes%slotDurationStep != 0 {
+		return 0, errors.New("Duration must be 15 to 120 minutes in 15 minute steps")
+	}
+
+	return time.Duration(minutes) * time.Minute, nil
+}
+
+// GetBookableSlots lists open slots for the listing's agent, starting on
+// fromDate (YYYY-MM-DD in the agent's timezone, default today) for the given
+// number of days.
+func (s *AvailabilityService) GetBookableSlots(
+	ctx context.Context,
+	listingId int,
+	fromDate string,
+	days int,
+	durationMinutes int,
+) ([]domain.BookableSlot, error) {
+	duration, err := validateSlotDuration(durationMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	if days == 0 {
+		days = defaultBookingDays
+	}
+
+	if days < 1 || days > maxBookingDays {
+		return nil, errors.New("Days must be between 1 and 14")
+	}
+
+	agentId, err := s.listingRepo.GetAgentIdByListingId(ctx, listingId)
+	if err != nil {
+		return nil, err
+	}
+
+	availability, loc, err := s.loadAvailability(ctx, agentId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	firstDay := now.In(loc)
+	if fromDate != "" {
+		if firstDay, err = time.ParseInLocation(dateFormat, fromDate, loc); err != nil {
+			return nil, errors.New("From date must be in YYYY-MM-DD format")
+		}
+	}
+
+	return s.freeSlots(ctx, availability, loc, firstDay, days, duration, now)
+}
+
+func (s *AvailabilityService) loadAvailability(
+	ctx context.Context,
+	agentId int,
+) (*domain.AgentAvailability, *time.Location, error) {
+	availability, err := s.availabilityRepo.GetAvailability(ctx, agentId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	loc, err := time.LoadLocation(availability.Timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return availability, loc, nil
+}
+
+// freeSlots loads the agent's confirmed showings and open houses around the
+// requested days and subtracts them from the weekly rules.
+func (s *AvailabilityService) freeSlots(
+	ctx context.Context,
+	availability *domain.AgentAvailability,
+	loc *time.Location,
+	firstDay time.Time,
+	days int,
+	duration time.Duration,
+	now time.Time,
+) ([]domain.BookableSlot, error) {
+	firstDay = firstDay.In(loc)
+	windowStart := time.Date(firstDay.Year(), firstDay.Month(), firstDay.Day(), 0, 0, 0, 0, loc).
+		AddDate(0, 0, -bookingBusyLookbackDays)
+	windowEnd := windowStart.AddDate(0, 0, days+2*bookingBusyLookbackDays)
+
+	showings, err := s.showingRepo.GetConfirmedShowingsByAgentId(
+		ctx,
+		availability.AgentID,
+		windowStart,
+		windowEnd,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	openHouses, err := s.openHouseRepo.GetOpenHousesByAgentId(ctx, availability.AgentID, windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	var busy []domain.BookableSlot
+	for _, showing := range showings {
+		busy = append(busy, domain.BookableSlot{
+			StartTime: *showing.ScheduledStart,
+			EndTime:   *showing.ScheduledEnd,
+		})
+	}
+
+	for _, openHouse := range openHouses {
+		if openHouse.CancelledAt == nil && openHouse.StartTime.Before(windowEnd) {
+			busy = append(busy, domain.BookableSlot{
+				StartTime: openHouse.StartTime,
+				EndTime:   openHouse.EndTime,
+			})
+		}
+	}
+
+	earliest := now.Add(minBookingNotice)
+
+	return computeSlots(availability, loc, busy, firstDay, days, duration, earliest), nil
+}
+
+// computeSlots expands the weekly rules into concrete slots for each day,
+// skipping blackout days, busy intervals and anything starting before
+// earliest. Wall-clock times are resolved per day with time.Date so rules stay
+// at the same local time across DST changes.
+func computeSlots(
+	availability *domain.AgentAvailability,
+	loc *time.Location,
+	busy []domain.BookableSlot,
+	firstDay time.Time,
+	days int,
+	duration time.Duration,
+	earliest time.Time,
+) []domain.BookableSlot {
+	slots := []domain.BookableSlot{}
+	firstDay = firstDay.In(loc)
+
+	for i := range days {
+		day := time.Date(firstDay.Year(), firstDay.Month(), firstDay.Day()+i, 0, 0, 0, 0, loc)
+		date := day.Format(dateFormat)
+
+		blackedOut := false
+		for _, blackout := range availability.Blackouts {
+			if date >= blackout.StartDate && date <= blackout.EndDate {
+				blackedOut = true
+				break
+			}
+		}
+
+		if blackedOut {
+			continue
+		}
+
+		for _, rule := range availability.Rules {
+			if rule.Weekday != int(day.Weekday()) {
+				continue
+			}
+
+			ruleStart, _ := time.Parse(clockFormat, rule.StartTime)
+			ruleEnd, _ := time.Parse(clockFormat, rule.EndTime)
+			windowStart := time.Date(
+				day.Year(), day.Month(), day.Day(),
+				ruleStart.Hour(), ruleStart.Minute(), 0, 0, loc,
+			)
+			windowEnd := time.Date(
+				day.Year(), day.Month(), day.Day(),
+				ruleEnd.Hour(), ruleEnd.Minute(), 0, 0, loc,
+			)
+
+			for start := windowStart; !start.Add(duration).After(windowEnd); start = start.Add(duration) {
+				end := start.Add(duration)
+
+				if start.Before(earliest) || overlapsAny(start, end, busy) {
+					continue
+				}
+
+				slots = append(slots, domain.BookableSlot{StartTime: start, EndTime: end})
+			}
+		}
+	}
+
+	return slots
+}
+
+func overlapsAny(start, end time.Time, busy []domain.BookableSlot) bool {
+	for _, b := range busy {
+		if start.Before(b.EndTime) && end.After(b.StartTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BookShowing confirms a showing immediately if the requested time is one of
+// the agent's open slots.
+func (s *AvailabilityService) BookShowing(
+	ctx context.Context,
+	req *dto.BookShowingRequest,
+	currentUserCtx *domain.ContextSessionData,
+	listingId int,
+) (*domain.Showing, error) {
+	duration, err := validateSlotDuration(req.DurationMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.StartTime.IsZero() {
+		return nil, errors.New("Start time is required")
+	}
+
+	agentId, err := s.listingRepo.GetAgentIdByListingId(ctx, listingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if agentId == currentUserCtx.UserID {
+		return nil, errors.New("Cannot book a showing on your own listing")
+	}
+
+	availability, loc, err := s.loadAvailability(ctx, agentId)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := s.freeSlots(ctx, availability, loc, req.StartTime, 1, duration, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	var selected *domain.BookableSlot
+	for i := range slots {
+		if slots[i].StartTime.Equal(req.StartTime) {
+			selected = &slots[i]
+			break
+		}
+	}
+
+	if selected == nil {
+		return nil, ErrSlotUnavailable
+	}
+
+	showing, err := s.showingRepo.BookShowing(ctx, &domain.Showing{
+		ListingID: listingId,
+		BuyerID:   currentUserCtx.UserID,
+		AgentID:   agentId,
+		Message:   req.Message,
+		Slots: []domain.ShowingSlot{{
+			StartTime:  selected.StartTime,
+			EndTime:    selected.EndTime,
+			ProposedBy: currentUserCtx.UserID,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.notificationService.NotifyUsers(
+		ctx,
+		map[int]bool{agentId: true},
+		listingId,
+		domain.NotificationTypeShowingBooked,
+		"A buyer booked a showing on your listing",
+	)
+	if err != nil {
+		slog.Warn(
+			"Failed to notify agent of booked showing",
+			slog.Int("showing_id", showing.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	return showing, nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+func TestComputeSlots(t *testing.T) {
+	chicago, _ := time.LoadLocation("America/Chicago")
+
+	availability := &domain.AgentAvailability{
+		AgentID:  2,
+		Timezone: "America/Chicago",
+		Rules: []domain.AvailabilityRule{
+			{Weekday: int(time.Saturday), StartTime: "10:00", EndTime: "11:00"},
+			{Weekday: int(time.Sunday), StartTime: "10:00", EndTime: "11:00"},
+			{Weekday: int(time.Monday), StartTime: "10:00", EndTime: "11:00"},
+		},
+		Blackouts: []domain.AvailabilityBlackout{
+			{StartDate: "2025-11-03", EndDate: "2025-11-03"},
+		},
+	}
+
+	// DST ends in Chicago on Sunday 2025-11-02
+	saturday := time.Date(2025, 11, 1, 0, 0, 0, 0, chicago)
+	busy := []domain.BookableSlot{
+		{
+			StartTime: time.Date(2025, 11, 1, 10, 15, 0, 0, chicago),
+			EndTime:   time.Date(2025, 11, 1, 10, 45, 0, 0, chicago),
+		},
+	}
+
+	slots := computeSlots(availability, chicago, busy, saturday, 3, 30*time.Minute, saturday)
+
+	expected := []time.Time{
+		time.Date(2025, 11, 2, 10, 0, 0, 0, chicago),
+		time.Date(2025, 11, 2, 10, 30, 0, 0, chicago),
+	}
+
+	if len(slots) != len(expected) {
+		t.Fatalf("Expected %d slots, received %d: %v", len(expected), len(slots), slots)
+	}
+
+	for i, want := range expected {
+		if !slots[i].StartTime.Equal(want) {
+			t.Errorf("Expected slot %d at %v, received %v", i, want, slots[i].StartTime)
+		}
+	}
+
+	// 10:00 CST on Sunday is 16:00 UTC, an hour later than Saturday's CDT offset
+	if slots[0].StartTime.UTC().Hour() != 16 {
+		t.Errorf("Expected 16:00 UTC after DST ends, received %v", slots[0].StartTime.UTC())
+	}
+}
+
+func TestBookShowing(t *testing.T) {
+	chicago, _ := time.LoadLocation("America/Chicago")
+	now := time.Date(2025, 11, 17, 8, 0, 0, 0, chicago)
+
+	newAvailabilityService := func(booked *bool) *AvailabilityService {
+		listingRepo := &repo.ListingRepoMock{
+			GetAgentIdByListingIdFunc: func(ctx context.Context, listingId int) (int, error) {
+				return 2, nil
+			},
+		}
+		availabilityRepo := &repo.AvailabilityRepoMock{
+			GetAvailabilityFunc: func(ctx context.Context, agentId int) (*domain.AgentAvailability, error) {
+				return &domain.AgentAvailability{
+					AgentID:  agentId,
+					Timezone: "America/Chicago",
+					Rules: []domain.AvailabilityRule{
+						{Weekday: int(time.Monday), StartTime: "13:00", EndTime: "15:00"},
+					},
+				}, nil
+			},
+		}
+		confirmedStart := time.Date(2025, 11, 17, 13, 0, 0, 0, chicago)
+		confirmedEnd := confirmedStart.Add(time.Hour)
+		showingRepo := &repo.ShowingRepoMock{
+			GetConfirmedShowingsByAgentIdFunc: func(ctx context.Context, agentId int, from time.Time, to time.Time) ([]*domain.Showing, error) {
+				return []*domain.Showing{{ScheduledStart: &confirmedStart, ScheduledEnd: &confirmedEnd}}, nil
+			},
+			BookShowingFunc: func(ctx context.Context, showing *domain.Showing) (*domain.Showing, error) {
+				*booked = true
+				showing.Status = domain.ShowingStatusConfirmed
+				return showing, nil
+			},
+		}
+		openHouseRepo := &repo.OpenHouseRepoMock{
+			GetOpenHousesByAgentIdFunc: func(ctx context.Context, agentId int, from time.Time) ([]*domain.OpenHouse, error) {
+				return nil, nil
+			},
+		}
+		notificationRepo := &repo.NotificationRepoMock{
+			CreateNotificationsFunc: storeNotifications,
+		}
+		notificationService := newTestNotificationService(notificationRepo, &repo.FavoriteRepoMock{}, nil)
+
+		s := NewAvailabilityService(availabilityRepo, showingRepo, openHouseRepo, listingRepo, notificationService)
+		s.now = func() time.Time { return now }
+
+		return s
+	}
+
+	tests := []struct {
+		name        string
+		start       time.Time
+		expectedErr error
+	}{
+		{
+			name:  "Open slot is booked",
+			start: time.Date(2025, 11, 17, 14, 0, 0, 0, chicago),
+		},
+		{
+			name:        "Slot overlapping a confirmed showing is rejected",
+			start:       time.Date(2025, 11, 17, 13, 30, 0, 0, chicago),
+			expectedErr: ErrSlotUnavailable,
+		},
+		{
+			name:        "Slot outside availability is rejected",
+			start:       time.Date(2025, 11, 17, 16, 0, 0, 0, chicago),
+			expectedErr: ErrSlotUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booked := false
+			s := newAvailabilityService(&booked)
+
+			showing, err := s.BookShowing(
+				context.Background(),
+				&dto.BookShowingRequest{StartTime: tt.start.UTC()},
+				&domain.ContextSessionData{UserID: 5, Role: "user"},
+				1,
+			)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) || booked {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if showing.Status != domain.ShowingStatusConfirmed || !showing.Slots[0].StartTime.Equal(tt.start) {
+				t.Errorf("Expected confirmed showing at %v, received %+v", tt.start, showing)
+			}
+		})
+	}
+}
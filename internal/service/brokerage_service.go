@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+const maxTeamNameLength = 100
+
+var (
+	ErrBrokerageForbidden = errors.New("You do not manage this brokerage")
+
+	hexColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+)
+
+type BrokerageService struct {
+	brokerageRepo repo.IBrokerageRepo
+	userRepo      repo.IUserRepo
+}
+
+func NewBrokerageService(
+	brokerageRepo repo.IBrokerageRepo,
+	userRepo repo.IUserRepo,
+) *BrokerageService {
+	return &BrokerageService{brokerageRepo: brokerageRepo, userRepo: userRepo}
+}
+
+// canManageBrokerage reports whether the user is an admin or a broker of
+// brokerageId.
+func canManageBrokerage(currentUserCtx *domain.ContextSessionData, brokerageId int) bool {
+	return currentUserCtx.Role == "admin" || currentUserCtx.IsBrokerOf(&brokerageId)
+}
+
+// canManageAgent reports whether the user may act on records owned by
+// agentId: the agent themselves, an admin, or a broker of the agent's
+// brokerage.
+func canManageAgent(
+	ctx context.Context,
+	userRepo repo.IUserRepo,
+	currentUserCtx *domain.ContextSessionData,
+	agentId int,
+) (bool, error) {
+	if currentUserCtx.Role == "admin" || currentUserCtx.UserID == agentId {
+		return true, nil
+	}
+
+	if currentUserCtx.Role != "broker" {
+		return false, nil
+	}
+
+	agent, err := userRepo.GetUserById(ctx, agentId)
+	if err != nil {
+		return false, err
+	}
+
+	return currentUserCtx.IsBrokerOf(agent.BrokerageID), nil
+}
+
+func validateWebURL(value *string, field string) error {
+	if value == nil {
+		return nil
+	}
+
+	parsed, err := url.Parse(*value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New(field + " must be an http or https URL")
+	}
+
+	return nil
+}
+
+func validateBrokerage(brokerage *domain.Brokerage) error {
+	if brokerage.Name == "" {
+		return errors.New("Brokerage name is required")
+	}
+
+	if utf8.RuneCountInString(brokerage.Name) > maxBrokerageLength {
+		return errors.New("Brokerage name cannot be longer than 100 characters")
+	}
+
+	if brokerage.PrimaryColor != nil && !hexColorPattern.MatchString(*brokerage.PrimaryColor) {
+		return errors.New("Primary color must be a hex color like #1A2B3C")
+	}
+
+	if err := validateWebURL(brokerage.LogoURL, "Logo URL"); err != nil {
+		return err
+	}
+
+	return validateWebURL(brokerage.WebsiteURL, "Website URL")
+}
+
+func (s *BrokerageService) CreateBrokerage(
+	ctx context.Context,
+	req *dto.CreateBrokerageRequest,
+	currentUserCtx *domain.ContextSessionData,
+) (*domain.Brokerage, error) {
+	if currentUserCtx.Role != "admin" {
+		return nil, ErrBrokerageForbidden
+	}
+
+	brokerage := &domain.Brokerage{}
+	brokerage.Name = strings.TrimSpace(req.Name)
+
+	if req.LogoURL != nil {
+		brokerage.LogoURL = optionalText(*req.LogoURL)
+	}
+
+	if req.PrimaryColor != nil {
+		brokerage.PrimaryColor = optionalText(*req.PrimaryColor)
+	}
+
+	if req.WebsiteURL != nil {
+		brokerage.WebsiteURL = optionalText(*req.WebsiteURL)
+	}
+
+	if err := validateBrokerage(brokerage); err != nil {
+		return nil, err
+	}
+
+	return s.brokerageRepo.CreateBrokerage(ctx, brokerage)
+}
+
+func (s *BrokerageService) GetBrokerageById(ctx context.Context, id int) (*domain.Brokerage, error) {
+	return s.brokerageRepo.GetBrokerageById(ctx, id)
+}
+
+// UpdateBrokerage applies the set fields of req to the brokerage's name and
+// branding.
+func (s *BrokerageService) UpdateBrokerage(
+	ctx context.Context,
+	req *dto.UpdateBrokerageRequest,
+	currentUserCtx *domain.ContextSessionData,
+	brokerageId int,
+) (*domain.Brokerage, error) {
+	if !canManageBrokerage(currentUserCtx, brokerageId) {
+		return nil, ErrBrokerageForbidden
+	}
+
+	brokerage, err := s.brokerageRepo.GetBrokerageById(ctx, brokerageId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		brokerage.Name = strings.TrimSpace(*req.Name)
+	}
+
+	if req.LogoURL != nil {
+		brokerage.LogoURL = optionalText(*req.LogoURL)
+	}
+
+	if req.PrimaryColor != nil {
+		brokerage.PrimaryColor = optionalText(*req.PrimaryColor)
+	}
+
+	if req.WebsiteURL != nil {
+		brokerage.WebsiteURL = optionalText(*req.WebsiteURL)
+	}
+
+	if err := validateBrokerage(brokerage); err != nil {
+		return nil, err
+	}
+
+	return s.brokerageRepo.UpdateBrokerage(ctx, brokerage)
+}
+
+func (s *BrokerageService) CreateTeam(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	brokerageId int,
+	name string,
+) (*domain.Team, error) {
+	if !canManageBrokerage(currentUserCtx, brokerageId) {
+		return nil, ErrBrokerageForbidden
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("Team name is required")
+	}
+
+	if utf8.RuneCountInString(name) > maxTeamNameLength {
+		return nil, errors.New("Team name cannot be longer than 100 characters")
+	}
+
+	brokerage, err := s.brokerageRepo.GetBrokerageById(ctx, brokerageId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, team := range brokerage.Teams {
+		if strings.EqualFold(team.Name, name) {
+			return nil, errors.New("A team with this name already exists")
+		}
+	}
+
+	return s.brokerageRepo.CreateTeam(ctx, &domain.Team{BrokerageID: brokerageId, Name: name})
+}
+
+// AddMember puts an agent into the brokerage, optionally on one of its teams.
+// Joining a brokerage hands its brokers control of the agent's listings and
+// leads, so only admins bring agents in; brokers can only move their own
+// members between teams.
+func (s *BrokerageService) AddMember(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	brokerageId int,
+	userId int,
+	teamId *int,
+) error {
+	if !canManageBrokerage(currentUserCtx, brokerageId) {
+		return ErrBrokerageForbidden
+	}
+
+	user, err := s.userRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if user.Role != "agent" && user.Role != "broker" {
+		return errors.New("Only agents and brokers can join a brokerage")
+	}
+
+	if currentUserCtx.Role != "admin" {
+		if user.Role == "broker" && user.ID != currentUserCtx.UserID {
+			return ErrBrokerageForbidden
+		}
+
+		if user.BrokerageID == nil || *user.BrokerageID != brokerageId {
+			return ErrBrokerageForbidden
+		}
+	}
+
+	return s.brokerageRepo.SetMembership(ctx, userId, &brokerageId, teamId)
+}
+
+func (s *BrokerageService) RemoveMember(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	brokerageId int,
+	userId int,
+) error {
+	if !canManageBrokerage(currentUserCtx, brokerageId) {
+		return ErrBrokerageForbidden
+	}
+
+	user, err := s.userRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if user.BrokerageID == nil || *user.BrokerageID != brokerageId {
+		return errors.New("User is not a member of this brokerage")
+	}
+
+	if currentUserCtx.Role != "admin" && user.Role == "broker" {
+		return ErrBrokerageForbidden
+	}
+
+	return s.brokerageRepo.SetMembership(ctx, userId, nil, nil)
+}
+
+func (s *BrokerageService) GetAgents(ctx context.Context, brokerageId int) ([]*domain.Agent, error) {
+	agents, err := s.brokerageRepo.GetBrokerageAgents(ctx, brokerageId)
+	if err != nil {
+		return nil, err
+	}
+
+	if agents == nil {
+		agents = []*domain.Agent{}
+	}
+
+	return agents, nil
+}
+
+func (s *BrokerageService) GetListings(
+	ctx context.Context,
+	brokerageId int,
+	status string,
+) ([]*domain.Listing, error) {
+	if status != "" && !domain.ListingStatuses[status] {
+		return nil, errors.New("Invalid listing status")
+	}
+
+	listings, err := s.brokerageRepo.GetBrokerageListings(ctx, brokerageId, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if listings == nil {
+		listings = []*domain.Listing{}
+	}
+
+	return listings, nil
+}
+
+func (s *BrokerageService) GetLeads(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	brokerageId int,
+	status string,
+) ([]*domain.Lead, error) {
+	if !canManageBrokerage(currentUserCtx, brokerageId) {
+		return nil, ErrBrokerageForbidden
+	}
+
+	if status != "" && !domain.LeadStatuses[status] {
+		return nil, errors.New("Invalid lead status")
+	}
+
+	leads, err := s.brokerageRepo.GetBrokerageLeads(ctx, brokerageId, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if leads == nil {
+		leads = []*domain.Lead{}
+	}
+
+	return leads, nil
+}
+
+func (s *BrokerageService) GetAnalytics(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	brokerageId int,
+) ([]*domain.BrokerageAgentStats, error) {
+	if !canManageBrokerage(currentUserCtx, brokerageId) {
+		return nil, ErrBrokerageForbidden
+	}
+
+	stats, err := s.brokerageRepo.GetBrokerageAgentStats(ctx, brokerageId)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats == nil {
+		stats = []*domain.BrokerageAgentStats{}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func TestAddMember(t *testing.T) {
+	tests := []struct {
+		name        string
+		currentUser *domain.ContextSessionData
+		target      *domain.User
+		expectedErr error
+	}{
+		{
+			name:        "Broker moves a member onto a team",
+			currentUser: &domain.ContextSessionData{UserID: 1, Role: "broker", BrokerageID: intPtr(10)},
+			target:      &domain.User{ID: 5, Role: "agent", BrokerageID: intPtr(10)},
+		},
+		{
+			name:        "Broker cannot pull in an unaffiliated agent",
+			currentUser: &domain.ContextSessionData{UserID: 1, Role: "broker", BrokerageID: intPtr(10)},
+			target:      &domain.User{ID: 5, Role: "agent"},
+			expectedErr: ErrBrokerageForbidden,
+		},
+		{
+			name:        "Admin adds an unaffiliated agent",
+			currentUser: &domain.ContextSessionData{UserID: 1, Role: "admin"},
+			target:      &domain.User{ID: 5, Role: "agent"},
+		},
+		{
+			name:        "Broker of another brokerage is forbidden",
+			currentUser: &domain.ContextSessionData{UserID: 1, Role: "broker", BrokerageID: intPtr(11)},
+			target:      &domain.User{ID: 5, Role: "agent"},
+			expectedErr: ErrBrokerageForbidden,
+		},
+		{
+			name:        "Broker cannot take an agent from another brokerage",
+			currentUser: &domain.ContextSessionData{UserID: 1, Role: "broker", BrokerageID: intPtr(10)},
+			target:      &domain.User{ID: 5, Role: "agent", BrokerageID: intPtr(11)},
+			expectedErr: ErrBrokerageForbidden,
+		},
+		{
+			name:        "Broker cannot add another broker",
+			currentUser: &domain.ContextSessionData{UserID: 1, Role: "broker", BrokerageID: intPtr(10)},
+			target:      &domain.User{ID: 6, Role: "broker"},
+			expectedErr: ErrBrokerageForbidden,
+		},
+		{
+			name:        "Admin moves an agent between brokerages",
+			currentUser: &domain.ContextSessionData{UserID: 1, Role: "admin"},
+			target:      &domain.User{ID: 5, Role: "agent", BrokerageID: intPtr(11)},
+		},
+		{
+			name:        "Buyers cannot join a brokerage",
+			currentUser: &domain.ContextSessionData{UserID: 1, Role: "admin"},
+			target:      &domain.User{ID: 7, Role: "user"},
+			expectedErr: errors.New("Only agents and brokers can join a brokerage"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var member int
+
+			brokerageRepo := &repo.BrokerageRepoMock{
+				SetMembershipFunc: func(ctx context.Context, userId int, brokerageId *int, teamId *int) error {
+					member = userId
+					return nil
+				},
+			}
+			userRepo := &repo.UserRepoMock{
+				GetUserByIdFunc: func(ctx context.Context, id int) (*domain.User, error) {
+					return tt.target, nil
+				},
+			}
+
+			s := NewBrokerageService(brokerageRepo, userRepo)
+
+			err := s.AddMember(context.Background(), tt.currentUser, 10, tt.target.ID, nil)
+
+			if tt.expectedErr != nil {
+				if err == nil || err.Error() != tt.expectedErr.Error() {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if member != tt.target.ID {
+				t.Errorf("Expected %d, received %d", tt.target.ID, member)
+			}
+		})
+	}
+}
+
+func TestUpdateBrokerage(t *testing.T) {
+	tests := []struct {
+		name        string
+		currentUser *domain.ContextSessionData
+		req         *dto.UpdateBrokerageRequest
+		expectedErr error
+	}{
+		{
+			name:        "Broker updates branding",
+			currentUser: &domain.ContextSessionData{UserID: 1, Role: "broker", BrokerageID: intPtr(10)},
+			req:         &dto.UpdateBrokerageRequest{PrimaryColor: ptr("#1A2B3C")},
+		},
+		{
+			name:        "Agent cannot update brokerage",
+			currentUser: &domain.ContextSessionData{UserID: 2, Role: "agent", BrokerageID: intPtr(10)},
+			req:         &dto.UpdateBrokerageRequest{PrimaryColor: ptr("#1A2B3C")},
+			expectedErr: ErrBrokerageForbidden,
+		},
+		{
+			name:        "Invalid color",
+			currentUser: &domain.ContextSessionData{UserID: 1, Role: "admin"},
+			req:         &dto.UpdateBrokerageRequest{PrimaryColor: ptr("blue")},
+			expectedErr: errors.New("Primary color must be a hex color like #1A2B3C"),
+		},
+		{
+			name:        "Logo must be a web URL",
+			currentUser: &domain.ContextSessionData{UserID: 1, Role: "admin"},
+			req:         &dto.UpdateBrokerageRequest{LogoURL: ptr("javascript:alert(1)")},
+			expectedErr: errors.New("Logo URL must be an http or https URL"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			brokerageRepo := &repo.BrokerageRepoMock{
+				GetBrokerageByIdFunc: func(ctx context.Context, id int) (*domain.Brokerage, error) {
+					brokerage := &domain.Brokerage{}
+					brokerage.ID = id
+					brokerage.Name = "Acme Realty"
+					return brokerage, nil
+				},
+				UpdateBrokerageFunc: func(ctx context.Context, brokerage *domain.Brokerage) (*domain.Brokerage, error) {
+					return brokerage, nil
+				},
+			}
+
+			s := NewBrokerageService(brokerageRepo, &repo.UserRepoMock{})
+
+			brokerage, err := s.UpdateBrokerage(context.Background(), tt.req, tt.currentUser, 10)
+
+			if tt.expectedErr != nil {
+				if err == nil || err.Error() != tt.expectedErr.Error() {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if brokerage.Name != "Acme Realty" || brokerage.PrimaryColor == nil {
+				t.Errorf("Expected branding update, received %+v", brokerage)
+			}
+		})
+	}
+}
+
+func TestGetLeadByIdAsBroker(t *testing.T) {
+	leadRepo := &repo.LeadRepoMock{
+		GetLeadByIdFunc: func(ctx context.Context, id int) (*domain.Lead, error) {
+			return &domain.Lead{ID: id, AgentID: 2}, nil
+		},
+	}
+	userRepo := &repo.UserRepoMock{
+		GetUserByIdFunc: func(ctx context.Context, id int) (*domain.User, error) {
+			return &domain.User{ID: id, Role: "agent", BrokerageID: intPtr(10)}, nil
+		},
+	}
+
+	s := NewLeadService(leadRepo, &repo.ListingRepoMock{}, userRepo, nil, nil)
+	ctx := context.Background()
+
+	broker := &domain.ContextSessionData{UserID: 1, Role: "broker", BrokerageID: intPtr(10)}
+	if _, err := s.GetLeadById(ctx, broker, 1); err != nil {
+		t.Errorf("Expected success, received %v", err)
+	}
+
+	otherBroker := &domain.ContextSessionData{UserID: 3, Role: "broker", BrokerageID: intPtr(11)}
+	if _, err := s.GetLeadById(ctx, otherBroker, 1); !errors.Is(err, ErrLeadForbidden) {
+		t.Errorf("Expected %v, received %v", ErrLeadForbidden, err)
+	}
+
+	agent := &domain.ContextSessionData{UserID: 4, Role: "agent", BrokerageID: intPtr(10)}
+	if _, err := s.GetLeadById(ctx, agent, 1); !errors.Is(err, ErrLeadForbidden) {
+		t.Errorf("Expected %v, received %v", ErrLeadForbidden, err)
+	}
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"server/internal/domain"
+	"server/internal/mail"
+	"server/internal/repo"
+)
+
+// EmailService renders notification and transactional emails and hands them
+// to the mailer, which is expected to queue them rather than send inline.
+type EmailService struct {
+	mailer         mail.Mailer
+	userRepo       repo.IUserRepo
+	listingRepo    repo.IListingRepo
+	preferenceRepo repo.INotificationPreferenceRepo
+	appURL         string
+	now            func() time.Time
+}
+
+// digestHour is the local hour daily digests go out; weekly digests go out
+// at the same hour on Mondays.
+const digestHour = 8
+
+func NewEmailService(
+	mailer mail.Mailer,
+	userRepo repo.IUserRepo,
+	listingRepo repo.IListingRepo,
+	preferenceRepo repo.INotificationPreferenceRepo,
+	appURL string,
+) *EmailService {
+	return &EmailService{
+		mailer:         mailer,
+		userRepo:       userRepo,
+		listingRepo:    listingRepo,
+		preferenceRepo: preferenceRepo,
+		appURL:         strings.TrimSuffix(appURL, "/"),
+		now:            time.Now,
+	}
+}
+
+func (s *EmailService) listingURL(listingId int) string {
+	return fmt.Sprintf("%s/listings/%d", s.appURL, listingId)
+}
+
+func (s *EmailService) send(ctx context.Context, template string, to string, data any) error {
+	msg, err := mail.Render(template, to, data)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, msg)
+}
+
+// Send delivers a notification over the email channel with the listing it
+// refers to filled in.
+func (s *EmailService) Send(ctx context.Context, notification *domain.Notification) error {
+	user, err := s.userRepo.GetUserById(ctx, notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	data := mail.NotificationData{Name: user.FirstName, Message: notification.Message}
+
+	if notification.ListingID != 0 {
+		listing, err := s.listingRepo.GetListingById(ctx, notification.ListingID)
+		if err != nil {
+			return err
+		}
+
+		data.Listing = listing
+		data.ListingURL = s.listingURL(listing.ID)
+	}
+
+	return s.send(ctx, mail.TemplateNotification, user.Email, data)
+}
+
+func (s *EmailService) SendWelcome(ctx context.Context, user *domain.User) error {
+	return s.send(ctx, mail.TemplateWelcome, user.Email, mail.WelcomeData{
+		Name:   user.FirstName,
+		AppURL: s.appURL,
+	})
+}
+
+// SendPasswordReset emails a link carrying the reset token to the user.
+func (s *EmailService) SendPasswordReset(ctx context.Context, user *domain.User, token string) error {
+	return s.send(ctx, mail.TemplatePasswordReset, user.Email, mail.PasswordResetData{
+		Name:     user.FirstName,
+		ResetURL: s.appURL + "/reset-password?token=" + url.QueryEscape(token),
+	})
+}
+
+// SendShowingConfirmed emails the buyer the confirmed time in their own
+// timezone along with the listing and agent details.
+func (s *EmailService) SendShowingConfirmed(ctx context.Context, showing *domain.Showing) error {
+	if showing.ScheduledStart == nil || showing.ScheduledEnd == nil {
+		return fmt.Errorf("Showing %d has not been scheduled", showing.ID)
+	}
+
+	buyer, err := s.userRepo.GetUserById(ctx, showing.BuyerID)
+	if err != nil {
+		return err
+	}
+
+	listing, err := s.listingRepo.GetListingById(ctx, showing.ListingID)
+	if err != nil {
+		return err
+	}
+
+	location := time.UTC
+	deliveries, err := s.preferenceRepo.GetDeliveries(
+		ctx,
+		[]int{buyer.ID},
+		domain.NotificationTypeShowingConfirmed,
+	)
+	if err != nil {
+		return err
+	}
+
+	if delivery, ok := deliveries[buyer.ID]; ok {
+		if loc, err := time.LoadLocation(delivery.Timezone); err == nil {
+			location = loc
+		}
+	}
+
+	return s.send(ctx, mail.TemplateShowingConfirmed, buyer.Email, mail.ShowingConfirmedData{
+		Name:       buyer.FirstName,
+		Listing:    listing,
+		ListingURL: s.listingURL(listing.ID),
+		Start:      showing.ScheduledStart.In(location),
+		End:        showing.ScheduledEnd.In(location),
+	})
+}
+
+// digestPeriodStart returns the most recent digest send time at or before now
+// in the subscriber's timezone.
+func digestPeriodStart(subscriber domain.DigestSubscriber, now time.Time) time.Time {
+	loc, err := time.LoadLocation(subscriber.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), digestHour, 0, 0, 0, loc)
+
+	if subscriber.Digest == domain.NotificationDigestWeekly {
+		daysSinceMonday := (int(local.Weekday()) + 6) % 7
+		start = start.AddDate(0, 0, -daysSinceMonday)
+		if start.After(local) {
+			start = start.AddDate(0, 0, -7)
+		}
+
+		return start
+	}
+
+	if start.After(local) {
+		start = start.AddDate(0, 0, -1)
+	}
+
+	return start
+}
+
+// SendDigests emails each digest subscriber whose period has come round a
+// summary of their unread notifications of the types they get email for. It
+// runs as a scheduled job and is safe to run repeatedly: the period and the
+// notifications are claimed before the email is queued, so a digest is never
+// sent twice, and the claim is released if the email cannot be queued so the
+// next run tries again.
+func (s *EmailService) SendDigests(ctx context.Context) error {
+	subscribers, err := s.preferenceRepo.GetDigestSubscribers(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+
+	for _, subscriber := range subscribers {
+		periodStart := digestPeriodStart(subscriber, now)
+		if subscriber.LastDigestAt != nil && !subscriber.LastDigestAt.Before(periodStart) {
+			continue
+		}
+
+		if err := s.sendDigest(ctx, subscriber, periodStart); err != nil {
+			slog.Warn(
+				"Failed to send notification digest",
+				slog.Int("user_id", subscriber.UserID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s *EmailService) sendDigest(
+	ctx context.Context,
+	subscriber domain.DigestSubscriber,
+	periodStart time.Time,
+) error {
+	notifications, err := s.preferenceRepo.ClaimDigest(ctx, subscriber.UserID, periodStart)
+	if err != nil {
+		return err
+	}
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	if err := s.sendDigestEmail(ctx, subscriber, notifications); err != nil {
+		notificationIds := make([]int, len(notifications))
+		for i, notification := range notifications {
+			notificationIds[i] = notification.ID
+		}
+
+		releaseErr := s.preferenceRepo.ReleaseDigest(
+			ctx,
+			subscriber.UserID,
+			subscriber.LastDigestAt,
+			notificationIds,
+		)
+		if releaseErr != nil {
+			return errors.Join(err, fmt.Errorf("Failed to release digest: %w", releaseErr))
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *EmailService) sendDigestEmail(
+	ctx context.Context,
+	subscriber domain.DigestSubscriber,
+	notifications []*domain.Notification,
+) error {
+	user, err := s.userRepo.GetUserById(ctx, subscriber.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Newest first, with each listing placed by its latest notification
+	slices.SortFunc(notifications, func(a, b *domain.Notification) int {
+		return b.ID - a.ID
+	})
+
+	var listings []mail.DigestListing
+	groups := make(map[int]int)
+	for _, notification := range notifications {
+		i, ok := groups[notification.ListingID]
+		if !ok {
+			i = len(listings)
+			groups[notification.ListingID] = i
+			listings = append(listings, mail.DigestListing{
+				ListingURL: s.listingURL(notification.ListingID),
+			})
+
+			if listing, err := s.listingRepo.GetListingById(ctx, notification.ListingID); err == nil {
+				listings[i].Listing = listing
+			}
+		}
+
+		listings[i].Notifications = append(listings[i].Notifications, notification)
+	}
+
+	return s.send(ctx, mail.TemplateDigest, user.Email, mail.DigestData{
+		Name:     user.FirstName,
+		Period:   subscriber.Digest,
+		Count:    len(notifications),
+		Listings: listings,
+		InboxURL: s.appURL + "/notifications",
+	})
+}
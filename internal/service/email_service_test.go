@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"server/internal/domain"
+	"server/internal/mail"
+	"server/internal/repo"
+)
+
+type mailRecorder struct {
+	sent []*mail.Message
+	err  error
+}
+
+func (m *mailRecorder) Send(ctx context.Context, msg *mail.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func newTestEmailService(mailer mail.Mailer, timezone string) *EmailService {
+	userRepo := &repo.UserRepoMock{
+		GetUserByIdFunc: func(ctx context.Context, id int) (*domain.User, error) {
+			return &domain.User{ID: id, FirstName: "Ada", Email: "ada@example.com"}, nil
+		},
+	}
+	listingRepo := &repo.ListingRepoMock{
+		GetListingByIdFunc: func(ctx context.Context, id int) (*domain.Listing, error) {
+			return &domain.Listing{ID: id, Address: "12 Main St", City: "Austin", Price: 450000}, nil
+		},
+	}
+	preferenceRepo := &repo.NotificationPreferenceRepoMock{
+		GetDeliveriesFunc: func(ctx context.Context, userIds []int, notificationType string) (map[int]*domain.NotificationDelivery, error) {
+			return map[int]*domain.NotificationDelivery{
+				userIds[0]: {Channels: domain.DefaultNotificationChannels, Timezone: timezone},
+			}, nil
+		},
+	}
+
+	return NewEmailService(mailer, userRepo, listingRepo, preferenceRepo, "https://homes.example.com/")
+}
+
+func TestEmailService(t *testing.T) {
+	t.Run("Notification includes listing details", func(t *testing.T) {
+		mailer := &mailRecorder{}
+		s := newTestEmailService(mailer, "UTC")
+
+		err := s.Send(context.Background(), &domain.Notification{
+			UserID:    1,
+			ListingID: 7,
+			Type:      domain.NotificationTypePriceDrop,
+			Message:   "Price dropped",
+		})
+		if err != nil {
+			t.Fatalf("Expected success, received %v", err)
+		}
+
+		if len(mailer.sent) != 1 {
+			t.Fatalf("Expected 1 email, received %d", len(mailer.sent))
+		}
+
+		msg := mailer.sent[0]
+		if msg.To != "ada@example.com" {
+			t.Errorf("Expected %v, received %v", "ada@example.com", msg.To)
+		}
+
+		for _, want := range []string{"$450,000", "12 Main St", "https://homes.example.com/listings/7"} {
+			if !strings.Contains(msg.Text, want) {
+				t.Errorf("Expected email to contain %q, received %q", want, msg.Text)
+			}
+		}
+	})
+
+	t.Run("Showing confirmation uses the buyer's timezone", func(t *testing.T) {
+		mailer := &mailRecorder{}
+		s := newTestEmailService(mailer, "America/Chicago")
+
+		start := time.Date(2025, 11, 20, 16, 0, 0, 0, time.UTC)
+		end := start.Add(time.Hour)
+
+		err := s.SendShowingConfirmed(context.Background(), &domain.Showing{
+			ID:             3,
+			ListingID:      7,
+			BuyerID:        1,
+			ScheduledStart: &start,
+			ScheduledEnd:   &end,
+		})
+		if err != nil {
+			t.Fatalf("Expected success, received %v", err)
+		}
+
+		if len(mailer.sent) != 1 {
+			t.Fatalf("Expected 1 email, received %d", len(mailer.sent))
+		}
+
+		if want := "Thu, Nov 20, 2025 at 10:00 AM CST"; !strings.Contains(mailer.sent[0].Text, want) {
+			t.Errorf("Expected email to contain %q, received %q", want, mailer.sent[0].Text)
+		}
+	})
+
+	t.Run("Unscheduled showing is rejected", func(t *testing.T) {
+		mailer := &mailRecorder{}
+		s := newTestEmailService(mailer, "UTC")
+
+		if err := s.SendShowingConfirmed(context.Background(), &domain.Showing{ID: 3}); err == nil {
+			t.Errorf("Expected an error, received nil")
+		}
+
+		if len(mailer.sent) != 0 {
+			t.Errorf("Expected no email, received %d", len(mailer.sent))
+		}
+	})
+}
+
+func TestDigestPeriodStart(t *testing.T) {
+	chicago, _ := time.LoadLocation("America/Chicago")
+
+	tests := []struct {
+		name     string
+		digest   string
+		now      time.Time
+		expected time.Time
+	}{
+		{
+			name:     "Daily after the send hour",
+			digest:   domain.NotificationDigestDaily,
+			now:      time.Date(2025, 12, 17, 9, 30, 0, 0, chicago),
+			expected: time.Date(2025, 12, 17, 8, 0, 0, 0, chicago),
+		},
+		{
+			name:     "Daily before the send hour falls back a day",
+			digest:   domain.NotificationDigestDaily,
+			now:      time.Date(2025, 12, 17, 7, 0, 0, 0, chicago),
+			expected: time.Date(2025, 12, 16, 8, 0, 0, 0, chicago),
+		},
+		{
+			name:     "Weekly midweek starts on Monday",
+			digest:   domain.NotificationDigestWeekly,
+			now:      time.Date(2025, 12, 17, 12, 0, 0, 0, chicago),
+			expected: time.Date(2025, 12, 15, 8, 0, 0, 0, chicago),
+		},
+		{
+			name:     "Weekly early Monday falls back a week",
+			digest:   domain.NotificationDigestWeekly,
+			now:      time.Date(2025, 12, 15, 6, 0, 0, 0, chicago),
+			expected: time.Date(2025, 12, 8, 8, 0, 0, 0, chicago),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscriber := domain.DigestSubscriber{Digest: tt.digest, Timezone: "America/Chicago"}
+
+			start := digestPeriodStart(subscriber, tt.now.UTC())
+			if !start.Equal(tt.expected) {
+				t.Errorf("Expected %v, received %v", tt.expected, start)
+			}
+		})
+	}
+}
+
+func TestSendDigests(t *testing.T) {
+	now := time.Date(2025, 12, 17, 15, 0, 0, 0, time.UTC)
+	sentToday := time.Date(2025, 12, 17, 8, 5, 0, 0, time.UTC)
+
+	pending := map[int][]*domain.Notification{
+		1: {
+			{ID: 1, UserID: 1, ListingID: 7, Message: "Price dropped"},
+			{ID: 2, UserID: 1, ListingID: 9, Message: "Status changed to pending"},
+			{ID: 3, UserID: 1, ListingID: 7, Message: "Price dropped again"},
+		},
+		2: {{ID: 4, UserID: 2, ListingID: 7, Message: "Price dropped"}},
+	}
+
+	var claims []int
+	preferenceRepo := &repo.NotificationPreferenceRepoMock{
+		GetDigestSubscribersFunc: func(ctx context.Context) ([]domain.DigestSubscriber, error) {
+			return []domain.DigestSubscriber{
+				{UserID: 1, Digest: domain.NotificationDigestDaily, Timezone: "UTC"},
+				{UserID: 2, Digest: domain.NotificationDigestDaily, Timezone: "UTC", LastDigestAt: &sentToday},
+			}, nil
+		},
+		ClaimDigestFunc: func(ctx context.Context, userId int, periodStart time.Time) ([]*domain.Notification, error) {
+			claims = append(claims, userId)
+
+			// Claiming marks the notifications, so a second run finds none
+			notifications := pending[userId]
+			delete(pending, userId)
+			return notifications, nil
+		},
+	}
+
+	mailer := &mailRecorder{}
+	s := newTestEmailService(mailer, "UTC")
+	s.preferenceRepo = preferenceRepo
+	s.now = func() time.Time { return now }
+
+	for range 2 {
+		if err := s.SendDigests(context.Background()); err != nil {
+			t.Fatalf("Expected success, received %v", err)
+		}
+	}
+
+	if len(claims) != 2 || claims[0] != 1 || claims[1] != 1 {
+		t.Errorf("Expected only user 1 to be claimed, received %v", claims)
+	}
+
+	if len(mailer.sent) != 1 {
+		t.Fatalf("Expected 1 digest, received %d", len(mailer.sent))
+	}
+
+	msg := mailer.sent[0]
+	if msg.Subject != "Your daily digest: 3 updates" {
+		t.Errorf("Expected %v, received %v", "Your daily digest: 3 updates", msg.Subject)
+	}
+
+	// Listing 7 has the newest notification, so it leads with both of its updates
+	first := strings.Index(msg.Text, "Price dropped again")
+	second := strings.Index(msg.Text, "Price dropped (")
+	third := strings.Index(msg.Text, "Status changed to pending")
+	if first < 0 || second < first || third < second {
+		t.Errorf("Expected notifications grouped by listing, received %q", msg.Text)
+	}
+}
+
+func TestSendDigestReleasesClaimOnFailure(t *testing.T) {
+	now := time.Date(2025, 12, 17, 15, 0, 0, 0, time.UTC)
+	sentYesterday := time.Date(2025, 12, 16, 8, 5, 0, 0, time.UTC)
+
+	lastDigestAt := &sentYesterday
+	pending := []*domain.Notification{{ID: 1, UserID: 1, ListingID: 7, Message: "Price dropped"}}
+
+	preferenceRepo := &repo.NotificationPreferenceRepoMock{
+		GetDigestSubscribersFunc: func(ctx context.Context) ([]domain.DigestSubscriber, error) {
+			return []domain.DigestSubscriber{
+				{UserID: 1, Digest: domain.NotificationDigestDaily, Timezone: "UTC", LastDigestAt: lastDigestAt},
+			}, nil
+		},
+		ClaimDigestFunc: func(ctx context.Context, userId int, periodStart time.Time) ([]*domain.Notification, error) {
+			claimedAt := now
+			lastDigestAt = &claimedAt
+
+			notifications := pending
+			pending = nil
+			return notifications, nil
+		},
+		ReleaseDigestFunc: func(ctx context.Context, userId int, previous *time.Time, notificationIds []int) error {
+			lastDigestAt = previous
+			for _, id := range notificationIds {
+				pending = append(pending, &domain.Notification{ID: id, UserID: userId, ListingID: 7, Message: "Price dropped"})
+			}
+			return nil
+		},
+	}
+
+	mailer := &mailRecorder{err: mail.ErrQueueFull}
+	s := newTestEmailService(mailer, "UTC")
+	s.preferenceRepo = preferenceRepo
+	s.now = func() time.Time { return now }
+
+	if err := s.SendDigests(context.Background()); err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if lastDigestAt != &sentYesterday || len(pending) != 1 {
+		t.Fatalf("Expected the claim to be released, received %v and %v", lastDigestAt, pending)
+	}
+
+	// The queue has drained by the next run, which sends the digest
+	mailer.err = nil
+	if err := s.SendDigests(context.Background()); err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if len(mailer.sent) != 1 {
+		t.Errorf("Expected 1 digest, received %d", len(mailer.sent))
+	}
+}
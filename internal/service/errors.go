@@ -0,0 +1,24 @@
+package service
+
+import "errors"
+
+// ValidationError rejects a request for what it asked for rather than
+// because anything went wrong serving it, so handlers can answer 400 for it
+// and 500 for everything else.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func newValidationError(message string) error {
+	return &ValidationError{Message: message}
+}
+
+// IsValidationError reports whether err is or wraps a ValidationError.
+func IsValidationError(err error) bool {
+	var validationErr *ValidationError
+	return errors.As(err, &validationErr)
+}
@@ -2,17 +2,51 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
 
 	"server/internal/domain"
+	"server/internal/events"
 	"server/internal/repo"
 )
 
+const (
+	maxFavoriteNoteLength   = 2000
+	maxCollectionNameLength = 100
+	defaultFavoritePageSize = 20
+	maxFavoritePageSize     = 100
+)
+
+var (
+	ErrCollectionForbidden       = errors.New("Collection does not belong to you")
+	ErrListingFavoritesForbidden = errors.New("You can only see who saved your own listings")
+)
+
 type FavoriteService struct {
 	favoriteRepo repo.IFavoriteRepo
+	listingRepo  repo.IListingRepo
+	userRepo     repo.IUserRepo
+	bus          *events.Bus
 }
 
-func NewFavoriteService(favoriteRepo repo.IFavoriteRepo) *FavoriteService {
-	return &FavoriteService{favoriteRepo: favoriteRepo}
+// NewFavoriteService publishes FavoriteRemoved on bus. FavoriteAdded is
+// recorded in the outbox by the favorite repo and reaches the bus through
+// the outbox relay.
+func NewFavoriteService(
+	favoriteRepo repo.IFavoriteRepo,
+	listingRepo repo.IListingRepo,
+	userRepo repo.IUserRepo,
+	bus *events.Bus,
+) *FavoriteService {
+	return &FavoriteService{
+		favoriteRepo: favoriteRepo,
+		listingRepo:  listingRepo,
+		userRepo:     userRepo,
+		bus:          bus,
+	}
 }
 
 func (s *FavoriteService) GetUserFavorites(
@@ -31,10 +65,157 @@ func (s *FavoriteService) GetUserFavorites(
 	return favorites, nil
 }
 
+// GetFavoriteListings returns a page of the user's favorites with their
+// listings. sort is "saved" (default, newest first) or "price" (lowest
+// first); order overrides the direction with "asc" or "desc".
+func (s *FavoriteService) GetFavoriteListings(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	sort string,
+	order string,
+	limit int,
+	offset int,
+) (*domain.FavoritePage, error) {
+	if sort == "" {
+		sort = "saved"
+	}
+
+	if _, ok := repo.FavoriteSortColumns[sort]; !ok {
+		return nil, errors.New("Sort must be saved or price")
+	}
+
+	var descending bool
+	switch order {
+	case "":
+		descending = sort == "saved"
+	case "asc":
+	case "desc":
+		descending = true
+	default:
+		return nil, errors.New("Order must be asc or desc")
+	}
+
+	if limit == 0 {
+		limit = defaultFavoritePageSize
+	}
+
+	if limit < 1 || limit > maxFavoritePageSize {
+		return nil, errors.New("Limit must be between 1 and 100")
+	}
+
+	if offset < 0 {
+		return nil, errors.New("Offset cannot be negative")
+	}
+
+	favorites, err := s.favoriteRepo.GetFavoriteListings(
+		ctx,
+		userCtx.UserID,
+		sort,
+		descending,
+		limit+1,
+		offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.FavoritePage{Favorites: []*domain.Favorite{}}
+
+	if len(favorites) > limit {
+		favorites = favorites[:limit]
+		nextOffset := offset + limit
+		page.NextOffset = &nextOffset
+	}
+
+	if favorites != nil {
+		page.Favorites = favorites
+	}
+
+	return page, nil
+}
+
+// AttachFavoriteCounts fills in how many users saved each listing with a
+// single query.
+func (s *FavoriteService) AttachFavoriteCounts(
+	ctx context.Context,
+	listings ...*domain.Listing,
+) error {
+	if len(listings) == 0 {
+		return nil
+	}
+
+	listingIds := make([]int, 0, len(listings))
+	for _, listing := range listings {
+		listingIds = append(listingIds, listing.ID)
+	}
+
+	counts, err := s.favoriteRepo.GetFavoriteCounts(ctx, listingIds)
+	if err != nil {
+		return err
+	}
+
+	for _, listing := range listings {
+		count := counts[listing.ID]
+		listing.FavoriteCount = &count
+	}
+
+	return nil
+}
+
+// GetListingFavoriters shows the listing's agent (or their broker, or an
+// admin) how many users saved it and which of them opted in to being named.
+func (s *FavoriteService) GetListingFavoriters(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	listingId int,
+) (*domain.ListingFavoriters, error) {
+	agentId, err := s.listingRepo.GetAgentIdByListingId(ctx, listingId)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := canManageAgent(ctx, s.userRepo, currentUserCtx, agentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, ErrListingFavoritesForbidden
+	}
+
+	counts, err := s.favoriteRepo.GetFavoriteCounts(ctx, []int{listingId})
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.favoriteRepo.GetListingFavoriters(ctx, listingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if users == nil {
+		users = []*domain.ListingFavoriter{}
+	}
+
+	return &domain.ListingFavoriters{
+		ListingID:     listingId,
+		FavoriteCount: counts[listingId],
+		Users:         users,
+	}, nil
+}
+
 func (s *FavoriteService) CreateFavorite(
 	ctx context.Context,
 	favorite *domain.Favorite,
 ) (*domain.Favorite, error) {
+	if favorite.Note != nil {
+		if utf8.RuneCountInString(*favorite.Note) > maxFavoriteNoteLength {
+			return nil, errors.New("Note cannot be longer than 2000 characters")
+		}
+
+		favorite.Note = optionalText(*favorite.Note)
+	}
+
 	return s.favoriteRepo.CreateFavorite(ctx, favorite)
 }
 
@@ -43,7 +224,28 @@ func (s *FavoriteService) DeleteFavoriteByListingId(
 	listingId int,
 	userCtx *domain.ContextSessionData,
 ) error {
-	return s.favoriteRepo.DeleteFavoriteByListingId(ctx, listingId, userCtx)
+	if err := s.favoriteRepo.DeleteFavoriteByListingId(ctx, listingId, userCtx); err != nil {
+		return err
+	}
+
+	agentId, err := s.listingRepo.GetAgentIdByListingId(ctx, listingId)
+	if err == nil {
+		err = s.bus.Publish(ctx, events.FavoriteRemoved{
+			UserID:    userCtx.UserID,
+			ListingID: listingId,
+			AgentID:   agentId,
+		})
+	}
+
+	if err != nil {
+		slog.Warn(
+			"Failed to publish removed favorite",
+			slog.Int("listing_id", listingId),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	return nil
 }
 
 func (s *FavoriteService) GetUserFavoritesMap(
@@ -62,3 +264,229 @@ func (s *FavoriteService) GetUserFavoritesMap(
 
 	return favMap, nil
 }
+
+func (s *FavoriteService) UpdateFavoriteNote(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	listingId int,
+	note string,
+) (*domain.Favorite, error) {
+	if utf8.RuneCountInString(note) > maxFavoriteNoteLength {
+		return nil, errors.New("Note cannot be longer than 2000 characters")
+	}
+
+	return s.favoriteRepo.UpdateFavoriteNote(ctx, userCtx.UserID, listingId, optionalText(note))
+}
+
+// SetPriceAlert asks to be notified when the saved listing drops below
+// belowPrice or by more than dropPercent from its current price.
+func (s *FavoriteService) SetPriceAlert(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	listingId int,
+	belowPrice *int,
+	dropPercent *float64,
+) (*domain.Favorite, error) {
+	if belowPrice == nil && dropPercent == nil {
+		return nil, errors.New("Please enter a price or a percentage drop")
+	}
+
+	if belowPrice != nil && *belowPrice <= 0 {
+		return nil, errors.New("Alert price must be greater than 0")
+	}
+
+	if dropPercent != nil && (*dropPercent <= 0 || *dropPercent >= 100) {
+		return nil, errors.New("Percentage drop must be between 0 and 100")
+	}
+
+	return s.favoriteRepo.SetPriceAlert(ctx, userCtx.UserID, listingId, belowPrice, dropPercent)
+}
+
+func (s *FavoriteService) ClearPriceAlert(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	listingId int,
+) (*domain.Favorite, error) {
+	return s.favoriteRepo.SetPriceAlert(ctx, userCtx.UserID, listingId, nil, nil)
+}
+
+func (s *FavoriteService) GetCollections(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+) ([]*domain.FavoriteCollection, error) {
+	collections, err := s.favoriteRepo.GetCollectionsByUserId(ctx, userCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if collections == nil {
+		collections = []*domain.FavoriteCollection{}
+	}
+
+	return collections, nil
+}
+
+func (s *FavoriteService) GetCollectionById(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	collectionId int,
+) (*domain.FavoriteCollection, error) {
+	collection, err := s.favoriteRepo.GetCollectionById(ctx, collectionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection.UserID != userCtx.UserID {
+		return nil, ErrCollectionForbidden
+	}
+
+	return collection, nil
+}
+
+func validateCollectionName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return "", errors.New("Collection name is required")
+	}
+
+	if utf8.RuneCountInString(name) > maxCollectionNameLength {
+		return "", errors.New("Collection name cannot be longer than 100 characters")
+	}
+
+	// The default collection is created with this name on the first save, so
+	// another collection holding it would make saving fail
+	if strings.EqualFold(name, domain.DefaultCollectionName) {
+		return "", errors.New(`"Saved" is reserved for the default collection`)
+	}
+
+	return name, nil
+}
+
+func (s *FavoriteService) CreateCollection(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	name string,
+) (*domain.FavoriteCollection, error) {
+	name, err := validateCollectionName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.favoriteRepo.CreateCollection(ctx, &domain.FavoriteCollection{
+		UserID: userCtx.UserID,
+		Name:   name,
+	})
+}
+
+func (s *FavoriteService) RenameCollection(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	collectionId int,
+	name string,
+) (*domain.FavoriteCollection, error) {
+	name, err := validateCollectionName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetCollectionById(ctx, userCtx, collectionId); err != nil {
+		return nil, err
+	}
+
+	return s.favoriteRepo.RenameCollection(ctx, collectionId, name)
+}
+
+func (s *FavoriteService) DeleteCollection(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	collectionId int,
+) error {
+	if _, err := s.GetCollectionById(ctx, userCtx, collectionId); err != nil {
+		return err
+	}
+
+	return s.favoriteRepo.DeleteCollection(ctx, collectionId)
+}
+
+func (s *FavoriteService) AddToCollection(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	collectionId int,
+	listingId int,
+) error {
+	if _, err := s.GetCollectionById(ctx, userCtx, collectionId); err != nil {
+		return err
+	}
+
+	return s.favoriteRepo.AddToCollection(ctx, collectionId, userCtx.UserID, listingId)
+}
+
+func (s *FavoriteService) RemoveFromCollection(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	collectionId int,
+	listingId int,
+) error {
+	if _, err := s.GetCollectionById(ctx, userCtx, collectionId); err != nil {
+		return err
+	}
+
+	return s.favoriteRepo.RemoveFromCollection(ctx, collectionId, listingId)
+}
+
+// ShareCollection gives the collection an unguessable share token, keeping
+// the existing one if it is already shared.
+func (s *FavoriteService) ShareCollection(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	collectionId int,
+) (*domain.FavoriteCollection, error) {
+	collection, err := s.GetCollectionById(ctx, userCtx, collectionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection.ShareToken != nil {
+		return collection, nil
+	}
+
+	token := rand.Text()
+
+	return s.favoriteRepo.SetCollectionShareToken(ctx, collectionId, &token)
+}
+
+// UnshareCollection revokes the share token so existing links stop working.
+func (s *FavoriteService) UnshareCollection(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	collectionId int,
+) (*domain.FavoriteCollection, error) {
+	if _, err := s.GetCollectionById(ctx, userCtx, collectionId); err != nil {
+		return nil, err
+	}
+
+	return s.favoriteRepo.SetCollectionShareToken(ctx, collectionId, nil)
+}
+
+func (s *FavoriteService) GetSharedCollection(
+	ctx context.Context,
+	token string,
+) (*domain.SharedFavoriteCollection, error) {
+	collection, err := s.favoriteRepo.GetCollectionByShareToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := &domain.SharedFavoriteCollection{
+		Name:       collection.Name,
+		ListingIDs: make([]int, 0, len(collection.Favorites)),
+		UpdatedAt:  collection.UpdatedAt,
+	}
+
+	for _, favorite := range collection.Favorites {
+		shared.ListingIDs = append(shared.ListingIDs, favorite.ListingID)
+	}
+
+	return shared, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -77,7 +78,7 @@ func TestGetAllFavorites(t *testing.T) {
 				Role:      "user",
 			}
 
-			f := NewFavoriteService(mockRepo)
+			f := NewFavoriteService(mockRepo, &repo.ListingRepoMock{}, &repo.UserRepoMock{}, nil)
 
 			favorites, err := f.GetUserFavorites(ctx, userCtx)
 			if err != nil {
@@ -147,7 +148,7 @@ func TestGetFavoritesMap(t *testing.T) {
 				Role:      "user",
 			}
 
-			f := NewFavoriteService(mockRepo)
+			f := NewFavoriteService(mockRepo, &repo.ListingRepoMock{}, &repo.UserRepoMock{}, nil)
 
 			favorites, err := f.GetUserFavoritesMap(ctx, userCtx)
 			if err != nil {
@@ -160,3 +161,404 @@ func TestGetFavoritesMap(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectionOwnership(t *testing.T) {
+	mockRepo := &repo.FavoriteRepoMock{
+		GetCollectionByIdFunc: func(ctx context.Context, id int) (*domain.FavoriteCollection, error) {
+			return &domain.FavoriteCollection{ID: id, UserID: 4, Name: "Downtown condos"}, nil
+		},
+		AddToCollectionFunc: func(ctx context.Context, collectionId int, userId int, listingId int) error {
+			return nil
+		},
+		DeleteCollectionFunc: func(ctx context.Context, id int) error {
+			return nil
+		},
+	}
+
+	f := NewFavoriteService(mockRepo, &repo.ListingRepoMock{}, &repo.UserRepoMock{}, nil)
+	ctx := context.Background()
+	owner := &domain.ContextSessionData{UserID: 4, Role: "user"}
+	other := &domain.ContextSessionData{UserID: 5, Role: "user"}
+
+	if err := f.AddToCollection(ctx, other, 1, 2); !errors.Is(err, ErrCollectionForbidden) {
+		t.Errorf("Expected %v, received %v", ErrCollectionForbidden, err)
+	}
+
+	if err := f.DeleteCollection(ctx, other, 1); !errors.Is(err, ErrCollectionForbidden) {
+		t.Errorf("Expected %v, received %v", ErrCollectionForbidden, err)
+	}
+
+	if err := f.AddToCollection(ctx, owner, 1, 2); err != nil {
+		t.Errorf("Expected success, received %v", err)
+	}
+}
+
+func TestShareCollection(t *testing.T) {
+	var storedToken *string
+
+	mockRepo := &repo.FavoriteRepoMock{
+		GetCollectionByIdFunc: func(ctx context.Context, id int) (*domain.FavoriteCollection, error) {
+			return &domain.FavoriteCollection{ID: id, UserID: 4, ShareToken: storedToken}, nil
+		},
+		SetCollectionShareTokenFunc: func(ctx context.Context, id int, token *string) (*domain.FavoriteCollection, error) {
+			storedToken = token
+			return &domain.FavoriteCollection{ID: id, UserID: 4, ShareToken: token}, nil
+		},
+		GetCollectionByShareTokenFunc: func(ctx context.Context, token string) (*domain.FavoriteCollection, error) {
+			note := "Great backyard"
+			return &domain.FavoriteCollection{
+				ID:     1,
+				UserID: 4,
+				Name:   "Backup options",
+				Favorites: []domain.Favorite{
+					{ListingID: 7, Note: &note},
+					{ListingID: 9},
+				},
+			}, nil
+		},
+	}
+
+	f := NewFavoriteService(mockRepo, &repo.ListingRepoMock{}, &repo.UserRepoMock{}, nil)
+	ctx := context.Background()
+	owner := &domain.ContextSessionData{UserID: 4, Role: "user"}
+
+	collection, err := f.ShareCollection(ctx, owner, 1)
+	if err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if collection.ShareToken == nil || len(*collection.ShareToken) < 20 {
+		t.Fatalf("Expected an unguessable token, received %v", collection.ShareToken)
+	}
+
+	token := *collection.ShareToken
+
+	collection, err = f.ShareCollection(ctx, owner, 1)
+	if err != nil || *collection.ShareToken != token {
+		t.Errorf("Expected sharing twice to keep token %s, received %v", token, collection.ShareToken)
+	}
+
+	shared, err := f.GetSharedCollection(ctx, token)
+	if err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if !reflect.DeepEqual(shared.ListingIDs, []int{7, 9}) {
+		t.Errorf("Expected %v, received %v", []int{7, 9}, shared.ListingIDs)
+	}
+}
+
+func TestCreateCollection(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedErr error
+	}{
+		{name: "Valid name is trimmed", input: "  Downtown condos  "},
+		{name: "Empty name", input: "   ", expectedErr: errors.New("Collection name is required")},
+		{name: "Duplicate name", input: "Weekend tours", expectedErr: repo.ErrCollectionExists},
+		{
+			name:        "Default collection name is reserved",
+			input:       "saved",
+			expectedErr: errors.New(`"Saved" is reserved for the default collection`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &repo.FavoriteRepoMock{
+				CreateCollectionFunc: func(ctx context.Context, collection *domain.FavoriteCollection) (*domain.FavoriteCollection, error) {
+					if collection.Name == "Weekend tours" {
+						return nil, repo.ErrCollectionExists
+					}
+					return collection, nil
+				},
+			}
+
+			f := NewFavoriteService(mockRepo, &repo.ListingRepoMock{}, &repo.UserRepoMock{}, nil)
+
+			collection, err := f.CreateCollection(
+				context.Background(),
+				&domain.ContextSessionData{UserID: 4, Role: "user"},
+				tt.input,
+			)
+
+			if tt.expectedErr != nil {
+				if err == nil || err.Error() != tt.expectedErr.Error() {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if collection.Name != "Downtown condos" || collection.UserID != 4 {
+				t.Errorf("Expected trimmed collection for user 4, received %+v", collection)
+			}
+		})
+	}
+}
+
+func TestGetFavoriteListings(t *testing.T) {
+	tests := []struct {
+		name               string
+		sort               string
+		order              string
+		limit              int
+		offset             int
+		stored             int
+		expectedDescending bool
+		expectedCount      int
+		expectedNext       *int
+		expectedErr        error
+	}{
+		{
+			name:               "Defaults to newest saved first",
+			stored:             3,
+			expectedDescending: true,
+			expectedCount:      3,
+		},
+		{
+			name:          "Price sorts cheapest first",
+			sort:          "price",
+			stored:        3,
+			expectedCount: 3,
+		},
+		{
+			name:               "Price sorted most expensive first",
+			sort:               "price",
+			order:              "desc",
+			stored:             3,
+			expectedDescending: true,
+			expectedCount:      3,
+		},
+		{
+			name:               "Full page points at the next offset",
+			limit:              2,
+			offset:             4,
+			stored:             3,
+			expectedDescending: true,
+			expectedCount:      2,
+			expectedNext:       intPtr(6),
+		},
+		{
+			name:        "Unknown sort",
+			sort:        "beds",
+			expectedErr: errors.New("Sort must be saved or price"),
+		},
+		{
+			name:        "Unknown order",
+			order:       "up",
+			expectedErr: errors.New("Order must be asc or desc"),
+		},
+		{
+			name:        "Limit too large",
+			limit:       101,
+			expectedErr: errors.New("Limit must be between 1 and 100"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var descending bool
+
+			mockRepo := &repo.FavoriteRepoMock{
+				GetFavoriteListingsFunc: func(
+					ctx context.Context,
+					userId int,
+					sort string,
+					desc bool,
+					limit int,
+					offset int,
+				) ([]*domain.Favorite, error) {
+					descending = desc
+
+					var favorites []*domain.Favorite
+					for i := 0; i < tt.stored && i < limit; i++ {
+						favorites = append(favorites, &domain.Favorite{
+							UserID:  userId,
+							Listing: &domain.Listing{ID: offset + i + 1},
+						})
+					}
+					return favorites, nil
+				},
+			}
+
+			f := NewFavoriteService(mockRepo, &repo.ListingRepoMock{}, &repo.UserRepoMock{}, nil)
+
+			page, err := f.GetFavoriteListings(
+				context.Background(),
+				&domain.ContextSessionData{UserID: 4, Role: "user"},
+				tt.sort,
+				tt.order,
+				tt.limit,
+				tt.offset,
+			)
+
+			if tt.expectedErr != nil {
+				if err == nil || err.Error() != tt.expectedErr.Error() {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if descending != tt.expectedDescending {
+				t.Errorf("Expected descending %v, received %v", tt.expectedDescending, descending)
+			}
+
+			if len(page.Favorites) != tt.expectedCount {
+				t.Errorf("Expected %d favorites, received %d", tt.expectedCount, len(page.Favorites))
+			}
+
+			if !reflect.DeepEqual(page.NextOffset, tt.expectedNext) {
+				t.Errorf("Expected %v, received %v", tt.expectedNext, page.NextOffset)
+			}
+		})
+	}
+}
+
+func TestGetListingFavoriters(t *testing.T) {
+	tests := []struct {
+		name        string
+		currentUser *domain.ContextSessionData
+		expectedErr error
+	}{
+		{
+			name:        "Listing agent sees favoriters",
+			currentUser: &domain.ContextSessionData{UserID: 2, Role: "agent"},
+		},
+		{
+			name:        "Broker of the listing agent sees favoriters",
+			currentUser: &domain.ContextSessionData{UserID: 1, Role: "broker", BrokerageID: intPtr(10)},
+		},
+		{
+			name:        "Other agent is forbidden",
+			currentUser: &domain.ContextSessionData{UserID: 3, Role: "agent"},
+			expectedErr: ErrListingFavoritesForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &repo.FavoriteRepoMock{
+				GetFavoriteCountsFunc: func(ctx context.Context, listingIds []int) (map[int]int, error) {
+					return map[int]int{7: 5}, nil
+				},
+				GetListingFavoritersFunc: func(ctx context.Context, listingId int) ([]*domain.ListingFavoriter, error) {
+					return []*domain.ListingFavoriter{{UserID: 8, FirstName: "Ada"}}, nil
+				},
+			}
+			listingRepo := &repo.ListingRepoMock{
+				GetAgentIdByListingIdFunc: func(ctx context.Context, listingId int) (int, error) {
+					return 2, nil
+				},
+			}
+			userRepo := &repo.UserRepoMock{
+				GetUserByIdFunc: func(ctx context.Context, id int) (*domain.User, error) {
+					return &domain.User{ID: id, Role: "agent", BrokerageID: intPtr(10)}, nil
+				},
+			}
+
+			f := NewFavoriteService(mockRepo, listingRepo, userRepo, nil)
+
+			favoriters, err := f.GetListingFavoriters(context.Background(), tt.currentUser, 7)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if favoriters.FavoriteCount != 5 || len(favoriters.Users) != 1 {
+				t.Errorf("Expected 5 favorites and 1 named user, received %+v", favoriters)
+			}
+		})
+	}
+}
+
+func TestSetPriceAlert(t *testing.T) {
+	below := 450000
+	zero := 0
+	percent := 5.0
+	tooMuch := 100.0
+
+	tests := []struct {
+		name        string
+		belowPrice  *int
+		dropPercent *float64
+		expectedErr error
+	}{
+		{name: "Below a price", belowPrice: &below},
+		{name: "By a percentage", dropPercent: &percent},
+		{name: "Both thresholds", belowPrice: &below, dropPercent: &percent},
+		{
+			name:        "No threshold",
+			expectedErr: errors.New("Please enter a price or a percentage drop"),
+		},
+		{
+			name:        "Price must be positive",
+			belowPrice:  &zero,
+			expectedErr: errors.New("Alert price must be greater than 0"),
+		},
+		{
+			name:        "Percentage out of range",
+			dropPercent: &tooMuch,
+			expectedErr: errors.New("Percentage drop must be between 0 and 100"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &repo.FavoriteRepoMock{
+				SetPriceAlertFunc: func(
+					ctx context.Context,
+					userId int,
+					listingId int,
+					belowPrice *int,
+					dropPercent *float64,
+				) (*domain.Favorite, error) {
+					favorite := &domain.Favorite{UserID: userId, ListingID: listingId}
+					favorite.BelowPrice = belowPrice
+					favorite.DropPercent = dropPercent
+					return favorite, nil
+				},
+			}
+
+			f := NewFavoriteService(mockRepo, &repo.ListingRepoMock{}, &repo.UserRepoMock{}, nil)
+
+			favorite, err := f.SetPriceAlert(
+				context.Background(),
+				&domain.ContextSessionData{UserID: 4, Role: "user"},
+				7,
+				tt.belowPrice,
+				tt.dropPercent,
+			)
+
+			if tt.expectedErr != nil {
+				if err == nil || err.Error() != tt.expectedErr.Error() {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if favorite.BelowPrice != tt.belowPrice || favorite.DropPercent != tt.dropPercent {
+				t.Errorf("Expected thresholds to be stored, received %+v", favorite.PriceAlert)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"server/internal/domain"
+	"server/internal/events"
+	"server/internal/repo"
+)
+
+const (
+	followerBatchSize     = 500
+	followerFanOutTimeout = 5 * time.Minute
+)
+
+type FollowService struct {
+	followRepo          repo.IFollowRepo
+	userRepo            repo.IUserRepo
+	notificationService *NotificationService
+}
+
+func NewFollowService(
+	followRepo repo.IFollowRepo,
+	userRepo repo.IUserRepo,
+	notificationService *NotificationService,
+) *FollowService {
+	return &FollowService{
+		followRepo:          followRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+	}
+}
+
+func (s *FollowService) FollowAgent(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	agentId int,
+) error {
+	if agentId == currentUserCtx.UserID {
+		return errors.New("You cannot follow yourself")
+	}
+
+	if _, err := s.userRepo.GetAgentById(ctx, agentId); err != nil {
+		return err
+	}
+
+	return s.followRepo.FollowAgent(ctx, currentUserCtx.UserID, agentId)
+}
+
+func (s *FollowService) UnfollowAgent(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	agentId int,
+) error {
+	return s.followRepo.UnfollowAgent(ctx, currentUserCtx.UserID, agentId)
+}
+
+func (s *FollowService) IsFollowing(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	agentId int,
+) (bool, error) {
+	return s.followRepo.IsFollowing(ctx, currentUserCtx.UserID, agentId)
+}
+
+// OnListingCreated tells the listing agent's followers about the listing in
+// the background, so agents with large followings don't hold up the request.
+func (s *FollowService) OnListingCreated(ctx context.Context, event events.ListingCreated) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), followerFanOutTimeout)
+
+	go func() {
+		defer cancel()
+
+		if err := s.notifyFollowers(ctx, event.Listing); err != nil {
+			slog.Warn(
+				"Failed to notify followers of new listing",
+				slog.Int("listing_id", event.Listing.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}()
+
+	return nil
+}
+
+// notifyFollowers pages through the agent's followers, persisting and
+// pushing one batch at a time.
+func (s *FollowService) notifyFollowers(ctx context.Context, listing *domain.Listing) error {
+	message := fmt.Sprintf("An agent you follow listed %s", listing.Address)
+	if listing.Agent != nil {
+		message = fmt.Sprintf(
+			"%s %s listed %s",
+			listing.Agent.FirstName,
+			listing.Agent.LastName,
+			listing.Address,
+		)
+	}
+
+	afterUserId := 0
+
+	for {
+		userIds, err := s.followRepo.GetFollowerIds(
+			ctx,
+			listing.AgentID,
+			afterUserId,
+			followerBatchSize,
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(userIds) == 0 {
+			return nil
+		}
+
+		err = s.notificationService.NotifyUserBatch(
+			ctx,
+			userIds,
+			listing.ID,
+			domain.NotificationTypeAgentListed,
+			message,
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(userIds) < followerBatchSize {
+			return nil
+		}
+
+		afterUserId = userIds[len(userIds)-1]
+	}
+}
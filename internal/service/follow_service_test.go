@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+func TestFollowAgent(t *testing.T) {
+	tests := []struct {
+		name        string
+		userId      int
+		agentId     int
+		expectedErr error
+	}{
+		{
+			name:    "Buyer follows agent",
+			userId:  5,
+			agentId: 2,
+		},
+		{
+			name:        "Agent cannot follow themselves",
+			userId:      2,
+			agentId:     2,
+			expectedErr: errors.New("You cannot follow yourself"),
+		},
+		{
+			name:        "Unknown agent",
+			userId:      5,
+			agentId:     9,
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			followed := false
+
+			followRepo := &repo.FollowRepoMock{
+				FollowAgentFunc: func(ctx context.Context, userId int, agentId int) error {
+					followed = true
+					return nil
+				},
+			}
+			userRepo := &repo.UserRepoMock{
+				GetAgentByIdFunc: func(ctx context.Context, id int) (*domain.Agent, error) {
+					if id != 2 {
+						return nil, sql.ErrNoRows
+					}
+					return &domain.Agent{ID: id}, nil
+				},
+			}
+
+			s := NewFollowService(followRepo, userRepo, nil)
+
+			err := s.FollowAgent(
+				context.Background(),
+				&domain.ContextSessionData{UserID: tt.userId, Role: "user"},
+				tt.agentId,
+			)
+
+			if tt.expectedErr != nil {
+				if err == nil || err.Error() != tt.expectedErr.Error() {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+
+				if followed {
+					t.Error("Expected no follow to be stored")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if !followed {
+				t.Error("Expected follow to be stored")
+			}
+		})
+	}
+}
+
+func TestNotifyFollowersInBatches(t *testing.T) {
+	const followerCount = 2*followerBatchSize + 3
+
+	var batches [][]int
+
+	followRepo := &repo.FollowRepoMock{
+		GetFollowerIdsFunc: func(ctx context.Context, agentId int, afterUserId int, limit int) ([]int, error) {
+			var userIds []int
+			for id := afterUserId + 1; id <= followerCount && len(userIds) < limit; id++ {
+				userIds = append(userIds, id)
+			}
+			return userIds, nil
+		},
+	}
+	notificationRepo := &repo.NotificationRepoMock{
+		CreateNotificationsFunc: func(
+			ctx context.Context,
+			userIds []int,
+			listingId int,
+			notificationType string,
+			message string,
+		) ([]*domain.Notification, error) {
+			batches = append(batches, userIds)
+
+			notifications := make([]*domain.Notification, len(userIds))
+			for i, userId := range userIds {
+				notifications[i] = &domain.Notification{UserID: userId, ListingID: listingId, Type: notificationType}
+			}
+			return notifications, nil
+		},
+	}
+	pusher := &pushRecorder{pushes: map[int]string{}}
+	notificationService := newTestNotificationService(notificationRepo, &repo.FavoriteRepoMock{}, pusher)
+
+	s := NewFollowService(followRepo, &repo.UserRepoMock{}, notificationService)
+
+	listing := &domain.Listing{ID: 7, AgentID: 2, Address: "1 Main St"}
+	if err := s.notifyFollowers(context.Background(), listing); err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if len(batches) != 3 {
+		t.Fatalf("Expected 3 batches, received %d", len(batches))
+	}
+
+	if len(batches[0]) != followerBatchSize || len(batches[2]) != 3 {
+		t.Errorf("Expected batches of %d and 3, received %d and %d", followerBatchSize, len(batches[0]), len(batches[2]))
+	}
+
+	if len(pusher.pushes) != followerCount {
+		t.Errorf("Expected %d pushes, received %d", followerCount, len(pusher.pushes))
+	}
+
+	if pusher.pushes[followerCount] != domain.NotificationTypeAgentListed {
+		t.Errorf("Expected %s, received %s", domain.NotificationTypeAgentListed, pusher.pushes[followerCount])
+	}
+}
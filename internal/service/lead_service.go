@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+const (
+	inquiryWindow       = time.Hour
+	maxInquiriesPerHour = 5
+	maxInquiryLength    = 2000
+)
+
+var (
+	ErrInquirySpam      = errors.New("Inquiry rejected as spam")
+	ErrTooManyInquiries = errors.New("Too many inquiries. Please try again later")
+	ErrLeadForbidden    = errors.New("Lead is not assigned to you")
+)
+
+type LeadService struct {
+	leadRepo            repo.ILeadRepo
+	listingRepo         repo.IListingRepo
+	userRepo            repo.IUserRepo
+	notificationService *NotificationService
+	webhooks            WebhookPublisher
+	now                 func() time.Time
+}
+
+func NewLeadService(
+	leadRepo repo.ILeadRepo,
+	listingRepo repo.IListingRepo,
+	userRepo repo.IUserRepo,
+	notificationService *NotificationService,
+	webhooks WebhookPublisher,
+) *LeadService {
+	return &LeadService{
+		leadRepo:            leadRepo,
+		listingRepo:         listingRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		webhooks:            webhooks,
+		now:                 time.Now,
+	}
+}
+
+// SubmitInquiry turns a public inquiry into a lead for the listing agent.
+// currentUserCtx is nil for anonymous visitors. Honeypot hits return
+// ErrInquirySpam so the handler can answer as if the inquiry succeeded.
+func (s *LeadService) SubmitInquiry(
+	ctx context.Context,
+	req *dto.CreateInquiryRequest,
+	currentUserCtx *domain.ContextSessionData,
+	listingId int,
+	ipAddress string,
+) (*domain.Lead, error) {
+	if req.Website != "" {
+		return nil, ErrInquirySpam
+	}
+
+	name := strings.TrimSpace(req.Name)
+	message := strings.TrimSpace(req.Message)
+
+	if name == "" || message == "" {
+		return nil, errors.New("Name and message are required")
+	}
+
+	if utf8.RuneCountInString(message) > maxInquiryLength {
+		return nil, errors.New("Message cannot be longer than 2000 characters")
+	}
+
+	address, err := mail.ParseAddress(req.Email)
+	if err != nil {
+		return nil, errors.New("Please enter a valid email")
+	}
+
+	if req.PreferredContact == "" {
+		req.PreferredContact = "email"
+	}
+
+	if !domain.PreferredContactMethods[req.PreferredContact] {
+		return nil, errors.New("Preferred contact must be email, phone or text")
+	}
+
+	if req.PreferredContact != "email" && (req.Phone == nil || *req.Phone == "") {
+		return nil, errors.New("Phone is required for phone or text contact")
+	}
+
+	recent, err := s.leadRepo.CountRecentInquiries(
+		ctx,
+		ipAddress,
+		address.Address,
+		s.now().Add(-inquiryWindow),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if recent >= maxInquiriesPerHour {
+		return nil, ErrTooManyInquiries
+	}
+
+	agentId, err := s.listingRepo.GetAgentIdByListingId(ctx, listingId)
+	if err != nil {
+		return nil, err
+	}
+
+	lead := &domain.Lead{
+		ListingID:        listingId,
+		AgentID:          agentId,
+		Name:             name,
+		Email:            address.Address,
+		Phone:            req.Phone,
+		Message:          message,
+		PreferredContact: req.PreferredContact,
+		IPAddress:        ipAddress,
+	}
+
+	if currentUserCtx != nil {
+		lead.UserID = &currentUserCtx.UserID
+	}
+
+	newLead, err := s.leadRepo.CreateLead(ctx, lead)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.notificationService.NotifyUsers(
+		ctx,
+		map[int]bool{agentId: true},
+		listingId,
+		domain.NotificationTypeNewLead,
+		fmt.Sprintf("New inquiry from %s", name),
+	)
+	if err != nil {
+		slog.Warn(
+			"Failed to notify agent of new lead",
+			slog.Int("lead_id", newLead.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	publishWebhook(ctx, s.webhooks, domain.WebhookEventLeadCreated, newLead, agentId)
+
+	return newLead, nil
+}
+
+func (s *LeadService) GetMyLeads(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+	status string,
+) ([]*domain.Lead, error) {
+	if status != "" && !domain.LeadStatuses[status] {
+		return nil, errors.New("Invalid lead status")
+	}
+
+	leads, err := s.leadRepo.GetLeadsByAgentId(ctx, currentAgentCtx.UserID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if leads == nil {
+		leads = []*domain.Lead{}
+	}
+
+	return leads, nil
+}
+
+func (s *LeadService) GetLeadById(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+	leadId int,
+) (*domain.Lead, error) {
+	lead, err := s.leadRepo.GetLeadById(ctx, leadId)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := canManageAgent(ctx, s.userRepo, currentAgentCtx, lead.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, ErrLeadForbidden
+	}
+
+	return lead, nil
+}
+
+func (s *LeadService) UpdateLeadStatus(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+	leadId int,
+	status string,
+) (*domain.Lead, error) {
+	if !domain.LeadStatuses[status] {
+		return nil, errors.New("Status must be new, contacted, qualified or lost")
+	}
+
+	if _, err := s.GetLeadById(ctx, currentAgentCtx, leadId); err != nil {
+		return nil, err
+	}
+
+	return s.leadRepo.UpdateLeadStatus(ctx, leadId, status)
+}
+
+func (s *LeadService) AddLeadNote(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+	leadId int,
+	body string,
+) (*domain.LeadNote, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, errors.New("Note cannot be empty")
+	}
+
+	if _, err := s.GetLeadById(ctx, currentAgentCtx, leadId); err != nil {
+		return nil, err
+	}
+
+	return s.leadRepo.CreateLeadNote(ctx, &domain.LeadNote{
+		LeadID:   leadId,
+		AuthorID: currentAgentCtx.UserID,
+		Body:     body,
+	})
+}
+
+// AssignLead hands the lead to another agent, who is notified like a new lead.
+func (s *LeadService) AssignLead(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+	leadId int,
+	agentId int,
+) (*domain.Lead, error) {
+	if _, err := s.GetLeadById(ctx, currentAgentCtx, leadId); err != nil {
+		return nil, err
+	}
+
+	agent, err := s.userRepo.GetAgentById(ctx, agentId)
+	if err != nil {
+		return nil, errors.New("Leads can only be assigned to agents")
+	}
+
+	// Brokers can only hand leads to agents in their own brokerage
+	if currentAgentCtx.Role == "broker" &&
+		(agent.Brokerage == nil || !currentAgentCtx.IsBrokerOf(&agent.Brokerage.ID)) {
+		return nil, ErrLeadForbidden
+	}
+
+	lead, err := s.leadRepo.AssignLead(ctx, leadId, agentId, currentAgentCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.notificationService.NotifyUsers(
+		ctx,
+		map[int]bool{agentId: true},
+		lead.ListingID,
+		domain.NotificationTypeNewLead,
+		fmt.Sprintf("A lead from %s was assigned to you", lead.Name),
+	)
+	if err != nil {
+		slog.Warn(
+			"Failed to notify agent of assigned lead",
+			slog.Int("lead_id", lead.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	publishWebhook(ctx, s.webhooks, domain.WebhookEventLeadAssigned, lead, agentId)
+
+	return lead, nil
+}
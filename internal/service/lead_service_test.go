@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+func TestSubmitInquiry(t *testing.T) {
+	phone := "615-555-0100"
+
+	tests := []struct {
+		name          string
+		req           *dto.CreateInquiryRequest
+		userCtx       *domain.ContextSessionData
+		recentCount   int
+		expectedErr   error
+		expectCreated bool
+	}{
+		{
+			name: "Anonymous inquiry creates lead",
+			req: &dto.CreateInquiryRequest{
+				Name:    "Jane Buyer",
+				Email:   "jane@example.com",
+				Message: "Is this still available?",
+			},
+			expectCreated: true,
+		},
+		{
+			name: "Logged in inquiry records user",
+			req: &dto.CreateInquiryRequest{
+				Name:             "Jane Buyer",
+				Email:            "jane@example.com",
+				Phone:            &phone,
+				Message:          "Please call me",
+				PreferredContact: "phone",
+			},
+			userCtx:       &domain.ContextSessionData{UserID: 5},
+			expectCreated: true,
+		},
+		{
+			name: "Honeypot is rejected as spam",
+			req: &dto.CreateInquiryRequest{
+				Name:    "Bot",
+				Email:   "bot@example.com",
+				Message: "Buy now",
+				Website: "http://spam.example.com",
+			},
+			expectedErr: ErrInquirySpam,
+		},
+		{
+			name: "Rate limit",
+			req: &dto.CreateInquiryRequest{
+				Name:    "Jane Buyer",
+				Email:   "jane@example.com",
+				Message: "Hello again",
+			},
+			recentCount: maxInquiriesPerHour,
+			expectedErr: ErrTooManyInquiries,
+		},
+		{
+			name: "Phone contact without phone",
+			req: &dto.CreateInquiryRequest{
+				Name:             "Jane Buyer",
+				Email:            "jane@example.com",
+				Message:          "Call me",
+				PreferredContact: "text",
+			},
+			expectedErr: errors.New("Phone is required for phone or text contact"),
+		},
+		{
+			name: "Invalid email",
+			req: &dto.CreateInquiryRequest{
+				Name:    "Jane Buyer",
+				Email:   "not-an-email",
+				Message: "Hello",
+			},
+			expectedErr: errors.New("Please enter a valid email"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *domain.Lead
+			pusher := &pushRecorder{pushes: map[int]string{}}
+
+			leadRepo := &repo.LeadRepoMock{
+				CountRecentInquiriesFunc: func(ctx context.Context, ipAddress string, email string, since time.Time) (int, error) {
+					return tt.recentCount, nil
+				},
+				CreateLeadFunc: func(ctx context.Context, lead *domain.Lead) (*domain.Lead, error) {
+					created = lead
+					lead.ID = 1
+					return lead, nil
+				},
+			}
+			listingRepo := &repo.ListingRepoMock{
+				GetAgentIdByListingIdFunc: func(ctx context.Context, listingId int) (int, error) {
+					return 2, nil
+				},
+			}
+			notificationRepo := &repo.NotificationRepoMock{
+				CreateNotificationsFunc: storeNotifications,
+			}
+			notificationService := newTestNotificationService(notificationRepo, &repo.FavoriteRepoMock{}, pusher)
+
+			s := NewLeadService(leadRepo, listingRepo, &repo.UserRepoMock{}, notificationService, nil)
+
+			_, err := s.SubmitInquiry(context.Background(), tt.req, tt.userCtx, 1, "203.0.113.7")
+
+			if tt.expectedErr != nil {
+				if err == nil || err.Error() != tt.expectedErr.Error() {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+
+				if created != nil {
+					t.Error("Expected no lead to be created")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if created.AgentID != 2 || created.IPAddress != "203.0.113.7" {
+				t.Errorf("Expected lead for agent 2 from client IP, received %+v", created)
+			}
+
+			if (tt.userCtx != nil) != (created.UserID != nil) {
+				t.Errorf("Expected user id to match session, received %v", created.UserID)
+			}
+
+			if pusher.pushes[2] != domain.NotificationTypeNewLead {
+				t.Errorf("Expected agent to be notified, received %v", pusher.pushes)
+			}
+		})
+	}
+}
+
+func TestAssignLead(t *testing.T) {
+	leadRepo := &repo.LeadRepoMock{
+		GetLeadByIdFunc: func(ctx context.Context, id int) (*domain.Lead, error) {
+			return &domain.Lead{ID: id, AgentID: 2, ListingID: 1}, nil
+		},
+		AssignLeadFunc: func(ctx context.Context, leadId int, agentId int, assignedBy int) (*domain.Lead, error) {
+			return &domain.Lead{ID: leadId, AgentID: agentId, ListingID: 1}, nil
+		},
+	}
+	userRepo := &repo.UserRepoMock{
+		GetAgentByIdFunc: func(ctx context.Context, id int) (*domain.Agent, error) {
+			if id != 3 {
+				return nil, sql.ErrNoRows
+			}
+			return &domain.Agent{ID: id}, nil
+		},
+	}
+	notificationRepo := &repo.NotificationRepoMock{
+		CreateNotificationsFunc: storeNotifications,
+	}
+	notificationService := newTestNotificationService(notificationRepo, &repo.FavoriteRepoMock{}, nil)
+
+	s := NewLeadService(leadRepo, &repo.ListingRepoMock{}, userRepo, notificationService, nil)
+	ctx := context.Background()
+
+	if _, err := s.AssignLead(ctx, &domain.ContextSessionData{UserID: 4, Role: "agent"}, 1, 3); !errors.Is(err, ErrLeadForbidden) {
+		t.Errorf("Expected %v, received %v", ErrLeadForbidden, err)
+	}
+
+	if _, err := s.AssignLead(ctx, &domain.ContextSessionData{UserID: 2, Role: "agent"}, 1, 9); err == nil {
+		t.Error("Expected error assigning to non-agent, received nil")
+	}
+
+	lead, err := s.AssignLead(ctx, &domain.ContextSessionData{UserID: 2, Role: "agent"}, 1, 3)
+	if err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if lead.AgentID != 3 {
+		t.Errorf("Expected agent 3, received %d", lead.AgentID)
+	}
+}
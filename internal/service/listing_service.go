@@ -3,18 +3,27 @@ package service
 import (
 	"context"
 	"errors"
+	"log/slog"
 
 	"server/internal/api/dto"
 	"server/internal/domain"
+	"server/internal/events"
 	listingRepo "server/internal/repo"
 )
 
+// ListingService publishes ListingCreated itself. Price and status changes
+// are recorded in the outbox by the listing repo and reach the bus through
+// the outbox relay.
 type ListingService struct {
 	listingRepo listingRepo.IListingRepo
+	bus         *events.Bus
 }
 
-func NewListingService(listingRepo listingRepo.IListingRepo) *ListingService {
-	return &ListingService{listingRepo: listingRepo}
+func NewListingService(listingRepo listingRepo.IListingRepo, bus *events.Bus) *ListingService {
+	return &ListingService{
+		listingRepo: listingRepo,
+		bus:         bus,
+	}
 }
 
 func (s *ListingService) GetAllListings(ctx context.Context) ([]*domain.Listing, error) {
@@ -54,7 +63,28 @@ func (s *ListingService) CreateListing(
 	ctx context.Context,
 	listing *domain.Listing,
 ) (*domain.Listing, error) {
-	return s.listingRepo.CreateListing(ctx, listing)
+	if listing.PropertyType == "" {
+		listing.PropertyType = "single_family"
+	}
+
+	if !domain.PropertyTypes[listing.PropertyType] {
+		return nil, errors.New("Invalid property type")
+	}
+
+	newListing, err := s.listingRepo.CreateListing(ctx, listing)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.bus.Publish(ctx, events.ListingCreated{Listing: newListing}); err != nil {
+		slog.Warn(
+			"Failed to publish new listing",
+			slog.Int("listing_id", newListing.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	return newListing, nil
 }
 
 func (s *ListingService) UpdateListingById(
@@ -69,6 +99,14 @@ func (s *ListingService) UpdateListingById(
 		)
 	}
 
+	if listingReq.PropertyType != nil && !domain.PropertyTypes[*listingReq.PropertyType] {
+		return nil, errors.New("Invalid property type")
+	}
+
+	if listingReq.Status != nil && !domain.ListingStatuses[*listingReq.Status] {
+		return nil, errors.New("Invalid listing status")
+	}
+
 	return s.listingRepo.UpdateListingById(ctx, listingReq, currentUserCtx, listingId)
 }
 
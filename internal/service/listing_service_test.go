@@ -92,7 +92,7 @@ func TestGetAllListings(t *testing.T) {
 			mockRepo := tt.MockRepo
 			ctx := context.Background()
 
-			l := NewListingService(mockRepo)
+			l := NewListingService(mockRepo, nil)
 
 			listings, err := l.GetAllListings(ctx)
 			if err != nil {
@@ -188,7 +188,7 @@ func TestGetAllListingsByAgentId(t *testing.T) {
 			ctx := context.Background()
 			agentId := 1
 
-			l := NewListingService(mockRepo)
+			l := NewListingService(mockRepo, nil)
 
 			favorites, err := l.GetListingsByAgentId(ctx, agentId)
 			if err != nil {
@@ -225,7 +225,7 @@ func TestUpdateListing(t *testing.T) {
 		userCtx := &domain.ContextSessionData{SessionID: "123abc", UserID: 123, Role: "agent"}
 		ctx := context.Background()
 
-		l := NewListingService(mockListing)
+		l := NewListingService(mockListing, nil)
 		_, err := l.UpdateListingById(ctx, listingReq, userCtx, 1)
 		wantErr := "Cannot update agent on listing. Please contact admin to change agent"
 
@@ -260,7 +260,7 @@ func TestUpdateListing(t *testing.T) {
 		userCtx := &domain.ContextSessionData{SessionID: "123abc", UserID: 123, Role: "admin"}
 		ctx := context.Background()
 
-		l := NewListingService(mockListing)
+		l := NewListingService(mockListing, nil)
 		_, err := l.UpdateListingById(ctx, listingReq, userCtx, 1)
 		if err != nil {
 			t.Errorf("Expected success, received %q", err.Error())
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+type MarketService struct {
+	marketRepo   repo.IMarketRepo
+	materialized bool
+}
+
+// NewMarketService serves stats from the market_stats materialized view when
+// materialized is set. The view must then be refreshed with RefreshMarketStats.
+func NewMarketService(marketRepo repo.IMarketRepo, materialized bool) *MarketService {
+	return &MarketService{marketRepo: marketRepo, materialized: materialized}
+}
+
+func (s *MarketService) GetMarketStats(
+	ctx context.Context,
+	filter *domain.MarketFilter,
+) (*domain.MarketStats, error) {
+	if filter.PropertyType != "" && !domain.PropertyTypes[filter.PropertyType] {
+		return nil, newValidationError("Invalid property type")
+	}
+
+	var stats *domain.MarketStats
+	var err error
+
+	if s.materialized {
+		stats, err = s.marketRepo.GetMaterializedMarketStats(ctx, filter)
+	} else {
+		stats, err = s.marketRepo.GetMarketStats(ctx, filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stats.City = filter.City
+	stats.ZipCode = filter.ZipCode
+	stats.PropertyType = filter.PropertyType
+
+	stats.MedianListPrice = roundTwoPlaces(stats.MedianListPrice)
+	stats.AverageListPrice = roundTwoPlaces(stats.AverageListPrice)
+	stats.MedianPricePerSqFt = roundTwoPlaces(stats.MedianPricePerSqFt)
+	stats.MedianDaysOnMarket = roundTwoPlaces(stats.MedianDaysOnMarket)
+	stats.Trend.CurrentMonthMedianPrice = roundTwoPlaces(stats.Trend.CurrentMonthMedianPrice)
+	stats.Trend.PreviousMonthMedianPrice = roundTwoPlaces(stats.Trend.PreviousMonthMedianPrice)
+
+	if stats.Trend.PreviousMonthMedianPrice > 0 && stats.Trend.CurrentMonthMedianPrice > 0 {
+		change := (stats.Trend.CurrentMonthMedianPrice - stats.Trend.PreviousMonthMedianPrice) /
+			stats.Trend.PreviousMonthMedianPrice * 100
+		change = roundTwoPlaces(change)
+		stats.Trend.MedianPriceChangePct = &change
+	}
+
+	return stats, nil
+}
+
+func (s *MarketService) RefreshMarketStats(ctx context.Context) error {
+	return s.marketRepo.RefreshMarketStats(ctx)
+}
+
+func roundTwoPlaces(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+func TestGetMarketStats(t *testing.T) {
+	t.Run("Live stats compute month over month change", func(t *testing.T) {
+		mockRepo := &repo.MarketRepoMock{
+			GetMarketStatsFunc: func(ctx context.Context, filter *domain.MarketFilter) (*domain.MarketStats, error) {
+				return &domain.MarketStats{
+					MedianListPrice: 412500.456,
+					Trend: domain.MarketTrend{
+						CurrentMonthMedianPrice:  440000,
+						PreviousMonthMedianPrice: 400000,
+					},
+				}, nil
+			},
+		}
+
+		m := NewMarketService(mockRepo, false)
+		filter := &domain.MarketFilter{City: "Nashville"}
+
+		stats, err := m.GetMarketStats(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("Expected success, received %v", err)
+		}
+
+		if stats.MedianListPrice != 412500.46 {
+			t.Errorf("Expected median 412500.46, received %v", stats.MedianListPrice)
+		}
+
+		if stats.Trend.MedianPriceChangePct == nil || *stats.Trend.MedianPriceChangePct != 10 {
+			t.Errorf("Expected 10%% change, received %v", stats.Trend.MedianPriceChangePct)
+		}
+
+		if stats.City != "Nashville" {
+			t.Errorf("Expected city Nashville, received %s", stats.City)
+		}
+	})
+
+	t.Run("No listings last month leaves change empty", func(t *testing.T) {
+		mockRepo := &repo.MarketRepoMock{
+			GetMarketStatsFunc: func(ctx context.Context, filter *domain.MarketFilter) (*domain.MarketStats, error) {
+				return &domain.MarketStats{
+					Trend: domain.MarketTrend{CurrentMonthMedianPrice: 440000},
+				}, nil
+			},
+		}
+
+		m := NewMarketService(mockRepo, false)
+
+		stats, err := m.GetMarketStats(context.Background(), &domain.MarketFilter{})
+		if err != nil {
+			t.Fatalf("Expected success, received %v", err)
+		}
+
+		if stats.Trend.MedianPriceChangePct != nil {
+			t.Errorf("Expected nil change, received %v", *stats.Trend.MedianPriceChangePct)
+		}
+	})
+
+	t.Run("Materialized service reads from the view", func(t *testing.T) {
+		materializedCalled := false
+		mockRepo := &repo.MarketRepoMock{
+			GetMaterializedMarketStatsFunc: func(ctx context.Context, filter *domain.MarketFilter) (*domain.MarketStats, error) {
+				materializedCalled = true
+				return &domain.MarketStats{}, nil
+			},
+		}
+
+		m := NewMarketService(mockRepo, true)
+
+		if _, err := m.GetMarketStats(context.Background(), &domain.MarketFilter{}); err != nil {
+			t.Fatalf("Expected success, received %v", err)
+		}
+
+		if !materializedCalled {
+			t.Error("Expected materialized stats to be used")
+		}
+	})
+
+	t.Run("Unknown property type returns error", func(t *testing.T) {
+		m := NewMarketService(&repo.MarketRepoMock{}, false)
+
+		_, err := m.GetMarketStats(context.Background(), &domain.MarketFilter{PropertyType: "castle"})
+		if !IsValidationError(err) {
+			t.Errorf("Expected a validation error, received %v", err)
+		}
+	})
+
+	t.Run("Repository failure is not a validation error", func(t *testing.T) {
+		m := NewMarketService(&repo.MarketRepoMock{
+			GetMarketStatsFunc: func(ctx context.Context, filter *domain.MarketFilter) (*domain.MarketStats, error) {
+				return nil, errors.New("connection refused")
+			},
+		}, false)
+
+		_, err := m.GetMarketStats(context.Background(), &domain.MarketFilter{})
+		if err == nil || IsValidationError(err) {
+			t.Errorf("Expected a repository error, received %v", err)
+		}
+	})
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+const (
+	maxMessageLength       = 2000
+	defaultMessagePageSize = 50
+	maxMessagePageSize     = 100
+)
+
+var ErrConversationForbidden = errors.New("You are not a participant in this conversation")
+
+type MessageService struct {
+	messageRepo    repo.IMessageRepo
+	listingRepo    repo.IListingRepo
+	preferenceRepo repo.INotificationPreferenceRepo
+	pusher         Pusher
+}
+
+func NewMessageService(
+	messageRepo repo.IMessageRepo,
+	listingRepo repo.IListingRepo,
+	preferenceRepo repo.INotificationPreferenceRepo,
+) *MessageService {
+	return &MessageService{
+		messageRepo:    messageRepo,
+		listingRepo:    listingRepo,
+		preferenceRepo: preferenceRepo,
+	}
+}
+
+// SetPusher wires real-time delivery in after construction, since the
+// WebSocket manager is created after the services.
+func (s *MessageService) SetPusher(pusher Pusher) {
+	s.pusher = pusher
+}
+
+func (s *MessageService) push(userId int, eventType string, payload any) {
+	if s.pusher != nil {
+		s.pusher.PushToUser(userId, eventType, payload)
+	}
+}
+
+func validateMessageBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+
+	if body == "" {
+		return "", errors.New("Message cannot be empty")
+	}
+
+	if utf8.RuneCountInString(body) > maxMessageLength {
+		return "", errors.New("Message cannot be longer than 2000 characters")
+	}
+
+	return body, nil
+}
+
+// conversationFor loads the conversation and returns the other participant.
+func (s *MessageService) conversationFor(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	conversationId int,
+) (*domain.Conversation, int, error) {
+	conversation, err := s.messageRepo.GetConversationById(ctx, conversationId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch currentUserCtx.UserID {
+	case conversation.BuyerID:
+		return conversation, conversation.AgentID, nil
+	case conversation.AgentID:
+		return conversation, conversation.BuyerID, nil
+	default:
+		return nil, 0, ErrConversationForbidden
+	}
+}
+
+func (s *MessageService) send(
+	ctx context.Context,
+	conversation *domain.Conversation,
+	senderId int,
+	recipientId int,
+	body string,
+) (*domain.Message, error) {
+	message, err := s.messageRepo.CreateMessage(ctx, &domain.Message{
+		ConversationID: conversation.ID,
+		SenderID:       senderId,
+		Body:           body,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.pushEnabled(ctx, recipientId) {
+		s.push(recipientId, domain.EventNewMessage, message)
+	}
+
+	return message, nil
+}
+
+// pushEnabled reports whether the recipient wants new messages pushed. The
+// conversation itself is the inbox for messages, so no notification row is
+// written. The message is already stored when preferences cannot be read,
+// so the push falls back to the default rather than being dropped.
+func (s *MessageService) pushEnabled(ctx context.Context, userId int) bool {
+	deliveries, err := s.preferenceRepo.GetDeliveries(
+		ctx,
+		[]int{userId},
+		domain.NotificationTypeNewMessage,
+	)
+	if err != nil {
+		slog.Warn(
+			"Failed to fetch message push preference",
+			slog.Int("user_id", userId),
+			slog.String("error", err.Error()),
+		)
+		return domain.DefaultNotificationChannels.Push
+	}
+
+	if delivery, ok := deliveries[userId]; ok {
+		return delivery.Channels.Push
+	}
+
+	return domain.DefaultNotificationChannels.Push
+}
+
+// StartConversation opens (or reuses) the thread between the current user and
+// the listing's agent and sends the first message.
+func (s *MessageService) StartConversation(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	listingId int,
+	body string,
+) (*domain.Message, error) {
+	body, err := validateMessageBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	agentId, err := s.listingRepo.GetAgentIdByListingId(ctx, listingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if agentId == currentUserCtx.UserID {
+		return nil, errors.New("Cannot start a conversation on your own listing")
+	}
+
+	conversation, err := s.messageRepo.GetOrCreateConversation(
+		ctx,
+		listingId,
+		currentUserCtx.UserID,
+		agentId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.send(ctx, conversation, currentUserCtx.UserID, agentId, body)
+}
+
+func (s *MessageService) SendMessage(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	conversationId int,
+	body string,
+) (*domain.Message, error) {
+	body, err := validateMessageBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, recipientId, err := s.conversationFor(ctx, currentUserCtx, conversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.send(ctx, conversation, currentUserCtx.UserID, recipientId, body)
+}
+
+func (s *MessageService) GetConversations(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+) ([]*domain.Conversation, error) {
+	conversations, err := s.messageRepo.GetConversationsByUserId(ctx, currentUserCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if conversations == nil {
+		conversations = []*domain.Conversation{}
+	}
+
+	return conversations, nil
+}
+
+// GetMessages pages backwards through history. One extra row is fetched to
+// tell whether an older page exists.
+func (s *MessageService) GetMessages(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	conversationId int,
+	beforeId int,
+	limit int,
+) (*domain.MessagePage, error) {
+	if limit == 0 {
+		limit = defaultMessagePageSize
+	}
+
+	if limit < 1 || limit > maxMessagePageSize {
+		return nil, errors.New("Limit must be between 1 and 100")
+	}
+
+	if _, _, err := s.conversationFor(ctx, currentUserCtx, conversationId); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.messageRepo.GetMessages(ctx, conversationId, beforeId, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.MessagePage{Messages: []*domain.Message{}}
+
+	if len(messages) > limit {
+		messages = messages[:limit]
+		nextCursor := messages[limit-1].ID
+		page.NextCursor = &nextCursor
+	}
+
+	if messages != nil {
+		page.Messages = messages
+	}
+
+	return page, nil
+}
+
+// MarkConversationRead marks the other participant's messages read and sends
+// them a read receipt.
+func (s *MessageService) MarkConversationRead(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	conversationId int,
+) (*domain.ReadReceipt, error) {
+	_, senderId, err := s.conversationFor(ctx, currentUserCtx, conversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := s.messageRepo.MarkConversationRead(ctx, conversationId, currentUserCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if receipt == nil {
+		return &domain.ReadReceipt{
+			ConversationID: conversationId,
+			ReaderID:       currentUserCtx.UserID,
+		}, nil
+	}
+
+	s.push(senderId, domain.EventMessagesRead, receipt)
+
+	return receipt, nil
+}
+
+func (s *MessageService) GetUnreadCount(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+) (int, error) {
+	return s.messageRepo.GetUnreadCount(ctx, currentUserCtx.UserID)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+// newTestMessageService pushes to message recipients with the given
+// preferences.
+func newTestMessageService(
+	messageRepo *repo.MessageRepoMock,
+	deliveries map[int]*domain.NotificationDelivery,
+	pusher Pusher,
+) *MessageService {
+	s := NewMessageService(messageRepo, &repo.ListingRepoMock{}, preferenceRepoWith(deliveries))
+	s.SetPusher(pusher)
+
+	return s
+}
+
+func TestSendMessage(t *testing.T) {
+	conversation := &domain.Conversation{ID: 1, ListingID: 1, BuyerID: 5, AgentID: 2}
+
+	tests := []struct {
+		name           string
+		userId         int
+		body           string
+		deliveries     map[int]*domain.NotificationDelivery
+		expectedErr    error
+		expectedPushes map[int]string
+	}{
+		{
+			name:           "Buyer message is pushed to agent",
+			userId:         5,
+			body:           "  Is the basement finished?  ",
+			expectedPushes: map[int]string{2: domain.EventNewMessage},
+		},
+		{
+			name:           "Agent message is pushed to buyer",
+			userId:         2,
+			body:           "Yes it is",
+			expectedPushes: map[int]string{5: domain.EventNewMessage},
+		},
+		{
+			name:   "Recipient with message pushes off is not pushed",
+			userId: 5,
+			body:   "Is the basement finished?",
+			deliveries: map[int]*domain.NotificationDelivery{
+				2: {Channels: domain.NotificationChannels{InApp: true}, Timezone: "UTC"},
+			},
+			expectedPushes: map[int]string{},
+		},
+		{
+			name:        "Outsider cannot send",
+			userId:      9,
+			body:        "Hello",
+			expectedErr: ErrConversationForbidden,
+		},
+		{
+			name:        "Empty message",
+			userId:      5,
+			body:        "   ",
+			expectedErr: errors.New("Message cannot be empty"),
+		},
+		{
+			name:        "Message too long",
+			userId:      5,
+			body:        strings.Repeat("a", maxMessageLength+1),
+			expectedErr: errors.New("Message cannot be longer than 2000 characters"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pusher := &pushRecorder{pushes: map[int]string{}}
+			mockRepo := &repo.MessageRepoMock{
+				GetConversationByIdFunc: func(ctx context.Context, id int) (*domain.Conversation, error) {
+					return conversation, nil
+				},
+				CreateMessageFunc: func(ctx context.Context, message *domain.Message) (*domain.Message, error) {
+					message.ID = 10
+					return message, nil
+				},
+			}
+
+			s := newTestMessageService(mockRepo, tt.deliveries, pusher)
+
+			message, err := s.SendMessage(
+				context.Background(),
+				&domain.ContextSessionData{UserID: tt.userId},
+				1,
+				tt.body,
+			)
+
+			if tt.expectedErr != nil {
+				if err == nil || err.Error() != tt.expectedErr.Error() {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if message.Body != strings.TrimSpace(tt.body) {
+				t.Errorf("Expected trimmed body, received %q", message.Body)
+			}
+
+			if !reflect.DeepEqual(pusher.pushes, tt.expectedPushes) {
+				t.Errorf("Expected %v, received %v", tt.expectedPushes, pusher.pushes)
+			}
+		})
+	}
+}
+
+func TestGetMessages(t *testing.T) {
+	conversation := &domain.Conversation{ID: 1, BuyerID: 5, AgentID: 2}
+
+	newMockRepo := func(total int) *repo.MessageRepoMock {
+		return &repo.MessageRepoMock{
+			GetConversationByIdFunc: func(ctx context.Context, id int) (*domain.Conversation, error) {
+				return conversation, nil
+			},
+			GetMessagesFunc: func(ctx context.Context, conversationId int, beforeId int, limit int) ([]*domain.Message, error) {
+				var messages []*domain.Message
+				for id := total; id > 0 && len(messages) < limit; id-- {
+					if beforeId == 0 || id < beforeId {
+						messages = append(messages, &domain.Message{ID: id})
+					}
+				}
+				return messages, nil
+			},
+		}
+	}
+
+	t.Run("Full page returns cursor", func(t *testing.T) {
+		s := newTestMessageService(newMockRepo(5), nil, nil)
+
+		page, err := s.GetMessages(context.Background(), &domain.ContextSessionData{UserID: 5}, 1, 0, 3)
+		if err != nil {
+			t.Fatalf("Expected success, received %v", err)
+		}
+
+		if len(page.Messages) != 3 || page.NextCursor == nil || *page.NextCursor != 3 {
+			t.Errorf("Expected 3 messages and cursor 3, received %d and %v", len(page.Messages), page.NextCursor)
+		}
+
+		page, _ = s.GetMessages(context.Background(), &domain.ContextSessionData{UserID: 5}, 1, 3, 3)
+		if len(page.Messages) != 2 || page.NextCursor != nil {
+			t.Errorf("Expected last page of 2 messages, received %d and %v", len(page.Messages), page.NextCursor)
+		}
+	})
+
+	t.Run("Limit out of range", func(t *testing.T) {
+		s := newTestMessageService(newMockRepo(5), nil, nil)
+
+		_, err := s.GetMessages(context.Background(), &domain.ContextSessionData{UserID: 5}, 1, 0, 500)
+		if err == nil {
+			t.Error("Expected error, received nil")
+		}
+	})
+}
+
+func TestMarkConversationRead(t *testing.T) {
+	pusher := &pushRecorder{pushes: map[int]string{}}
+	mockRepo := &repo.MessageRepoMock{
+		GetConversationByIdFunc: func(ctx context.Context, id int) (*domain.Conversation, error) {
+			return &domain.Conversation{ID: id, BuyerID: 5, AgentID: 2}, nil
+		},
+		MarkConversationReadFunc: func(ctx context.Context, conversationId int, readerId int) (*domain.ReadReceipt, error) {
+			return &domain.ReadReceipt{ConversationID: conversationId, ReaderID: readerId, LastReadID: 7}, nil
+		},
+	}
+
+	s := newTestMessageService(mockRepo, nil, pusher)
+
+	receipt, err := s.MarkConversationRead(context.Background(), &domain.ContextSessionData{UserID: 2}, 1)
+	if err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if receipt.LastReadID != 7 {
+		t.Errorf("Expected last read 7, received %d", receipt.LastReadID)
+	}
+
+	if pusher.pushes[5] != domain.EventMessagesRead {
+		t.Errorf("Expected read receipt pushed to buyer, received %v", pusher.pushes)
+	}
+}
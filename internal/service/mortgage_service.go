@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+const (
+	defaultPropertyTaxRate = 0.011
+	defaultInsuranceRate   = 0.0035
+)
+
+var DefaultMortgageTerms = domain.MortgageTerms{
+	DownPaymentPct: 20,
+	InterestRate:   6.5,
+	TermYears:      30,
+}
+
+type MortgageService struct {
+	mortgageRepo repo.IMortgageRepo
+}
+
+func NewMortgageService(mortgageRepo repo.IMortgageRepo) *MortgageService {
+	return &MortgageService{mortgageRepo: mortgageRepo}
+}
+
+// ResolveTerms layers request overrides on top of the user's saved
+// preferences, falling back to DefaultMortgageTerms. userCtx may be nil for
+// anonymous requests.
+func (s *MortgageService) ResolveTerms(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	overrides *dto.MortgageTermsRequest,
+) (*domain.MortgageTerms, error) {
+	terms := DefaultMortgageTerms
+
+	if userCtx != nil {
+		prefs, err := s.mortgageRepo.GetMortgagePreferences(ctx, userCtx.UserID)
+		switch {
+		case err == nil:
+			terms = domain.MortgageTerms{
+				DownPaymentPct: prefs.DownPaymentPct,
+				InterestRate:   prefs.InterestRate,
+				TermYears:      prefs.TermYears,
+			}
+		case !errors.Is(err, sql.ErrNoRows):
+			return nil, err
+		}
+	}
+
+	applyTermOverrides(&terms, overrides)
+
+	if err := validateMortgageTerms(&terms); err != nil {
+		return nil, err
+	}
+
+	return &terms, nil
+}
+
+func (s *MortgageService) GetMortgagePreferences(
+	ctx context.Context,
+	userId int,
+) (*domain.MortgagePreferences, error) {
+	prefs, err := s.mortgageRepo.GetMortgagePreferences(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.MortgagePreferences{
+			UserID:         userId,
+			DownPaymentPct: DefaultMortgageTerms.DownPaymentPct,
+			InterestRate:   DefaultMortgageTerms.InterestRate,
+			TermYears:      DefaultMortgageTerms.TermYears,
+		}, nil
+	}
+
+	return prefs, err
+}
+
+func (s *MortgageService) UpdateMortgagePreferences(
+	ctx context.Context,
+	userId int,
+	req *dto.MortgageTermsRequest,
+) (*domain.MortgagePreferences, error) {
+	prefs, err := s.GetMortgagePreferences(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := domain.MortgageTerms{
+		DownPaymentPct: prefs.DownPaymentPct,
+		InterestRate:   prefs.InterestRate,
+		TermYears:      prefs.TermYears,
+	}
+	applyTermOverrides(&terms, req)
+
+	if err := validateMortgageTerms(&terms); err != nil {
+		return nil, err
+	}
+
+	return s.mortgageRepo.UpsertMortgagePreferences(ctx, &domain.MortgagePreferences{
+		UserID:         userId,
+		DownPaymentPct: terms.DownPaymentPct,
+		InterestRate:   terms.InterestRate,
+		TermYears:      terms.TermYears,
+	})
+}
+
+func (s *MortgageService) CalculateMortgage(
+	ctx context.Context,
+	userCtx *domain.ContextSessionData,
+	req *dto.MortgageCalculatorRequest,
+) (*domain.MortgageEstimate, error) {
+	if req.Price <= 0 {
+		return nil, errors.New("Price must be greater than 0")
+	}
+
+	terms, err := s.ResolveTerms(ctx, userCtx, &req.MortgageTermsRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	price := float64(req.Price)
+
+	annualTax := price * defaultPropertyTaxRate
+	if req.AnnualPropertyTax != nil {
+		annualTax = *req.AnnualPropertyTax
+	}
+
+	annualInsurance := price * defaultInsuranceRate
+	if req.AnnualInsurance != nil {
+		annualInsurance = *req.AnnualInsurance
+	}
+
+	if annualTax < 0 || annualInsurance < 0 || req.HOAMonthly < 0 {
+		return nil, errors.New("Taxes, insurance and HOA cannot be negative")
+	}
+
+	monthly := calculateMonthlyPayment(price, terms, annualTax, annualInsurance, req.HOAMonthly)
+	schedule := amortizationSchedule(monthly.LoanAmount, terms)
+
+	var totalInterest float64
+	for _, period := range schedule {
+		totalInterest += period.Interest
+	}
+
+	estimate := &domain.MortgageEstimate{
+		Price:         req.Price,
+		Monthly:       monthly,
+		TotalInterest: roundTwoPlaces(totalInterest),
+	}
+
+	if req.IncludeSchedule {
+		estimate.AmortizationTable = schedule
+	}
+
+	return estimate, nil
+}
+
+// EstimateListingPayment prices a listing with terms, using the listing's own
+// tax and HOA figures when the agent has provided them.
+func (s *MortgageService) EstimateListingPayment(
+	listing *domain.Listing,
+	terms *domain.MortgageTerms,
+) *domain.MonthlyPayment {
+	price := float64(listing.Price)
+
+	annualTax := price * defaultPropertyTaxRate
+	if listing.AnnualTax != nil {
+		annualTax = float64(*listing.AnnualTax)
+	}
+
+	payment := calculateMonthlyPayment(
+		price,
+		terms,
+		annualTax,
+		price*defaultInsuranceRate,
+		float64(listing.HOAMonthly),
+	)
+
+	return &payment
+}
+
+func applyTermOverrides(terms *domain.MortgageTerms, overrides *dto.MortgageTermsRequest) {
+	if overrides == nil {
+		return
+	}
+
+	if overrides.DownPaymentPct != nil {
+		terms.DownPaymentPct = *overrides.DownPaymentPct
+	}
+
+	if overrides.InterestRate != nil {
+		terms.InterestRate = *overrides.InterestRate
+	}
+
+	if overrides.TermYears != nil {
+		terms.TermYears = *overrides.TermYears
+	}
+}
+
+func validateMortgageTerms(terms *domain.MortgageTerms) error {
+	if terms.DownPaymentPct < 0 || terms.DownPaymentPct > 100 {
+		return errors.New("Down payment must be between 0 and 100 percent")
+	}
+
+	if terms.InterestRate < 0 || terms.InterestRate > 30 {
+		return errors.New("Interest rate must be between 0 and 30 percent")
+	}
+
+	if terms.TermYears < 1 || terms.TermYears > 40 {
+		return errors.New("Loan term must be between 1 and 40 years")
+	}
+
+	return nil
+}
+
+func calculateMonthlyPayment(
+	price float64,
+	terms *domain.MortgageTerms,
+	annualTax float64,
+	annualInsurance float64,
+	hoaMonthly float64,
+) domain.MonthlyPayment {
+	downPayment := price * terms.DownPaymentPct / 100
+	loanAmount := price - downPayment
+
+	payment := domain.MonthlyPayment{
+		MortgageTerms:        *terms,
+		DownPayment:          roundTwoPlaces(downPayment),
+		LoanAmount:           roundTwoPlaces(loanAmount),
+		PrincipalAndInterest: roundTwoPlaces(principalAndInterest(loanAmount, terms)),
+		PropertyTax:          roundTwoPlaces(annualTax / 12),
+		HOA:                  roundTwoPlaces(hoaMonthly),
+		Insurance:            roundTwoPlaces(annualInsurance / 12),
+	}
+
+	payment.Total = roundTwoPlaces(
+		payment.PrincipalAndInterest + payment.PropertyTax + payment.HOA + payment.Insurance,
+	)
+
+	return payment
+}
+
+// principalAndInterest is the standard fixed-rate annuity payment.
+func principalAndInterest(loanAmount float64, terms *domain.MortgageTerms) float64 {
+	months := float64(terms.TermYears * 12)
+	if loanAmount <= 0 {
+		return 0
+	}
+
+	monthlyRate := terms.InterestRate / 100 / 12
+	if monthlyRate == 0 {
+		return loanAmount / months
+	}
+
+	return loanAmount * monthlyRate / (1 - math.Pow(1+monthlyRate, -months))
+}
+
+// amortizationSchedule splits each payment into principal and interest. The
+// final payment absorbs rounding so the balance always ends at zero.
+func amortizationSchedule(
+	loanAmount float64,
+	terms *domain.MortgageTerms,
+) []domain.AmortizationPeriod {
+	months := terms.TermYears * 12
+	monthlyRate := terms.InterestRate / 100 / 12
+	payment := roundTwoPlaces(principalAndInterest(loanAmount, terms))
+	balance := roundTwoPlaces(loanAmount)
+
+	schedule := make([]domain.AmortizationPeriod, 0, months)
+	for month := 1; month <= months && balance > 0; month++ {
+		interest := roundTwoPlaces(balance * monthlyRate)
+		principal := roundTwoPlaces(payment - interest)
+
+		if month == months || principal > balance {
+			principal = balance
+		}
+
+		balance = roundTwoPlaces(balance - principal)
+
+		schedule = append(schedule, domain.AmortizationPeriod{
+			Month:     month,
+			Payment:   roundTwoPlaces(principal + interest),
+			Principal: principal,
+			Interest:  interest,
+			Balance:   balance,
+		})
+	}
+
+	return schedule
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+func TestCalculateMortgage(t *testing.T) {
+	noPrefsRepo := &repo.MortgageRepoMock{
+		GetMortgagePreferencesFunc: func(ctx context.Context, userId int) (*domain.MortgagePreferences, error) {
+			return nil, sql.ErrNoRows
+		},
+	}
+
+	t.Run("Default terms return monthly breakdown", func(t *testing.T) {
+		m := NewMortgageService(noPrefsRepo)
+		req := &dto.MortgageCalculatorRequest{Price: 500000, IncludeSchedule: true}
+
+		estimate, err := m.CalculateMortgage(context.Background(), nil, req)
+		if err != nil {
+			t.Fatalf("Expected success, received %v", err)
+		}
+
+		want := domain.MonthlyPayment{
+			MortgageTerms:        DefaultMortgageTerms,
+			DownPayment:          100000,
+			LoanAmount:           400000,
+			PrincipalAndInterest: 2528.27,
+			PropertyTax:          458.33,
+			HOA:                  0,
+			Insurance:            145.83,
+			Total:                3132.43,
+		}
+
+		if estimate.Monthly != want {
+			t.Errorf("Expected %#v, received %#v", want, estimate.Monthly)
+		}
+
+		if len(estimate.AmortizationTable) != 360 {
+			t.Fatalf("Expected 360 periods, received %d", len(estimate.AmortizationTable))
+		}
+
+		var principal float64
+		for _, period := range estimate.AmortizationTable {
+			principal += period.Principal
+		}
+
+		if roundTwoPlaces(principal) != 400000 {
+			t.Errorf("Expected principal to total 400000, received %v", principal)
+		}
+
+		if last := estimate.AmortizationTable[359]; last.Balance != 0 {
+			t.Errorf("Expected final balance 0, received %v", last.Balance)
+		}
+	})
+
+	t.Run("Zero interest splits loan evenly", func(t *testing.T) {
+		m := NewMortgageService(noPrefsRepo)
+		rate := 0.0
+		term := 10
+		req := &dto.MortgageCalculatorRequest{
+			MortgageTermsRequest: dto.MortgageTermsRequest{InterestRate: &rate, TermYears: &term},
+			Price:                150000,
+		}
+
+		estimate, err := m.CalculateMortgage(context.Background(), nil, req)
+		if err != nil {
+			t.Fatalf("Expected success, received %v", err)
+		}
+
+		if estimate.Monthly.PrincipalAndInterest != 1000 {
+			t.Errorf("Expected 1000, received %v", estimate.Monthly.PrincipalAndInterest)
+		}
+
+		if estimate.TotalInterest != 0 {
+			t.Errorf("Expected no interest, received %v", estimate.TotalInterest)
+		}
+	})
+
+	t.Run("Invalid down payment returns error", func(t *testing.T) {
+		m := NewMortgageService(noPrefsRepo)
+		downPayment := 120.0
+		req := &dto.MortgageCalculatorRequest{
+			MortgageTermsRequest: dto.MortgageTermsRequest{DownPaymentPct: &downPayment},
+			Price:                500000,
+		}
+
+		if _, err := m.CalculateMortgage(context.Background(), nil, req); err == nil {
+			t.Error("Expected error, received nil")
+		}
+	})
+}
+
+func TestResolveTerms(t *testing.T) {
+	mockRepo := &repo.MortgageRepoMock{
+		GetMortgagePreferencesFunc: func(ctx context.Context, userId int) (*domain.MortgagePreferences, error) {
+			return &domain.MortgagePreferences{
+				UserID:         userId,
+				DownPaymentPct: 10,
+				InterestRate:   5.75,
+				TermYears:      15,
+			}, nil
+		},
+	}
+	userCtx := &domain.ContextSessionData{UserID: 1, Role: "user"}
+	rate := 7.0
+
+	tests := []struct {
+		Name      string
+		UserCtx   *domain.ContextSessionData
+		Overrides *dto.MortgageTermsRequest
+		Expected  domain.MortgageTerms
+	}{
+		{
+			Name:     "Anonymous request uses defaults",
+			Expected: DefaultMortgageTerms,
+		},
+		{
+			Name:     "Logged in user uses saved preferences",
+			UserCtx:  userCtx,
+			Expected: domain.MortgageTerms{DownPaymentPct: 10, InterestRate: 5.75, TermYears: 15},
+		},
+		{
+			Name:      "Query overrides win over saved preferences",
+			UserCtx:   userCtx,
+			Overrides: &dto.MortgageTermsRequest{InterestRate: &rate},
+			Expected:  domain.MortgageTerms{DownPaymentPct: 10, InterestRate: 7, TermYears: 15},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			m := NewMortgageService(mockRepo)
+
+			terms, err := m.ResolveTerms(context.Background(), tt.UserCtx, tt.Overrides)
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if *terms != tt.Expected {
+				t.Errorf("Expected %#v, received %#v", tt.Expected, *terms)
+			}
+		})
+	}
+}
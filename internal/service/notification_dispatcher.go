@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+// ChannelSender delivers a notification over an out-of-band channel such as
+// email or webhooks.
+type ChannelSender interface {
+	Send(ctx context.Context, notification *domain.Notification) error
+}
+
+// NotificationDispatcher is the single path notifications are delivered
+// through. It honours each user's channel preferences for the notification
+// type and suppresses push and email during their quiet hours; nothing is
+// sent once they end, though the inbox row and webhook still go out. Users on
+// a digest get no per-notification email; the digest job summarises their
+// inbox instead.
+type NotificationDispatcher struct {
+	notificationRepo repo.INotificationRepo
+	preferenceRepo   repo.INotificationPreferenceRepo
+	pusher           Pusher
+	senders          map[string]ChannelSender
+	now              func() time.Time
+}
+
+func NewNotificationDispatcher(
+	notificationRepo repo.INotificationRepo,
+	preferenceRepo repo.INotificationPreferenceRepo,
+) *NotificationDispatcher {
+	return &NotificationDispatcher{
+		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
+		senders:          map[string]ChannelSender{},
+		now:              time.Now,
+	}
+}
+
+// SetPusher wires real-time delivery in after construction, since the
+// WebSocket manager is created after the services. Without a pusher the push
+// channel is skipped.
+func (d *NotificationDispatcher) SetPusher(pusher Pusher) {
+	d.pusher = pusher
+}
+
+// RegisterSender delivers the email or webhook channel through sender.
+// Channels without a sender are skipped.
+func (d *NotificationDispatcher) RegisterSender(channel string, sender ChannelSender) {
+	d.senders[channel] = sender
+}
+
+// Dispatch delivers the notification to each user over the channels they
+// have enabled for notificationType. Inbox rows are persisted in a single
+// insert; other channels are best-effort and only logged on failure.
+func (d *NotificationDispatcher) Dispatch(
+	ctx context.Context,
+	userIds []int,
+	listingId int,
+	notificationType string,
+	message string,
+) ([]*domain.Notification, error) {
+	return d.dispatch(ctx, "", userIds, listingId, notificationType, message)
+}
+
+// DispatchOnce is Dispatch for events relayed at least once. Users who
+// already have the notification for dedupKey in their inbox are skipped on
+// every channel. Users with the inbox turned off have nothing to dedupe
+// against and may see a repeat push or email when an event is retried.
+func (d *NotificationDispatcher) DispatchOnce(
+	ctx context.Context,
+	dedupKey string,
+	userIds []int,
+	listingId int,
+	notificationType string,
+	message string,
+) ([]*domain.Notification, error) {
+	return d.dispatch(ctx, dedupKey, userIds, listingId, notificationType, message)
+}
+
+func (d *NotificationDispatcher) dispatch(
+	ctx context.Context,
+	dedupKey string,
+	userIds []int,
+	listingId int,
+	notificationType string,
+	message string,
+) ([]*domain.Notification, error) {
+	if err := validateNotificationType(notificationType); err != nil {
+		return nil, err
+	}
+
+	if len(userIds) == 0 {
+		return nil, nil
+	}
+
+	deliveries, err := d.preferenceRepo.GetDeliveries(ctx, userIds, notificationType)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch notification preferences: %w", err)
+	}
+
+	delivery := func(userId int) *domain.NotificationDelivery {
+		if delivery, ok := deliveries[userId]; ok {
+			return delivery
+		}
+		return domain.DefaultNotificationDelivery()
+	}
+
+	var inAppIds []int
+	for _, userId := range userIds {
+		if delivery(userId).Channels.InApp {
+			inAppIds = append(inAppIds, userId)
+		}
+	}
+
+	persisted := make(map[int]*domain.Notification, len(inAppIds))
+	if len(inAppIds) > 0 {
+		var notifications []*domain.Notification
+		if dedupKey == "" {
+			notifications, err = d.notificationRepo.CreateNotifications(
+				ctx,
+				inAppIds,
+				listingId,
+				notificationType,
+				message,
+			)
+		} else {
+			notifications, err = d.notificationRepo.CreateNotificationsOnce(
+				ctx,
+				dedupKey,
+				inAppIds,
+				listingId,
+				notificationType,
+				message,
+			)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to persist notifications: %w", err)
+		}
+
+		for _, notification := range notifications {
+			persisted[notification.UserID] = notification
+		}
+	}
+
+	now := d.now()
+
+	for _, userId := range userIds {
+		userDelivery := delivery(userId)
+
+		notification, ok := persisted[userId]
+		if !ok && userDelivery.Channels.InApp {
+			// Only a repeat of an already delivered event inserts nothing
+			continue
+		}
+
+		if !ok {
+			notification = &domain.Notification{
+				UserID:    userId,
+				ListingID: listingId,
+				Type:      notificationType,
+				Message:   message,
+				CreatedAt: now,
+			}
+		}
+
+		quiet := userDelivery.InQuietHours(now)
+
+		if userDelivery.Channels.Push && !quiet && d.pusher != nil {
+			d.pusher.PushToUser(userId, notificationType, notification)
+		}
+
+		digest := userDelivery.Digest != "" && userDelivery.Digest != domain.NotificationDigestOff
+		if userDelivery.Channels.Email && !quiet && !digest {
+			d.send(ctx, domain.NotificationChannelEmail, notification)
+		}
+
+		if userDelivery.Channels.Webhook {
+			d.send(ctx, domain.NotificationChannelWebhook, notification)
+		}
+	}
+
+	notifications := make([]*domain.Notification, 0, len(persisted))
+	for _, userId := range inAppIds {
+		if notification, ok := persisted[userId]; ok {
+			notifications = append(notifications, notification)
+		}
+	}
+
+	return notifications, nil
+}
+
+func (d *NotificationDispatcher) send(
+	ctx context.Context,
+	channel string,
+	notification *domain.Notification,
+) {
+	sender, ok := d.senders[channel]
+	if !ok {
+		return
+	}
+
+	if err := sender.Send(ctx, notification); err != nil {
+		slog.Warn(
+			"Failed to deliver notification",
+			slog.String("channel", channel),
+			slog.Int("user_id", notification.UserID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// sortedUserIds turns a user id set into a stable slice for dispatching.
+func sortedUserIds(userIds map[int]bool) []int {
+	ids := make([]int, 0, len(userIds))
+	for userId := range userIds {
+		ids = append(ids, userId)
+	}
+
+	slices.Sort(ids)
+
+	return ids
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+// storeNotifications stands in for the batch insert, echoing back one
+// notification per user.
+func storeNotifications(
+	ctx context.Context,
+	userIds []int,
+	listingId int,
+	notificationType string,
+	message string,
+) ([]*domain.Notification, error) {
+	notifications := make([]*domain.Notification, len(userIds))
+	for i, userId := range userIds {
+		notifications[i] = &domain.Notification{
+			ID:        i + 1,
+			UserID:    userId,
+			ListingID: listingId,
+			Type:      notificationType,
+			Message:   message,
+		}
+	}
+	return notifications, nil
+}
+
+// preferenceRepoWith serves the given deliveries; users not listed get the
+// defaults.
+func preferenceRepoWith(deliveries map[int]*domain.NotificationDelivery) *repo.NotificationPreferenceRepoMock {
+	return &repo.NotificationPreferenceRepoMock{
+		GetDeliveriesFunc: func(ctx context.Context, userIds []int, notificationType string) (map[int]*domain.NotificationDelivery, error) {
+			return deliveries, nil
+		},
+	}
+}
+
+// newTestNotificationService delivers with default preferences for every user.
+func newTestNotificationService(
+	notificationRepo repo.INotificationRepo,
+	favoriteRepo repo.IFavoriteRepo,
+	pusher Pusher,
+) *NotificationService {
+	preferenceRepo := preferenceRepoWith(nil)
+
+	dispatcher := NewNotificationDispatcher(notificationRepo, preferenceRepo)
+	dispatcher.SetPusher(pusher)
+
+	return NewNotificationService(notificationRepo, preferenceRepo, favoriteRepo, dispatcher)
+}
+
+type sendRecorder struct {
+	sent []int
+}
+
+func (r *sendRecorder) Send(ctx context.Context, notification *domain.Notification) error {
+	r.sent = append(r.sent, notification.UserID)
+	return nil
+}
+
+func TestDispatch(t *testing.T) {
+	// 23:30 in Chicago
+	now := time.Date(2025, 12, 17, 5, 30, 0, 0, time.UTC)
+	overnight := &domain.QuietHours{Start: "22:00", End: "07:00"}
+
+	tests := []struct {
+		name            string
+		delivery        *domain.NotificationDelivery
+		expectedInbox   bool
+		expectedPush    bool
+		expectedEmail   bool
+		expectedWebhook bool
+	}{
+		{
+			name:          "Defaults store and push",
+			expectedInbox: true,
+			expectedPush:  true,
+		},
+		{
+			name: "Type switched off everywhere",
+			delivery: &domain.NotificationDelivery{
+				Timezone: "UTC",
+			},
+		},
+		{
+			name: "Push only",
+			delivery: &domain.NotificationDelivery{
+				Channels: domain.NotificationChannels{Push: true},
+				Timezone: "UTC",
+			},
+			expectedPush: true,
+		},
+		{
+			name: "Quiet hours suppress push and email but not inbox or webhooks",
+			delivery: &domain.NotificationDelivery{
+				Channels:   domain.NotificationChannels{InApp: true, Push: true, Email: true, Webhook: true},
+				Timezone:   "America/Chicago",
+				QuietHours: overnight,
+			},
+			expectedInbox:   true,
+			expectedWebhook: true,
+		},
+		{
+			name: "Outside quiet hours in the user's timezone",
+			delivery: &domain.NotificationDelivery{
+				Channels:   domain.NotificationChannels{Push: true, Email: true},
+				Timezone:   "Asia/Tokyo",
+				QuietHours: overnight,
+			},
+			expectedPush:  true,
+			expectedEmail: true,
+		},
+		{
+			name: "Digest holds back email but keeps the inbox row",
+			delivery: &domain.NotificationDelivery{
+				Channels: domain.NotificationChannels{InApp: true, Email: true},
+				Timezone: "UTC",
+				Digest:   domain.NotificationDigestDaily,
+			},
+			expectedInbox: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored []int
+
+			notificationRepo := &repo.NotificationRepoMock{
+				CreateNotificationsFunc: func(
+					ctx context.Context,
+					userIds []int,
+					listingId int,
+					notificationType string,
+					message string,
+				) ([]*domain.Notification, error) {
+					stored = userIds
+					return storeNotifications(ctx, userIds, listingId, notificationType, message)
+				},
+			}
+
+			deliveries := map[int]*domain.NotificationDelivery{}
+			if tt.delivery != nil {
+				deliveries[8] = tt.delivery
+			}
+
+			pusher := &pushRecorder{pushes: map[int]string{}}
+			email := &sendRecorder{}
+			webhook := &sendRecorder{}
+
+			d := NewNotificationDispatcher(notificationRepo, preferenceRepoWith(deliveries))
+			d.SetPusher(pusher)
+			d.RegisterSender(domain.NotificationChannelEmail, email)
+			d.RegisterSender(domain.NotificationChannelWebhook, webhook)
+			d.now = func() time.Time { return now }
+
+			_, err := d.Dispatch(context.Background(), []int{8}, 7, domain.NotificationTypePriceDrop, "Reduced")
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if (len(stored) == 1) != tt.expectedInbox {
+				t.Errorf("Expected inbox %v, received %v", tt.expectedInbox, stored)
+			}
+
+			if (len(pusher.pushes) == 1) != tt.expectedPush {
+				t.Errorf("Expected push %v, received %v", tt.expectedPush, pusher.pushes)
+			}
+
+			if (len(email.sent) == 1) != tt.expectedEmail {
+				t.Errorf("Expected email %v, received %v", tt.expectedEmail, email.sent)
+			}
+
+			if (len(webhook.sent) == 1) != tt.expectedWebhook {
+				t.Errorf("Expected webhook %v, received %v", tt.expectedWebhook, webhook.sent)
+			}
+		})
+	}
+}
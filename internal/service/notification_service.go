@@ -2,69 +2,396 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"server/internal/api/dto"
 	"server/internal/domain"
+	"server/internal/events"
 	"server/internal/repo"
 )
 
+const (
+	defaultNotificationPageSize = 50
+	maxNotificationPageSize     = 100
+)
+
+var ErrNotificationForbidden = errors.New("Only admins can send notifications")
+
+// Pusher delivers real-time events to a user's open connections.
+type Pusher interface {
+	PushToUser(userId int, eventType string, payload any)
+}
+
 type NotificationService struct {
 	notificationRepo repo.INotificationRepo
+	preferenceRepo   repo.INotificationPreferenceRepo
 	favoriteRepo     repo.IFavoriteRepo
-	listingRepo      repo.IListingRepo
+	dispatcher       *NotificationDispatcher
 }
 
 func NewNotificationService(
 	notificationRepo repo.INotificationRepo,
+	preferenceRepo repo.INotificationPreferenceRepo,
 	favoriteRepo repo.IFavoriteRepo,
-	listingRepo repo.IListingRepo,
+	dispatcher *NotificationDispatcher,
 ) *NotificationService {
 	return &NotificationService{
 		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
 		favoriteRepo:     favoriteRepo,
-		listingRepo:      listingRepo,
+		dispatcher:       dispatcher,
 	}
 }
 
-func (s *NotificationService) GetAllNotificationsByUserId(
+// GetNotifications returns a page of the user's inbox, newest first. It
+// fetches one extra row to tell whether an older page exists.
+func (s *NotificationService) GetNotifications(
 	ctx context.Context,
-	userID int,
-) ([]*domain.Notification, error) {
-	notifications, err := s.notificationRepo.GetAllNotificationsByUserId(ctx, userID)
+	userId int,
+	filter domain.NotificationFilter,
+	beforeId int,
+	limit int,
+) (*domain.NotificationPage, error) {
+	if limit == 0 {
+		limit = defaultNotificationPageSize
+	}
+
+	if limit < 1 || limit > maxNotificationPageSize {
+		return nil, newValidationError("Limit must be between 1 and 100")
+	}
+
+	notifications, err := s.notificationRepo.GetNotifications(ctx, userId, filter, beforeId, limit+1)
 	if err != nil {
 		return nil, err
 	}
 
-	if notifications == nil {
-		notifications = []*domain.Notification{}
+	page := &domain.NotificationPage{Notifications: []*domain.Notification{}}
+
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+		nextCursor := notifications[limit-1].ID
+		page.NextCursor = &nextCursor
+	}
+
+	if notifications != nil {
+		page.Notifications = notifications
 	}
 
-	return notifications, nil
+	return page, nil
 }
 
-func (s *NotificationService) CreateNotification(
+func (s *NotificationService) GetUnreadCount(ctx context.Context, userId int) (int, error) {
+	return s.notificationRepo.GetUnreadCount(ctx, userId)
+}
+
+func validateNotificationType(notificationType string) error {
+	if !domain.NotificationTypes[notificationType] {
+		return newValidationError(fmt.Sprintf("Unknown notification type %q", notificationType))
+	}
+
+	return nil
+}
+
+// SendNotification lets an admin notify a user directly. Delivery still
+// follows the user's preferences.
+func (s *NotificationService) SendNotification(
 	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
 	notification *domain.Notification,
-) (*domain.Notification, error) {
-	return s.notificationRepo.CreateNotification(ctx, notification)
+) error {
+	if currentUserCtx.Role != "admin" {
+		return ErrNotificationForbidden
+	}
+
+	if strings.TrimSpace(notification.Message) == "" {
+		return newValidationError("Please enter a message")
+	}
+
+	_, err := s.dispatcher.Dispatch(
+		ctx,
+		[]int{notification.UserID},
+		notification.ListingID,
+		notification.Type,
+		notification.Message,
+	)
+	return err
 }
 
-func (s *NotificationService) ToggleNotificationReadStatus(
+// SetReadStatus marks one of the user's notifications read or unread. Other
+// users' notifications are reported as sql.ErrNoRows.
+func (s *NotificationService) SetReadStatus(
 	ctx context.Context,
+	userId int,
 	id int,
+	isRead bool,
 ) (*domain.Notification, error) {
-	return s.notificationRepo.ToggleNotificationReadStatus(ctx, id)
+	return s.notificationRepo.SetNotificationRead(ctx, userId, id, isRead)
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userId int) error {
+	return s.notificationRepo.MarkAllNotificationsRead(ctx, userId)
+}
+
+// DeleteNotification removes one of the user's notifications. Other users'
+// notifications are reported as sql.ErrNoRows.
+func (s *NotificationService) DeleteNotification(ctx context.Context, userId int, id int) error {
+	return s.notificationRepo.DeleteNotification(ctx, userId, id)
+}
+
+// NotifyUsers delivers a notification to each user through the dispatcher.
+func (s *NotificationService) NotifyUsers(
+	ctx context.Context,
+	userIds map[int]bool,
+	listingId int,
+	notificationType string,
+	message string,
+) error {
+	return s.NotifyUserBatch(ctx, sortedUserIds(userIds), listingId, notificationType, message)
 }
 
-func (s *NotificationService) GetAllUserIdsByListingId(
+// NotifyUserBatch is NotifyUsers for large audiences given as a slice.
+func (s *NotificationService) NotifyUserBatch(
 	ctx context.Context,
+	userIds []int,
 	listingId int,
-) (map[int]bool, error) {
-	return s.favoriteRepo.GetAllUserIdsByListingId(ctx, listingId)
+	notificationType string,
+	message string,
+) error {
+	_, err := s.dispatcher.Dispatch(ctx, userIds, listingId, notificationType, message)
+	return err
+}
+
+// OnListingPriceChanged tells favoriters about a price drop. Those whose
+// price alert was crossed get a price alert. Favoriters with an alert that
+// was not crossed asked to hear only about their threshold and get nothing;
+// everyone else gets a price drop.
+func (s *NotificationService) OnListingPriceChanged(
+	ctx context.Context,
+	change events.ListingPriceChanged,
+) error {
+	if change.NewPrice >= change.OldPrice {
+		return nil
+	}
+
+	alerts, err := s.favoriteRepo.GetPriceAlerts(
+		ctx,
+		change.ListingID,
+		change.OldPrice,
+		change.NewPrice,
+	)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch price alerts for listing: %w", err)
+	}
+
+	favoriterIds, err := s.favoriteRepo.GetAllUserIdsByListingId(ctx, change.ListingID)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch users for listing: %w", err)
+	}
+
+	crossedIds := map[int]bool{}
+	for userId, crossed := range alerts {
+		delete(favoriterIds, userId)
+
+		if crossed {
+			crossedIds[userId] = true
+		}
+	}
+	alertIds := sortedUserIds(crossedIds)
+
+	if len(alertIds) > 0 {
+		_, err := s.dispatcher.DispatchOnce(
+			ctx,
+			change.DedupKey,
+			alertIds,
+			change.ListingID,
+			domain.NotificationTypePriceAlert,
+			fmt.Sprintf(
+				"Price alert: %s dropped from $%d to $%d",
+				change.Address,
+				change.OldPrice,
+				change.NewPrice,
+			),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.dispatcher.DispatchOnce(
+		ctx,
+		change.DedupKey,
+		sortedUserIds(favoriterIds),
+		change.ListingID,
+		domain.NotificationTypePriceDrop,
+		fmt.Sprintf("Price Drop: %s was reduced to $%d", change.Address, change.NewPrice),
+	)
+	return err
+}
+
+// OnListingStatusChanged tells favoriters when a listing goes pending or
+// changes status in any other way.
+func (s *NotificationService) OnListingStatusChanged(
+	ctx context.Context,
+	change events.ListingStatusChanged,
+) error {
+	notificationType := domain.NotificationTypeStatusChange
+	message := fmt.Sprintf(
+		"Status Change: Status of %s was changed to %s",
+		change.Address,
+		change.NewStatus,
+	)
+
+	if change.NewStatus == domain.ListingStatusPending {
+		notificationType = domain.NotificationTypeListingPending
+		message = fmt.Sprintf("%s is now pending", change.Address)
+	}
+
+	userIds, err := s.favoriteRepo.GetAllUserIdsByListingId(ctx, change.ListingID)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch users for listing: %w", err)
+	}
+
+	_, err = s.dispatcher.DispatchOnce(
+		ctx,
+		change.DedupKey,
+		sortedUserIds(userIds),
+		change.ListingID,
+		notificationType,
+		message,
+	)
+	return err
+}
+
+// OnFavoriteAdded tells the listing agent about a new favorite.
+func (s *NotificationService) OnFavoriteAdded(ctx context.Context, favorite events.FavoriteAdded) error {
+	_, err := s.dispatcher.DispatchOnce(
+		ctx,
+		favorite.DedupKey,
+		[]int{favorite.AgentID},
+		favorite.ListingID,
+		domain.NotificationTypeFavoritedListing,
+		fmt.Sprintf("New favorite on %s", favorite.Address),
+	)
+	return err
 }
 
-func (s *NotificationService) GetAgentIdByListingId(
+func (s *NotificationService) NotifyListingFavoriters(
 	ctx context.Context,
 	listingId int,
-) (int, error) {
-	return s.listingRepo.GetAgentIdByListingId(ctx, listingId)
+	notificationType string,
+	message string,
+) error {
+	userIds, err := s.favoriteRepo.GetAllUserIdsByListingId(ctx, listingId)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch users for listing: %w", err)
+	}
+
+	return s.NotifyUsers(ctx, userIds, listingId, notificationType, message)
+}
+
+// GetSettings returns the user's quiet hours and the channels for every
+// registered notification type, with defaults filled in.
+func (s *NotificationService) GetSettings(
+	ctx context.Context,
+	userId int,
+) (*domain.NotificationSettings, error) {
+	settings, err := s.preferenceRepo.GetSettings(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return withDefaultPreferences(settings), nil
+}
+
+func (s *NotificationService) UpdateSettings(
+	ctx context.Context,
+	userId int,
+	req *dto.UpdateNotificationSettingsRequest,
+) (*domain.NotificationSettings, error) {
+	if _, err := time.LoadLocation(req.Timezone); err != nil || req.Timezone == "" {
+		return nil, newValidationError("Timezone must be a valid IANA name such as America/Chicago")
+	}
+
+	if req.QuietHours != nil {
+		_, startErr := time.Parse(clockFormat, req.QuietHours.Start)
+		_, endErr := time.Parse(clockFormat, req.QuietHours.End)
+		if startErr != nil || endErr != nil {
+			return nil, newValidationError("Quiet hours must be in HH:MM format")
+		}
+
+		if req.QuietHours.Start == req.QuietHours.End {
+			return nil, newValidationError("Quiet hours must start and end at different times")
+		}
+	}
+
+	digest := req.Digest
+	switch digest {
+	case "":
+		digest = domain.NotificationDigestOff
+	case domain.NotificationDigestOff, domain.NotificationDigestDaily, domain.NotificationDigestWeekly:
+	default:
+		return nil, newValidationError("Digest must be off, daily or weekly")
+	}
+
+	seen := make(map[string]bool, len(req.Preferences))
+	for _, preference := range req.Preferences {
+		if err := validateNotificationType(preference.Type); err != nil {
+			return nil, err
+		}
+
+		if seen[preference.Type] {
+			return nil, newValidationError(fmt.Sprintf("Notification type %q is listed more than once", preference.Type))
+		}
+		seen[preference.Type] = true
+	}
+
+	settings, err := s.preferenceRepo.ReplaceSettings(ctx, &domain.NotificationSettings{
+		UserID:      userId,
+		Timezone:    req.Timezone,
+		QuietHours:  req.QuietHours,
+		Digest:      digest,
+		Preferences: req.Preferences,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return withDefaultPreferences(settings), nil
+}
+
+// withDefaultPreferences lists every registered type, using the default
+// channels for those the user hasn't configured.
+func withDefaultPreferences(settings *domain.NotificationSettings) *domain.NotificationSettings {
+	configured := make(map[string]domain.NotificationChannels, len(settings.Preferences))
+	for _, preference := range settings.Preferences {
+		configured[preference.Type] = preference.NotificationChannels
+	}
+
+	types := make([]string, 0, len(domain.NotificationTypes))
+	for notificationType := range domain.NotificationTypes {
+		types = append(types, notificationType)
+	}
+
+	slices.Sort(types)
+
+	preferences := make([]domain.NotificationPreference, 0, len(types))
+	for _, notificationType := range types {
+		channels, ok := configured[notificationType]
+		if !ok {
+			channels = domain.DefaultNotificationChannels
+		}
+
+		preferences = append(preferences, domain.NotificationPreference{
+			Type:                 notificationType,
+			NotificationChannels: channels,
+		})
+	}
+
+	settings.Preferences = preferences
+
+	return settings
 }
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"server/internal/domain"
+	"server/internal/events"
+	"server/internal/repo"
+)
+
+func TestGetNotifications(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         int
+		stored        int
+		expectedLimit int
+		expectedCount int
+		expectedNext  *int
+		expectedErr   error
+	}{
+		{
+			name:          "Default page size",
+			stored:        3,
+			expectedLimit: defaultNotificationPageSize + 1,
+			expectedCount: 3,
+		},
+		{
+			name:          "Full page points at the oldest notification",
+			limit:         2,
+			stored:        5,
+			expectedLimit: 3,
+			expectedCount: 2,
+			expectedNext:  intPtr(9),
+		},
+		{
+			name:        "Limit too large",
+			limit:       101,
+			expectedErr: newValidationError("Limit must be between 1 and 100"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestedLimit int
+
+			notificationRepo := &repo.NotificationRepoMock{
+				GetNotificationsFunc: func(
+					ctx context.Context,
+					userId int,
+					filter domain.NotificationFilter,
+					beforeId int,
+					limit int,
+				) ([]*domain.Notification, error) {
+					requestedLimit = limit
+
+					// Newest first, counting down from id 10
+					var notifications []*domain.Notification
+					for i := 0; i < tt.stored && i < limit; i++ {
+						notifications = append(notifications, &domain.Notification{ID: 10 - i, UserID: userId})
+					}
+					return notifications, nil
+				},
+			}
+
+			s := newTestNotificationService(notificationRepo, &repo.FavoriteRepoMock{}, nil)
+
+			page, err := s.GetNotifications(context.Background(), 4, domain.NotificationFilter{}, 0, tt.limit)
+
+			if tt.expectedErr != nil {
+				if err == nil || err.Error() != tt.expectedErr.Error() || !IsValidationError(err) {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if requestedLimit != tt.expectedLimit {
+				t.Errorf("Expected limit %d, received %d", tt.expectedLimit, requestedLimit)
+			}
+
+			if len(page.Notifications) != tt.expectedCount {
+				t.Errorf("Expected %d notifications, received %d", tt.expectedCount, len(page.Notifications))
+			}
+
+			if !reflect.DeepEqual(page.NextCursor, tt.expectedNext) {
+				t.Errorf("Expected %v, received %v", tt.expectedNext, page.NextCursor)
+			}
+		})
+	}
+}
+
+func TestSendNotification(t *testing.T) {
+	tests := []struct {
+		name         string
+		currentUser  *domain.ContextSessionData
+		notification *domain.Notification
+		createErr    error
+		expectedErr  error
+	}{
+		{
+			name:         "Admin notifies a user",
+			currentUser:  &domain.ContextSessionData{UserID: 1, Role: "admin"},
+			notification: &domain.Notification{UserID: 8, ListingID: 7, Type: domain.NotificationTypePriceDrop, Message: "Reduced"},
+		},
+		{
+			name:         "Users cannot create notifications",
+			currentUser:  &domain.ContextSessionData{UserID: 8, Role: "user"},
+			notification: &domain.Notification{UserID: 8, ListingID: 7, Type: domain.NotificationTypePriceDrop, Message: "Reduced"},
+			expectedErr:  ErrNotificationForbidden,
+		},
+		{
+			name:         "Agents cannot create notifications",
+			currentUser:  &domain.ContextSessionData{UserID: 2, Role: "agent"},
+			notification: &domain.Notification{UserID: 8, ListingID: 7, Type: domain.NotificationTypePriceDrop, Message: "Reduced"},
+			expectedErr:  ErrNotificationForbidden,
+		},
+		{
+			name:         "Unknown type",
+			currentUser:  &domain.ContextSessionData{UserID: 1, Role: "admin"},
+			notification: &domain.Notification{UserID: 8, ListingID: 7, Type: "free_money", Message: "Click here"},
+			expectedErr:  newValidationError(`Unknown notification type "free_money"`),
+		},
+		{
+			name:         "Empty message",
+			currentUser:  &domain.ContextSessionData{UserID: 1, Role: "admin"},
+			notification: &domain.Notification{UserID: 8, ListingID: 7, Type: domain.NotificationTypePriceDrop, Message: " "},
+			expectedErr:  newValidationError("Please enter a message"),
+		},
+		{
+			name:         "Inbox failure is not the request's fault",
+			currentUser:  &domain.ContextSessionData{UserID: 1, Role: "admin"},
+			notification: &domain.Notification{UserID: 8, ListingID: 7, Type: domain.NotificationTypePriceDrop, Message: "Reduced"},
+			createErr:    errors.New("connection refused"),
+			expectedErr:  errors.New("Failed to persist notifications: connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+
+			notificationRepo := &repo.NotificationRepoMock{
+				CreateNotificationsFunc: func(
+					ctx context.Context,
+					userIds []int,
+					listingId int,
+					notificationType string,
+					message string,
+				) ([]*domain.Notification, error) {
+					if tt.createErr != nil {
+						return nil, tt.createErr
+					}
+
+					created = true
+					return storeNotifications(ctx, userIds, listingId, notificationType, message)
+				},
+			}
+			pusher := &pushRecorder{pushes: map[int]string{}}
+
+			s := newTestNotificationService(notificationRepo, &repo.FavoriteRepoMock{}, pusher)
+
+			err := s.SendNotification(context.Background(), tt.currentUser, tt.notification)
+
+			if tt.expectedErr != nil {
+				if err == nil || err.Error() != tt.expectedErr.Error() {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+
+				if IsValidationError(err) != IsValidationError(tt.expectedErr) {
+					t.Errorf("Expected %T, received %T", tt.expectedErr, err)
+				}
+
+				if created {
+					t.Error("Expected no notification to be stored")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if pusher.pushes[tt.notification.UserID] != tt.notification.Type {
+				t.Errorf("Expected push of %s, received %v", tt.notification.Type, pusher.pushes)
+			}
+		})
+	}
+}
+
+func TestNotifyUsersRejectsUnknownType(t *testing.T) {
+	s := newTestNotificationService(&repo.NotificationRepoMock{}, &repo.FavoriteRepoMock{}, nil)
+
+	err := s.NotifyUsers(context.Background(), map[int]bool{8: true}, 7, "made_up_notification", "Hello")
+	if err == nil {
+		t.Error("Expected unknown type to be rejected")
+	}
+}
+
+func TestOnListingPriceChanged(t *testing.T) {
+	tests := []struct {
+		name           string
+		newPrice       int
+		expectedPushes map[int]string
+	}{
+		{
+			name:     "Price drop alerts crossed users and tells favoriters without an alert",
+			newPrice: 440000,
+			expectedPushes: map[int]string{
+				8: domain.NotificationTypePriceAlert,
+				9: domain.NotificationTypePriceDrop,
+			},
+		},
+		{
+			name:           "Price increase notifies nobody",
+			newPrice:       500000,
+			expectedPushes: map[int]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Remember which users already hold each dedup key, like the
+			// unique index on notifications does
+			delivered := map[string]map[int]bool{}
+			notificationRepo := &repo.NotificationRepoMock{
+				CreateNotificationsOnceFunc: func(ctx context.Context, dedupKey string, userIds []int, listingId int, notificationType string, message string) ([]*domain.Notification, error) {
+					if delivered[dedupKey] == nil {
+						delivered[dedupKey] = map[int]bool{}
+					}
+
+					var fresh []int
+					for _, userId := range userIds {
+						if !delivered[dedupKey][userId] {
+							delivered[dedupKey][userId] = true
+							fresh = append(fresh, userId)
+						}
+					}
+
+					return storeNotifications(ctx, fresh, listingId, notificationType, message)
+				},
+			}
+			favoriteRepo := &repo.FavoriteRepoMock{
+				// 10's alert is set below the new price, so it is not crossed
+				GetPriceAlertsFunc: func(ctx context.Context, listingId int, oldPrice int, newPrice int) (map[int]bool, error) {
+					return map[int]bool{8: true, 10: false}, nil
+				},
+				GetAllUserIdsByListingIdFunc: func(ctx context.Context, listingId int) (map[int]bool, error) {
+					return map[int]bool{8: true, 9: true, 10: true}, nil
+				},
+			}
+
+			pusher := &pushRecorder{pushes: map[int]string{}}
+			s := newTestNotificationService(notificationRepo, favoriteRepo, pusher)
+
+			event := events.ListingPriceChanged{
+				ListingID: 7,
+				Address:   "2912 River Bend Dr",
+				OldPrice:  475000,
+				NewPrice:  tt.newPrice,
+				DedupKey:  "listing.price_changed:7:1",
+			}
+
+			if err := s.OnListingPriceChanged(context.Background(), event); err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if !reflect.DeepEqual(pusher.pushes, tt.expectedPushes) {
+				t.Errorf("Expected %v, received %v", tt.expectedPushes, pusher.pushes)
+			}
+
+			// Relaying the same event again must not notify anyone twice
+			pusher.pushes = map[int]string{}
+			if err := s.OnListingPriceChanged(context.Background(), event); err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if len(pusher.pushes) != 0 {
+				t.Errorf("Expected no repeat pushes, received %v", pusher.pushes)
+			}
+		})
+	}
+}
@@ -0,0 +1,446 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+var (
+	ErrOfferForbidden   = errors.New("You are not a party to this offer")
+	ErrOfferNotYourTurn = errors.New("Waiting on the other party to respond")
+)
+
+type OfferService struct {
+	offerRepo           repo.IOfferRepo
+	listingRepo         repo.IListingRepo
+	notificationService *NotificationService
+	webhooks            WebhookPublisher
+	now                 func() time.Time
+}
+
+func NewOfferService(
+	offerRepo repo.IOfferRepo,
+	listingRepo repo.IListingRepo,
+	notificationService *NotificationService,
+	webhooks WebhookPublisher,
+) *OfferService {
+	return &OfferService{
+		offerRepo:           offerRepo,
+		listingRepo:         listingRepo,
+		notificationService: notificationService,
+		webhooks:            webhooks,
+		now:                 time.Now,
+	}
+}
+
+// validateTerms checks the terms of a new offer or counter and turns them into
+// a revision authored by authorId.
+func (s *OfferService) validateTerms(
+	req *dto.OfferTermsRequest,
+	authorId int,
+) (*domain.OfferRevision, error) {
+	if req.Amount <= 0 {
+		return nil, errors.New("Amount must be greater than 0")
+	}
+
+	if req.EarnestMoney < 0 || req.EarnestMoney > req.Amount {
+		return nil, errors.New("Earnest money must be between 0 and the offer amount")
+	}
+
+	seen := make(map[string]bool, len(req.Contingencies))
+	contingencies := make([]string, 0, len(req.Contingencies))
+	for _, contingency := range req.Contingencies {
+		if !domain.OfferContingencies[contingency] {
+			return nil, errors.New(
+				"Contingencies must be inspection, financing, appraisal or sale_of_home",
+			)
+		}
+
+		if !seen[contingency] {
+			seen[contingency] = true
+			contingencies = append(contingencies, contingency)
+		}
+	}
+
+	closingDate, err := time.Parse(time.DateOnly, req.ClosingDate)
+	if err != nil {
+		return nil, errors.New("Closing date must be in YYYY-MM-DD format")
+	}
+
+	now := s.now()
+
+	if !closingDate.After(now) {
+		return nil, errors.New("Closing date must be in the future")
+	}
+
+	if !req.ExpiresAt.After(now) {
+		return nil, errors.New("Expiry must be in the future")
+	}
+
+	return &domain.OfferRevision{
+		AuthorID:      authorId,
+		Amount:        req.Amount,
+		EarnestMoney:  req.EarnestMoney,
+		Contingencies: contingencies,
+		ClosingDate:   req.ClosingDate,
+		ExpiresAt:     req.ExpiresAt,
+		Message:       req.Message,
+	}, nil
+}
+
+// offerCounterparty returns the other agent on the offer, or
+// ErrOfferForbidden if userId is not a party to it.
+func offerCounterparty(offer *domain.Offer, userId int) (int, error) {
+	switch userId {
+	case offer.BuyerAgentID:
+		return offer.ListingAgentID, nil
+	case offer.ListingAgentID:
+		return offer.BuyerAgentID, nil
+	default:
+		return 0, ErrOfferForbidden
+	}
+}
+
+// authorizeTurn checks that userId may respond to the offer's current terms.
+// Whoever wrote the latest revision waits for the other party.
+func (s *OfferService) authorizeTurn(offer *domain.Offer, userId int) (int, error) {
+	otherId, err := offerCounterparty(offer, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	if !offer.IsOpen() {
+		return 0, errors.New("Offer is no longer open")
+	}
+
+	if !s.now().Before(offer.ExpiresAt) {
+		return 0, errors.New("Offer has expired")
+	}
+
+	if offer.LatestRevision().AuthorID == userId {
+		return 0, ErrOfferNotYourTurn
+	}
+
+	return otherId, nil
+}
+
+func (s *OfferService) notify(
+	ctx context.Context,
+	offer *domain.Offer,
+	userIds map[int]bool,
+	notificationType string,
+	message string,
+) {
+	err := s.notificationService.NotifyUsers(
+		ctx,
+		userIds,
+		offer.ListingID,
+		notificationType,
+		message,
+	)
+	if err != nil {
+		slog.Warn(
+			"Failed to notify offer party",
+			slog.Int("offer_id", offer.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// publish sends the offer event to both agents' webhooks.
+func (s *OfferService) publish(ctx context.Context, offer *domain.Offer, eventType string) {
+	publishWebhook(ctx, s.webhooks, eventType, offer, offer.BuyerAgentID, offer.ListingAgentID)
+}
+
+// SubmitOffer opens a negotiation between the current agent, acting for a
+// buyer, and the listing agent.
+func (s *OfferService) SubmitOffer(
+	ctx context.Context,
+	req *dto.OfferTermsRequest,
+	currentAgentCtx *domain.ContextSessionData,
+	listingId int,
+) (*domain.Offer, error) {
+	revision, err := s.validateTerms(req, currentAgentCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	listing, err := s.listingRepo.GetListingById(ctx, listingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if listing.Status != domain.ListingStatusActive {
+		return nil, errors.New("Offers can only be made on active listings")
+	}
+
+	if listing.AgentID == currentAgentCtx.UserID {
+		return nil, errors.New("Cannot make an offer on your own listing")
+	}
+
+	offer, err := s.offerRepo.CreateOffer(ctx, &domain.Offer{
+		ListingID:      listingId,
+		BuyerAgentID:   currentAgentCtx.UserID,
+		ListingAgentID: listing.AgentID,
+		Revisions:      []domain.OfferRevision{*revision},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(
+		ctx,
+		offer,
+		map[int]bool{listing.AgentID: true},
+		domain.NotificationTypeOfferReceived,
+		fmt.Sprintf("New offer of $%d on %s", revision.Amount, listing.Address),
+	)
+	s.publish(ctx, offer, domain.WebhookEventOfferSubmitted)
+
+	return offer, nil
+}
+
+func (s *OfferService) GetMyOffers(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+) ([]*domain.Offer, error) {
+	offers, err := s.offerRepo.GetOffersByUserId(ctx, currentAgentCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if offers == nil {
+		offers = []*domain.Offer{}
+	}
+
+	return offers, nil
+}
+
+// GetOfferById returns the offer with its full history. Offers are only
+// visible to the two agents negotiating them and to admins.
+func (s *OfferService) GetOfferById(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+	offerId int,
+) (*domain.Offer, error) {
+	offer, err := s.offerRepo.GetOfferById(ctx, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if currentAgentCtx.Role != "admin" {
+		if _, err := offerCounterparty(offer, currentAgentCtx.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return offer, nil
+}
+
+// CounterOffer replaces the current terms and hands the turn back.
+func (s *OfferService) CounterOffer(
+	ctx context.Context,
+	req *dto.OfferTermsRequest,
+	currentAgentCtx *domain.ContextSessionData,
+	offerId int,
+) (*domain.Offer, error) {
+	revision, err := s.validateTerms(req, currentAgentCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	offer, err := s.offerRepo.GetOfferById(ctx, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	otherId, err := s.authorizeTurn(offer, currentAgentCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	countered, err := s.offerRepo.CounterOffer(ctx, offerId, offer.LatestRevision().ID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(
+		ctx,
+		countered,
+		map[int]bool{otherId: true},
+		domain.NotificationTypeOfferCountered,
+		fmt.Sprintf("Your offer was countered at $%d", revision.Amount),
+	)
+	s.publish(ctx, countered, domain.WebhookEventOfferCountered)
+
+	return countered, nil
+}
+
+// AcceptOffer accepts the current terms and puts the listing under contract.
+// Competing open offers are rejected, and users who favorited the listing
+// are told it is now pending.
+func (s *OfferService) AcceptOffer(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+	offerId int,
+) (*domain.Offer, error) {
+	offer, err := s.offerRepo.GetOfferById(ctx, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	otherId, err := s.authorizeTurn(offer, currentAgentCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	listing, err := s.listingRepo.GetListingById(ctx, offer.ListingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if listing.Status != domain.ListingStatusActive {
+		return nil, repo.ErrListingNotActive
+	}
+
+	// The listing goes pending in the same transaction; favoriters hear of it
+	// through the outbox
+	accepted, rejected, err := s.offerRepo.AcceptOffer(ctx, offerId, offer.LatestRevision().ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(
+		ctx,
+		accepted,
+		map[int]bool{otherId: true},
+		domain.NotificationTypeOfferAccepted,
+		fmt.Sprintf("Offer on %s was accepted", listing.Address),
+	)
+	s.publish(ctx, accepted, domain.WebhookEventOfferAccepted)
+
+	for _, competing := range rejected {
+		s.notify(
+			ctx,
+			competing,
+			map[int]bool{competing.BuyerAgentID: true},
+			domain.NotificationTypeOfferRejected,
+			fmt.Sprintf("Another offer on %s was accepted", listing.Address),
+		)
+		s.publish(ctx, competing, domain.WebhookEventOfferRejected)
+	}
+
+	return accepted, nil
+}
+
+func (s *OfferService) RejectOffer(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+	offerId int,
+) (*domain.Offer, error) {
+	offer, err := s.offerRepo.GetOfferById(ctx, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	otherId, err := s.authorizeTurn(offer, currentAgentCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	rejected, err := s.offerRepo.UpdateOfferStatus(
+		ctx,
+		offerId,
+		offer.Status,
+		domain.OfferStatusRejected,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(
+		ctx,
+		rejected,
+		map[int]bool{otherId: true},
+		domain.NotificationTypeOfferRejected,
+		"Your offer was rejected",
+	)
+	s.publish(ctx, rejected, domain.WebhookEventOfferRejected)
+
+	return rejected, nil
+}
+
+// WithdrawOffer lets the buyer's agent pull an open offer at any point in the
+// negotiation.
+func (s *OfferService) WithdrawOffer(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+	offerId int,
+) (*domain.Offer, error) {
+	offer, err := s.GetOfferById(ctx, currentAgentCtx, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer.BuyerAgentID != currentAgentCtx.UserID {
+		return nil, errors.New("Only the buyer's agent can withdraw an offer")
+	}
+
+	if !offer.IsOpen() {
+		return nil, errors.New("Offer is no longer open")
+	}
+
+	withdrawn, err := s.offerRepo.UpdateOfferStatus(
+		ctx,
+		offerId,
+		offer.Status,
+		domain.OfferStatusWithdrawn,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(
+		ctx,
+		withdrawn,
+		map[int]bool{withdrawn.ListingAgentID: true},
+		domain.NotificationTypeOfferWithdrawn,
+		"An offer on your listing was withdrawn",
+	)
+	s.publish(ctx, withdrawn, domain.WebhookEventOfferWithdrawn)
+
+	return withdrawn, nil
+}
+
+// ExpireOffers lapses open offers whose current terms have expired and tells
+// both agents. It runs as a background job.
+func (s *OfferService) ExpireOffers(ctx context.Context) error {
+	offers, err := s.offerRepo.ExpireOffers(ctx, s.now())
+	if err != nil {
+		return err
+	}
+
+	for _, offer := range offers {
+		s.notify(
+			ctx,
+			offer,
+			map[int]bool{offer.BuyerAgentID: true, offer.ListingAgentID: true},
+			domain.NotificationTypeOfferExpired,
+			"An offer expired without a response",
+		)
+		s.publish(ctx, offer, domain.WebhookEventOfferExpired)
+	}
+
+	if len(offers) > 0 {
+		slog.Info("Expired offers", slog.Int("count", len(offers)))
+	}
+
+	return nil
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+func newTestOfferService(
+	offerRepo *repo.OfferRepoMock,
+	listingRepo *repo.ListingRepoMock,
+	pusher Pusher,
+) *OfferService {
+	notificationRepo := &repo.NotificationRepoMock{
+		CreateNotificationsFunc: storeNotifications,
+	}
+	notificationService := newTestNotificationService(notificationRepo, &repo.FavoriteRepoMock{}, pusher)
+
+	return NewOfferService(offerRepo, listingRepo, notificationService, nil)
+}
+
+func TestSubmitOffer(t *testing.T) {
+	now := time.Date(2025, 11, 26, 12, 0, 0, 0, time.UTC)
+	validTerms := func() dto.OfferTermsRequest {
+		return dto.OfferTermsRequest{
+			Amount:        500000,
+			EarnestMoney:  10000,
+			Contingencies: []string{"inspection", "financing", "inspection"},
+			ClosingDate:   "2026-01-15",
+			ExpiresAt:     now.Add(48 * time.Hour),
+		}
+	}
+
+	tests := []struct {
+		name          string
+		agentId       int
+		listingStatus string
+		modify        func(req *dto.OfferTermsRequest)
+		expectedErr   string
+	}{
+		{
+			name:          "Valid offer notifies listing agent",
+			agentId:       3,
+			listingStatus: domain.ListingStatusActive,
+		},
+		{
+			name:          "Earnest money above amount",
+			agentId:       3,
+			listingStatus: domain.ListingStatusActive,
+			modify:        func(req *dto.OfferTermsRequest) { req.EarnestMoney = 600000 },
+			expectedErr:   "Earnest money must be between 0 and the offer amount",
+		},
+		{
+			name:          "Unknown contingency",
+			agentId:       3,
+			listingStatus: domain.ListingStatusActive,
+			modify:        func(req *dto.OfferTermsRequest) { req.Contingencies = []string{"vibes"} },
+			expectedErr:   "Contingencies must be inspection, financing, appraisal or sale_of_home",
+		},
+		{
+			name:          "Expiry in the past",
+			agentId:       3,
+			listingStatus: domain.ListingStatusActive,
+			modify:        func(req *dto.OfferTermsRequest) { req.ExpiresAt = now.Add(-time.Minute) },
+			expectedErr:   "Expiry must be in the future",
+		},
+		{
+			name:          "Listing not active",
+			agentId:       3,
+			listingStatus: domain.ListingStatusPending,
+			expectedErr:   "Offers can only be made on active listings",
+		},
+		{
+			name:          "Agent cannot offer on own listing",
+			agentId:       2,
+			listingStatus: domain.ListingStatusActive,
+			expectedErr:   "Cannot make an offer on your own listing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pusher := &pushRecorder{pushes: map[int]string{}}
+			listingRepo := &repo.ListingRepoMock{
+				GetListingByIdFunc: func(ctx context.Context, id int) (*domain.Listing, error) {
+					return &domain.Listing{ID: id, AgentID: 2, Status: tt.listingStatus}, nil
+				},
+			}
+			offerRepo := &repo.OfferRepoMock{
+				CreateOfferFunc: func(ctx context.Context, offer *domain.Offer) (*domain.Offer, error) {
+					offer.ID = 1
+					offer.Status = domain.OfferStatusPending
+					return offer, nil
+				},
+			}
+
+			s := newTestOfferService(offerRepo, listingRepo, pusher)
+			s.now = func() time.Time { return now }
+
+			req := validTerms()
+			if tt.modify != nil {
+				tt.modify(&req)
+			}
+
+			offer, err := s.SubmitOffer(
+				context.Background(),
+				&req,
+				&domain.ContextSessionData{UserID: tt.agentId, Role: "agent"},
+				1,
+			)
+
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if offer.ListingAgentID != 2 || offer.BuyerAgentID != 3 {
+				t.Errorf("Expected listing agent 2 and buyer agent 3, received %+v", offer)
+			}
+
+			if len(offer.LatestRevision().Contingencies) != 2 {
+				t.Errorf("Expected 2, received %v", offer.LatestRevision().Contingencies)
+			}
+
+			if pusher.pushes[2] != domain.NotificationTypeOfferReceived {
+				t.Errorf("Expected listing agent to be notified, received %v", pusher.pushes)
+			}
+		})
+	}
+}
+
+func TestAcceptOffer(t *testing.T) {
+	now := time.Date(2025, 11, 26, 12, 0, 0, 0, time.UTC)
+
+	openOffer := func() *domain.Offer {
+		return &domain.Offer{
+			ID:             1,
+			ListingID:      4,
+			BuyerAgentID:   3,
+			ListingAgentID: 2,
+			Status:         domain.OfferStatusPending,
+			ExpiresAt:      now.Add(time.Hour),
+			Revisions:      []domain.OfferRevision{{ID: 10, AuthorID: 3}},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		userId      int
+		modify      func(offer *domain.Offer)
+		acceptErr   error
+		expectedErr error
+	}{
+		{
+			name:   "Listing agent accepts buyer offer",
+			userId: 2,
+		},
+		{
+			name:        "Buyer agent cannot accept own terms",
+			userId:      3,
+			expectedErr: ErrOfferNotYourTurn,
+		},
+		{
+			name:        "Outsider cannot accept",
+			userId:      9,
+			expectedErr: ErrOfferForbidden,
+		},
+		{
+			name:   "Buyer agent accepts counter",
+			userId: 3,
+			modify: func(offer *domain.Offer) {
+				offer.Status = domain.OfferStatusCountered
+				offer.Revisions = append(offer.Revisions, domain.OfferRevision{ID: 11, AuthorID: 2})
+			},
+		},
+		{
+			name:        "Another offer was accepted first",
+			userId:      2,
+			acceptErr:   repo.ErrListingUnderContract,
+			expectedErr: repo.ErrListingUnderContract,
+		},
+		{
+			name:        "Listing update fails and nothing is accepted",
+			userId:      2,
+			acceptErr:   repo.ErrListingNotActive,
+			expectedErr: repo.ErrListingNotActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pusher := &pushRecorder{pushes: map[int]string{}}
+			listingRepo := &repo.ListingRepoMock{
+				GetListingByIdFunc: func(ctx context.Context, id int) (*domain.Listing, error) {
+					return &domain.Listing{ID: id, AgentID: 2, Status: domain.ListingStatusActive}, nil
+				},
+			}
+			offerRepo := &repo.OfferRepoMock{
+				GetOfferByIdFunc: func(ctx context.Context, id int) (*domain.Offer, error) {
+					offer := openOffer()
+					if tt.modify != nil {
+						tt.modify(offer)
+					}
+					return offer, nil
+				},
+				AcceptOfferFunc: func(ctx context.Context, offerId int, latestRevisionId int) (*domain.Offer, []*domain.Offer, error) {
+					if tt.acceptErr != nil {
+						return nil, nil, tt.acceptErr
+					}
+
+					offer := openOffer()
+					offer.Status = domain.OfferStatusAccepted
+					competing := &domain.Offer{
+						ID:             5,
+						ListingID:      4,
+						BuyerAgentID:   6,
+						ListingAgentID: 2,
+						Status:         domain.OfferStatusRejected,
+					}
+					return offer, []*domain.Offer{competing}, nil
+				},
+			}
+
+			s := newTestOfferService(offerRepo, listingRepo, pusher)
+			s.now = func() time.Time { return now }
+
+			offer, err := s.AcceptOffer(
+				context.Background(),
+				&domain.ContextSessionData{UserID: tt.userId, Role: "agent"},
+				1,
+			)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if offer.Status != domain.OfferStatusAccepted {
+				t.Errorf("Expected accepted, received %s", offer.Status)
+			}
+
+			if pusher.pushes[6] != domain.NotificationTypeOfferRejected {
+				t.Errorf("Expected the competing buyer agent to hear of the rejection, received %v", pusher.pushes)
+			}
+		})
+	}
+}
+
+func TestAcceptExpiredOffer(t *testing.T) {
+	now := time.Date(2025, 11, 26, 12, 0, 0, 0, time.UTC)
+
+	offerRepo := &repo.OfferRepoMock{
+		GetOfferByIdFunc: func(ctx context.Context, id int) (*domain.Offer, error) {
+			return &domain.Offer{
+				ID:             1,
+				BuyerAgentID:   3,
+				ListingAgentID: 2,
+				Status:         domain.OfferStatusPending,
+				ExpiresAt:      now.Add(-time.Minute),
+				Revisions:      []domain.OfferRevision{{ID: 10, AuthorID: 3}},
+			}, nil
+		},
+	}
+
+	s := newTestOfferService(offerRepo, &repo.ListingRepoMock{}, &pushRecorder{pushes: map[int]string{}})
+	s.now = func() time.Time { return now }
+
+	_, err := s.AcceptOffer(context.Background(), &domain.ContextSessionData{UserID: 2}, 1)
+
+	if err == nil || err.Error() != "Offer has expired" {
+		t.Errorf("Expected %v, received %v", "Offer has expired", err)
+	}
+}
+
+func TestExpireOffers(t *testing.T) {
+	now := time.Date(2025, 11, 26, 12, 0, 0, 0, time.UTC)
+	var expiredAt time.Time
+
+	offerRepo := &repo.OfferRepoMock{
+		ExpireOffersFunc: func(ctx context.Context, at time.Time) ([]*domain.Offer, error) {
+			expiredAt = at
+			return []*domain.Offer{
+				{ID: 1, ListingID: 4, BuyerAgentID: 3, ListingAgentID: 2, Status: domain.OfferStatusExpired},
+			}, nil
+		},
+	}
+
+	pusher := &pushRecorder{pushes: map[int]string{}}
+	s := newTestOfferService(offerRepo, &repo.ListingRepoMock{}, pusher)
+	s.now = func() time.Time { return now }
+
+	if err := s.ExpireOffers(context.Background()); err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if !expiredAt.Equal(now) {
+		t.Errorf("Expected %v, received %v", now, expiredAt)
+	}
+
+	for _, userId := range []int{2, 3} {
+		if pusher.pushes[userId] != domain.NotificationTypeOfferExpired {
+			t.Errorf("Expected agent %d to be notified, received %v", userId, pusher.pushes)
+		}
+	}
+}
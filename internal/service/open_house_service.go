@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/ical"
+	"server/internal/repo"
+)
+
+const maxOpenHouseLength = 12 * time.Hour
+
+var ErrOpenHouseForbidden = errors.New("Cannot manage open houses on another agent's listing")
+
+type OpenHouseService struct {
+	openHouseRepo       repo.IOpenHouseRepo
+	listingRepo         repo.IListingRepo
+	userRepo            repo.IUserRepo
+	notificationService *NotificationService
+	now                 func() time.Time
+}
+
+func NewOpenHouseService(
+	openHouseRepo repo.IOpenHouseRepo,
+	listingRepo repo.IListingRepo,
+	userRepo repo.IUserRepo,
+	notificationService *NotificationService,
+) *OpenHouseService {
+	return &OpenHouseService{
+		openHouseRepo:       openHouseRepo,
+		listingRepo:         listingRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		now:                 time.Now,
+	}
+}
+
+// authorizeListing allows whoever may manage the listing's agent: the agent,
+// their broker or an admin.
+func (s *OpenHouseService) authorizeListing(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	listingId int,
+) error {
+	agentId, err := s.listingRepo.GetAgentIdByListingId(ctx, listingId)
+	if err != nil {
+		return err
+	}
+
+	allowed, err := canManageAgent(ctx, s.userRepo, currentUserCtx, agentId)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return ErrOpenHouseForbidden
+	}
+
+	return nil
+}
+
+func (s *OpenHouseService) CreateOpenHouse(
+	ctx context.Context,
+	req *dto.CreateOpenHouseRequest,
+	currentUserCtx *domain.ContextSessionData,
+	listingId int,
+) (*domain.OpenHouse, error) {
+	if req.StartTime.IsZero() || req.EndTime.IsZero() {
+		return nil, errors.New("Start and end time are required")
+	}
+
+	if !req.EndTime.After(req.StartTime) {
+		return nil, errors.New("End time must be after start time")
+	}
+
+	if req.EndTime.Sub(req.StartTime) > maxOpenHouseLength {
+		return nil, errors.New("Open house cannot be longer than 12 hours")
+	}
+
+	if req.StartTime.Before(s.now()) {
+		return nil, errors.New("Open house must start in the future")
+	}
+
+	if err := s.authorizeListing(ctx, currentUserCtx, listingId); err != nil {
+		return nil, err
+	}
+
+	openHouse, err := s.openHouseRepo.CreateOpenHouse(ctx, &domain.OpenHouse{
+		ListingID: listingId,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Notes:     req.Notes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyFavoriters(
+		ctx,
+		openHouse,
+		domain.NotificationTypeOpenHouseScheduled,
+		"An open house was scheduled for a listing you favorited",
+	)
+
+	return openHouse, nil
+}
+
+func (s *OpenHouseService) CancelOpenHouse(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	listingId int,
+	openHouseId int,
+) (*domain.OpenHouse, error) {
+	if err := s.authorizeListing(ctx, currentUserCtx, listingId); err != nil {
+		return nil, err
+	}
+
+	openHouse, err := s.openHouseRepo.CancelOpenHouse(ctx, listingId, openHouseId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyFavoriters(
+		ctx,
+		openHouse,
+		domain.NotificationTypeOpenHouseCancelled,
+		"An open house was cancelled for a listing you favorited",
+	)
+
+	return openHouse, nil
+}
+
+// notifyFavoriters is best effort. The open house change has already been
+// saved, so a delivery failure is logged rather than returned.
+func (s *OpenHouseService) notifyFavoriters(
+	ctx context.Context,
+	openHouse *domain.OpenHouse,
+	notificationType string,
+	message string,
+) {
+	err := s.notificationService.NotifyListingFavoriters(
+		ctx,
+		openHouse.ListingID,
+		notificationType,
+		message,
+	)
+	if err != nil {
+		slog.Warn(
+			"Failed to notify favoriters of open house",
+			slog.Int("open_house_id", openHouse.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (s *OpenHouseService) GetUpcomingOpenHousesByListingId(
+	ctx context.Context,
+	listingId int,
+) ([]*domain.OpenHouse, error) {
+	openHouses, err := s.openHouseRepo.GetUpcomingOpenHousesByListingIds(
+		ctx,
+		[]int{listingId},
+		s.now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if openHouses == nil {
+		openHouses = []*domain.OpenHouse{}
+	}
+
+	return openHouses, nil
+}
+
+// AttachUpcomingOpenHouses fills in each listing's upcoming open houses with a
+// single query.
+func (s *OpenHouseService) AttachUpcomingOpenHouses(
+	ctx context.Context,
+	listings ...*domain.Listing,
+) error {
+	if len(listings) == 0 {
+		return nil
+	}
+
+	byId := make(map[int]*domain.Listing, len(listings))
+	listingIds := make([]int, 0, len(listings))
+	for _, listing := range listings {
+		byId[listing.ID] = listing
+		listingIds = append(listingIds, listing.ID)
+	}
+
+	openHouses, err := s.openHouseRepo.GetUpcomingOpenHousesByListingIds(ctx, listingIds, s.now())
+	if err != nil {
+		return err
+	}
+
+	for _, openHouse := range openHouses {
+		if listing, ok := byId[openHouse.ListingID]; ok {
+			listing.OpenHouses = append(listing.OpenHouses, *openHouse)
+		}
+	}
+
+	return nil
+}
+
+// FilterOpenThisWeekend keeps the listings with an open house overlapping the
+// coming weekend in loc.
+func (s *OpenHouseService) FilterOpenThisWeekend(
+	ctx context.Context,
+	listings []*domain.Listing,
+	loc *time.Location,
+) ([]*domain.Listing, error) {
+	from, to := upcomingWeekend(s.now(), loc)
+
+	listingIds, err := s.openHouseRepo.GetListingIdsWithOpenHousesBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []*domain.Listing{}
+	for _, listing := range listings {
+		if listingIds[listing.ID] {
+			filtered = append(filtered, listing)
+		}
+	}
+
+	return filtered, nil
+}
+
+// upcomingWeekend returns Saturday 00:00 through Monday 00:00 in loc. During a
+// weekend the window starts now, so open houses that have ended are skipped.
+func upcomingWeekend(now time.Time, loc *time.Location) (time.Time, time.Time) {
+	local := now.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	var saturday time.Time
+	switch local.Weekday() {
+	case time.Saturday:
+		saturday = midnight
+	case time.Sunday:
+		saturday = midnight.AddDate(0, 0, -1)
+	default:
+		saturday = midnight.AddDate(0, 0, int(time.Saturday-local.Weekday()))
+	}
+
+	from := saturday
+	if now.After(from) {
+		from = now
+	}
+
+	return from, saturday.AddDate(0, 0, 2)
+}
+
+func (s *OpenHouseService) GetListingCalendar(
+	ctx context.Context,
+	listingId int,
+) (*ical.Calendar, error) {
+	listing, err := s.listingRepo.GetListingById(ctx, listingId)
+	if err != nil {
+		return nil, err
+	}
+
+	openHouses, err := s.openHouseRepo.GetOpenHousesByListingId(ctx, listingId, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, openHouse := range openHouses {
+		openHouse.Listing = listing
+	}
+
+	return s.calendar("Open houses: "+listing.Address, openHouses), nil
+}
+
+func (s *OpenHouseService) GetAgentCalendar(
+	ctx context.Context,
+	agentId int,
+) (*ical.Calendar, error) {
+	openHouses, err := s.openHouseRepo.GetOpenHousesByAgentId(ctx, agentId, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.calendar("Agent open houses", openHouses), nil
+}
+
+func (s *OpenHouseService) calendar(name string, openHouses []*domain.OpenHouse) *ical.Calendar {
+	cal := &ical.Calendar{Name: name, Events: []ical.Event{}}
+
+	for _, openHouse := range openHouses {
+		event := ical.Event{
+			UID:       fmt.Sprintf("open-house-%d@livelistings", openHouse.ID),
+			Start:     openHouse.StartTime,
+			End:       openHouse.EndTime,
+			Summary:   "Open house",
+			Cancelled: openHouse.CancelledAt != nil,
+			Stamp:     openHouse.CreatedAt,
+		}
+
+		if openHouse.CancelledAt != nil {
+			event.Stamp = *openHouse.CancelledAt
+		}
+
+		if openHouse.Listing != nil {
+			event.Summary = "Open house: " + openHouse.Listing.Address
+			event.Location = openHouse.Listing.Address
+		}
+
+		if openHouse.Notes != nil {
+			event.Description = *openHouse.Notes
+		}
+
+		cal.Events = append(cal.Events, event)
+	}
+
+	return cal
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+type pushRecorder struct {
+	pushes map[int]string
+}
+
+func (p *pushRecorder) PushToUser(userId int, eventType string, payload any) {
+	p.pushes[userId] = eventType
+}
+
+func TestCreateOpenHouse(t *testing.T) {
+	now := time.Date(2025, 11, 12, 12, 0, 0, 0, time.UTC)
+	agentCtx := &domain.ContextSessionData{UserID: 2, Role: "agent"}
+
+	newOpenHouseService := func(
+		openHouseRepo *repo.OpenHouseRepoMock,
+		notificationRepo *repo.NotificationRepoMock,
+		pusher Pusher,
+	) *OpenHouseService {
+		listingRepo := &repo.ListingRepoMock{
+			GetAgentIdByListingIdFunc: func(ctx context.Context, listingId int) (int, error) {
+				return 2, nil
+			},
+		}
+		favoriteRepo := &repo.FavoriteRepoMock{
+			GetAllUserIdsByListingIdFunc: func(ctx context.Context, listingId int) (map[int]bool, error) {
+				return map[int]bool{7: true, 8: true}, nil
+			},
+		}
+
+		userRepo := &repo.UserRepoMock{
+			GetUserByIdFunc: func(ctx context.Context, id int) (*domain.User, error) {
+				return &domain.User{ID: id, Role: "agent", BrokerageID: intPtr(10)}, nil
+			},
+		}
+
+		notificationService := newTestNotificationService(notificationRepo, favoriteRepo, pusher)
+
+		s := NewOpenHouseService(openHouseRepo, listingRepo, userRepo, notificationService)
+		s.now = func() time.Time { return now }
+
+		return s
+	}
+
+	tests := []struct {
+		name        string
+		userCtx     *domain.ContextSessionData
+		req         *dto.CreateOpenHouseRequest
+		expectedErr string
+	}{
+		{
+			name:    "Valid open house",
+			userCtx: agentCtx,
+			req: &dto.CreateOpenHouseRequest{
+				StartTime: now.Add(48 * time.Hour),
+				EndTime:   now.Add(50 * time.Hour),
+			},
+		},
+		{
+			name:    "End before start",
+			userCtx: agentCtx,
+			req: &dto.CreateOpenHouseRequest{
+				StartTime: now.Add(50 * time.Hour),
+				EndTime:   now.Add(48 * time.Hour),
+			},
+			expectedErr: "End time must be after start time",
+		},
+		{
+			name:    "Starts in the past",
+			userCtx: agentCtx,
+			req: &dto.CreateOpenHouseRequest{
+				StartTime: now.Add(-time.Hour),
+				EndTime:   now.Add(time.Hour),
+			},
+			expectedErr: "Open house must start in the future",
+		},
+		{
+			name:    "Another agent's listing",
+			userCtx: &domain.ContextSessionData{UserID: 3, Role: "agent"},
+			req: &dto.CreateOpenHouseRequest{
+				StartTime: now.Add(48 * time.Hour),
+				EndTime:   now.Add(50 * time.Hour),
+			},
+			expectedErr: ErrOpenHouseForbidden.Error(),
+		},
+		{
+			name:    "Broker of the listing agent",
+			userCtx: &domain.ContextSessionData{UserID: 1, Role: "broker", BrokerageID: intPtr(10)},
+			req: &dto.CreateOpenHouseRequest{
+				StartTime: now.Add(48 * time.Hour),
+				EndTime:   now.Add(50 * time.Hour),
+			},
+		},
+		{
+			name:    "Broker of another brokerage",
+			userCtx: &domain.ContextSessionData{UserID: 1, Role: "broker", BrokerageID: intPtr(11)},
+			req: &dto.CreateOpenHouseRequest{
+				StartTime: now.Add(48 * time.Hour),
+				EndTime:   now.Add(50 * time.Hour),
+			},
+			expectedErr: ErrOpenHouseForbidden.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pusher := &pushRecorder{pushes: map[int]string{}}
+			openHouseRepo := &repo.OpenHouseRepoMock{
+				CreateOpenHouseFunc: func(ctx context.Context, openHouse *domain.OpenHouse) (*domain.OpenHouse, error) {
+					openHouse.ID = 1
+					return openHouse, nil
+				},
+			}
+			notificationRepo := &repo.NotificationRepoMock{
+				CreateNotificationsFunc: storeNotifications,
+			}
+
+			s := newOpenHouseService(openHouseRepo, notificationRepo, pusher)
+
+			openHouse, err := s.CreateOpenHouse(context.Background(), tt.req, tt.userCtx, 1)
+
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if openHouse.ListingID != 1 {
+				t.Errorf("Expected listing 1, received %d", openHouse.ListingID)
+			}
+
+			if len(pusher.pushes) != 2 ||
+				pusher.pushes[7] != domain.NotificationTypeOpenHouseScheduled {
+				t.Errorf("Expected favoriters to be notified, received %v", pusher.pushes)
+			}
+		})
+	}
+
+	t.Run("Notification failure does not fail creation", func(t *testing.T) {
+		openHouseRepo := &repo.OpenHouseRepoMock{
+			CreateOpenHouseFunc: func(ctx context.Context, openHouse *domain.OpenHouse) (*domain.OpenHouse, error) {
+				return openHouse, nil
+			},
+		}
+		notificationRepo := &repo.NotificationRepoMock{
+			CreateNotificationsFunc: func(
+				ctx context.Context,
+				userIds []int,
+				listingId int,
+				notificationType string,
+				message string,
+			) ([]*domain.Notification, error) {
+				return nil, errors.New("db down")
+			},
+		}
+
+		s := newOpenHouseService(openHouseRepo, notificationRepo, nil)
+
+		_, err := s.CreateOpenHouse(context.Background(), &dto.CreateOpenHouseRequest{
+			StartTime: now.Add(48 * time.Hour),
+			EndTime:   now.Add(50 * time.Hour),
+		}, agentCtx, 1)
+		if err != nil {
+			t.Errorf("Expected success, received %v", err)
+		}
+	})
+}
+
+func TestUpcomingWeekend(t *testing.T) {
+	chicago, _ := time.LoadLocation("America/Chicago")
+
+	tests := []struct {
+		name         string
+		now          time.Time
+		expectedFrom time.Time
+		expectedTo   time.Time
+	}{
+		{
+			name:         "Weekday looks ahead to Saturday",
+			now:          time.Date(2025, 11, 12, 9, 0, 0, 0, chicago),
+			expectedFrom: time.Date(2025, 11, 15, 0, 0, 0, 0, chicago),
+			expectedTo:   time.Date(2025, 11, 17, 0, 0, 0, 0, chicago),
+		},
+		{
+			name:         "Sunday starts from now",
+			now:          time.Date(2025, 11, 16, 14, 0, 0, 0, chicago),
+			expectedFrom: time.Date(2025, 11, 16, 14, 0, 0, 0, chicago),
+			expectedTo:   time.Date(2025, 11, 17, 0, 0, 0, 0, chicago),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := upcomingWeekend(tt.now.UTC(), chicago)
+
+			if !from.Equal(tt.expectedFrom) || !to.Equal(tt.expectedTo) {
+				t.Errorf(
+					"Expected %v - %v, received %v - %v",
+					tt.expectedFrom,
+					tt.expectedTo,
+					from,
+					to,
+				)
+			}
+		})
+	}
+}
+
+func TestGetListingCalendar(t *testing.T) {
+	now := time.Date(2025, 11, 12, 12, 0, 0, 0, time.UTC)
+	cancelledAt := now.Add(-time.Hour)
+
+	listingRepo := &repo.ListingRepoMock{
+		GetListingByIdFunc: func(ctx context.Context, id int) (*domain.Listing, error) {
+			return &domain.Listing{ID: id, Address: "123 Test St"}, nil
+		},
+	}
+	openHouseRepo := &repo.OpenHouseRepoMock{
+		GetOpenHousesByListingIdFunc: func(ctx context.Context, listingId int, from time.Time) ([]*domain.OpenHouse, error) {
+			return []*domain.OpenHouse{
+				{ID: 1, ListingID: listingId, StartTime: now.Add(time.Hour), EndTime: now.Add(3 * time.Hour)},
+				{ID: 2, ListingID: listingId, StartTime: now.Add(time.Hour), EndTime: now.Add(3 * time.Hour), CancelledAt: &cancelledAt},
+			}, nil
+		},
+	}
+
+	s := NewOpenHouseService(openHouseRepo, listingRepo, &repo.UserRepoMock{}, nil)
+	s.now = func() time.Time { return now }
+
+	cal, err := s.GetListingCalendar(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if len(cal.Events) != 2 {
+		t.Fatalf("Expected 2 events, received %d", len(cal.Events))
+	}
+
+	if cal.Events[0].Location != "123 Test St" || cal.Events[0].Cancelled {
+		t.Errorf("Expected confirmed event at listing address, received %+v", cal.Events[0])
+	}
+
+	if !cal.Events[1].Cancelled || !cal.Events[1].Stamp.Equal(cancelledAt) {
+		t.Errorf("Expected cancelled event stamped at cancellation, received %+v", cal.Events[1])
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+const (
+	// Events are claimed in batches and relayed one at a time in the order
+	// they were recorded.
+	outboxBatchSize = 100
+	outboxLease     = time.Minute
+
+	// A failing event is retried after 10s, 20s, 40s, ... capped at 1h, and
+	// marked failed after the last attempt.
+	maxOutboxAttempts    = 10
+	outboxInitialBackoff = 10 * time.Second
+	maxOutboxBackoff     = time.Hour
+)
+
+// OutboxHandler publishes a relayed event to its consumers. Events are
+// relayed at least once, so handlers pass event.DedupKey on to whatever they
+// persist. A handler fanning out to several consumers adds those that
+// succeeded to event.DeliveredTo, which is saved with the attempt, and skips
+// them when the event is retried.
+type OutboxHandler func(ctx context.Context, event *domain.OutboxEvent) error
+
+// OutboxRelay publishes the events services record in the outbox to the
+// handlers subscribed to each event type.
+type OutboxRelay struct {
+	outboxRepo repo.IOutboxRepo
+	handlers   map[string][]OutboxHandler
+	now        func() time.Time
+}
+
+func NewOutboxRelay(outboxRepo repo.IOutboxRepo) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo: outboxRepo,
+		handlers:   map[string][]OutboxHandler{},
+		now:        time.Now,
+	}
+}
+
+// Subscribe runs handler for every relayed event of eventType, after the
+// handlers subscribed before it.
+func (r *OutboxRelay) Subscribe(eventType string, handler OutboxHandler) {
+	r.handlers[eventType] = append(r.handlers[eventType], handler)
+}
+
+// RelayPending publishes every event whose next attempt is due. It runs as a
+// scheduled job.
+func (r *OutboxRelay) RelayPending(ctx context.Context) error {
+	for {
+		events, err := r.outboxRepo.ClaimDueEvents(ctx, outboxBatchSize, outboxLease)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			r.relay(ctx, event)
+		}
+
+		if len(events) < outboxBatchSize {
+			return nil
+		}
+	}
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxInitialBackoff
+	for range attempts - 1 {
+		backoff *= 2
+		if backoff >= maxOutboxBackoff {
+			return maxOutboxBackoff
+		}
+	}
+
+	return backoff
+}
+
+func (r *OutboxRelay) relay(ctx context.Context, event *domain.OutboxEvent) {
+	var errs []error
+	for _, handler := range r.handlers[event.EventType] {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	now := r.now()
+	event.Attempts++
+
+	if err := errors.Join(errs...); err != nil {
+		message := err.Error()
+		event.LastError = &message
+
+		if event.Attempts >= maxOutboxAttempts {
+			event.Status = domain.OutboxEventFailed
+			slog.Error(
+				"Outbox event failed",
+				slog.Int("event_id", event.ID),
+				slog.String("event_type", event.EventType),
+				slog.String("error", message),
+			)
+		} else {
+			event.NextAttemptAt = now.Add(outboxBackoff(event.Attempts))
+			slog.Warn(
+				"Failed to relay outbox event",
+				slog.Int("event_id", event.ID),
+				slog.String("event_type", event.EventType),
+				slog.Int("attempts", event.Attempts),
+				slog.String("error", message),
+			)
+		}
+	} else {
+		event.Status = domain.OutboxEventPublished
+		event.LastError = nil
+		event.PublishedAt = &now
+	}
+
+	if err := r.outboxRepo.RecordAttempt(ctx, event); err != nil {
+		slog.Error(
+			"Failed to record outbox attempt",
+			slog.Int("event_id", event.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"server/internal/domain"
+	"server/internal/events"
+	"server/internal/repo"
+)
+
+func TestRelayPending(t *testing.T) {
+	now := time.Date(2025, 12, 26, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		attempts          int
+		failWith          error
+		expectedStatus    string
+		expectedAttempts  int
+		expectedNextRetry time.Time
+	}{
+		{
+			name:             "Published once every handler succeeds",
+			expectedStatus:   domain.OutboxEventPublished,
+			expectedAttempts: 1,
+		},
+		{
+			name:              "Failed handler retries with backoff",
+			attempts:          2,
+			failWith:          errors.New("db down"),
+			expectedStatus:    domain.OutboxEventPending,
+			expectedAttempts:  3,
+			expectedNextRetry: now.Add(40 * time.Second),
+		},
+		{
+			name:             "Failed on the last attempt",
+			attempts:         maxOutboxAttempts - 1,
+			failWith:         errors.New("db down"),
+			expectedStatus:   domain.OutboxEventFailed,
+			expectedAttempts: maxOutboxAttempts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorded *domain.OutboxEvent
+			outboxRepo := &repo.OutboxRepoMock{
+				ClaimDueEventsFunc: func(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxEvent, error) {
+					return []*domain.OutboxEvent{{
+						ID:        1,
+						EventType: domain.OutboxEventFavoriteCreated,
+						DedupKey:  "favorite.created:1",
+						Status:    domain.OutboxEventPending,
+						Attempts:  tt.attempts,
+					}}, nil
+				},
+				RecordAttemptFunc: func(ctx context.Context, event *domain.OutboxEvent) error {
+					recorded = event
+					return nil
+				},
+			}
+
+			var ran []string
+			r := NewOutboxRelay(outboxRepo)
+			r.now = func() time.Time { return now }
+			r.Subscribe(domain.OutboxEventFavoriteCreated, func(ctx context.Context, event *domain.OutboxEvent) error {
+				ran = append(ran, "notifications")
+				return tt.failWith
+			})
+			r.Subscribe(domain.OutboxEventFavoriteCreated, func(ctx context.Context, event *domain.OutboxEvent) error {
+				ran = append(ran, "webhooks")
+				return nil
+			})
+			r.Subscribe(domain.OutboxEventListingPriceChanged, func(ctx context.Context, event *domain.OutboxEvent) error {
+				ran = append(ran, "price")
+				return nil
+			})
+
+			if err := r.RelayPending(context.Background()); err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			// A failing handler must not keep the others from running
+			if len(ran) != 2 || ran[0] != "notifications" || ran[1] != "webhooks" {
+				t.Errorf("Expected [notifications webhooks], received %v", ran)
+			}
+
+			if recorded.Status != tt.expectedStatus {
+				t.Errorf("Expected %s, received %s", tt.expectedStatus, recorded.Status)
+			}
+
+			if recorded.Attempts != tt.expectedAttempts {
+				t.Errorf("Expected %d attempts, received %d", tt.expectedAttempts, recorded.Attempts)
+			}
+
+			if !tt.expectedNextRetry.IsZero() && !recorded.NextAttemptAt.Equal(tt.expectedNextRetry) {
+				t.Errorf("Expected retry at %v, received %v", tt.expectedNextRetry, recorded.NextAttemptAt)
+			}
+
+			if tt.failWith == nil && (recorded.PublishedAt == nil || recorded.LastError != nil) {
+				t.Errorf("Expected published with no error, received %v", recorded)
+			}
+
+			if tt.failWith != nil && (recorded.LastError == nil || *recorded.LastError != tt.failWith.Error()) {
+				t.Errorf("Expected last error %q, received %v", tt.failWith, recorded.LastError)
+			}
+		})
+	}
+}
+
+func TestRelayRetrySkipsDeliveredSubscribers(t *testing.T) {
+	pending := &domain.OutboxEvent{
+		ID:        1,
+		EventType: domain.OutboxEventFavoriteCreated,
+		DedupKey:  "favorite.created:1",
+		Payload:   []byte(`{"favorite_id":1,"user_id":8,"listing_id":7,"agent_id":2}`),
+		Status:    domain.OutboxEventPending,
+	}
+
+	outboxRepo := &repo.OutboxRepoMock{
+		ClaimDueEventsFunc: func(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxEvent, error) {
+			if pending.Status != domain.OutboxEventPending {
+				return nil, nil
+			}
+
+			claimed := *pending
+			return []*domain.OutboxEvent{&claimed}, nil
+		},
+		RecordAttemptFunc: func(ctx context.Context, event *domain.OutboxEvent) error {
+			pending = event
+			return nil
+		},
+	}
+
+	notificationsDown := true
+	var notified, pushed int
+
+	bus := events.NewBus()
+	events.Subscribe(bus, "notifications", func(ctx context.Context, event events.FavoriteAdded) error {
+		if notificationsDown {
+			return errors.New("db down")
+		}
+		notified++
+		return nil
+	})
+	events.Subscribe(bus, "ws", func(ctx context.Context, event events.FavoriteAdded) error {
+		pushed++
+		return nil
+	})
+
+	r := NewOutboxRelay(outboxRepo)
+	r.Subscribe(domain.OutboxEventFavoriteCreated, bus.PublishOutboxEvent)
+
+	if err := r.RelayPending(context.Background()); err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if pending.Status != domain.OutboxEventPending || !reflect.DeepEqual(pending.DeliveredTo, []string{"ws"}) {
+		t.Fatalf("Expected a retry recorded as delivered to ws, received %+v", pending)
+	}
+
+	notificationsDown = false
+	pending.NextAttemptAt = time.Time{}
+	if err := r.RelayPending(context.Background()); err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if pending.Status != domain.OutboxEventPublished {
+		t.Errorf("Expected %s, received %s", domain.OutboxEventPublished, pending.Status)
+	}
+
+	if notified != 1 || pushed != 1 {
+		t.Errorf("Expected one notification and one push, received %d and %d", notified, pushed)
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+const maxReviewLength = 2000
+
+var (
+	ErrReviewNotAllowed = errors.New("You can only review agents you have worked with")
+	ErrReviewForbidden  = errors.New("You cannot manage this review")
+)
+
+type ReviewService struct {
+	reviewRepo repo.IReviewRepo
+	userRepo   repo.IUserRepo
+}
+
+func NewReviewService(reviewRepo repo.IReviewRepo, userRepo repo.IUserRepo) *ReviewService {
+	return &ReviewService{reviewRepo: reviewRepo, userRepo: userRepo}
+}
+
+// CreateReview records the current user's one review of the agent. Only users
+// who have had a showing, conversation or inquiry with the agent may review.
+func (s *ReviewService) CreateReview(
+	ctx context.Context,
+	req *dto.CreateReviewRequest,
+	currentUserCtx *domain.ContextSessionData,
+	agentId int,
+) (*domain.Review, error) {
+	if req.Rating < 1 || req.Rating > 5 {
+		return nil, errors.New("Rating must be between 1 and 5")
+	}
+
+	if req.Body != nil {
+		body := strings.TrimSpace(*req.Body)
+		if utf8.RuneCountInString(body) > maxReviewLength {
+			return nil, errors.New("Review cannot be longer than 2000 characters")
+		}
+
+		req.Body = &body
+		if body == "" {
+			req.Body = nil
+		}
+	}
+
+	if agentId == currentUserCtx.UserID {
+		return nil, errors.New("You cannot review yourself")
+	}
+
+	if _, err := s.userRepo.GetAgentById(ctx, agentId); err != nil {
+		return nil, err
+	}
+
+	interacted, err := s.reviewRepo.HasInteractedWithAgent(ctx, currentUserCtx.UserID, agentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !interacted {
+		return nil, ErrReviewNotAllowed
+	}
+
+	return s.reviewRepo.CreateReview(ctx, &domain.Review{
+		AgentID:    agentId,
+		ReviewerID: currentUserCtx.UserID,
+		Rating:     req.Rating,
+		Body:       req.Body,
+	})
+}
+
+// GetAgentReviews returns the agent's published reviews. Admins also see
+// hidden ones. currentUserCtx is nil for anonymous visitors.
+func (s *ReviewService) GetAgentReviews(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	agentId int,
+) ([]*domain.Review, error) {
+	includeHidden := currentUserCtx != nil && currentUserCtx.Role == "admin"
+
+	reviews, err := s.reviewRepo.GetReviewsByAgentId(ctx, agentId, includeHidden)
+	if err != nil {
+		return nil, err
+	}
+
+	if reviews == nil {
+		reviews = []*domain.Review{}
+	}
+
+	return reviews, nil
+}
+
+// ReplyToReview posts the reviewed agent's public reply.
+func (s *ReviewService) ReplyToReview(
+	ctx context.Context,
+	currentAgentCtx *domain.ContextSessionData,
+	reviewId int,
+	reply string,
+) (*domain.Review, error) {
+	reply = strings.TrimSpace(reply)
+	if reply == "" {
+		return nil, errors.New("Reply cannot be empty")
+	}
+
+	if utf8.RuneCountInString(reply) > maxReviewLength {
+		return nil, errors.New("Reply cannot be longer than 2000 characters")
+	}
+
+	review, err := s.reviewRepo.GetReviewById(ctx, reviewId)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.AgentID != currentAgentCtx.UserID {
+		return nil, ErrReviewForbidden
+	}
+
+	return s.reviewRepo.ReplyToReview(ctx, reviewId, reply)
+}
+
+// ModerateReview lets admins hide a review from the public or restore it.
+func (s *ReviewService) ModerateReview(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	reviewId int,
+	status string,
+) (*domain.Review, error) {
+	if currentUserCtx.Role != "admin" {
+		return nil, ErrReviewForbidden
+	}
+
+	if !domain.ReviewStatuses[status] {
+		return nil, errors.New("Status must be published or hidden")
+	}
+
+	return s.reviewRepo.UpdateReviewStatus(ctx, reviewId, status)
+}
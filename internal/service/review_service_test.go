@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+func TestCreateReview(t *testing.T) {
+	tests := []struct {
+		name        string
+		userId      int
+		rating      int
+		interacted  bool
+		createErr   error
+		expectedErr error
+	}{
+		{
+			name:       "User who worked with agent can review",
+			userId:     5,
+			rating:     4,
+			interacted: true,
+		},
+		{
+			name:        "Rating out of range",
+			userId:      5,
+			rating:      6,
+			interacted:  true,
+			expectedErr: errors.New("Rating must be between 1 and 5"),
+		},
+		{
+			name:        "User without interaction cannot review",
+			userId:      5,
+			rating:      5,
+			expectedErr: ErrReviewNotAllowed,
+		},
+		{
+			name:        "Agent cannot review themselves",
+			userId:      2,
+			rating:      5,
+			interacted:  true,
+			expectedErr: errors.New("You cannot review yourself"),
+		},
+		{
+			name:        "Second review is rejected",
+			userId:      5,
+			rating:      3,
+			interacted:  true,
+			createErr:   repo.ErrReviewExists,
+			expectedErr: repo.ErrReviewExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := &repo.UserRepoMock{
+				GetAgentByIdFunc: func(ctx context.Context, id int) (*domain.Agent, error) {
+					return &domain.Agent{ID: id}, nil
+				},
+			}
+			reviewRepo := &repo.ReviewRepoMock{
+				HasInteractedWithAgentFunc: func(ctx context.Context, userId int, agentId int) (bool, error) {
+					return tt.interacted, nil
+				},
+				CreateReviewFunc: func(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+					if tt.createErr != nil {
+						return nil, tt.createErr
+					}
+
+					review.ID = 1
+					review.Status = domain.ReviewStatusPublished
+					return review, nil
+				},
+			}
+
+			s := NewReviewService(reviewRepo, userRepo)
+
+			review, err := s.CreateReview(
+				context.Background(),
+				&dto.CreateReviewRequest{Rating: tt.rating},
+				&domain.ContextSessionData{UserID: tt.userId, Role: "user"},
+				2,
+			)
+
+			if tt.expectedErr != nil {
+				if err == nil || err.Error() != tt.expectedErr.Error() {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if review.AgentID != 2 || review.ReviewerID != tt.userId || review.Rating != tt.rating {
+				t.Errorf("Expected review of agent 2, received %+v", review)
+			}
+		})
+	}
+}
+
+func TestReplyToReview(t *testing.T) {
+	tests := []struct {
+		name        string
+		agentId     int
+		reply       string
+		expectedErr error
+	}{
+		{
+			name:    "Reviewed agent can reply",
+			agentId: 2,
+			reply:   "Thanks for working with me!",
+		},
+		{
+			name:        "Other agent cannot reply",
+			agentId:     3,
+			reply:       "Hello",
+			expectedErr: ErrReviewForbidden,
+		},
+		{
+			name:        "Empty reply",
+			agentId:     2,
+			reply:       "   ",
+			expectedErr: errors.New("Reply cannot be empty"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reviewRepo := &repo.ReviewRepoMock{
+				GetReviewByIdFunc: func(ctx context.Context, id int) (*domain.Review, error) {
+					return &domain.Review{ID: id, AgentID: 2, ReviewerID: 5}, nil
+				},
+				ReplyToReviewFunc: func(ctx context.Context, id int, reply string) (*domain.Review, error) {
+					return &domain.Review{ID: id, AgentID: 2, Reply: &reply}, nil
+				},
+			}
+
+			s := NewReviewService(reviewRepo, &repo.UserRepoMock{})
+
+			review, err := s.ReplyToReview(
+				context.Background(),
+				&domain.ContextSessionData{UserID: tt.agentId, Role: "agent"},
+				1,
+				tt.reply,
+			)
+
+			if tt.expectedErr != nil {
+				if err == nil || err.Error() != tt.expectedErr.Error() {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if review.Reply == nil || *review.Reply != tt.reply {
+				t.Errorf("Expected %v, received %v", tt.reply, review.Reply)
+			}
+		})
+	}
+}
+
+func TestModerateReview(t *testing.T) {
+	reviewRepo := &repo.ReviewRepoMock{
+		UpdateReviewStatusFunc: func(ctx context.Context, id int, status string) (*domain.Review, error) {
+			return &domain.Review{ID: id, Status: status}, nil
+		},
+	}
+
+	s := NewReviewService(reviewRepo, &repo.UserRepoMock{})
+
+	_, err := s.ModerateReview(
+		context.Background(),
+		&domain.ContextSessionData{UserID: 2, Role: "agent"},
+		1,
+		domain.ReviewStatusHidden,
+	)
+	if !errors.Is(err, ErrReviewForbidden) {
+		t.Errorf("Expected %v, received %v", ErrReviewForbidden, err)
+	}
+
+	review, err := s.ModerateReview(
+		context.Background(),
+		&domain.ContextSessionData{UserID: 1, Role: "admin"},
+		1,
+		domain.ReviewStatusHidden,
+	)
+	if err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if review.Status != domain.ReviewStatusHidden {
+		t.Errorf("Expected %s, received %s", domain.ReviewStatusHidden, review.Status)
+	}
+}
@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+const (
+	maxShowingSlots      = 5
+	maxShowingLength     = 2 * time.Hour
+	defaultCalendarRange = 30 * 24 * time.Hour
+	maxCalendarRange     = 90 * 24 * time.Hour
+)
+
+var (
+	ErrShowingForbidden = errors.New("You are not a party to this showing")
+	ErrShowingNotYours  = errors.New("Waiting on the other party to respond")
+)
+
+// ShowingMailer sends the buyer a confirmation email once a time is agreed.
+type ShowingMailer interface {
+	SendShowingConfirmed(ctx context.Context, showing *domain.Showing) error
+}
+
+type ShowingService struct {
+	showingRepo         repo.IShowingRepo
+	listingRepo         repo.IListingRepo
+	notificationService *NotificationService
+	mailer              ShowingMailer
+	webhooks            WebhookPublisher
+	now                 func() time.Time
+}
+
+func NewShowingService(
+	showingRepo repo.IShowingRepo,
+	listingRepo repo.IListingRepo,
+	notificationService *NotificationService,
+	mailer ShowingMailer,
+	webhooks WebhookPublisher,
+) *ShowingService {
+	return &ShowingService{
+		showingRepo:         showingRepo,
+		listingRepo:         listingRepo,
+		notificationService: notificationService,
+		mailer:              mailer,
+		webhooks:            webhooks,
+		now:                 time.Now,
+	}
+}
+
+func (s *ShowingService) validateSlot(slot dto.ShowingSlotRequest) error {
+	if slot.StartTime.IsZero() || slot.EndTime.IsZero() {
+		return errors.New("Start and end time are required")
+	}
+
+	if !slot.EndTime.After(slot.StartTime) {
+		return errors.New("End time must be after start time")
+	}
+
+	if slot.EndTime.Sub(slot.StartTime) > maxShowingLength {
+		return errors.New("Showing cannot be longer than 2 hours")
+	}
+
+	if slot.StartTime.Before(s.now()) {
+		return errors.New("Showing must start in the future")
+	}
+
+	return nil
+}
+
+// counterparty returns the other party's user id, or ErrShowingForbidden if
+// userId is not on the showing.
+func counterparty(showing *domain.Showing, userId int) (int, error) {
+	switch userId {
+	case showing.BuyerID:
+		return showing.AgentID, nil
+	case showing.AgentID:
+		return showing.BuyerID, nil
+	default:
+		return 0, ErrShowingForbidden
+	}
+}
+
+// awaitingUser returns who must act next on a pending showing. Requests wait
+// on the agent and proposals wait on the buyer.
+func awaitingUser(showing *domain.Showing) (int, error) {
+	switch showing.Status {
+	case domain.ShowingStatusRequested:
+		return showing.AgentID, nil
+	case domain.ShowingStatusProposed:
+		return showing.BuyerID, nil
+	default:
+		return 0, errors.New("Showing is no longer pending")
+	}
+}
+
+func (s *ShowingService) authorizeTurn(showing *domain.Showing, userId int) (int, error) {
+	otherId, err := counterparty(showing, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	awaitingId, err := awaitingUser(showing)
+	if err != nil {
+		return 0, err
+	}
+
+	if awaitingId != userId {
+		return 0, ErrShowingNotYours
+	}
+
+	return otherId, nil
+}
+
+func (s *ShowingService) notify(
+	ctx context.Context,
+	showing *domain.Showing,
+	userId int,
+	notificationType string,
+	message string,
+) {
+	err := s.notificationService.NotifyUsers(
+		ctx,
+		map[int]bool{userId: true},
+		showing.ListingID,
+		notificationType,
+		message,
+	)
+	if err != nil {
+		slog.Warn(
+			"Failed to notify showing party",
+			slog.Int("showing_id", showing.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (s *ShowingService) RequestShowing(
+	ctx context.Context,
+	req *dto.CreateShowingRequest,
+	currentUserCtx *domain.ContextSessionData,
+	listingId int,
+) (*domain.Showing, error) {
+	if len(req.Slots) == 0 || len(req.Slots) > maxShowingSlots {
+		return nil, errors.New("Please propose between 1 and 5 time slots")
+	}
+
+	slots := make([]domain.ShowingSlot, 0, len(req.Slots))
+	for _, slot := range req.Slots {
+		if err := s.validateSlot(slot); err != nil {
+			return nil, err
+		}
+
+		slots = append(slots, domain.ShowingSlot{
+			StartTime:  slot.StartTime,
+			EndTime:    slot.EndTime,
+			ProposedBy: currentUserCtx.UserID,
+		})
+	}
+
+	agentId, err := s.listingRepo.GetAgentIdByListingId(ctx, listingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if agentId == currentUserCtx.UserID {
+		return nil, errors.New("Cannot request a showing on your own listing")
+	}
+
+	showing, err := s.showingRepo.CreateShowing(ctx, &domain.Showing{
+		ListingID: listingId,
+		BuyerID:   currentUserCtx.UserID,
+		AgentID:   agentId,
+		Message:   req.Message,
+		Slots:     slots,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(
+		ctx,
+		showing,
+		agentId,
+		domain.NotificationTypeShowingRequested,
+		"A buyer requested a showing on your listing",
+	)
+	publishWebhook(ctx, s.webhooks, domain.WebhookEventShowingRequested, showing, agentId)
+
+	return showing, nil
+}
+
+func (s *ShowingService) GetShowingById(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	showingId int,
+) (*domain.Showing, error) {
+	showing, err := s.showingRepo.GetShowingById(ctx, showingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if currentUserCtx.Role != "admin" {
+		if _, err := counterparty(showing, currentUserCtx.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return showing, nil
+}
+
+func (s *ShowingService) GetMyShowings(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+) ([]*domain.Showing, error) {
+	showings, err := s.showingRepo.GetShowingsByUserId(ctx, currentUserCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if showings == nil {
+		showings = []*domain.Showing{}
+	}
+
+	return showings, nil
+}
+
+// AcceptShowing confirms one of the slots offered by the other party.
+func (s *ShowingService) AcceptShowing(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	showingId int,
+	slotId int,
+) (*domain.Showing, error) {
+	showing, err := s.showingRepo.GetShowingById(ctx, showingId)
+	if err != nil {
+		return nil, err
+	}
+
+	otherId, err := s.authorizeTurn(showing, currentUserCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var slot *domain.ShowingSlot
+	for i := range showing.Slots {
+		if showing.Slots[i].ID == slotId && showing.Slots[i].ProposedBy == otherId {
+			slot = &showing.Slots[i]
+			break
+		}
+	}
+
+	if slot == nil {
+		return nil, errors.New("Slot was not offered by the other party")
+	}
+
+	if slot.StartTime.Before(s.now()) {
+		return nil, errors.New("Slot has already started")
+	}
+
+	confirmed, err := s.showingRepo.ConfirmShowing(ctx, showingId, showing.Status, slotId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(
+		ctx,
+		confirmed,
+		otherId,
+		domain.NotificationTypeShowingConfirmed,
+		"Your showing has been confirmed",
+	)
+	publishWebhook(ctx, s.webhooks, domain.WebhookEventShowingConfirmed, confirmed, confirmed.AgentID)
+
+	if s.mailer != nil {
+		if err := s.mailer.SendShowingConfirmed(ctx, confirmed); err != nil {
+			slog.Warn(
+				"Failed to send showing confirmation email",
+				slog.Int("showing_id", confirmed.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	return confirmed, nil
+}
+
+// ProposeShowingTime offers a new slot and hands the turn to the other party.
+func (s *ShowingService) ProposeShowingTime(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	showingId int,
+	req *dto.ShowingSlotRequest,
+) (*domain.Showing, error) {
+	if err := s.validateSlot(*req); err != nil {
+		return nil, err
+	}
+
+	showing, err := s.showingRepo.GetShowingById(ctx, showingId)
+	if err != nil {
+		return nil, err
+	}
+
+	otherId, err := s.authorizeTurn(showing, currentUserCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	toStatus := domain.ShowingStatusProposed
+	if currentUserCtx.UserID == showing.BuyerID {
+		toStatus = domain.ShowingStatusRequested
+	}
+
+	updated, err := s.showingRepo.AddShowingSlot(
+		ctx,
+		showingId,
+		showing.Status,
+		toStatus,
+		&domain.ShowingSlot{
+			StartTime:  req.StartTime,
+			EndTime:    req.EndTime,
+			ProposedBy: currentUserCtx.UserID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(
+		ctx,
+		updated,
+		otherId,
+		domain.NotificationTypeShowingProposed,
+		"A new time was proposed for your showing",
+	)
+
+	return updated, nil
+}
+
+// DeclineShowing lets either party back out of a pending or confirmed showing.
+func (s *ShowingService) DeclineShowing(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	showingId int,
+) (*domain.Showing, error) {
+	showing, err := s.showingRepo.GetShowingById(ctx, showingId)
+	if err != nil {
+		return nil, err
+	}
+
+	otherId, err := counterparty(showing, currentUserCtx.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if showing.Status == domain.ShowingStatusDeclined {
+		return nil, errors.New("Showing has already been declined")
+	}
+
+	declined, err := s.showingRepo.UpdateShowingStatus(
+		ctx,
+		showingId,
+		showing.Status,
+		domain.ShowingStatusDeclined,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(
+		ctx,
+		declined,
+		otherId,
+		domain.NotificationTypeShowingDeclined,
+		"Your showing has been declined",
+	)
+
+	return declined, nil
+}
+
+// GetAgentCalendar returns confirmed showings between from and to, defaulting
+// to the next 30 days.
+func (s *ShowingService) GetAgentCalendar(
+	ctx context.Context,
+	agentId int,
+	from time.Time,
+	to time.Time,
+) ([]*domain.Showing, error) {
+	if from.IsZero() {
+		from = s.now()
+	}
+
+	if to.IsZero() {
+		to = from.Add(defaultCalendarRange)
+	}
+
+	if !to.After(from) {
+		return nil, errors.New("End of range must be after start")
+	}
+
+	if to.Sub(from) > maxCalendarRange {
+		return nil, errors.New("Calendar range cannot exceed 90 days")
+	}
+
+	showings, err := s.showingRepo.GetConfirmedShowingsByAgentId(ctx, agentId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if showings == nil {
+		showings = []*domain.Showing{}
+	}
+
+	return showings, nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+func newTestShowingService(showingRepo *repo.ShowingRepoMock, pusher Pusher) *ShowingService {
+	listingRepo := &repo.ListingRepoMock{
+		GetAgentIdByListingIdFunc: func(ctx context.Context, listingId int) (int, error) {
+			return 2, nil
+		},
+	}
+	notificationRepo := &repo.NotificationRepoMock{
+		CreateNotificationsFunc: storeNotifications,
+	}
+
+	notificationService := newTestNotificationService(notificationRepo, &repo.FavoriteRepoMock{}, pusher)
+
+	return NewShowingService(showingRepo, listingRepo, notificationService, nil, nil)
+}
+
+func TestRequestShowing(t *testing.T) {
+	now := time.Date(2025, 11, 17, 12, 0, 0, 0, time.UTC)
+	slot := dto.ShowingSlotRequest{StartTime: now.Add(24 * time.Hour), EndTime: now.Add(25 * time.Hour)}
+
+	tests := []struct {
+		name        string
+		userCtx     *domain.ContextSessionData
+		slots       []dto.ShowingSlotRequest
+		expectedErr string
+	}{
+		{
+			name:    "Valid request notifies agent",
+			userCtx: &domain.ContextSessionData{UserID: 5, Role: "user"},
+			slots:   []dto.ShowingSlotRequest{slot},
+		},
+		{
+			name:        "No slots",
+			userCtx:     &domain.ContextSessionData{UserID: 5, Role: "user"},
+			expectedErr: "Please propose between 1 and 5 time slots",
+		},
+		{
+			name:    "Slot too long",
+			userCtx: &domain.ContextSessionData{UserID: 5, Role: "user"},
+			slots: []dto.ShowingSlotRequest{
+				{StartTime: slot.StartTime, EndTime: slot.StartTime.Add(3 * time.Hour)},
+			},
+			expectedErr: "Showing cannot be longer than 2 hours",
+		},
+		{
+			name:        "Agent cannot request own listing",
+			userCtx:     &domain.ContextSessionData{UserID: 2, Role: "agent"},
+			slots:       []dto.ShowingSlotRequest{slot},
+			expectedErr: "Cannot request a showing on your own listing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pusher := &pushRecorder{pushes: map[int]string{}}
+			showingRepo := &repo.ShowingRepoMock{
+				CreateShowingFunc: func(ctx context.Context, showing *domain.Showing) (*domain.Showing, error) {
+					showing.ID = 1
+					showing.Status = domain.ShowingStatusRequested
+					return showing, nil
+				},
+			}
+
+			s := newTestShowingService(showingRepo, pusher)
+			s.now = func() time.Time { return now }
+
+			showing, err := s.RequestShowing(
+				context.Background(),
+				&dto.CreateShowingRequest{Slots: tt.slots},
+				tt.userCtx,
+				1,
+			)
+
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if showing.AgentID != 2 || showing.Slots[0].ProposedBy != 5 {
+				t.Errorf("Expected agent 2 and buyer slot, received %+v", showing)
+			}
+
+			if pusher.pushes[2] != domain.NotificationTypeShowingRequested {
+				t.Errorf("Expected agent to be notified, received %v", pusher.pushes)
+			}
+		})
+	}
+}
+
+func TestAcceptShowing(t *testing.T) {
+	now := time.Date(2025, 11, 17, 12, 0, 0, 0, time.UTC)
+
+	pendingShowing := func() *domain.Showing {
+		return &domain.Showing{
+			ID:        1,
+			ListingID: 1,
+			BuyerID:   5,
+			AgentID:   2,
+			Status:    domain.ShowingStatusRequested,
+			Slots: []domain.ShowingSlot{
+				{ID: 10, StartTime: now.Add(24 * time.Hour), EndTime: now.Add(25 * time.Hour), ProposedBy: 5},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		userId      int
+		slotId      int
+		confirmErr  error
+		expectedErr error
+	}{
+		{
+			name:   "Agent accepts buyer slot",
+			userId: 2,
+			slotId: 10,
+		},
+		{
+			name:        "Buyer cannot accept own slot",
+			userId:      5,
+			slotId:      10,
+			expectedErr: ErrShowingNotYours,
+		},
+		{
+			name:        "Outsider cannot accept",
+			userId:      9,
+			slotId:      10,
+			expectedErr: ErrShowingForbidden,
+		},
+		{
+			name:        "Double booking is rejected",
+			userId:      2,
+			slotId:      10,
+			confirmErr:  repo.ErrShowingConflict,
+			expectedErr: repo.ErrShowingConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pusher := &pushRecorder{pushes: map[int]string{}}
+			showingRepo := &repo.ShowingRepoMock{
+				GetShowingByIdFunc: func(ctx context.Context, id int) (*domain.Showing, error) {
+					return pendingShowing(), nil
+				},
+				ConfirmShowingFunc: func(ctx context.Context, showingId int, fromStatus string, slotId int) (*domain.Showing, error) {
+					if tt.confirmErr != nil {
+						return nil, tt.confirmErr
+					}
+
+					showing := pendingShowing()
+					showing.Status = domain.ShowingStatusConfirmed
+					return showing, nil
+				},
+			}
+
+			s := newTestShowingService(showingRepo, pusher)
+			s.now = func() time.Time { return now }
+
+			showing, err := s.AcceptShowing(
+				context.Background(),
+				&domain.ContextSessionData{UserID: tt.userId},
+				1,
+				tt.slotId,
+			)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if showing.Status != domain.ShowingStatusConfirmed {
+				t.Errorf("Expected confirmed, received %s", showing.Status)
+			}
+
+			if pusher.pushes[5] != domain.NotificationTypeShowingConfirmed {
+				t.Errorf("Expected buyer to be notified, received %v", pusher.pushes)
+			}
+		})
+	}
+}
+
+func TestProposeShowingTime(t *testing.T) {
+	now := time.Date(2025, 11, 17, 12, 0, 0, 0, time.UTC)
+	var toStatus string
+
+	showingRepo := &repo.ShowingRepoMock{
+		GetShowingByIdFunc: func(ctx context.Context, id int) (*domain.Showing, error) {
+			return &domain.Showing{ID: 1, BuyerID: 5, AgentID: 2, Status: domain.ShowingStatusRequested}, nil
+		},
+		AddShowingSlotFunc: func(ctx context.Context, showingId int, from string, to string, slot *domain.ShowingSlot) (*domain.Showing, error) {
+			toStatus = to
+			return &domain.Showing{ID: 1, BuyerID: 5, AgentID: 2, Status: to}, nil
+		},
+	}
+
+	pusher := &pushRecorder{pushes: map[int]string{}}
+	s := newTestShowingService(showingRepo, pusher)
+	s.now = func() time.Time { return now }
+
+	_, err := s.ProposeShowingTime(
+		context.Background(),
+		&domain.ContextSessionData{UserID: 2, Role: "agent"},
+		1,
+		&dto.ShowingSlotRequest{StartTime: now.Add(48 * time.Hour), EndTime: now.Add(49 * time.Hour)},
+	)
+	if err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if toStatus != domain.ShowingStatusProposed {
+		t.Errorf("Expected %s, received %s", domain.ShowingStatusProposed, toStatus)
+	}
+
+	if pusher.pushes[5] != domain.NotificationTypeShowingProposed {
+		t.Errorf("Expected buyer to be notified, received %v", pusher.pushes)
+	}
+}
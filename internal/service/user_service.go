@@ -40,6 +40,34 @@ func (s *UserService) GetAgentById(ctx context.Context, id int) (*domain.Agent,
 	return s.userRepo.GetAgentById(ctx, id)
 }
 
+func (s *UserService) GetAgents(
+	ctx context.Context,
+	filter *domain.AgentFilter,
+) ([]*domain.Agent, error) {
+	if filter.Sort != "" && filter.Sort != domain.AgentSortRating {
+		return nil, errors.New("Sort must be rating")
+	}
+
+	if filter.ZipCode != "" && !zipCodePattern.MatchString(filter.ZipCode) {
+		return nil, errors.New("ZIP code must be 5 digits")
+	}
+
+	if filter.Specialty != "" && !domain.AgentSpecialties[filter.Specialty] {
+		return nil, errors.New("Invalid specialty")
+	}
+
+	agents, err := s.userRepo.GetAgents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if agents == nil {
+		agents = []*domain.Agent{}
+	}
+
+	return agents, nil
+}
+
 func (s *UserService) GetUsersByRole(ctx context.Context, role string) ([]*domain.User, error) {
 	return s.userRepo.GetUsersByRole(ctx, role)
 }
@@ -56,5 +84,9 @@ func (s *UserService) UpdateUserById(
 		)
 	}
 
+	if userCtx.Role != "admin" && userReq.Role != nil && *userReq.Role == "broker" {
+		return nil, errors.New("Only admins can grant the broker role")
+	}
+
 	return s.userRepo.UpdateUserById(ctx, userReq, userId)
 }
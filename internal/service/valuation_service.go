@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+const (
+	compLookback       = 365 * 24 * time.Hour
+	maxComparables     = 6
+	sizeAdjustmentRate = 0.5
+	minRangePct        = 0.05
+	thinDataRangePct   = 0.10
+)
+
+var ErrNotEnoughComparables = errors.New("Not enough comparable listings to estimate value")
+
+var compStatusWeights = map[string]float64{
+	domain.ListingStatusSold:    1.0,
+	domain.ListingStatusPending: 0.9,
+	domain.ListingStatusActive:  0.8,
+}
+
+type ValuationService struct {
+	listingRepo repo.IListingRepo
+	now         func() time.Time
+}
+
+func NewValuationService(listingRepo repo.IListingRepo) *ValuationService {
+	return &ValuationService{listingRepo: listingRepo, now: time.Now}
+}
+
+func (s *ValuationService) EstimateListingValue(
+	ctx context.Context,
+	listingId int,
+) (*domain.ValuationEstimate, error) {
+	subject, err := s.listingRepo.GetListingById(ctx, listingId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Comps are matched by area, and without one every other listing
+	// missing an area would match
+	if strings.TrimSpace(subject.ZipCode) == "" && strings.TrimSpace(subject.City) == "" {
+		return nil, ErrNotEnoughComparables
+	}
+
+	now := s.now()
+
+	candidates, err := s.listingRepo.GetComparableListings(ctx, subject, now.Add(-compLookback))
+	if err != nil {
+		return nil, err
+	}
+
+	return estimateValue(subject, candidates, now)
+}
+
+// estimateValue picks the highest scoring comps, adjusts each to the subject's
+// size and returns a score-weighted estimate. Ties are broken by listing id so
+// the same inputs always produce the same comps.
+func estimateValue(
+	subject *domain.Listing,
+	candidates []*domain.Listing,
+	now time.Time,
+) (*domain.ValuationEstimate, error) {
+	var comps []domain.Comparable
+	for _, candidate := range candidates {
+		if candidate.ID == subject.ID || candidate.SqFt <= 0 {
+			continue
+		}
+
+		score := scoreComparable(subject, candidate, now)
+		if score <= 0 {
+			continue
+		}
+
+		comps = append(comps, domain.Comparable{
+			Listing:       candidate,
+			Score:         math.Round(score*1000) / 1000,
+			AdjustedPrice: adjustForSize(subject, candidate),
+		})
+	}
+
+	if len(comps) == 0 {
+		return nil, ErrNotEnoughComparables
+	}
+
+	sort.Slice(comps, func(i, j int) bool {
+		if comps[i].Score != comps[j].Score {
+			return comps[i].Score > comps[j].Score
+		}
+		return comps[i].Listing.ID < comps[j].Listing.ID
+	})
+
+	if len(comps) > maxComparables {
+		comps = comps[:maxComparables]
+	}
+
+	var weightSum, weightedPrice float64
+	for _, comp := range comps {
+		weightSum += comp.Score
+		weightedPrice += comp.Score * float64(comp.AdjustedPrice)
+	}
+	estimate := weightedPrice / weightSum
+
+	var variance float64
+	for _, comp := range comps {
+		diff := float64(comp.AdjustedPrice) - estimate
+		variance += comp.Score * diff * diff
+	}
+	spread := math.Sqrt(variance / weightSum)
+
+	rangePct := minRangePct
+	if len(comps) < 3 {
+		rangePct = thinDataRangePct
+	}
+	spread = math.Max(spread, estimate*rangePct)
+
+	return &domain.ValuationEstimate{
+		ListingID:   subject.ID,
+		Estimate:    roundToHundred(estimate),
+		Low:         roundToHundred(estimate - spread),
+		High:        roundToHundred(estimate + spread),
+		Comparables: comps,
+	}, nil
+}
+
+// scoreComparable rates how similar comp is to subject between 0 and 1,
+// weighting size, bedrooms, bathrooms and recency, then discounting listings
+// that have not sold.
+func scoreComparable(subject, comp *domain.Listing, now time.Time) float64 {
+	statusWeight, ok := compStatusWeights[comp.Status]
+	if !ok || subject.SqFt <= 0 {
+		return 0
+	}
+
+	sizeDiff := math.Abs(float64(comp.SqFt-subject.SqFt)) / float64(subject.SqFt)
+	sizeScore := clamp01(1 - sizeDiff/0.3)
+	bedScore := clamp01(1 - 0.25*math.Abs(float64(comp.Beds-subject.Beds)))
+	bathScore := clamp01(1 - 0.25*math.Abs(float64(comp.Baths-subject.Baths)))
+
+	compDate := comp.CreatedAt
+	if comp.SoldAt != nil {
+		compDate = *comp.SoldAt
+	}
+	age := now.Sub(compDate)
+	recencyScore := clamp01(1 - float64(age)/float64(compLookback))
+
+	score := 0.4*sizeScore + 0.2*bedScore + 0.15*bathScore + 0.25*recencyScore
+
+	return score * statusWeight
+}
+
+// adjustForSize moves comp's price toward the subject's size at a fraction of
+// comp's price per square foot, since extra space is worth less than the
+// average foot.
+func adjustForSize(subject, comp *domain.Listing) int {
+	pricePerSqFt := float64(comp.Price) / float64(comp.SqFt)
+	adjustment := float64(subject.SqFt-comp.SqFt) * pricePerSqFt * sizeAdjustmentRate
+
+	return int(math.Round(float64(comp.Price) + adjustment))
+}
+
+func clamp01(v float64) float64 {
+	return math.Min(1, math.Max(0, v))
+}
+
+func roundToHundred(v float64) int {
+	return int(math.Round(v/100) * 100)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"server/internal/domain"
+	"server/internal/repo"
+)
+
+func TestEstimateListingValue(t *testing.T) {
+	now := time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC)
+	subject := &domain.Listing{
+		ID:           1,
+		ZipCode:      "37214",
+		PropertyType: "single_family",
+		Price:        425000,
+		Beds:         3,
+		Baths:        2,
+		SqFt:         2000,
+	}
+
+	t.Run("Comparables produce weighted estimate and range", func(t *testing.T) {
+		mockRepo := &repo.ListingRepoMock{
+			GetListingByIdFunc: func(ctx context.Context, id int) (*domain.Listing, error) {
+				return subject, nil
+			},
+			GetComparableListingsFunc: func(ctx context.Context, s *domain.Listing, since time.Time) ([]*domain.Listing, error) {
+				if !since.Equal(now.Add(-compLookback)) {
+					t.Errorf("Expected lookback from %v, received %v", now.Add(-compLookback), since)
+				}
+
+				return []*domain.Listing{
+					{ID: 3, Status: "active", Price: 440000, Beds: 3, Baths: 2, SqFt: 2200, CreatedAt: now},
+					{ID: 2, Status: "sold", Price: 400000, Beds: 3, Baths: 2, SqFt: 2000, SoldAt: &now},
+				}, nil
+			},
+		}
+
+		v := NewValuationService(mockRepo)
+		v.now = func() time.Time { return now }
+
+		estimate, err := v.EstimateListingValue(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("Expected success, received %v", err)
+		}
+
+		if estimate.Estimate != 408200 {
+			t.Errorf("Expected estimate 408200, received %d", estimate.Estimate)
+		}
+
+		// Fewer than three comps widens the range to 10%
+		if estimate.Low != 367400 || estimate.High != 449000 {
+			t.Errorf("Expected range 367400-449000, received %d-%d", estimate.Low, estimate.High)
+		}
+
+		if estimate.Comparables[0].Listing.ID != 2 {
+			t.Errorf("Expected sold comp first, received listing %d", estimate.Comparables[0].Listing.ID)
+		}
+
+		if estimate.Comparables[1].AdjustedPrice != 420000 {
+			t.Errorf("Expected adjusted price 420000, received %d", estimate.Comparables[1].AdjustedPrice)
+		}
+	})
+
+	t.Run("No usable comparables returns error", func(t *testing.T) {
+		mockRepo := &repo.ListingRepoMock{
+			GetListingByIdFunc: func(ctx context.Context, id int) (*domain.Listing, error) {
+				return subject, nil
+			},
+			GetComparableListingsFunc: func(ctx context.Context, s *domain.Listing, since time.Time) ([]*domain.Listing, error) {
+				return []*domain.Listing{
+					{ID: 4, Status: "withdrawn", Price: 400000, Beds: 3, Baths: 2, SqFt: 2000, CreatedAt: now},
+				}, nil
+			},
+		}
+
+		v := NewValuationService(mockRepo)
+		v.now = func() time.Time { return now }
+
+		_, err := v.EstimateListingValue(context.Background(), 1)
+		if !errors.Is(err, ErrNotEnoughComparables) {
+			t.Errorf("Expected %v, received %v", ErrNotEnoughComparables, err)
+		}
+	})
+
+	t.Run("Subject without zip or city returns error", func(t *testing.T) {
+		noArea := *subject
+		noArea.ZipCode = ""
+		noArea.City = ""
+
+		mockRepo := &repo.ListingRepoMock{
+			GetListingByIdFunc: func(ctx context.Context, id int) (*domain.Listing, error) {
+				return &noArea, nil
+			},
+			GetComparableListingsFunc: func(ctx context.Context, s *domain.Listing, since time.Time) ([]*domain.Listing, error) {
+				t.Error("Expected no comparable lookup without an area")
+				return nil, nil
+			},
+		}
+
+		v := NewValuationService(mockRepo)
+		v.now = func() time.Time { return now }
+
+		_, err := v.EstimateListingValue(context.Background(), 1)
+		if !errors.Is(err, ErrNotEnoughComparables) {
+			t.Errorf("Expected %v, received %v", ErrNotEnoughComparables, err)
+		}
+	})
+}
+
+func TestScoreComparable(t *testing.T) {
+	now := time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC)
+	subject := &domain.Listing{ID: 1, Beds: 3, Baths: 2, SqFt: 2000}
+
+	tests := []struct {
+		Name          string
+		Comp          *domain.Listing
+		ExpectedScore float64
+	}{
+		{
+			Name:          "Identical sold listing scores 1",
+			Comp:          &domain.Listing{Status: "sold", Beds: 3, Baths: 2, SqFt: 2000, SoldAt: &now},
+			ExpectedScore: 1,
+		},
+		{
+			Name:          "Identical active listing is discounted",
+			Comp:          &domain.Listing{Status: "active", Beds: 3, Baths: 2, SqFt: 2000, CreatedAt: now},
+			ExpectedScore: 0.8,
+		},
+		{
+			Name:          "Listing older than lookback loses recency",
+			Comp:          &domain.Listing{Status: "sold", Beds: 3, Baths: 2, SqFt: 2000, CreatedAt: now.AddDate(-2, 0, 0)},
+			ExpectedScore: 0.75,
+		},
+		{
+			Name:          "Withdrawn listing scores 0",
+			Comp:          &domain.Listing{Status: "withdrawn", Beds: 3, Baths: 2, SqFt: 2000, CreatedAt: now},
+			ExpectedScore: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			score := scoreComparable(subject, tt.Comp, now)
+
+			if roundTwoPlaces(score) != tt.ExpectedScore {
+				t.Errorf("Expected %v, received %v", tt.ExpectedScore, score)
+			}
+		})
+	}
+}
@@ -0,0 +1,541 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/events"
+	"server/internal/repo"
+	"server/internal/webhook"
+)
+
+const (
+	minWebhookSecretLength = 16
+
+	defaultWebhookDeliveryPageSize = 50
+	maxWebhookDeliveryPageSize     = 100
+
+	// Deliveries are claimed in batches and sent concurrently, so a batch
+	// takes at most webhookTimeout and never outlives its lease.
+	webhookBatchSize = 50
+	webhookLease     = 2 * time.Minute
+	webhookTimeout   = 10 * time.Second
+
+	// A delivery is retried after 30s, 1m, 2m, ... capped at 6h, and dead
+	// lettered after the last attempt fails.
+	maxWebhookAttempts    = 8
+	webhookInitialBackoff = 30 * time.Second
+	maxWebhookBackoff     = 6 * time.Hour
+)
+
+var ErrWebhookForbidden = errors.New("You do not manage this webhook")
+
+// WebhookPublisher queues an event for the webhook subscriptions of the
+// agents it concerns.
+type WebhookPublisher interface {
+	Publish(ctx context.Context, eventType string, data any, agentIds ...int)
+}
+
+// publishWebhook lets services run without webhooks wired in.
+func publishWebhook(
+	ctx context.Context,
+	publisher WebhookPublisher,
+	eventType string,
+	data any,
+	agentIds ...int,
+) {
+	if publisher != nil {
+		publisher.Publish(ctx, eventType, data, agentIds...)
+	}
+}
+
+type WebhookService struct {
+	webhookRepo repo.IWebhookRepo
+	userRepo    repo.IUserRepo
+	client      *http.Client
+	now         func() time.Time
+}
+
+func NewWebhookService(webhookRepo repo.IWebhookRepo, userRepo repo.IUserRepo) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		userRepo:    userRepo,
+		client:      webhook.NewClient(webhookTimeout),
+		now:         time.Now,
+	}
+}
+
+// validateWebhookURL turns away receivers that are plainly internal. Hosts
+// are only resolved at delivery time, where the client refuses internal
+// addresses again whatever the name resolves to then.
+func validateWebhookURL(value *string) error {
+	if value == nil {
+		return nil
+	}
+
+	if err := validateWebURL(value, "Webhook URL"); err != nil {
+		return err
+	}
+
+	parsed, _ := url.Parse(*value)
+	host := strings.ToLower(parsed.Hostname())
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("Webhook URL must point to a public address")
+	}
+
+	if ip, err := netip.ParseAddr(host); err == nil && webhook.IsBlockedIP(ip) {
+		return errors.New("Webhook URL must point to a public address")
+	}
+
+	return nil
+}
+
+func validateWebhookEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, errors.New("Please choose at least one event type")
+	}
+
+	seen := make(map[string]bool, len(eventTypes))
+	deduped := make([]string, 0, len(eventTypes))
+
+	for _, eventType := range eventTypes {
+		if !domain.WebhookEvents[eventType] {
+			return nil, fmt.Errorf("Unknown event type %q", eventType)
+		}
+
+		if !seen[eventType] {
+			seen[eventType] = true
+			deduped = append(deduped, eventType)
+		}
+	}
+
+	return deduped, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+// getManagedSubscription loads a subscription the user may manage: their
+// own, one for an agent they broker, or their brokerage's.
+func (s *WebhookService) getManagedSubscription(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	id int,
+) (*domain.WebhookSubscription, error) {
+	subscription, err := s.webhookRepo.GetSubscriptionById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription.BrokerageID != nil {
+		if !canManageBrokerage(currentUserCtx, *subscription.BrokerageID) {
+			return nil, ErrWebhookForbidden
+		}
+
+		return subscription, nil
+	}
+
+	ok, err := canManageAgent(ctx, s.userRepo, currentUserCtx, *subscription.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, ErrWebhookForbidden
+	}
+
+	return subscription, nil
+}
+
+// CreateSubscription is the only response that includes the signing secret.
+func (s *WebhookService) CreateSubscription(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	req *dto.CreateWebhookRequest,
+) (*domain.WebhookSubscription, error) {
+	if err := validateWebhookURL(&req.URL); err != nil {
+		return nil, err
+	}
+
+	eventTypes, err := validateWebhookEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, err
+		}
+	} else if utf8.RuneCountInString(secret) < minWebhookSecretLength {
+		return nil, errors.New("Secret must be at least 16 characters")
+	}
+
+	subscription := &domain.WebhookSubscription{
+		URL:        req.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+	}
+
+	if req.BrokerageID != nil {
+		if !canManageBrokerage(currentUserCtx, *req.BrokerageID) {
+			return nil, ErrBrokerageForbidden
+		}
+		subscription.BrokerageID = req.BrokerageID
+	} else {
+		subscription.AgentID = &currentUserCtx.UserID
+	}
+
+	return s.webhookRepo.CreateSubscription(ctx, subscription)
+}
+
+// GetSubscriptions lists the user's own subscriptions, plus their
+// brokerage's when they are a broker.
+func (s *WebhookService) GetSubscriptions(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+) ([]*domain.WebhookSubscription, error) {
+	var brokerageId *int
+	if currentUserCtx.Role == "broker" {
+		brokerageId = currentUserCtx.BrokerageID
+	}
+
+	subscriptions, err := s.webhookRepo.GetSubscriptions(ctx, currentUserCtx.UserID, brokerageId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+
+	return subscriptions, nil
+}
+
+func (s *WebhookService) UpdateSubscription(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	id int,
+	req *dto.UpdateWebhookRequest,
+) (*domain.WebhookSubscription, error) {
+	subscription, err := s.getManagedSubscription(ctx, currentUserCtx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := validateWebhookURL(req.URL); err != nil {
+			return nil, err
+		}
+		subscription.URL = *req.URL
+	}
+
+	if req.EventTypes != nil {
+		if subscription.EventTypes, err = validateWebhookEventTypes(req.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.IsActive != nil {
+		subscription.IsActive = *req.IsActive
+	}
+
+	updated, err := s.webhookRepo.UpdateSubscription(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	updated.Secret = ""
+
+	return updated, nil
+}
+
+func (s *WebhookService) DeleteSubscription(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	id int,
+) error {
+	if _, err := s.getManagedSubscription(ctx, currentUserCtx, id); err != nil {
+		return err
+	}
+
+	return s.webhookRepo.DeleteSubscription(ctx, id)
+}
+
+// GetDeliveries pages through a subscription's delivery log, newest first.
+func (s *WebhookService) GetDeliveries(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	subscriptionId int,
+	before int,
+	limit int,
+) (*domain.WebhookDeliveryPage, error) {
+	if limit == 0 {
+		limit = defaultWebhookDeliveryPageSize
+	}
+
+	if limit < 1 || limit > maxWebhookDeliveryPageSize {
+		return nil, errors.New("Limit must be between 1 and 100")
+	}
+
+	if _, err := s.getManagedSubscription(ctx, currentUserCtx, subscriptionId); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.webhookRepo.GetDeliveries(ctx, subscriptionId, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.WebhookDeliveryPage{Deliveries: deliveries}
+	if len(deliveries) > limit {
+		page.Deliveries = deliveries[:limit]
+		page.NextCursor = &page.Deliveries[limit-1].ID
+	}
+
+	return page, nil
+}
+
+// Redeliver queues a delivery again with a fresh set of attempts, including
+// ones that were dead lettered or already delivered.
+func (s *WebhookService) Redeliver(
+	ctx context.Context,
+	currentUserCtx *domain.ContextSessionData,
+	subscriptionId int,
+	deliveryId int,
+) (*domain.WebhookDelivery, error) {
+	if _, err := s.getManagedSubscription(ctx, currentUserCtx, subscriptionId); err != nil {
+		return nil, err
+	}
+
+	return s.webhookRepo.RedeliverDelivery(ctx, subscriptionId, deliveryId)
+}
+
+// Publish queues the event for the agents' and their brokerages'
+// subscriptions. Failures are logged rather than returned so a webhook
+// problem never fails the action that raised the event.
+func (s *WebhookService) Publish(ctx context.Context, eventType string, data any, agentIds ...int) {
+	if err := s.enqueue(ctx, "", eventType, data, agentIds...); err != nil {
+		slog.Warn(
+			"Failed to queue webhook event",
+			slog.Any("agent_ids", agentIds),
+			slog.String("event_type", eventType),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// Send delivers notifications routed to the webhook channel as
+// notification.created events.
+func (s *WebhookService) Send(ctx context.Context, notification *domain.Notification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.webhookRepo.EnqueueDeliveries(
+		ctx,
+		[]int{notification.UserID},
+		domain.WebhookEventNotificationCreated,
+		"",
+		payload,
+	)
+	return err
+}
+
+func (s *WebhookService) enqueue(
+	ctx context.Context,
+	dedupKey string,
+	eventType string,
+	data any,
+	agentIds ...int,
+) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.webhookRepo.EnqueueDeliveries(ctx, agentIds, eventType, dedupKey, payload)
+	return err
+}
+
+// favoriteEvent is the favorite.created webhook payload. User is only set
+// when the buyer shares their favorites with agents.
+type favoriteEvent struct {
+	ListingID   int                      `json:"listing_id"`
+	FavoritedAt time.Time                `json:"favorited_at"`
+	User        *domain.ListingFavoriter `json:"user"`
+}
+
+// OnListingPriceChanged, OnListingStatusChanged and OnFavoriteAdded queue
+// the event for the listing agent's subscriptions under its dedup key, so a
+// relayed repeat queues nothing.
+func (s *WebhookService) OnListingPriceChanged(
+	ctx context.Context,
+	change events.ListingPriceChanged,
+) error {
+	return s.enqueue(ctx, change.DedupKey, change.Name(), change, change.AgentID)
+}
+
+func (s *WebhookService) OnListingStatusChanged(
+	ctx context.Context,
+	change events.ListingStatusChanged,
+) error {
+	return s.enqueue(ctx, change.DedupKey, change.Name(), change, change.AgentID)
+}
+
+func (s *WebhookService) OnFavoriteAdded(ctx context.Context, favorite events.FavoriteAdded) error {
+	user, err := s.userRepo.GetUserById(ctx, favorite.UserID)
+	if err != nil {
+		return err
+	}
+
+	data := favoriteEvent{ListingID: favorite.ListingID, FavoritedAt: favorite.CreatedAt}
+	if user.ShareFavoritesWithAgents {
+		data.User = &domain.ListingFavoriter{
+			UserID:      user.ID,
+			FirstName:   user.FirstName,
+			LastName:    user.LastName,
+			Email:       user.Email,
+			FavoritedAt: favorite.CreatedAt,
+		}
+	}
+
+	return s.enqueue(ctx, favorite.DedupKey, favorite.Name(), data, favorite.AgentID)
+}
+
+// DeliverDue sends every delivery whose next attempt is due. It runs as a
+// scheduled job; delivery is at least once, so receivers should dedupe on
+// the X-Webhook-Id header.
+func (s *WebhookService) DeliverDue(ctx context.Context) error {
+	for {
+		deliveries, err := s.webhookRepo.ClaimDueDeliveries(ctx, webhookBatchSize, webhookLease)
+		if err != nil {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.deliver(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+
+		if len(deliveries) < webhookBatchSize || ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// webhookBackoff is the wait before the next attempt after attempts have
+// failed.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookInitialBackoff
+	for range attempts - 1 {
+		backoff *= 2
+		if backoff >= maxWebhookBackoff {
+			return maxWebhookBackoff
+		}
+	}
+
+	return backoff
+}
+
+func (s *WebhookService) deliver(ctx context.Context, delivery *domain.WebhookDelivery) {
+	statusCode, err := s.post(ctx, delivery)
+
+	now := s.now()
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = nil
+
+	switch {
+	case err == nil:
+		delivery.Status = domain.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= maxWebhookAttempts:
+		message := err.Error()
+		delivery.Status = domain.WebhookDeliveryDeadLetter
+		delivery.LastError = &message
+	default:
+		message := err.Error()
+		delivery.Status = domain.WebhookDeliveryPending
+		delivery.LastError = &message
+		delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+	}
+
+	if err := s.webhookRepo.RecordDeliveryAttempt(ctx, delivery); err != nil {
+		slog.Error(
+			"Failed to record webhook delivery",
+			slog.Int("delivery_id", delivery.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// post sends one signed delivery, returning the response status when the
+// receiver answered. Anything but a 2xx is a failure.
+func (s *WebhookService) post(ctx context.Context, delivery *domain.WebhookDelivery) (*int, error) {
+	body, err := json.Marshal(map[string]any{
+		"id":         delivery.ID,
+		"event":      delivery.EventType,
+		"created_at": delivery.CreatedAt,
+		"data":       delivery.Payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := s.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.HeaderID, strconv.Itoa(delivery.ID))
+	req.Header.Set(webhook.HeaderEvent, delivery.EventType)
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(delivery.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &resp.StatusCode, fmt.Errorf("Receiver responded with status %d", resp.StatusCode)
+	}
+
+	return &resp.StatusCode, nil
+}
@@ -0,0 +1,437 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"server/internal/api/dto"
+	"server/internal/domain"
+	"server/internal/events"
+	"server/internal/repo"
+	"server/internal/webhook"
+)
+
+func TestCreateWebhookSubscription(t *testing.T) {
+	brokerageId := 3
+	otherBrokerageId := 4
+
+	tests := []struct {
+		name        string
+		userCtx     *domain.ContextSessionData
+		req         dto.CreateWebhookRequest
+		expectedErr string
+	}{
+		{
+			name:    "Agent subscription with a generated secret",
+			userCtx: &domain.ContextSessionData{UserID: 2, Role: "agent"},
+			req: dto.CreateWebhookRequest{
+				URL:        "https://crm.example.com/hooks",
+				EventTypes: []string{domain.WebhookEventLeadCreated, domain.WebhookEventLeadCreated},
+			},
+		},
+		{
+			name:    "Broker subscribes their brokerage",
+			userCtx: &domain.ContextSessionData{UserID: 5, Role: "broker", BrokerageID: &brokerageId},
+			req: dto.CreateWebhookRequest{
+				URL:         "https://crm.example.com/hooks",
+				EventTypes:  []string{domain.WebhookEventOfferAccepted},
+				BrokerageID: &brokerageId,
+			},
+		},
+		{
+			name:    "Another brokerage",
+			userCtx: &domain.ContextSessionData{UserID: 5, Role: "broker", BrokerageID: &brokerageId},
+			req: dto.CreateWebhookRequest{
+				URL:         "https://crm.example.com/hooks",
+				EventTypes:  []string{domain.WebhookEventOfferAccepted},
+				BrokerageID: &otherBrokerageId,
+			},
+			expectedErr: ErrBrokerageForbidden.Error(),
+		},
+		{
+			name:    "Not a web URL",
+			userCtx: &domain.ContextSessionData{UserID: 2, Role: "agent"},
+			req: dto.CreateWebhookRequest{
+				URL:        "ftp://crm.example.com",
+				EventTypes: []string{domain.WebhookEventLeadCreated},
+			},
+			expectedErr: "Webhook URL must be an http or https URL",
+		},
+		{
+			name:    "Cloud metadata address",
+			userCtx: &domain.ContextSessionData{UserID: 2, Role: "agent"},
+			req: dto.CreateWebhookRequest{
+				URL:        "http://169.254.169.254/latest/meta-data",
+				EventTypes: []string{domain.WebhookEventLeadCreated},
+			},
+			expectedErr: "Webhook URL must point to a public address",
+		},
+		{
+			name:    "Localhost",
+			userCtx: &domain.ContextSessionData{UserID: 2, Role: "agent"},
+			req: dto.CreateWebhookRequest{
+				URL:        "http://localhost:8080/hooks",
+				EventTypes: []string{domain.WebhookEventLeadCreated},
+			},
+			expectedErr: "Webhook URL must point to a public address",
+		},
+		{
+			name:    "Unknown event type",
+			userCtx: &domain.ContextSessionData{UserID: 2, Role: "agent"},
+			req: dto.CreateWebhookRequest{
+				URL:        "https://crm.example.com/hooks",
+				EventTypes: []string{"listing.deleted"},
+			},
+			expectedErr: `Unknown event type "listing.deleted"`,
+		},
+		{
+			name:    "Short secret",
+			userCtx: &domain.ContextSessionData{UserID: 2, Role: "agent"},
+			req: dto.CreateWebhookRequest{
+				URL:        "https://crm.example.com/hooks",
+				EventTypes: []string{domain.WebhookEventLeadCreated},
+				Secret:     "hunter2",
+			},
+			expectedErr: "Secret must be at least 16 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookRepo := &repo.WebhookRepoMock{
+				CreateSubscriptionFunc: func(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+					return subscription, nil
+				},
+			}
+
+			s := NewWebhookService(webhookRepo, &repo.UserRepoMock{})
+
+			subscription, err := s.CreateSubscription(context.Background(), tt.userCtx, &tt.req)
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if (subscription.AgentID == nil) == (subscription.BrokerageID == nil) {
+				t.Errorf("Expected exactly one owner, received %+v", subscription)
+			}
+
+			if len(subscription.EventTypes) != 1 {
+				t.Errorf("Expected duplicate event types to be dropped, received %v", subscription.EventTypes)
+			}
+
+			if len(subscription.Secret) != 64 {
+				t.Errorf("Expected a generated secret, received %q", subscription.Secret)
+			}
+		})
+	}
+}
+
+func TestDeliverDue(t *testing.T) {
+	now := time.Date(2025, 12, 22, 12, 0, 0, 0, time.UTC)
+	secret := "0123456789abcdef"
+
+	tests := []struct {
+		name             string
+		status           int
+		closed           bool
+		guarded          bool
+		priorAttempts    int
+		expectedStatus   string
+		expectedCode     *int
+		expectedNextWait time.Duration
+	}{
+		{
+			name:           "Delivered",
+			status:         http.StatusNoContent,
+			expectedStatus: domain.WebhookDeliveryDelivered,
+			expectedCode:   intPtr(http.StatusNoContent),
+		},
+		{
+			name:             "Server error is retried with backoff",
+			status:           http.StatusInternalServerError,
+			priorAttempts:    2,
+			expectedStatus:   domain.WebhookDeliveryPending,
+			expectedCode:     intPtr(http.StatusInternalServerError),
+			expectedNextWait: 2 * time.Minute,
+		},
+		{
+			name:             "Unreachable receiver is retried",
+			closed:           true,
+			expectedStatus:   domain.WebhookDeliveryPending,
+			expectedNextWait: 30 * time.Second,
+		},
+		{
+			name:             "Internal receiver is refused",
+			status:           http.StatusNoContent,
+			guarded:          true,
+			expectedStatus:   domain.WebhookDeliveryPending,
+			expectedNextWait: 30 * time.Second,
+		},
+		{
+			name:           "Last attempt is dead lettered",
+			status:         http.StatusBadGateway,
+			priorAttempts:  maxWebhookAttempts - 1,
+			expectedStatus: domain.WebhookDeliveryDeadLetter,
+			expectedCode:   intPtr(http.StatusBadGateway),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received []map[string]any
+
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+
+				if err := webhook.Verify(secret, r.Header, body, 5*time.Minute, now); err != nil {
+					t.Errorf("Expected a valid signature, received %v", err)
+				}
+
+				if r.Header.Get(webhook.HeaderEvent) != domain.WebhookEventLeadCreated {
+					t.Errorf("Expected %v, received %v", domain.WebhookEventLeadCreated, r.Header.Get(webhook.HeaderEvent))
+				}
+
+				var envelope map[string]any
+				json.Unmarshal(body, &envelope)
+				received = append(received, envelope)
+
+				w.WriteHeader(tt.status)
+			}))
+			defer receiver.Close()
+
+			if tt.closed {
+				receiver.Close()
+			}
+
+			claimed := false
+			var recorded *domain.WebhookDelivery
+
+			webhookRepo := &repo.WebhookRepoMock{
+				ClaimDueDeliveriesFunc: func(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+					if claimed {
+						return nil, nil
+					}
+					claimed = true
+
+					return []*domain.WebhookDelivery{{
+						ID:             11,
+						SubscriptionID: 4,
+						EventType:      domain.WebhookEventLeadCreated,
+						Payload:        json.RawMessage(`{"id":9,"name":"Ada"}`),
+						Status:         domain.WebhookDeliveryPending,
+						Attempts:       tt.priorAttempts,
+						URL:            receiver.URL,
+						Secret:         secret,
+					}}, nil
+				},
+				RecordDeliveryAttemptFunc: func(ctx context.Context, delivery *domain.WebhookDelivery) error {
+					recorded = delivery
+					return nil
+				},
+			}
+
+			s := NewWebhookService(webhookRepo, &repo.UserRepoMock{})
+			s.now = func() time.Time { return now }
+			if !tt.guarded {
+				// the test receiver listens on loopback, which the default client refuses
+				s.client = receiver.Client()
+			}
+
+			if err := s.DeliverDue(context.Background()); err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if recorded == nil {
+				t.Fatal("Expected the attempt to be recorded")
+			}
+
+			if recorded.Status != tt.expectedStatus {
+				t.Errorf("Expected %v, received %v", tt.expectedStatus, recorded.Status)
+			}
+
+			if recorded.Attempts != tt.priorAttempts+1 {
+				t.Errorf("Expected %v, received %v", tt.priorAttempts+1, recorded.Attempts)
+			}
+
+			if (recorded.LastStatusCode == nil) != (tt.expectedCode == nil) ||
+				(tt.expectedCode != nil && *recorded.LastStatusCode != *tt.expectedCode) {
+				t.Errorf("Expected status code %v, received %v", tt.expectedCode, recorded.LastStatusCode)
+			}
+
+			if tt.expectedStatus == domain.WebhookDeliveryDelivered {
+				if recorded.DeliveredAt == nil || recorded.LastError != nil {
+					t.Errorf("Expected a clean delivery, received %+v", recorded)
+				}
+
+				if len(received) != 1 || received[0]["event"] != domain.WebhookEventLeadCreated {
+					t.Errorf("Expected one lead.created envelope, received %v", received)
+				}
+
+				if data, _ := received[0]["data"].(map[string]any); data["name"] != "Ada" {
+					t.Errorf("Expected the payload under data, received %v", received[0])
+				}
+			} else if recorded.LastError == nil {
+				t.Errorf("Expected the failure to be recorded")
+			}
+
+			if tt.guarded && len(received) != 0 {
+				t.Errorf("Expected nothing to reach an internal receiver, received %v", received)
+			}
+
+			if tt.expectedNextWait > 0 && !recorded.NextAttemptAt.Equal(now.Add(tt.expectedNextWait)) {
+				t.Errorf("Expected %v, received %v", now.Add(tt.expectedNextWait), recorded.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestWebhookSubscriptionOwnership(t *testing.T) {
+	agentId := 2
+	brokerageId := 3
+
+	webhookRepo := &repo.WebhookRepoMock{
+		GetSubscriptionByIdFunc: func(ctx context.Context, id int) (*domain.WebhookSubscription, error) {
+			return &domain.WebhookSubscription{ID: id, AgentID: &agentId}, nil
+		},
+		DeleteSubscriptionFunc: func(ctx context.Context, id int) error {
+			return nil
+		},
+	}
+	userRepo := &repo.UserRepoMock{
+		GetUserByIdFunc: func(ctx context.Context, id int) (*domain.User, error) {
+			return &domain.User{ID: id, BrokerageID: &brokerageId}, nil
+		},
+	}
+
+	s := NewWebhookService(webhookRepo, userRepo)
+
+	tests := []struct {
+		name        string
+		userCtx     *domain.ContextSessionData
+		expectedErr error
+	}{
+		{name: "Owner", userCtx: &domain.ContextSessionData{UserID: agentId, Role: "agent"}},
+		{
+			name:    "Agent's broker",
+			userCtx: &domain.ContextSessionData{UserID: 5, Role: "broker", BrokerageID: &brokerageId},
+		},
+		{
+			name:        "Another agent",
+			userCtx:     &domain.ContextSessionData{UserID: 6, Role: "agent"},
+			expectedErr: ErrWebhookForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.DeleteSubscription(context.Background(), tt.userCtx, 1)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestWebhookEventSubscribers(t *testing.T) {
+	favorite := events.FavoriteAdded{
+		FavoriteID: 5,
+		UserID:     8,
+		ListingID:  7,
+		AgentID:    2,
+		CreatedAt:  time.Date(2025, 12, 26, 9, 0, 0, 0, time.UTC),
+		DedupKey:   "favorite.created:5",
+	}
+
+	tests := []struct {
+		name          string
+		event         events.Event
+		shares        bool
+		expectedKey   string
+		expectedAgent int
+		expectedUser  bool
+	}{
+		{
+			name:          "Favorite hides a buyer who does not share favorites",
+			event:         favorite,
+			expectedKey:   "favorite.created:5",
+			expectedAgent: 2,
+		},
+		{
+			name:          "Favorite names a buyer who shares favorites",
+			event:         favorite,
+			shares:        true,
+			expectedKey:   "favorite.created:5",
+			expectedAgent: 2,
+			expectedUser:  true,
+		},
+		{
+			name: "Price change goes to the listing agent",
+			event: events.ListingPriceChanged{
+				ListingID: 7,
+				AgentID:   3,
+				OldPrice:  475000,
+				NewPrice:  440000,
+				DedupKey:  "listing.price_changed:7:1",
+			},
+			expectedKey:   "listing.price_changed:7:1",
+			expectedAgent: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queuedFor []int
+			var queuedType, queuedKey string
+			var queued map[string]any
+
+			webhookRepo := &repo.WebhookRepoMock{
+				EnqueueDeliveriesFunc: func(ctx context.Context, agentIds []int, eventType string, dedupKey string, payload []byte) (int, error) {
+					queuedFor, queuedType, queuedKey = agentIds, eventType, dedupKey
+					return 1, json.Unmarshal(payload, &queued)
+				},
+			}
+			userRepo := &repo.UserRepoMock{
+				GetUserByIdFunc: func(ctx context.Context, id int) (*domain.User, error) {
+					return &domain.User{ID: id, FirstName: "Dana", ShareFavoritesWithAgents: tt.shares}, nil
+				},
+			}
+
+			s := NewWebhookService(webhookRepo, userRepo)
+
+			bus := events.NewBus()
+			events.Subscribe(bus, "webhooks", s.OnListingPriceChanged)
+			events.Subscribe(bus, "webhooks", s.OnFavoriteAdded)
+
+			if err := bus.Publish(context.Background(), tt.event); err != nil {
+				t.Fatalf("Expected success, received %v", err)
+			}
+
+			if len(queuedFor) != 1 || queuedFor[0] != tt.expectedAgent {
+				t.Errorf("Expected agent %d, received %v", tt.expectedAgent, queuedFor)
+			}
+
+			if queuedType != tt.event.Name() || queuedKey != tt.expectedKey {
+				t.Errorf("Expected %s %s, received %s %s", tt.event.Name(), tt.expectedKey, queuedType, queuedKey)
+			}
+
+			if tt.event.Name() == events.NameFavoriteAdded {
+				if user := queued["user"]; (user != nil) != tt.expectedUser {
+					t.Errorf("Expected user shared %v, received %v", tt.expectedUser, user)
+				}
+			}
+		})
+	}
+}
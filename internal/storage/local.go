@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore keeps uploads on disk under Dir and serves them below BaseURL.
+// BaseURL may be absolute when a proxy or CDN serves the files instead.
+type LocalStore struct {
+	dir     string
+	baseURL string
+	path    string
+}
+
+func NewLocalStore(cfg Config) *LocalStore {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+
+	urlPath := baseURL
+	if parsed, err := url.Parse(baseURL); err == nil {
+		urlPath = parsed.Path
+	}
+
+	return &LocalStore{dir: cfg.Dir, baseURL: baseURL, path: urlPath}
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, body io.Reader) (string, error) {
+	filePath := filepath.Join(s.dir, filepath.FromSlash(path.Clean("/"+key)))
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		os.Remove(filePath)
+		return "", err
+	}
+
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	return s.baseURL + path.Clean("/"+key), nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, fileURL string) error {
+	key, ok := strings.CutPrefix(fileURL, s.baseURL+"/")
+	if !ok {
+		return nil
+	}
+
+	err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(path.Clean("/"+key))))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// Path is the URL path Handler must be mounted at.
+func (s *LocalStore) Path() string {
+	return s.path
+}
+
+// Handler serves stored files below Path.
+func (s *LocalStore) Handler() http.Handler {
+	return http.StripPrefix(s.path, http.FileServer(http.Dir(s.dir)))
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	s := NewLocalStore(Config{Dir: t.TempDir(), BaseURL: "/uploads/"})
+	ctx := context.Background()
+
+	url, err := s.Put(ctx, "headshots/../../1.png", strings.NewReader("image"))
+	if err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	if url != "/uploads/1.png" {
+		t.Errorf("Expected %v, received %v", "/uploads/1.png", url)
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "image" {
+		t.Errorf("Expected stored file, received %d %q", rec.Code, rec.Body.String())
+	}
+
+	if err := s.Delete(ctx, url); err != nil {
+		t.Fatalf("Expected success, received %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected %d, received %d", http.StatusNotFound, rec.Code)
+	}
+
+	if err := s.Delete(ctx, "https://elsewhere.example/1.png"); err != nil {
+		t.Errorf("Expected unknown URL to be ignored, received %v", err)
+	}
+}
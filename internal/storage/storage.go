@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// Store saves uploaded files and returns the public URL they are served from.
+type Store interface {
+	Put(ctx context.Context, key string, body io.Reader) (string, error)
+	// Delete removes the file behind a URL returned by Put. Unknown URLs are
+	// ignored.
+	Delete(ctx context.Context, url string) error
+}
+
+type Config struct {
+	Dir     string
+	BaseURL string
+}
+
+const (
+	defaultDir     = "uploads"
+	defaultBaseURL = "/uploads"
+)
+
+// ConfigFromEnv reads UPLOAD_DIR and UPLOAD_BASE_URL, defaulting to an
+// uploads directory served by the API itself.
+func ConfigFromEnv() Config {
+	cfg := Config{Dir: defaultDir, BaseURL: defaultBaseURL}
+
+	if dir := os.Getenv("UPLOAD_DIR"); dir != "" {
+		cfg.Dir = dir
+	}
+
+	if baseURL := os.Getenv("UPLOAD_BASE_URL"); baseURL != "" {
+		cfg.BaseURL = baseURL
+	}
+
+	return cfg
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestIsBlockedIP(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected bool
+	}{
+		{address: "127.0.0.1", expected: true},
+		{address: "::1", expected: true},
+		{address: "10.1.2.3", expected: true},
+		{address: "172.16.0.9", expected: true},
+		{address: "192.168.1.1", expected: true},
+		{address: "169.254.169.254", expected: true},
+		{address: "fe80::1", expected: true},
+		{address: "fd00::1", expected: true},
+		{address: "0.0.0.0", expected: true},
+		{address: "::ffff:127.0.0.1", expected: true},
+		{address: "93.184.216.34", expected: false},
+		{address: "2606:4700::1111", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if blocked := IsBlockedIP(netip.MustParseAddr(tt.address)); blocked != tt.expected {
+				t.Errorf("Expected %v, received %v", tt.expected, blocked)
+			}
+		})
+	}
+}
+
+func TestClientRefusesInternalReceivers(t *testing.T) {
+	reached := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	defer receiver.Close()
+
+	_, err := NewClient(time.Second).Get(receiver.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Expected %v, received %v", ErrBlockedAddress, err)
+	}
+
+	if reached {
+		t.Error("Expected the loopback receiver not to be reached")
+	}
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	client := NewClient(time.Second)
+
+	if err := client.CheckRedirect(&http.Request{}, nil); !errors.Is(err, http.ErrUseLastResponse) {
+		t.Errorf("Expected %v, received %v", http.ErrUseLastResponse, err)
+	}
+}
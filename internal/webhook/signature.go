@@ -0,0 +1,66 @@
+// Package webhook signs outbound webhook requests so receivers can check
+// that they came from us and were not replayed.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature carries "sha256=" followed by the hex HMAC-SHA256 of
+	// the timestamp, a dot and the raw body, keyed by the subscription secret.
+	HeaderSignature = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+var (
+	ErrMissingSignature = errors.New("webhook signature is missing")
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrStaleTimestamp   = errors.New("webhook timestamp is outside the tolerance")
+)
+
+// Sign returns the signature header value for body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers on a received request against body.
+// Requests signed more than tolerance away from now are rejected.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	signature := header.Get(HeaderSignature)
+	if signature == "" || !strings.HasPrefix(signature, signaturePrefix) {
+		return ErrMissingSignature
+	}
+
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrMissingSignature
+	}
+
+	if math.Abs(now.Sub(time.Unix(timestamp, 0)).Seconds()) > tolerance.Seconds() {
+		return ErrStaleTimestamp
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
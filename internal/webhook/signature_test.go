@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2025, 12, 22, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"event":"lead.created"}`)
+
+	signed := func(secret string, at time.Time) http.Header {
+		header := http.Header{}
+		header.Set(HeaderTimestamp, strconv.FormatInt(at.Unix(), 10))
+		header.Set(HeaderSignature, Sign(secret, at.Unix(), body))
+		return header
+	}
+
+	tests := []struct {
+		name        string
+		header      http.Header
+		body        []byte
+		expectedErr error
+	}{
+		{
+			name:   "Valid signature",
+			header: signed("secret", now),
+			body:   body,
+		},
+		{
+			name:        "Wrong secret",
+			header:      signed("other", now),
+			body:        body,
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name:        "Tampered body",
+			header:      signed("secret", now),
+			body:        []byte(`{"event":"offer.accepted"}`),
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name:        "Replayed outside tolerance",
+			header:      signed("secret", now.Add(-10*time.Minute)),
+			body:        body,
+			expectedErr: ErrStaleTimestamp,
+		},
+		{
+			name:        "Unsigned",
+			header:      http.Header{},
+			body:        body,
+			expectedErr: ErrMissingSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify("secret", tt.header, tt.body, 5*time.Minute, now)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected %v, received %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 var (
 	pongWait     = 10 * time.Second
 	pingInterval = (pongWait * 9) / 10
+	egressBuffer = 16
 )
 
 type ClientList map[*WSClient]bool
@@ -30,7 +31,7 @@ func NewWSClient(conn *websocket.Conn, userID int, userRole string, manager *Man
 		UserRole:   userRole,
 		Manager:    manager,
 		Connection: conn,
-		Egress:     make(chan Event),
+		Egress:     make(chan Event, egressBuffer),
 	}
 }
 
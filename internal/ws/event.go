@@ -2,6 +2,8 @@ package ws
 
 import (
 	"encoding/json"
+
+	"server/internal/domain"
 )
 
 type Event struct {
@@ -9,31 +11,22 @@ type Event struct {
 	Payload json.RawMessage `json:"payload"`
 }
 
-type ListingEvent struct {
-	ListingID int    `json:"listing_id"`
-	Address   string `json:"address"`
-}
-
-type FavoritedListingEvent struct {
-	ListingEvent
-}
-
-type PriceDropEvent struct {
-	Price string `json:"price"`
-	ListingEvent
-}
-
-type StatusChangeEvent struct {
-	Status string `json:"status"`
-	ListingEvent
-}
-
-type Notification struct {
-	Message string `json:"message"`
-}
+// EventHandler handles an event a client sends over its connection.
+type EventHandler func(event Event, client *WSClient) error
 
 const (
-	EventFavoritedListingNotification = "favorited_listing_notification"
-	EventPriceDropNotification        = "price_drop_notification"
-	EventStatusChangeNotification     = "status_changed_notification"
+	// Server-pushed only. Messages are sent over REST, and favorite, price
+	// and status notifications are raised by the outbox relay.
+	EventNewMessage   = domain.EventNewMessage
+	EventMessagesRead = domain.EventMessagesRead
+
+	// Server-pushed to the listing agent as buyers save and unsave listings
+	EventListingFavorited   = "listing_favorited"
+	EventListingUnfavorited = "listing_unfavorited"
 )
+
+// ListingFavoriteEvent leaves out who saved the listing, since buyers can
+// choose not to share their favorites with agents.
+type ListingFavoriteEvent struct {
+	ListingID int `json:"listing_id"`
+}
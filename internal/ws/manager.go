@@ -2,7 +2,9 @@ package ws
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
 	"sync"
 
@@ -10,7 +12,6 @@ import (
 
 	"server/internal/domain"
 	"server/internal/server/middleware"
-	"server/internal/service"
 	"server/util"
 )
 
@@ -21,28 +22,15 @@ var websocketUpgrader = &websocket.Upgrader{
 
 type Manager struct {
 	sync.RWMutex
-	Clients             ClientList
-	Handlers            map[string]EventHandler
-	NotificationService *service.NotificationService
+	Clients  ClientList
+	Handlers map[string]EventHandler
 }
 
-func NewManager(
-	notificationService *service.NotificationService,
-) *Manager {
-	m := &Manager{
-		Clients:             make(ClientList),
-		Handlers:            make(map[string]EventHandler),
-		NotificationService: notificationService,
+func NewManager() *Manager {
+	return &Manager{
+		Clients:  make(ClientList),
+		Handlers: make(map[string]EventHandler),
 	}
-
-	m.setupEventHandlers()
-	return m
-}
-
-func (m *Manager) setupEventHandlers() {
-	m.Handlers[EventFavoritedListingNotification] = handleFavoritedListing
-	m.Handlers[EventPriceDropNotification] = handlePriceDrop
-	m.Handlers[EventStatusChangeNotification] = handleStatusChange
 }
 
 func (m *Manager) StartWSConn(w http.ResponseWriter, r *http.Request) {
@@ -94,3 +82,30 @@ func (m *Manager) RemoveClient(client *WSClient) {
 		delete(m.Clients, client)
 	}
 }
+
+// PushToUser sends an event to every connection the user has open. Slow
+// clients drop the event rather than block the caller.
+func (m *Manager) PushToUser(userId int, eventType string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("Failed to marshal push payload", slog.String("error", err.Error()))
+		return
+	}
+
+	event := Event{Type: eventType, Payload: data}
+
+	m.RLock()
+	defer m.RUnlock()
+
+	for c := range m.Clients {
+		if c.UserId != userId {
+			continue
+		}
+
+		select {
+		case c.Egress <- event:
+		default:
+			slog.Warn("Dropped push to slow client", slog.Int("user_id", userId))
+		}
+	}
+}
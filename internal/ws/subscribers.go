@@ -0,0 +1,24 @@
+package ws
+
+import (
+	"context"
+
+	"server/internal/events"
+)
+
+// Subscribe fans favorite activity out to the listing agent's open
+// connections so their dashboard stays live.
+func (m *Manager) Subscribe(bus *events.Bus) {
+	events.Subscribe(bus, "ws", m.onFavoriteAdded)
+	events.Subscribe(bus, "ws", m.onFavoriteRemoved)
+}
+
+func (m *Manager) onFavoriteAdded(ctx context.Context, event events.FavoriteAdded) error {
+	m.PushToUser(event.AgentID, EventListingFavorited, ListingFavoriteEvent{ListingID: event.ListingID})
+	return nil
+}
+
+func (m *Manager) onFavoriteRemoved(ctx context.Context, event events.FavoriteRemoved) error {
+	m.PushToUser(event.AgentID, EventListingUnfavorited, ListingFavoriteEvent{ListingID: event.ListingID})
+	return nil
+}